@@ -2,29 +2,165 @@ package config
 
 import (
 	"log"
+	"strings"
 
 	"github.com/syrlramadhan/cashier-app/models"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 func RunMigration() {
+	// Must run before AutoMigrate changes the column types
+	convertMoneyColumns()
+
 	err := DB.AutoMigrate(
 		&models.User{},
 		&models.Category{},
 		&models.Product{},
+		&models.ProductPrice{},
+		&models.ProductVariantGroup{},
+		&models.ProductVariantOption{},
+		&models.ProductVariant{},
+		&models.ModifierGroup{},
+		&models.ModifierOption{},
+		&models.Ingredient{},
+		&models.RecipeItem{},
+		&models.StockMovement{},
+		&models.Supplier{},
+		&models.PurchaseOrder{},
+		&models.PurchaseOrderItem{},
+		&models.GoodsReceipt{},
+		&models.GoodsReceiptItem{},
+		&models.StockCount{},
+		&models.StockCountItem{},
+		&models.StockAlert{},
+		&models.WasteEntry{},
+		&models.WasteEntryIngredient{},
 		&models.Transaction{},
 		&models.TransactionItem{},
+		&models.TransactionItemModifier{},
+		&models.TransactionItemIngredient{},
+		&models.TransactionPayment{},
+		&models.TransactionDiscount{},
+		&models.Refund{},
+		&models.RefundItem{},
 		&models.Setting{},
+		&models.IdempotencyKey{},
+		&models.Sequence{},
+		&models.Promotion{},
+		&models.Voucher{},
+		&models.VoucherRedemption{},
+		&models.Table{},
+		&models.KitchenTicket{},
+		&models.KitchenTicketItem{},
 	)
 
 	if err != nil {
 		log.Fatal("Migration failed:", err)
 	}
 
+	backfillTransactionPayments()
+
 	log.Println("Database migration completed successfully")
 
 	// Seed default data
 	seedDefaultData()
+
+	backfillStockMovements()
+}
+
+// convertMoneyColumns rounds amounts stored by older versions as floating
+// point (half-up to whole rupiah) so AutoMigrate can turn the columns into
+// integer models.Money columns without losing or truncating values.
+func convertMoneyColumns() {
+	moneyColumns := []struct {
+		model   interface{}
+		columns []string
+	}{
+		{&models.Product{}, []string{"price"}},
+		{&models.Transaction{}, []string{"subtotal", "tax", "total"}},
+		{&models.TransactionItem{}, []string{"price", "subtotal"}},
+	}
+
+	migrator := DB.Migrator()
+	for _, table := range moneyColumns {
+		if !migrator.HasTable(table.model) {
+			continue
+		}
+
+		columnTypes, err := migrator.ColumnTypes(table.model)
+		if err != nil {
+			log.Fatal("Failed to read column types:", err)
+		}
+
+		for _, columnType := range columnTypes {
+			if !containsString(table.columns, columnType.Name()) {
+				continue
+			}
+
+			switch strings.ToLower(columnType.DatabaseTypeName()) {
+			case "double", "float", "decimal":
+				column := columnType.Name()
+				err := DB.Model(table.model).Session(&gorm.Session{AllowGlobalUpdate: true}).Unscoped().
+					UpdateColumn(column, gorm.Expr("FLOOR("+column+" + 0.5)")).Error
+				if err != nil {
+					log.Fatal("Failed to convert money column "+column+":", err)
+				}
+				log.Printf("Converted money column %s to whole rupiah", column)
+			}
+		}
+	}
+}
+
+// backfillTransactionPayments gives transactions created before split payments
+// existed a single payment line, so payment reports only need payment lines.
+func backfillTransactionPayments() {
+	result := DB.Exec(`INSERT INTO transaction_payments (transaction_id, method, amount, created_at)
+		SELECT t.id, t.payment_method, t.total, t.created_at FROM transactions t
+		WHERE t.status IN ('completed', 'cancelled')
+		AND NOT EXISTS (SELECT 1 FROM transaction_payments p WHERE p.transaction_id = t.id)`)
+	if result.Error != nil {
+		log.Fatal("Failed to backfill transaction payments:", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Backfilled %d transaction payments", result.RowsAffected)
+	}
+}
+
+// backfillStockMovements books the stock of products and variants that have
+// no movements yet (created before the ledger, or just seeded) as an opening
+// movement, so the ledger of every product adds up to its stock.
+func backfillStockMovements() {
+	result := DB.Exec(`INSERT INTO stock_movements (product_id, quantity, balance, reason, note, created_at)
+		SELECT p.id, p.stock, p.stock, 'opening', 'stock before the ledger', NOW() FROM products p
+		WHERE p.deleted_at IS NULL AND p.stock <> 0
+		AND NOT EXISTS (SELECT 1 FROM stock_movements m WHERE m.product_id = p.id AND m.variant_id IS NULL)`)
+	if result.Error != nil {
+		log.Fatal("Failed to backfill product stock movements:", result.Error)
+	}
+	backfilled := result.RowsAffected
+
+	result = DB.Exec(`INSERT INTO stock_movements (product_id, variant_id, quantity, balance, reason, note, created_at)
+		SELECT v.product_id, v.id, v.stock, v.stock, 'opening', 'stock before the ledger', NOW() FROM product_variants v
+		WHERE v.deleted_at IS NULL AND v.track_stock = TRUE AND v.stock <> 0
+		AND NOT EXISTS (SELECT 1 FROM stock_movements m WHERE m.variant_id = v.id)`)
+	if result.Error != nil {
+		log.Fatal("Failed to backfill variant stock movements:", result.Error)
+	}
+	backfilled += result.RowsAffected
+
+	if backfilled > 0 {
+		log.Printf("Backfilled %d opening stock movements", backfilled)
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
 }
 
 func seedDefaultData() {
@@ -50,9 +186,9 @@ func seedDefaultData() {
 	DB.Model(&models.Category{}).Count(&categoryCount)
 	if categoryCount == 0 {
 		categories := []models.Category{
-			{Name: "Makanan"},
-			{Name: "Minuman"},
-			{Name: "Snack"},
+			{Name: "Makanan", Station: "kitchen"},
+			{Name: "Minuman", Station: "bar"},
+			{Name: "Snack", Station: "kitchen"},
 		}
 		DB.Create(&categories)
 		log.Println("Default categories seeded")
@@ -122,34 +258,60 @@ func seedDefaultData() {
 		log.Println("Default products seeded")
 	}
 
-	// Seed default settings
-	var settingCount int64
-	DB.Model(&models.Setting{}).Count(&settingCount)
-	if settingCount == 0 {
-		defaultSettings := []models.Setting{
-			// Store settings
-			{Key: "store_name", Value: "Kasir POS"},
-			{Key: "store_address", Value: "Jl. Contoh No. 123, Jakarta"},
-			{Key: "store_phone", Value: "021-12345678"},
-			{Key: "store_logo", Value: ""},
-			// Payment settings
-			{Key: "tax_rate", Value: "11"},
-			{Key: "currency", Value: "IDR"},
-			{Key: "payment_cash_enabled", Value: "true"},
-			{Key: "payment_card_enabled", Value: "true"},
-			{Key: "payment_qris_enabled", Value: "true"},
-			// Printer settings
-			{Key: "printer_type", Value: "thermal"},
-			{Key: "receipt_footer", Value: "Terima kasih atas kunjungan Anda!"},
-			{Key: "auto_print", Value: "true"},
-			{Key: "print_logo", Value: "true"},
-			{Key: "print_duplicate", Value: "false"},
-			// General settings
-			{Key: "enable_sound", Value: "true"},
-			{Key: "enable_notifications", Value: "true"},
-			{Key: "auto_logout", Value: "30"},
-		}
-		DB.Create(&defaultSettings)
+	// Seed default settings (missing keys are added on every start so new
+	// settings reach existing installations too)
+	defaultSettings := []models.Setting{
+		// Store settings
+		{Key: "store_name", Value: "Kasir POS"},
+		{Key: "store_address", Value: "Jl. Contoh No. 123, Jakarta"},
+		{Key: "store_phone", Value: "021-12345678"},
+		{Key: "store_logo", Value: ""},
+		{Key: "outlet_code", Value: "01"},
+		// Payment settings
+		{Key: "tax_rate", Value: "11"},
+		{Key: "tax_inclusive", Value: "false"},
+		{Key: "currency", Value: "IDR"},
+		{Key: "payment_cash_enabled", Value: "true"},
+		{Key: "payment_card_enabled", Value: "true"},
+		{Key: "payment_qris_enabled", Value: "true"},
+		{Key: "cash_rounding", Value: "0"},       // round cash totals to the nearest 100/500 rupiah, 0 disables
+		{Key: "service_charge_rate", Value: "0"}, // percent charged on dine-in orders before tax, 0 disables
+		// Discount settings (largest manual discount in percent; admins are not capped)
+		{Key: "max_manual_discount_cashier", Value: "10"},
+		{Key: "max_manual_discount_manager", Value: "50"},
+		// Receipt numbering ({prefix}, {outlet}, {date}, {seq})
+		{Key: "transaction_code_prefix", Value: "TRX"},
+		{Key: "transaction_code_format", Value: "{prefix}-{date}-{seq}"},
+		{Key: "transaction_code_digits", Value: "4"},
+		{Key: "refund_code_prefix", Value: "RFD"},
+		{Key: "held_code_prefix", Value: "HLD"},
+		{Key: "tab_code_prefix", Value: "TAB"},
+		{Key: "purchase_order_code_prefix", Value: "PO"},
+		{Key: "stock_count_code_prefix", Value: "SC"},
+		// Held orders expire after this many minutes without changes, 0 disables
+		{Key: "held_order_expiry_minutes", Value: "120"},
+		// Products are low on stock below this unless they set their own min_stock
+		{Key: "low_stock_threshold", Value: "10"},
+		// Reorder suggestions: days of sales to average, and days of sales to order on top of the supplier lead time
+		{Key: "reorder_window_days", Value: "30"},
+		{Key: "reorder_cover_days", Value: "7"},
+		// Printer settings
+		{Key: "printer_type", Value: "thermal"},
+		{Key: "receipt_footer", Value: "Terima kasih atas kunjungan Anda!"},
+		{Key: "auto_print", Value: "true"},
+		{Key: "print_logo", Value: "true"},
+		{Key: "print_duplicate", Value: "false"},
+		// General settings
+		{Key: "enable_sound", Value: "true"},
+		{Key: "enable_notifications", Value: "true"},
+		{Key: "auto_logout", Value: "30"},
+	}
+	seeded := 0
+	for _, setting := range defaultSettings {
+		result := DB.Where(models.Setting{Key: setting.Key}).FirstOrCreate(&setting)
+		seeded += int(result.RowsAffected)
+	}
+	if seeded > 0 {
 		log.Println("Default settings seeded")
 	}
 }
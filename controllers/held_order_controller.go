@@ -0,0 +1,312 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/services"
+)
+
+type HeldOrderController struct {
+	heldOrderService *services.HeldOrderService
+}
+
+func NewHeldOrderController(heldOrderService *services.HeldOrderService) *HeldOrderController {
+	return &HeldOrderController{heldOrderService: heldOrderService}
+}
+
+// GetHeldOrders godoc
+// @Summary Get held orders
+// @Description Get the parked orders of the current cashier (managers and admins see all)
+// @Tags held-orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.APIResponse{data=[]dto.TransactionResponse}
+// @Failure 500 {object} dto.APIResponse
+// @Router /held-orders [get]
+func (c *HeldOrderController) GetHeldOrders(ctx *gin.Context) {
+	userID, role, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	orders, err := c.heldOrderService.GetHeldOrders(userID, role)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, dto.APIResponse{
+			Success: false,
+			Message: "Failed to get held orders",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Held orders retrieved successfully",
+		Data:    orders,
+	})
+}
+
+// GetHeldOrder godoc
+// @Summary Get held order by ID
+// @Description Get a parked order by ID
+// @Tags held-orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Held order ID"
+// @Success 200 {object} dto.APIResponse{data=dto.TransactionResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /held-orders/{id} [get]
+func (c *HeldOrderController) GetHeldOrder(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid held order ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	userID, role, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	order, err := c.heldOrderService.GetHeldOrder(uint(id), userID, role)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, dto.APIResponse{
+			Success: false,
+			Message: "Held order not found",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Held order retrieved successfully",
+		Data:    order,
+	})
+}
+
+// HoldOrder godoc
+// @Summary Hold order
+// @Description Park a cart without taking stock so the next customer can be served
+// @Tags held-orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.HeldOrderRequest true "Held order request"
+// @Success 201 {object} dto.APIResponse{data=dto.TransactionResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /held-orders [post]
+func (c *HeldOrderController) HoldOrder(ctx *gin.Context) {
+	var req dto.HeldOrderRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	userID, _, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	req.UserID = userID
+
+	order, err := c.heldOrderService.HoldOrder(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to hold order",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.APIResponse{
+		Success: true,
+		Message: "Order held successfully",
+		Data:    order,
+	})
+}
+
+// UpdateHeldOrder godoc
+// @Summary Update held order
+// @Description Replace the lines of a parked order
+// @Tags held-orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Held order ID"
+// @Param request body dto.HeldOrderRequest true "Held order request"
+// @Success 200 {object} dto.APIResponse{data=dto.TransactionResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /held-orders/{id} [put]
+func (c *HeldOrderController) UpdateHeldOrder(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid held order ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	var req dto.HeldOrderRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	userID, role, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	order, err := c.heldOrderService.UpdateHeldOrder(uint(id), userID, role, &req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to update held order",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Held order updated successfully",
+		Data:    order,
+	})
+}
+
+// FinalizeHeldOrder godoc
+// @Summary Finalize held order
+// @Description Pay a parked order, turning it into a completed transaction
+// @Tags held-orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Held order ID"
+// @Param request body dto.FinalizeOrderRequest true "Finalize order request"
+// @Success 200 {object} dto.APIResponse{data=dto.TransactionResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /held-orders/{id}/finalize [post]
+func (c *HeldOrderController) FinalizeHeldOrder(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid held order ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	var req dto.FinalizeOrderRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	userID, role, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	req.UserID = userID
+	req.Role = role
+
+	transaction, err := c.heldOrderService.FinalizeHeldOrder(uint(id), &req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to finalize held order",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Held order finalized successfully",
+		Data:    transaction,
+	})
+}
+
+// DeleteHeldOrder godoc
+// @Summary Delete held order
+// @Description Discard a parked order
+// @Tags held-orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Held order ID"
+// @Success 200 {object} dto.APIResponse
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /held-orders/{id} [delete]
+func (c *HeldOrderController) DeleteHeldOrder(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid held order ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	userID, role, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	err = c.heldOrderService.DeleteHeldOrder(uint(id), userID, role)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, dto.APIResponse{
+			Success: false,
+			Message: "Failed to delete held order",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Held order deleted successfully",
+	})
+}
+
+// currentUser reads the authenticated user set by the auth middleware and
+// answers 401 when it is missing.
+func currentUser(ctx *gin.Context) (uint, string, bool) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, dto.APIResponse{
+			Success: false,
+			Message: "Unauthorized",
+		})
+		return 0, "", false
+	}
+
+	return userID.(uint), ctx.GetString("role"), true
+}
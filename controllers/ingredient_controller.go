@@ -0,0 +1,520 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/services"
+)
+
+type IngredientController struct {
+	ingredientService *services.IngredientService
+}
+
+func NewIngredientController(ingredientService *services.IngredientService) *IngredientController {
+	return &IngredientController{ingredientService: ingredientService}
+}
+
+// GetAllIngredients godoc
+// @Summary Get all ingredients
+// @Description Get list of all ingredients with their stock
+// @Tags ingredients
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.APIResponse{data=[]dto.IngredientResponse}
+// @Failure 500 {object} dto.APIResponse
+// @Router /ingredients [get]
+func (c *IngredientController) GetAllIngredients(ctx *gin.Context) {
+	ingredients, err := c.ingredientService.GetAllIngredients()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, dto.APIResponse{
+			Success: false,
+			Message: "Failed to get ingredients",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Ingredients retrieved successfully",
+		Data:    ingredients,
+	})
+}
+
+// GetIngredientByID godoc
+// @Summary Get ingredient by ID
+// @Description Get ingredient details by ID
+// @Tags ingredients
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Ingredient ID"
+// @Success 200 {object} dto.APIResponse{data=dto.IngredientResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /ingredients/{id} [get]
+func (c *IngredientController) GetIngredientByID(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid ingredient ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ingredient, err := c.ingredientService.GetIngredientByID(uint(id))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, dto.APIResponse{
+			Success: false,
+			Message: "Ingredient not found",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Ingredient retrieved successfully",
+		Data:    ingredient,
+	})
+}
+
+// CreateIngredient godoc
+// @Summary Create new ingredient
+// @Description Create a new ingredient with its unit of measure, stock and unit cost
+// @Tags ingredients
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateIngredientRequest true "Create ingredient request"
+// @Success 201 {object} dto.APIResponse{data=dto.IngredientResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /ingredients [post]
+func (c *IngredientController) CreateIngredient(ctx *gin.Context) {
+	var req dto.CreateIngredientRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ingredient, err := c.ingredientService.CreateIngredient(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to create ingredient",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.APIResponse{
+		Success: true,
+		Message: "Ingredient created successfully",
+		Data:    ingredient,
+	})
+}
+
+// UpdateIngredient godoc
+// @Summary Update ingredient
+// @Description Update an existing ingredient
+// @Tags ingredients
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Ingredient ID"
+// @Param request body dto.UpdateIngredientRequest true "Update ingredient request"
+// @Success 200 {object} dto.APIResponse{data=dto.IngredientResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /ingredients/{id} [put]
+func (c *IngredientController) UpdateIngredient(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid ingredient ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	var req dto.UpdateIngredientRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ingredient, err := c.ingredientService.UpdateIngredient(uint(id), &req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to update ingredient",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Ingredient updated successfully",
+		Data:    ingredient,
+	})
+}
+
+// UpdateStock godoc
+// @Summary Update ingredient stock
+// @Description Add to (or, with a negative quantity, take from) the stock of an ingredient
+// @Tags ingredients
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Ingredient ID"
+// @Param request body dto.UpdateIngredientStockRequest true "Update stock request"
+// @Success 200 {object} dto.APIResponse
+// @Failure 400 {object} dto.APIResponse
+// @Router /ingredients/{id}/stock [patch]
+func (c *IngredientController) UpdateStock(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid ingredient ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	var req dto.UpdateIngredientStockRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if err := c.ingredientService.UpdateStock(uint(id), req.Quantity); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to update stock",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Stock updated successfully",
+	})
+}
+
+// DeleteIngredient godoc
+// @Summary Delete ingredient
+// @Description Delete an ingredient that no recipe uses
+// @Tags ingredients
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Ingredient ID"
+// @Success 200 {object} dto.APIResponse
+// @Failure 400 {object} dto.APIResponse
+// @Router /ingredients/{id} [delete]
+func (c *IngredientController) DeleteIngredient(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid ingredient ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if err := c.ingredientService.DeleteIngredient(uint(id)); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to delete ingredient",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Ingredient deleted successfully",
+	})
+}
+
+// GetProductRecipe godoc
+// @Summary Get product recipe
+// @Description Get the ingredients used to make one unit of a product
+// @Tags recipes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Product ID"
+// @Success 200 {object} dto.APIResponse{data=dto.RecipeResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /products/{id}/recipe [get]
+func (c *IngredientController) GetProductRecipe(ctx *gin.Context) {
+	productID, ok := pathID(ctx, "id", "product")
+	if !ok {
+		return
+	}
+
+	recipe, err := c.ingredientService.GetProductRecipe(productID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, dto.APIResponse{
+			Success: false,
+			Message: "Recipe not found",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Recipe retrieved successfully",
+		Data:    recipe,
+	})
+}
+
+// SetProductRecipe godoc
+// @Summary Set product recipe
+// @Description Replace the recipe of a product; the product is then sold from its ingredients instead of its own stock
+// @Tags recipes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Product ID"
+// @Param request body dto.RecipeRequest true "Recipe request"
+// @Success 200 {object} dto.APIResponse{data=dto.RecipeResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /products/{id}/recipe [put]
+func (c *IngredientController) SetProductRecipe(ctx *gin.Context) {
+	productID, ok := pathID(ctx, "id", "product")
+	if !ok {
+		return
+	}
+
+	var req dto.RecipeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	recipe, err := c.ingredientService.SetProductRecipe(productID, &req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to save recipe",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Recipe saved successfully",
+		Data:    recipe,
+	})
+}
+
+// GetVariantRecipe godoc
+// @Summary Get variant recipe
+// @Description Get the ingredients used to make one unit of a variant
+// @Tags recipes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Product ID"
+// @Param variantId path int true "Variant ID"
+// @Success 200 {object} dto.APIResponse{data=dto.RecipeResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /products/{id}/variants/{variantId}/recipe [get]
+func (c *IngredientController) GetVariantRecipe(ctx *gin.Context) {
+	productID, ok := pathID(ctx, "id", "product")
+	if !ok {
+		return
+	}
+	variantID, ok := pathID(ctx, "variantId", "variant")
+	if !ok {
+		return
+	}
+
+	recipe, err := c.ingredientService.GetVariantRecipe(productID, variantID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, dto.APIResponse{
+			Success: false,
+			Message: "Recipe not found",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Recipe retrieved successfully",
+		Data:    recipe,
+	})
+}
+
+// SetVariantRecipe godoc
+// @Summary Set variant recipe
+// @Description Replace the recipe of a variant; it is used instead of the product recipe
+// @Tags recipes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Product ID"
+// @Param variantId path int true "Variant ID"
+// @Param request body dto.RecipeRequest true "Recipe request"
+// @Success 200 {object} dto.APIResponse{data=dto.RecipeResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /products/{id}/variants/{variantId}/recipe [put]
+func (c *IngredientController) SetVariantRecipe(ctx *gin.Context) {
+	productID, ok := pathID(ctx, "id", "product")
+	if !ok {
+		return
+	}
+	variantID, ok := pathID(ctx, "variantId", "variant")
+	if !ok {
+		return
+	}
+
+	var req dto.RecipeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	recipe, err := c.ingredientService.SetVariantRecipe(productID, variantID, &req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to save recipe",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Recipe saved successfully",
+		Data:    recipe,
+	})
+}
+
+// GetModifierRecipe godoc
+// @Summary Get modifier recipe
+// @Description Get the ingredients a modifier option adds to one unit
+// @Tags recipes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Modifier group ID"
+// @Param optionId path int true "Modifier option ID"
+// @Success 200 {object} dto.APIResponse{data=dto.RecipeResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /modifier-groups/{id}/options/{optionId}/recipe [get]
+func (c *IngredientController) GetModifierRecipe(ctx *gin.Context) {
+	groupID, ok := pathID(ctx, "id", "modifier group")
+	if !ok {
+		return
+	}
+	optionID, ok := pathID(ctx, "optionId", "modifier option")
+	if !ok {
+		return
+	}
+
+	recipe, err := c.ingredientService.GetModifierRecipe(groupID, optionID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, dto.APIResponse{
+			Success: false,
+			Message: "Recipe not found",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Recipe retrieved successfully",
+		Data:    recipe,
+	})
+}
+
+// SetModifierRecipe godoc
+// @Summary Set modifier recipe
+// @Description Replace the ingredients a modifier option adds to one unit, e.g. the beans of an extra shot
+// @Tags recipes
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Modifier group ID"
+// @Param optionId path int true "Modifier option ID"
+// @Param request body dto.RecipeRequest true "Recipe request"
+// @Success 200 {object} dto.APIResponse{data=dto.RecipeResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /modifier-groups/{id}/options/{optionId}/recipe [put]
+func (c *IngredientController) SetModifierRecipe(ctx *gin.Context) {
+	groupID, ok := pathID(ctx, "id", "modifier group")
+	if !ok {
+		return
+	}
+	optionID, ok := pathID(ctx, "optionId", "modifier option")
+	if !ok {
+		return
+	}
+
+	var req dto.RecipeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	recipe, err := c.ingredientService.SetModifierRecipe(groupID, optionID, &req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to save recipe",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Recipe saved successfully",
+		Data:    recipe,
+	})
+}
@@ -0,0 +1,211 @@
+package controllers
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/services"
+)
+
+type KitchenController struct {
+	kitchenService *services.KitchenService
+}
+
+func NewKitchenController(kitchenService *services.KitchenService) *KitchenController {
+	return &KitchenController{kitchenService: kitchenService}
+}
+
+// GetTickets godoc
+// @Summary Get kitchen tickets
+// @Description Get the kitchen tickets of a station, oldest first; without a status only tickets not yet served are returned
+// @Tags kitchen
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param station query string false "Station (e.g. kitchen, bar)"
+// @Param status query string false "Status (new, in_progress, ready, served, cancelled)"
+// @Success 200 {object} dto.APIResponse{data=[]dto.KitchenTicketResponse}
+// @Failure 500 {object} dto.APIResponse
+// @Router /kitchen/tickets [get]
+func (c *KitchenController) GetTickets(ctx *gin.Context) {
+	tickets, err := c.kitchenService.GetTickets(ctx.Query("station"), ctx.Query("status"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, dto.APIResponse{
+			Success: false,
+			Message: "Failed to get tickets",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Tickets retrieved successfully",
+		Data:    tickets,
+	})
+}
+
+// GetTicket godoc
+// @Summary Get kitchen ticket by ID
+// @Description Get a kitchen ticket with its items
+// @Tags kitchen
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Ticket ID"
+// @Success 200 {object} dto.APIResponse{data=dto.KitchenTicketResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /kitchen/tickets/{id} [get]
+func (c *KitchenController) GetTicket(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid ticket ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ticket, err := c.kitchenService.GetTicket(uint(id))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, dto.APIResponse{
+			Success: false,
+			Message: "Ticket not found",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Ticket retrieved successfully",
+		Data:    ticket,
+	})
+}
+
+// BumpTicket godoc
+// @Summary Bump kitchen ticket
+// @Description Move a ticket to its next status (new, in_progress, ready, served)
+// @Tags kitchen
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Ticket ID"
+// @Success 200 {object} dto.APIResponse{data=dto.KitchenTicketResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /kitchen/tickets/{id}/bump [post]
+func (c *KitchenController) BumpTicket(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid ticket ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ticket, err := c.kitchenService.BumpTicket(uint(id))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to bump ticket",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Ticket bumped successfully",
+		Data:    ticket,
+	})
+}
+
+// RecallTicket godoc
+// @Summary Recall kitchen ticket
+// @Description Move a ticket bumped by mistake back to its previous status
+// @Tags kitchen
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Ticket ID"
+// @Success 200 {object} dto.APIResponse{data=dto.KitchenTicketResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /kitchen/tickets/{id}/recall [post]
+func (c *KitchenController) RecallTicket(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid ticket ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ticket, err := c.kitchenService.RecallTicket(uint(id))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to recall ticket",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Ticket recalled successfully",
+		Data:    ticket,
+	})
+}
+
+// StreamTickets godoc
+// @Summary Stream kitchen tickets
+// @Description Server-Sent Events stream of ticket changes (ticket.created, ticket.updated, ticket.cancelled) for a station; load GET /kitchen/tickets first. EventSource clients authenticate with a token from POST /auth/stream-token in the token query parameter
+// @Tags kitchen
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Param station query string false "Station (e.g. kitchen, bar); all stations when empty"
+// @Param token query string false "Stream token, instead of the Authorization header"
+// @Success 200 {object} dto.KitchenTicketResponse
+// @Router /kitchen/stream [get]
+func (c *KitchenController) StreamTickets(ctx *gin.Context) {
+	station := ctx.Query("station")
+
+	events, unsubscribe := c.kitchenService.Subscribe()
+	defer unsubscribe()
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			if ticket, isTicket := event.Data.(dto.KitchenTicketResponse); isTicket && station != "" && ticket.Station != station {
+				return true
+			}
+			ctx.SSEvent(event.Type, event.Data)
+			return true
+		case <-keepAlive.C:
+			ctx.SSEvent("ping", time.Now().Unix())
+			return true
+		case <-ctx.Request.Context().Done():
+			return false
+		}
+	})
+}
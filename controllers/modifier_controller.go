@@ -0,0 +1,253 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/services"
+)
+
+type ModifierController struct {
+	modifierService *services.ModifierService
+}
+
+func NewModifierController(modifierService *services.ModifierService) *ModifierController {
+	return &ModifierController{modifierService: modifierService}
+}
+
+// GetAllGroups godoc
+// @Summary Get all modifier groups
+// @Description Get list of all modifier groups with their options
+// @Tags modifiers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.APIResponse{data=[]dto.ModifierGroupResponse}
+// @Failure 500 {object} dto.APIResponse
+// @Router /modifier-groups [get]
+func (c *ModifierController) GetAllGroups(ctx *gin.Context) {
+	groups, err := c.modifierService.GetAllGroups()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, dto.APIResponse{
+			Success: false,
+			Message: "Failed to get modifier groups",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Modifier groups retrieved successfully",
+		Data:    groups,
+	})
+}
+
+// GetGroupByID godoc
+// @Summary Get modifier group by ID
+// @Description Get modifier group details by ID
+// @Tags modifiers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Modifier group ID"
+// @Success 200 {object} dto.APIResponse{data=dto.ModifierGroupResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /modifier-groups/{id} [get]
+func (c *ModifierController) GetGroupByID(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid modifier group ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	group, err := c.modifierService.GetGroupByID(uint(id))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, dto.APIResponse{
+			Success: false,
+			Message: "Modifier group not found",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Modifier group retrieved successfully",
+		Data:    group,
+	})
+}
+
+// GetProductModifiers godoc
+// @Summary Get product modifiers
+// @Description Get the modifier groups offered with a product, directly or through its category
+// @Tags modifiers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Product ID"
+// @Success 200 {object} dto.APIResponse{data=[]dto.ModifierGroupResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /products/{id}/modifiers [get]
+func (c *ModifierController) GetProductModifiers(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid product ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	groups, err := c.modifierService.GetProductModifiers(uint(id))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, dto.APIResponse{
+			Success: false,
+			Message: "Product not found",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Product modifiers retrieved successfully",
+		Data:    groups,
+	})
+}
+
+// CreateGroup godoc
+// @Summary Create modifier group
+// @Description Create a modifier group with its options, attached to products and/or categories
+// @Tags modifiers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.ModifierGroupRequest true "Modifier group request"
+// @Success 201 {object} dto.APIResponse{data=dto.ModifierGroupResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /modifier-groups [post]
+func (c *ModifierController) CreateGroup(ctx *gin.Context) {
+	var req dto.ModifierGroupRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	group, err := c.modifierService.CreateGroup(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to create modifier group",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.APIResponse{
+		Success: true,
+		Message: "Modifier group created successfully",
+		Data:    group,
+	})
+}
+
+// UpdateGroup godoc
+// @Summary Update modifier group
+// @Description Update a modifier group, its options and the products and categories it is attached to
+// @Tags modifiers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Modifier group ID"
+// @Param request body dto.ModifierGroupRequest true "Modifier group request"
+// @Success 200 {object} dto.APIResponse{data=dto.ModifierGroupResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /modifier-groups/{id} [put]
+func (c *ModifierController) UpdateGroup(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid modifier group ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	var req dto.ModifierGroupRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	group, err := c.modifierService.UpdateGroup(uint(id), &req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to update modifier group",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Modifier group updated successfully",
+		Data:    group,
+	})
+}
+
+// DeleteGroup godoc
+// @Summary Delete modifier group
+// @Description Delete a modifier group; past sales keep their modifiers
+// @Tags modifiers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Modifier group ID"
+// @Success 200 {object} dto.APIResponse
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /modifier-groups/{id} [delete]
+func (c *ModifierController) DeleteGroup(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid modifier group ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if err := c.modifierService.DeleteGroup(uint(id)); err != nil {
+		ctx.JSON(http.StatusNotFound, dto.APIResponse{
+			Success: false,
+			Message: "Modifier group not found",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Modifier group deleted successfully",
+	})
+}
@@ -126,6 +126,12 @@ func (c *ProductController) CreateProduct(ctx *gin.Context) {
 		return
 	}
 
+	userID, _, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	req.UserID = userID
+
 	product, err := c.productService.CreateProduct(&req)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
@@ -233,58 +239,6 @@ func (c *ProductController) DeleteProduct(ctx *gin.Context) {
 	})
 }
 
-// UpdateStock godoc
-// @Summary Update product stock
-// @Description Update stock quantity for a product
-// @Tags products
-// @Accept json
-// @Produce json
-// @Security BearerAuth
-// @Param id path int true "Product ID"
-// @Param request body object{quantity int} true "Stock update request"
-// @Success 200 {object} dto.APIResponse
-// @Failure 400 {object} dto.APIResponse
-// @Failure 404 {object} dto.APIResponse
-// @Router /products/{id}/stock [patch]
-func (c *ProductController) UpdateStock(ctx *gin.Context) {
-	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
-			Success: false,
-			Message: "Invalid product ID",
-			Error:   err.Error(),
-		})
-		return
-	}
-
-	var req struct {
-		Quantity int `json:"quantity" binding:"required"`
-	}
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
-			Success: false,
-			Message: "Invalid request body",
-			Error:   err.Error(),
-		})
-		return
-	}
-
-	err = c.productService.UpdateStock(uint(id), req.Quantity)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
-			Success: false,
-			Message: "Failed to update stock",
-			Error:   err.Error(),
-		})
-		return
-	}
-
-	ctx.JSON(http.StatusOK, dto.APIResponse{
-		Success: true,
-		Message: "Stock updated successfully",
-	})
-}
-
 // GetProductsByCategory godoc
 // @Summary Get products by category
 // @Description Get all products in a specific category
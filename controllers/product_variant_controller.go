@@ -0,0 +1,338 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/services"
+)
+
+type ProductVariantController struct {
+	variantService *services.ProductVariantService
+}
+
+func NewProductVariantController(variantService *services.ProductVariantService) *ProductVariantController {
+	return &ProductVariantController{variantService: variantService}
+}
+
+// GetVariants godoc
+// @Summary Get product variants
+// @Description Get the variant groups and variants of a product
+// @Tags product-variants
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Product ID"
+// @Success 200 {object} dto.APIResponse{data=dto.ProductVariantsResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /products/{id}/variants [get]
+func (c *ProductVariantController) GetVariants(ctx *gin.Context) {
+	productID, ok := pathID(ctx, "id", "product")
+	if !ok {
+		return
+	}
+
+	variants, err := c.variantService.GetVariants(productID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, dto.APIResponse{
+			Success: false,
+			Message: "Product not found",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Product variants retrieved successfully",
+		Data:    variants,
+	})
+}
+
+// CreateGroup godoc
+// @Summary Create variant group
+// @Description Add a variant group (e.g. Size, Temperature) with its options; only allowed before the product has variants
+// @Tags product-variants
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Product ID"
+// @Param request body dto.VariantGroupRequest true "Variant group request"
+// @Success 201 {object} dto.APIResponse{data=dto.VariantGroupResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /products/{id}/variant-groups [post]
+func (c *ProductVariantController) CreateGroup(ctx *gin.Context) {
+	productID, ok := pathID(ctx, "id", "product")
+	if !ok {
+		return
+	}
+
+	var req dto.VariantGroupRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	group, err := c.variantService.CreateGroup(productID, &req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to create variant group",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.APIResponse{
+		Success: true,
+		Message: "Variant group created successfully",
+		Data:    group,
+	})
+}
+
+// UpdateGroup godoc
+// @Summary Update variant group
+// @Description Rename or reorder a variant group and its options; options left out are removed unless a variant uses them
+// @Tags product-variants
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Product ID"
+// @Param groupId path int true "Variant group ID"
+// @Param request body dto.VariantGroupRequest true "Variant group request"
+// @Success 200 {object} dto.APIResponse{data=dto.VariantGroupResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /products/{id}/variant-groups/{groupId} [put]
+func (c *ProductVariantController) UpdateGroup(ctx *gin.Context) {
+	productID, ok := pathID(ctx, "id", "product")
+	if !ok {
+		return
+	}
+	groupID, ok := pathID(ctx, "groupId", "variant group")
+	if !ok {
+		return
+	}
+
+	var req dto.VariantGroupRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	group, err := c.variantService.UpdateGroup(productID, groupID, &req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to update variant group",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Variant group updated successfully",
+		Data:    group,
+	})
+}
+
+// DeleteGroup godoc
+// @Summary Delete variant group
+// @Description Delete a variant group that no variant uses
+// @Tags product-variants
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Product ID"
+// @Param groupId path int true "Variant group ID"
+// @Success 200 {object} dto.APIResponse
+// @Failure 400 {object} dto.APIResponse
+// @Router /products/{id}/variant-groups/{groupId} [delete]
+func (c *ProductVariantController) DeleteGroup(ctx *gin.Context) {
+	productID, ok := pathID(ctx, "id", "product")
+	if !ok {
+		return
+	}
+	groupID, ok := pathID(ctx, "groupId", "variant group")
+	if !ok {
+		return
+	}
+
+	if err := c.variantService.DeleteGroup(productID, groupID); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to delete variant group",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Variant group deleted successfully",
+	})
+}
+
+// CreateVariant godoc
+// @Summary Create product variant
+// @Description Create a variant from one option of every variant group, with an absolute price or a price delta and optional own stock
+// @Tags product-variants
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Product ID"
+// @Param request body dto.CreateProductVariantRequest true "Product variant request"
+// @Success 201 {object} dto.APIResponse{data=dto.ProductVariantResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /products/{id}/variants [post]
+func (c *ProductVariantController) CreateVariant(ctx *gin.Context) {
+	productID, ok := pathID(ctx, "id", "product")
+	if !ok {
+		return
+	}
+
+	var req dto.CreateProductVariantRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	userID, _, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	req.UserID = userID
+
+	variant, err := c.variantService.CreateVariant(productID, &req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to create variant",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.APIResponse{
+		Success: true,
+		Message: "Variant created successfully",
+		Data:    variant,
+	})
+}
+
+// UpdateVariant godoc
+// @Summary Update product variant
+// @Description Update the options, price and status of a variant; stock changes through PATCH /products/{id}/stock
+// @Tags product-variants
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Product ID"
+// @Param variantId path int true "Variant ID"
+// @Param request body dto.ProductVariantRequest true "Product variant request"
+// @Success 200 {object} dto.APIResponse{data=dto.ProductVariantResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /products/{id}/variants/{variantId} [put]
+func (c *ProductVariantController) UpdateVariant(ctx *gin.Context) {
+	productID, ok := pathID(ctx, "id", "product")
+	if !ok {
+		return
+	}
+	variantID, ok := pathID(ctx, "variantId", "variant")
+	if !ok {
+		return
+	}
+
+	var req dto.ProductVariantRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	variant, err := c.variantService.UpdateVariant(productID, variantID, &req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to update variant",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Variant updated successfully",
+		Data:    variant,
+	})
+}
+
+// DeleteVariant godoc
+// @Summary Delete product variant
+// @Description Delete a variant; past sales keep its name
+// @Tags product-variants
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Product ID"
+// @Param variantId path int true "Variant ID"
+// @Success 200 {object} dto.APIResponse
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /products/{id}/variants/{variantId} [delete]
+func (c *ProductVariantController) DeleteVariant(ctx *gin.Context) {
+	productID, ok := pathID(ctx, "id", "product")
+	if !ok {
+		return
+	}
+	variantID, ok := pathID(ctx, "variantId", "variant")
+	if !ok {
+		return
+	}
+
+	if err := c.variantService.DeleteVariant(productID, variantID); err != nil {
+		ctx.JSON(http.StatusNotFound, dto.APIResponse{
+			Success: false,
+			Message: "Variant not found",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Variant deleted successfully",
+	})
+}
+
+// pathID parses an ID path parameter and answers 400 when it is not a number
+func pathID(ctx *gin.Context, name, label string) (uint, bool) {
+	id, err := strconv.ParseUint(ctx.Param(name), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid " + label + " ID",
+			Error:   err.Error(),
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
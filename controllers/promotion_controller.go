@@ -0,0 +1,243 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/services"
+)
+
+type PromotionController struct {
+	promotionService *services.PromotionService
+}
+
+func NewPromotionController(promotionService *services.PromotionService) *PromotionController {
+	return &PromotionController{promotionService: promotionService}
+}
+
+// GetAllPromotions godoc
+// @Summary Get all promotions
+// @Description Get list of all promotions
+// @Tags promotions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.APIResponse{data=[]dto.PromotionResponse}
+// @Failure 500 {object} dto.APIResponse
+// @Router /promotions [get]
+func (c *PromotionController) GetAllPromotions(ctx *gin.Context) {
+	promotions, err := c.promotionService.GetAllPromotions()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, dto.APIResponse{
+			Success: false,
+			Message: "Failed to get promotions",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Promotions retrieved successfully",
+		Data:    promotions,
+	})
+}
+
+// GetActivePromotions godoc
+// @Summary Get active promotions
+// @Description Get promotions that apply right now, including happy-hour windows
+// @Tags promotions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.APIResponse{data=[]dto.PromotionResponse}
+// @Failure 500 {object} dto.APIResponse
+// @Router /promotions/active [get]
+func (c *PromotionController) GetActivePromotions(ctx *gin.Context) {
+	promotions, err := c.promotionService.GetActivePromotions()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, dto.APIResponse{
+			Success: false,
+			Message: "Failed to get promotions",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Promotions retrieved successfully",
+		Data:    promotions,
+	})
+}
+
+// GetPromotionByID godoc
+// @Summary Get promotion by ID
+// @Description Get promotion details by ID
+// @Tags promotions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Promotion ID"
+// @Success 200 {object} dto.APIResponse{data=dto.PromotionResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /promotions/{id} [get]
+func (c *PromotionController) GetPromotionByID(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid promotion ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	promotion, err := c.promotionService.GetPromotionByID(uint(id))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, dto.APIResponse{
+			Success: false,
+			Message: "Promotion not found",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Promotion retrieved successfully",
+		Data:    promotion,
+	})
+}
+
+// CreatePromotion godoc
+// @Summary Create new promotion
+// @Description Create a new promotion
+// @Tags promotions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.PromotionRequest true "Create promotion request"
+// @Success 201 {object} dto.APIResponse{data=dto.PromotionResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /promotions [post]
+func (c *PromotionController) CreatePromotion(ctx *gin.Context) {
+	var req dto.PromotionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	promotion, err := c.promotionService.CreatePromotion(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to create promotion",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.APIResponse{
+		Success: true,
+		Message: "Promotion created successfully",
+		Data:    promotion,
+	})
+}
+
+// UpdatePromotion godoc
+// @Summary Update promotion
+// @Description Update promotion details
+// @Tags promotions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Promotion ID"
+// @Param request body dto.PromotionRequest true "Update promotion request"
+// @Success 200 {object} dto.APIResponse{data=dto.PromotionResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /promotions/{id} [put]
+func (c *PromotionController) UpdatePromotion(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid promotion ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	var req dto.PromotionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	promotion, err := c.promotionService.UpdatePromotion(uint(id), &req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to update promotion",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Promotion updated successfully",
+		Data:    promotion,
+	})
+}
+
+// DeletePromotion godoc
+// @Summary Delete promotion
+// @Description Delete promotion by ID
+// @Tags promotions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Promotion ID"
+// @Success 200 {object} dto.APIResponse
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /promotions/{id} [delete]
+func (c *PromotionController) DeletePromotion(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid promotion ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	err = c.promotionService.DeletePromotion(uint(id))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, dto.APIResponse{
+			Success: false,
+			Message: "Failed to delete promotion",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Promotion deleted successfully",
+	})
+}
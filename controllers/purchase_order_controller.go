@@ -0,0 +1,341 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/services"
+)
+
+type PurchaseOrderController struct {
+	purchaseOrderService *services.PurchaseOrderService
+}
+
+func NewPurchaseOrderController(purchaseOrderService *services.PurchaseOrderService) *PurchaseOrderController {
+	return &PurchaseOrderController{purchaseOrderService: purchaseOrderService}
+}
+
+// GetAllPurchaseOrders godoc
+// @Summary Get all purchase orders
+// @Description Get purchase orders, newest first
+// @Tags purchase-orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "draft, sent, partially_received, received, closed or cancelled"
+// @Param supplier_id query int false "Supplier ID"
+// @Success 200 {object} dto.APIResponse{data=[]dto.PurchaseOrderResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 500 {object} dto.APIResponse
+// @Router /purchase-orders [get]
+func (c *PurchaseOrderController) GetAllPurchaseOrders(ctx *gin.Context) {
+	var supplierID uint
+	if supplierIDStr := ctx.Query("supplier_id"); supplierIDStr != "" {
+		id, err := strconv.ParseUint(supplierIDStr, 10, 32)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+				Success: false,
+				Message: "Invalid supplier ID",
+				Error:   err.Error(),
+			})
+			return
+		}
+		supplierID = uint(id)
+	}
+
+	orders, err := c.purchaseOrderService.GetAllPurchaseOrders(ctx.Query("status"), supplierID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, dto.APIResponse{
+			Success: false,
+			Message: "Failed to get purchase orders",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Purchase orders retrieved successfully",
+		Data:    orders,
+	})
+}
+
+// GetPurchaseOrderByID godoc
+// @Summary Get purchase order by ID
+// @Description Get a purchase order with its lines and goods receipts
+// @Tags purchase-orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Purchase order ID"
+// @Success 200 {object} dto.APIResponse{data=dto.PurchaseOrderResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /purchase-orders/{id} [get]
+func (c *PurchaseOrderController) GetPurchaseOrderByID(ctx *gin.Context) {
+	id, ok := pathID(ctx, "id", "purchase order")
+	if !ok {
+		return
+	}
+
+	order, err := c.purchaseOrderService.GetPurchaseOrderByID(id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, dto.APIResponse{
+			Success: false,
+			Message: "Purchase order not found",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Purchase order retrieved successfully",
+		Data:    order,
+	})
+}
+
+// CreatePurchaseOrder godoc
+// @Summary Create purchase order
+// @Description Create a draft purchase order; unit costs default to the current product cost
+// @Tags purchase-orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.PurchaseOrderRequest true "Purchase order request"
+// @Success 201 {object} dto.APIResponse{data=dto.PurchaseOrderResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /purchase-orders [post]
+func (c *PurchaseOrderController) CreatePurchaseOrder(ctx *gin.Context) {
+	var req dto.PurchaseOrderRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	userID, _, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	req.UserID = userID
+
+	order, err := c.purchaseOrderService.CreatePurchaseOrder(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to create purchase order",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.APIResponse{
+		Success: true,
+		Message: "Purchase order created successfully",
+		Data:    order,
+	})
+}
+
+// UpdatePurchaseOrder godoc
+// @Summary Update purchase order
+// @Description Replace the supplier and lines of a draft purchase order
+// @Tags purchase-orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Purchase order ID"
+// @Param request body dto.PurchaseOrderRequest true "Purchase order request"
+// @Success 200 {object} dto.APIResponse{data=dto.PurchaseOrderResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /purchase-orders/{id} [put]
+func (c *PurchaseOrderController) UpdatePurchaseOrder(ctx *gin.Context) {
+	id, ok := pathID(ctx, "id", "purchase order")
+	if !ok {
+		return
+	}
+
+	var req dto.PurchaseOrderRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	order, err := c.purchaseOrderService.UpdatePurchaseOrder(id, &req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to update purchase order",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Purchase order updated successfully",
+		Data:    order,
+	})
+}
+
+// SendPurchaseOrder godoc
+// @Summary Send purchase order
+// @Description Mark a draft purchase order as sent to the supplier
+// @Tags purchase-orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Purchase order ID"
+// @Success 200 {object} dto.APIResponse{data=dto.PurchaseOrderResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /purchase-orders/{id}/send [post]
+func (c *PurchaseOrderController) SendPurchaseOrder(ctx *gin.Context) {
+	id, ok := pathID(ctx, "id", "purchase order")
+	if !ok {
+		return
+	}
+
+	order, err := c.purchaseOrderService.SendPurchaseOrder(id)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to send purchase order",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Purchase order sent successfully",
+		Data:    order,
+	})
+}
+
+// ReceivePurchaseOrder godoc
+// @Summary Receive goods
+// @Description Book a (partial) delivery against a sent purchase order: received units go into stock, rejected units and cost differences are kept on the goods receipt
+// @Tags purchase-orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Purchase order ID"
+// @Param request body dto.ReceivePurchaseOrderRequest true "Goods receipt request"
+// @Success 200 {object} dto.APIResponse{data=dto.PurchaseOrderResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /purchase-orders/{id}/receive [post]
+func (c *PurchaseOrderController) ReceivePurchaseOrder(ctx *gin.Context) {
+	id, ok := pathID(ctx, "id", "purchase order")
+	if !ok {
+		return
+	}
+
+	var req dto.ReceivePurchaseOrderRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	userID, _, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	req.UserID = userID
+
+	order, err := c.purchaseOrderService.ReceivePurchaseOrder(id, &req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to receive goods",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Goods received successfully",
+		Data:    order,
+	})
+}
+
+// ClosePurchaseOrder godoc
+// @Summary Close purchase order
+// @Description Close a (partially) received purchase order when nothing more is expected
+// @Tags purchase-orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Purchase order ID"
+// @Success 200 {object} dto.APIResponse{data=dto.PurchaseOrderResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /purchase-orders/{id}/close [post]
+func (c *PurchaseOrderController) ClosePurchaseOrder(ctx *gin.Context) {
+	id, ok := pathID(ctx, "id", "purchase order")
+	if !ok {
+		return
+	}
+
+	order, err := c.purchaseOrderService.ClosePurchaseOrder(id)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to close purchase order",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Purchase order closed successfully",
+		Data:    order,
+	})
+}
+
+// CancelPurchaseOrder godoc
+// @Summary Cancel purchase order
+// @Description Cancel a draft or sent purchase order before anything was received
+// @Tags purchase-orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Purchase order ID"
+// @Success 200 {object} dto.APIResponse{data=dto.PurchaseOrderResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /purchase-orders/{id}/cancel [post]
+func (c *PurchaseOrderController) CancelPurchaseOrder(ctx *gin.Context) {
+	id, ok := pathID(ctx, "id", "purchase order")
+	if !ok {
+		return
+	}
+
+	order, err := c.purchaseOrderService.CancelPurchaseOrder(id)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to cancel purchase order",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Purchase order cancelled successfully",
+		Data:    order,
+	})
+}
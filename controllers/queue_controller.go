@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/services"
+)
+
+type QueueController struct {
+	queueService *services.QueueService
+}
+
+func NewQueueController(queueService *services.QueueService) *QueueController {
+	return &QueueController{queueService: queueService}
+}
+
+// GetBoard godoc
+// @Summary Get queue board
+// @Description Get today's queue numbers that are being prepared and ready to collect (public, for the customer display)
+// @Tags queue
+// @Accept json
+// @Produce json
+// @Success 200 {object} dto.APIResponse{data=dto.QueueBoardResponse}
+// @Failure 500 {object} dto.APIResponse
+// @Router /queue/board [get]
+func (c *QueueController) GetBoard(ctx *gin.Context) {
+	board, err := c.queueService.GetBoard()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, dto.APIResponse{
+			Success: false,
+			Message: "Failed to get queue board",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Queue board retrieved successfully",
+		Data:    board,
+	})
+}
+
+// StreamBoard godoc
+// @Summary Stream queue board
+// @Description Server-Sent Events stream of the queue board (public, read-only); every "board" event carries the whole board
+// @Tags queue
+// @Produce text/event-stream
+// @Success 200 {object} dto.QueueBoardResponse
+// @Router /queue/stream [get]
+func (c *QueueController) StreamBoard(ctx *gin.Context) {
+	events, unsubscribe := c.queueService.Subscribe()
+	defer unsubscribe()
+
+	board, err := c.queueService.GetBoard()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, dto.APIResponse{
+			Success: false,
+			Message: "Failed to get queue board",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+
+	ctx.SSEvent("board", board)
+	ctx.Writer.Flush()
+
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			ctx.SSEvent(event.Type, event.Data)
+			return true
+		case <-keepAlive.C:
+			ctx.SSEvent("ping", time.Now().Unix())
+			return true
+		case <-ctx.Request.Context().Done():
+			return false
+		}
+	})
+}
@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/services"
+)
+
+type RefundController struct {
+	refundService *services.RefundService
+}
+
+func NewRefundController(refundService *services.RefundService) *RefundController {
+	return &RefundController{refundService: refundService}
+}
+
+// GetRefundsByTransaction godoc
+// @Summary Get refunds of a transaction
+// @Description Get all refunds issued against a transaction
+// @Tags refunds
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Transaction ID"
+// @Success 200 {object} dto.APIResponse{data=[]dto.RefundResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /transactions/{id}/refunds [get]
+func (c *RefundController) GetRefundsByTransaction(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid transaction ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	refunds, err := c.refundService.GetRefundsByTransaction(uint(id))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, dto.APIResponse{
+			Success: false,
+			Message: "Failed to get refunds",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Refunds retrieved successfully",
+		Data:    refunds,
+	})
+}
+
+// CreateRefund godoc
+// @Summary Refund transaction items
+// @Description Refund some or all items of a completed transaction, or of a split order once all its bills are paid, optionally returning them to stock
+// @Tags refunds
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Transaction ID"
+// @Param request body dto.CreateRefundRequest true "Create refund request"
+// @Success 201 {object} dto.APIResponse{data=dto.RefundResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /transactions/{id}/refunds [post]
+func (c *RefundController) CreateRefund(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid transaction ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	var req dto.CreateRefundRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	// The approving manager is the authenticated user (route is ManagerOrAdmin)
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, dto.APIResponse{
+			Success: false,
+			Message: "Unauthorized",
+		})
+		return
+	}
+	req.ApprovedBy = userID.(uint)
+
+	refund, err := c.refundService.CreateRefund(uint(id), &req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to create refund",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.APIResponse{
+		Success: true,
+		Message: "Refund created successfully",
+		Data:    refund,
+	})
+}
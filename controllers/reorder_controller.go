@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/services"
+)
+
+type ReorderController struct {
+	reorderService *services.ReorderService
+}
+
+func NewReorderController(reorderService *services.ReorderService) *ReorderController {
+	return &ReorderController{reorderService: reorderService}
+}
+
+// GetSuggestions godoc
+// @Summary Get reorder suggestions
+// @Description Average daily sales, days of cover and suggested order quantity per product, from the sales of the last days and the supplier lead time
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param days query int false "Sales window in days (default setting reorder_window_days)"
+// @Param cover_days query int false "Days of sales to order on top of the lead time (default setting reorder_cover_days)"
+// @Param supplier_id query int false "Supplier ID"
+// @Param all query bool false "Also list products that need nothing"
+// @Success 200 {object} dto.APIResponse{data=dto.ReorderReportResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 500 {object} dto.APIResponse
+// @Router /reports/reorder-suggestions [get]
+func (c *ReorderController) GetSuggestions(ctx *gin.Context) {
+	var filter dto.ReorderFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid query parameters",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	report, err := c.reorderService.GetSuggestions(&filter)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, dto.APIResponse{
+			Success: false,
+			Message: "Failed to get reorder suggestions",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Reorder suggestions retrieved successfully",
+		Data:    report,
+	})
+}
+
+// CreatePurchaseOrder godoc
+// @Summary Create purchase order from reorder suggestions
+// @Description Create a draft purchase order for a supplier with the suggested quantities
+// @Tags purchase-orders
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.ReorderPurchaseOrderRequest true "Reorder request"
+// @Success 201 {object} dto.APIResponse{data=dto.PurchaseOrderResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /purchase-orders/from-suggestions [post]
+func (c *ReorderController) CreatePurchaseOrder(ctx *gin.Context) {
+	var req dto.ReorderPurchaseOrderRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	userID, _, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	req.UserID = userID
+
+	order, err := c.reorderService.CreatePurchaseOrder(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to create purchase order",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.APIResponse{
+		Success: true,
+		Message: "Purchase order created successfully",
+		Data:    order,
+	})
+}
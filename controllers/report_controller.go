@@ -144,6 +144,76 @@ func (c *ReportController) GetTopProducts(ctx *gin.Context) {
 	})
 }
 
+// GetTopVariants godoc
+// @Summary Get top selling variants
+// @Description Get top N best selling product variants (e.g. Large, Iced)
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Number of variants (default 10)"
+// @Success 200 {object} dto.APIResponse{data=[]dto.TopVariantResponse}
+// @Failure 500 {object} dto.APIResponse
+// @Router /reports/products/top-variants [get]
+func (c *ReportController) GetTopVariants(ctx *gin.Context) {
+	limitStr := ctx.DefaultQuery("limit", "10")
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil {
+		limit = 10
+	}
+
+	variants, err := c.reportService.GetTopVariants(limit)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, dto.APIResponse{
+			Success: false,
+			Message: "Failed to get top variants",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Top variants retrieved successfully",
+		Data:    variants,
+	})
+}
+
+// GetTopModifiers godoc
+// @Summary Get top selling modifiers
+// @Description Get top N most chosen modifiers (e.g. extra shot, oat milk)
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Number of modifiers (default 10)"
+// @Success 200 {object} dto.APIResponse{data=[]dto.TopModifierResponse}
+// @Failure 500 {object} dto.APIResponse
+// @Router /reports/products/top-modifiers [get]
+func (c *ReportController) GetTopModifiers(ctx *gin.Context) {
+	limitStr := ctx.DefaultQuery("limit", "10")
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil {
+		limit = 10
+	}
+
+	modifiers, err := c.reportService.GetTopModifiers(limit)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, dto.APIResponse{
+			Success: false,
+			Message: "Failed to get top modifiers",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Top modifiers retrieved successfully",
+		Data:    modifiers,
+	})
+}
+
 // GetRevenueByDateRange godoc
 // @Summary Get revenue by date range
 // @Description Get total revenue for a specific date range
@@ -153,6 +223,7 @@ func (c *ReportController) GetTopProducts(ctx *gin.Context) {
 // @Security BearerAuth
 // @Param start_date query string true "Start date (YYYY-MM-DD)"
 // @Param end_date query string true "End date (YYYY-MM-DD)"
+// @Param order_type query string false "Filter by order type (dine_in, takeaway, delivery)"
 // @Success 200 {object} dto.APIResponse{data=map[string]interface{}}
 // @Failure 400 {object} dto.APIResponse
 // @Failure 500 {object} dto.APIResponse
@@ -192,7 +263,12 @@ func (c *ReportController) GetRevenueByDateRange(ctx *gin.Context) {
 	// Set end date to end of day
 	endDate = endDate.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
 
-	revenue, err := c.reportService.GetRevenueByDateRange(startDate, endDate)
+	orderType, ok := orderTypeQuery(ctx)
+	if !ok {
+		return
+	}
+
+	revenue, err := c.reportService.GetRevenueByDateRange(startDate, endDate, orderType)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, dto.APIResponse{
 			Success: false,
@@ -208,6 +284,7 @@ func (c *ReportController) GetRevenueByDateRange(ctx *gin.Context) {
 		Data: map[string]interface{}{
 			"start_date":    startDateStr,
 			"end_date":      endDateStr,
+			"order_type":    orderType,
 			"total_revenue": revenue,
 		},
 	})
@@ -228,7 +305,7 @@ func (c *ReportController) GetMonthlySummary(ctx *gin.Context) {
 	startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
 	endOfMonth := startOfMonth.AddDate(0, 1, 0).Add(-time.Second)
 
-	revenue, err := c.reportService.GetRevenueByDateRange(startOfMonth, endOfMonth)
+	revenue, err := c.reportService.GetRevenueByDateRange(startOfMonth, endOfMonth, "")
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, dto.APIResponse{
 			Success: false,
@@ -253,6 +330,76 @@ func (c *ReportController) GetMonthlySummary(ctx *gin.Context) {
 	})
 }
 
+// GetTaxSummary godoc
+// @Summary Get tax summary
+// @Description Get tax collected per tax rate for a date range
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param start_date query string true "Start date (YYYY-MM-DD)"
+// @Param end_date query string true "End date (YYYY-MM-DD)"
+// @Param order_type query string false "Filter by order type (dine_in, takeaway, delivery)"
+// @Success 200 {object} dto.APIResponse{data=[]dto.TaxSummaryResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 500 {object} dto.APIResponse
+// @Router /reports/tax [get]
+func (c *ReportController) GetTaxSummary(ctx *gin.Context) {
+	startDateStr := ctx.Query("start_date")
+	endDateStr := ctx.Query("end_date")
+
+	if startDateStr == "" || endDateStr == "" {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "start_date and end_date are required",
+		})
+		return
+	}
+
+	startDate, err := time.Parse("2006-01-02", startDateStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid start_date format. Use YYYY-MM-DD",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	endDate, err := time.Parse("2006-01-02", endDateStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid end_date format. Use YYYY-MM-DD",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	endDate = endDate.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
+
+	orderType, ok := orderTypeQuery(ctx)
+	if !ok {
+		return
+	}
+
+	summary, err := c.reportService.GetTaxSummary(startDate, endDate, orderType)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, dto.APIResponse{
+			Success: false,
+			Message: "Failed to get tax summary",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Tax summary retrieved successfully",
+		Data:    summary,
+	})
+}
+
 // ExportTransactions godoc
 // @Summary Export transactions
 // @Description Export transactions data for a date range
@@ -262,6 +409,7 @@ func (c *ReportController) GetMonthlySummary(ctx *gin.Context) {
 // @Security BearerAuth
 // @Param start_date query string true "Start date (YYYY-MM-DD)"
 // @Param end_date query string true "End date (YYYY-MM-DD)"
+// @Param order_type query string false "Filter by order type (dine_in, takeaway, delivery)"
 // @Success 200 {object} dto.APIResponse{data=[]dto.TransactionResponse}
 // @Failure 400 {object} dto.APIResponse
 // @Failure 500 {object} dto.APIResponse
@@ -300,7 +448,12 @@ func (c *ReportController) ExportTransactions(ctx *gin.Context) {
 
 	endDate = endDate.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
 
-	transactions, err := c.reportService.ExportTransactions(startDate, endDate)
+	orderType, ok := orderTypeQuery(ctx)
+	if !ok {
+		return
+	}
+
+	transactions, err := c.reportService.ExportTransactions(startDate, endDate, orderType)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, dto.APIResponse{
 			Success: false,
@@ -316,3 +469,147 @@ func (c *ReportController) ExportTransactions(ctx *gin.Context) {
 		Data:    transactions,
 	})
 }
+
+// GetDiscountSummary godoc
+// @Summary Get discount summary
+// @Description Get promotion and manual discounts given on completed sales for a date range
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param start_date query string true "Start date (YYYY-MM-DD)"
+// @Param end_date query string true "End date (YYYY-MM-DD)"
+// @Success 200 {object} dto.APIResponse{data=[]dto.DiscountSummaryResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 500 {object} dto.APIResponse
+// @Router /reports/discounts [get]
+func (c *ReportController) GetDiscountSummary(ctx *gin.Context) {
+	startDateStr := ctx.Query("start_date")
+	endDateStr := ctx.Query("end_date")
+
+	if startDateStr == "" || endDateStr == "" {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "start_date and end_date are required",
+		})
+		return
+	}
+
+	startDate, err := time.Parse("2006-01-02", startDateStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid start_date format. Use YYYY-MM-DD",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	endDate, err := time.Parse("2006-01-02", endDateStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid end_date format. Use YYYY-MM-DD",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	endDate = endDate.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
+
+	summary, err := c.reportService.GetDiscountSummary(startDate, endDate)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, dto.APIResponse{
+			Success: false,
+			Message: "Failed to get discount summary",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Discount summary retrieved successfully",
+		Data:    summary,
+	})
+}
+
+// GetOrderTypeSummary godoc
+// @Summary Get order type summary
+// @Description Get completed sales broken down by order type (dine-in, takeaway, delivery) for a date range
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param start_date query string true "Start date (YYYY-MM-DD)"
+// @Param end_date query string true "End date (YYYY-MM-DD)"
+// @Success 200 {object} dto.APIResponse{data=[]dto.OrderTypeSummaryResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 500 {object} dto.APIResponse
+// @Router /reports/order-types [get]
+func (c *ReportController) GetOrderTypeSummary(ctx *gin.Context) {
+	startDateStr := ctx.Query("start_date")
+	endDateStr := ctx.Query("end_date")
+
+	if startDateStr == "" || endDateStr == "" {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "start_date and end_date are required",
+		})
+		return
+	}
+
+	startDate, err := time.Parse("2006-01-02", startDateStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid start_date format. Use YYYY-MM-DD",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	endDate, err := time.Parse("2006-01-02", endDateStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid end_date format. Use YYYY-MM-DD",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	endDate = endDate.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
+
+	summary, err := c.reportService.GetOrderTypeSummary(startDate, endDate)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, dto.APIResponse{
+			Success: false,
+			Message: "Failed to get order type summary",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Order type summary retrieved successfully",
+		Data:    summary,
+	})
+}
+
+// orderTypeQuery reads the optional order_type filter and answers 400 itself
+// when it is not a known order type
+func orderTypeQuery(ctx *gin.Context) (string, bool) {
+	orderType := ctx.Query("order_type")
+	switch orderType {
+	case "", "dine_in", "takeaway", "delivery":
+		return orderType, true
+	}
+
+	ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+		Success: false,
+		Message: "Invalid order_type. Use dine_in, takeaway or delivery",
+	})
+	return "", false
+}
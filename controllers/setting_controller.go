@@ -196,7 +196,7 @@ func (c *SettingController) GetStoreSettings(ctx *gin.Context) {
 // @Failure 500 {object} dto.APIResponse
 // @Router /settings/payment [get]
 func (c *SettingController) GetPaymentSettings(ctx *gin.Context) {
-	paymentKeys := []string{"payment_cash_enabled", "payment_card_enabled", "payment_qris_enabled"}
+	paymentKeys := []string{"payment_cash_enabled", "payment_card_enabled", "payment_qris_enabled", "cash_rounding"}
 
 	settings := make(map[string]string)
 	for _, key := range paymentKeys {
@@ -0,0 +1,170 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/services"
+)
+
+type SplitBillController struct {
+	splitBillService *services.SplitBillService
+}
+
+func NewSplitBillController(splitBillService *services.SplitBillService) *SplitBillController {
+	return &SplitBillController{splitBillService: splitBillService}
+}
+
+// SplitTab godoc
+// @Summary Split tab
+// @Description Split an open tab into bills by items or into equal shares; each bill is paid separately
+// @Tags split-bills
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Tab ID"
+// @Param request body dto.SplitOrderRequest true "Split tab request"
+// @Success 200 {object} dto.APIResponse{data=dto.TransactionResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /tabs/{id}/split [post]
+func (c *SplitBillController) SplitTab(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid tab ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	var req dto.SplitOrderRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	userID, role, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	req.UserID = userID
+	req.Role = role
+
+	order, err := c.splitBillService.SplitTab(uint(id), &req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to split tab",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Tab split successfully",
+		Data:    order,
+	})
+}
+
+// GetSplitBill godoc
+// @Summary Get split bill by ID
+// @Description Get one bill of a split order
+// @Tags split-bills
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Split bill ID"
+// @Success 200 {object} dto.APIResponse{data=dto.TransactionResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /split-bills/{id} [get]
+func (c *SplitBillController) GetSplitBill(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid split bill ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	bill, err := c.splitBillService.GetSplitBill(uint(id))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, dto.APIResponse{
+			Success: false,
+			Message: "Split bill not found",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Split bill retrieved successfully",
+		Data:    bill,
+	})
+}
+
+// PaySplitBill godoc
+// @Summary Pay split bill
+// @Description Pay one bill of a split order; the table is freed when every bill is paid
+// @Tags split-bills
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Split bill ID"
+// @Param request body dto.PaySplitBillRequest true "Pay split bill request"
+// @Success 200 {object} dto.APIResponse{data=dto.TransactionResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /split-bills/{id}/pay [post]
+func (c *SplitBillController) PaySplitBill(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid split bill ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	var req dto.PaySplitBillRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	userID, _, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	bill, err := c.splitBillService.PaySplitBill(uint(id), userID, &req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to pay split bill",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Split bill paid successfully",
+		Data:    bill,
+	})
+}
@@ -0,0 +1,7 @@
+package controllers
+
+import "time"
+
+// sseKeepAlive is how often idle Server-Sent Events streams (kitchen, queue
+// board, stock alerts) send a ping so proxies keep them open
+const sseKeepAlive = 30 * time.Second
@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/services"
+)
+
+type StockAlertController struct {
+	stockAlertService *services.StockAlertService
+}
+
+func NewStockAlertController(stockAlertService *services.StockAlertService) *StockAlertController {
+	return &StockAlertController{stockAlertService: stockAlertService}
+}
+
+// GetLowStockProducts godoc
+// @Summary Get low stock products
+// @Description Get the products whose stock is below their minimum (min_stock, or the low_stock_threshold setting), lowest stock first
+// @Tags stock-alerts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.APIResponse{data=[]dto.LowStockProductResponse}
+// @Failure 500 {object} dto.APIResponse
+// @Router /products/low-stock [get]
+func (c *StockAlertController) GetLowStockProducts(ctx *gin.Context) {
+	products, err := c.stockAlertService.GetLowStockProducts()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, dto.APIResponse{
+			Success: false,
+			Message: "Failed to get low stock products",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Low stock products retrieved successfully",
+		Data:    products,
+	})
+}
+
+// GetAlerts godoc
+// @Summary Get stock alerts
+// @Description Get the alerts raised when a product dropped below its minimum stock, newest first
+// @Tags stock-alerts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "open (default), acknowledged or all"
+// @Success 200 {object} dto.APIResponse{data=[]dto.StockAlertResponse}
+// @Failure 500 {object} dto.APIResponse
+// @Router /stock-alerts [get]
+func (c *StockAlertController) GetAlerts(ctx *gin.Context) {
+	alerts, err := c.stockAlertService.GetAlerts(ctx.Query("status"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, dto.APIResponse{
+			Success: false,
+			Message: "Failed to get stock alerts",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Stock alerts retrieved successfully",
+		Data:    alerts,
+	})
+}
+
+// AcknowledgeAlert godoc
+// @Summary Acknowledge stock alert
+// @Description Mark a stock alert as seen
+// @Tags stock-alerts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Stock alert ID"
+// @Success 200 {object} dto.APIResponse{data=dto.StockAlertResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /stock-alerts/{id}/acknowledge [post]
+func (c *StockAlertController) AcknowledgeAlert(ctx *gin.Context) {
+	id, ok := pathID(ctx, "id", "stock alert")
+	if !ok {
+		return
+	}
+
+	userID, _, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	alert, err := c.stockAlertService.AcknowledgeAlert(id, userID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to acknowledge stock alert",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Stock alert acknowledged successfully",
+		Data:    alert,
+	})
+}
+
+// StreamAlerts godoc
+// @Summary Stream stock alerts
+// @Description Server-Sent Events stream of stock.low events, sent once when a product drops below its minimum stock. EventSource clients authenticate with a token from POST /auth/stream-token in the token query parameter
+// @Tags stock-alerts
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Param token query string false "Stream token, instead of the Authorization header"
+// @Success 200 {object} dto.StockAlertResponse
+// @Router /stock-alerts/stream [get]
+func (c *StockAlertController) StreamAlerts(ctx *gin.Context) {
+	events, unsubscribe := c.stockAlertService.Subscribe()
+	defer unsubscribe()
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			ctx.SSEvent(event.Type, event.Data)
+			return true
+		case <-keepAlive.C:
+			ctx.SSEvent("ping", time.Now().Unix())
+			return true
+		case <-ctx.Request.Context().Done():
+			return false
+		}
+	})
+}
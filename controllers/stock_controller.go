@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/services"
+)
+
+type StockController struct {
+	stockService *services.StockService
+}
+
+func NewStockController(stockService *services.StockService) *StockController {
+	return &StockController{stockService: stockService}
+}
+
+// AdjustStock godoc
+// @Summary Adjust product stock
+// @Description Add to (or, with a negative quantity, take from) the stock of a product or of a variant that keeps its own stock, or set it with stock instead of quantity; the change is recorded as an adjustment movement
+// @Tags stock
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Product ID"
+// @Param request body dto.AdjustStockRequest true "Stock adjustment request"
+// @Success 200 {object} dto.APIResponse
+// @Failure 400 {object} dto.APIResponse
+// @Router /products/{id}/stock [patch]
+func (c *StockController) AdjustStock(ctx *gin.Context) {
+	productID, ok := pathID(ctx, "id", "product")
+	if !ok {
+		return
+	}
+
+	var req dto.AdjustStockRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	userID, _, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	req.UserID = userID
+
+	if err := c.stockService.AdjustStock(productID, &req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to update stock",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Stock updated successfully",
+	})
+}
+
+// GetMovements godoc
+// @Summary Get stock movements
+// @Description Get the stock movement history of a product, newest first
+// @Tags stock
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Product ID"
+// @Param variant_id query int false "Only movements of this variant"
+// @Param reason query string false "opening, sale, cancel, refund, adjustment, receive, waste or count"
+// @Param limit query int false "Number of movements (default 50)"
+// @Success 200 {object} dto.APIResponse{data=[]dto.StockMovementResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /products/{id}/stock-movements [get]
+func (c *StockController) GetMovements(ctx *gin.Context) {
+	productID, ok := pathID(ctx, "id", "product")
+	if !ok {
+		return
+	}
+
+	var variantID *uint
+	if variantIDStr := ctx.Query("variant_id"); variantIDStr != "" {
+		id, err := strconv.ParseUint(variantIDStr, 10, 32)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+				Success: false,
+				Message: "Invalid variant ID",
+				Error:   err.Error(),
+			})
+			return
+		}
+		vid := uint(id)
+		variantID = &vid
+	}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 {
+		limit = 50
+	}
+
+	movements, err := c.stockService.GetMovements(productID, variantID, ctx.Query("reason"), limit)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, dto.APIResponse{
+			Success: false,
+			Message: "Product not found",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Stock movements retrieved successfully",
+		Data:    movements,
+	})
+}
@@ -0,0 +1,329 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/services"
+)
+
+type StockCountController struct {
+	stockCountService *services.StockCountService
+}
+
+func NewStockCountController(stockCountService *services.StockCountService) *StockCountController {
+	return &StockCountController{stockCountService: stockCountService}
+}
+
+// GetAllStockCounts godoc
+// @Summary Get all stock counts
+// @Description Get stock counts newest first, with how many of their products have been counted
+// @Tags stock-counts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "counting, posted or cancelled"
+// @Success 200 {object} dto.APIResponse{data=[]dto.StockCountResponse}
+// @Failure 500 {object} dto.APIResponse
+// @Router /stock-counts [get]
+func (c *StockCountController) GetAllStockCounts(ctx *gin.Context) {
+	counts, err := c.stockCountService.GetAllStockCounts(ctx.Query("status"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, dto.APIResponse{
+			Success: false,
+			Message: "Failed to get stock counts",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Stock counts retrieved successfully",
+		Data:    counts,
+	})
+}
+
+// GetStockCountByID godoc
+// @Summary Get stock count by ID
+// @Description Get a stock count with the expected and counted quantity of every product
+// @Tags stock-counts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Stock count ID"
+// @Success 200 {object} dto.APIResponse{data=dto.StockCountResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /stock-counts/{id} [get]
+func (c *StockCountController) GetStockCountByID(ctx *gin.Context) {
+	id, ok := pathID(ctx, "id", "stock count")
+	if !ok {
+		return
+	}
+
+	count, err := c.stockCountService.GetStockCountByID(id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, dto.APIResponse{
+			Success: false,
+			Message: "Stock count not found",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Stock count retrieved successfully",
+		Data:    count,
+	})
+}
+
+// StartStockCount godoc
+// @Summary Start stock count
+// @Description Start a physical count of all products or one category, freezing their current stock as the expected quantity
+// @Tags stock-counts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.StartStockCountRequest true "Start stock count request"
+// @Success 201 {object} dto.APIResponse{data=dto.StockCountResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /stock-counts [post]
+func (c *StockCountController) StartStockCount(ctx *gin.Context) {
+	var req dto.StartStockCountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	userID, _, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	req.UserID = userID
+
+	count, err := c.stockCountService.StartStockCount(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to start stock count",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.APIResponse{
+		Success: true,
+		Message: "Stock count started successfully",
+		Data:    count,
+	})
+}
+
+// RecordCounts godoc
+// @Summary Enter counted quantities
+// @Description Enter the counted quantity of one or more products; with add the quantities are added to what was already counted
+// @Tags stock-counts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Stock count ID"
+// @Param request body dto.RecordStockCountRequest true "Counted quantities"
+// @Success 200 {object} dto.APIResponse{data=dto.StockCountResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /stock-counts/{id}/items [put]
+func (c *StockCountController) RecordCounts(ctx *gin.Context) {
+	id, ok := pathID(ctx, "id", "stock count")
+	if !ok {
+		return
+	}
+
+	var req dto.RecordStockCountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	userID, _, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	req.UserID = userID
+
+	count, err := c.stockCountService.RecordCounts(id, &req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to save counts",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Counts saved successfully",
+		Data:    count,
+	})
+}
+
+// PostStockCount godoc
+// @Summary Post stock count
+// @Description Close the count and adjust the stock of every counted product by its variance
+// @Tags stock-counts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Stock count ID"
+// @Success 200 {object} dto.APIResponse{data=dto.StockCountResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /stock-counts/{id}/post [post]
+func (c *StockCountController) PostStockCount(ctx *gin.Context) {
+	id, ok := pathID(ctx, "id", "stock count")
+	if !ok {
+		return
+	}
+
+	userID, _, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	count, err := c.stockCountService.PostStockCount(id, userID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to post stock count",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Stock count posted successfully",
+		Data:    count,
+	})
+}
+
+// CancelStockCount godoc
+// @Summary Cancel stock count
+// @Description Cancel an open stock count without changing stock
+// @Tags stock-counts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Stock count ID"
+// @Success 200 {object} dto.APIResponse{data=dto.StockCountResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /stock-counts/{id}/cancel [post]
+func (c *StockCountController) CancelStockCount(ctx *gin.Context) {
+	id, ok := pathID(ctx, "id", "stock count")
+	if !ok {
+		return
+	}
+
+	count, err := c.stockCountService.CancelStockCount(id)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to cancel stock count",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Stock count cancelled successfully",
+		Data:    count,
+	})
+}
+
+// GetVarianceReport godoc
+// @Summary Get stock count variance report
+// @Description Get the counted products whose count differs from the expected quantity, valued at cost. Use format=csv to download the report.
+// @Tags stock-counts
+// @Accept json
+// @Produce json
+// @Produce text/csv
+// @Security BearerAuth
+// @Param id path int true "Stock count ID"
+// @Param format query string false "json (default) or csv"
+// @Success 200 {object} dto.APIResponse{data=dto.StockCountVarianceResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /stock-counts/{id}/variance [get]
+func (c *StockCountController) GetVarianceReport(ctx *gin.Context) {
+	id, ok := pathID(ctx, "id", "stock count")
+	if !ok {
+		return
+	}
+
+	report, err := c.stockCountService.GetVarianceReport(id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, dto.APIResponse{
+			Success: false,
+			Message: "Stock count not found",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if ctx.Query("format") == "csv" {
+		writeVarianceCSV(ctx, report)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Variance report retrieved successfully",
+		Data:    report,
+	})
+}
+
+// writeVarianceCSV sends the variance report as a CSV download, one row per
+// product or variant followed by the totals
+func writeVarianceCSV(ctx *gin.Context, report *dto.StockCountVarianceResponse) {
+	ctx.Header("Content-Type", "text/csv")
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "variance-"+report.CountNumber+".csv"))
+	ctx.Status(http.StatusOK)
+
+	writer := csv.NewWriter(ctx.Writer)
+	writer.Write([]string{"product_id", "product_name", "variant_id", "variant_name", "expected", "counted", "variance", "unit_cost", "variance_value", "counted_by", "note"})
+	for _, item := range report.Items {
+		variantID := ""
+		if item.VariantID != nil {
+			variantID = strconv.FormatUint(uint64(*item.VariantID), 10)
+		}
+		writer.Write([]string{
+			strconv.FormatUint(uint64(item.ProductID), 10),
+			item.ProductName,
+			variantID,
+			item.VariantName,
+			strconv.Itoa(item.Expected),
+			strconv.Itoa(*item.Counted),
+			strconv.Itoa(item.Variance),
+			strconv.FormatInt(int64(item.UnitCost), 10),
+			strconv.FormatInt(int64(item.VarianceValue), 10),
+			item.CountedBy,
+			item.Note,
+		})
+	}
+	writer.Write([]string{"", "shortage", "", "", "", "", "", "", strconv.FormatInt(int64(-report.ShortageValue), 10), "", ""})
+	writer.Write([]string{"", "surplus", "", "", "", "", "", "", strconv.FormatInt(int64(report.SurplusValue), 10), "", ""})
+	writer.Write([]string{"", "net", "", "", "", "", "", "", strconv.FormatInt(int64(report.NetValue), 10), "", ""})
+	writer.Flush()
+}
@@ -0,0 +1,196 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/services"
+)
+
+type SupplierController struct {
+	supplierService *services.SupplierService
+}
+
+func NewSupplierController(supplierService *services.SupplierService) *SupplierController {
+	return &SupplierController{supplierService: supplierService}
+}
+
+// GetAllSuppliers godoc
+// @Summary Get all suppliers
+// @Description Get list of all suppliers
+// @Tags suppliers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.APIResponse{data=[]dto.SupplierResponse}
+// @Failure 500 {object} dto.APIResponse
+// @Router /suppliers [get]
+func (c *SupplierController) GetAllSuppliers(ctx *gin.Context) {
+	suppliers, err := c.supplierService.GetAllSuppliers()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, dto.APIResponse{
+			Success: false,
+			Message: "Failed to get suppliers",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Suppliers retrieved successfully",
+		Data:    suppliers,
+	})
+}
+
+// GetSupplierByID godoc
+// @Summary Get supplier by ID
+// @Description Get supplier details by ID
+// @Tags suppliers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Supplier ID"
+// @Success 200 {object} dto.APIResponse{data=dto.SupplierResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /suppliers/{id} [get]
+func (c *SupplierController) GetSupplierByID(ctx *gin.Context) {
+	id, ok := pathID(ctx, "id", "supplier")
+	if !ok {
+		return
+	}
+
+	supplier, err := c.supplierService.GetSupplierByID(id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, dto.APIResponse{
+			Success: false,
+			Message: "Supplier not found",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Supplier retrieved successfully",
+		Data:    supplier,
+	})
+}
+
+// CreateSupplier godoc
+// @Summary Create supplier
+// @Description Create a new supplier with its contact details and usual lead time
+// @Tags suppliers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.SupplierRequest true "Supplier request"
+// @Success 201 {object} dto.APIResponse{data=dto.SupplierResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /suppliers [post]
+func (c *SupplierController) CreateSupplier(ctx *gin.Context) {
+	var req dto.SupplierRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	supplier, err := c.supplierService.CreateSupplier(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to create supplier",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.APIResponse{
+		Success: true,
+		Message: "Supplier created successfully",
+		Data:    supplier,
+	})
+}
+
+// UpdateSupplier godoc
+// @Summary Update supplier
+// @Description Update an existing supplier
+// @Tags suppliers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Supplier ID"
+// @Param request body dto.SupplierRequest true "Supplier request"
+// @Success 200 {object} dto.APIResponse{data=dto.SupplierResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /suppliers/{id} [put]
+func (c *SupplierController) UpdateSupplier(ctx *gin.Context) {
+	id, ok := pathID(ctx, "id", "supplier")
+	if !ok {
+		return
+	}
+
+	var req dto.SupplierRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	supplier, err := c.supplierService.UpdateSupplier(id, &req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to update supplier",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Supplier updated successfully",
+		Data:    supplier,
+	})
+}
+
+// DeleteSupplier godoc
+// @Summary Delete supplier
+// @Description Delete a supplier without open purchase orders
+// @Tags suppliers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Supplier ID"
+// @Success 200 {object} dto.APIResponse
+// @Failure 400 {object} dto.APIResponse
+// @Router /suppliers/{id} [delete]
+func (c *SupplierController) DeleteSupplier(ctx *gin.Context) {
+	id, ok := pathID(ctx, "id", "supplier")
+	if !ok {
+		return
+	}
+
+	if err := c.supplierService.DeleteSupplier(id); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to delete supplier",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Supplier deleted successfully",
+	})
+}
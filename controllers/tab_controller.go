@@ -0,0 +1,370 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/services"
+)
+
+type TabController struct {
+	tabService *services.TabService
+}
+
+func NewTabController(tabService *services.TabService) *TabController {
+	return &TabController{tabService: tabService}
+}
+
+// GetOpenTabs godoc
+// @Summary Get open tabs
+// @Description Get every open dine-in tab
+// @Tags tabs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.APIResponse{data=[]dto.TransactionResponse}
+// @Failure 500 {object} dto.APIResponse
+// @Router /tabs [get]
+func (c *TabController) GetOpenTabs(ctx *gin.Context) {
+	tabs, err := c.tabService.GetOpenTabs()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, dto.APIResponse{
+			Success: false,
+			Message: "Failed to get tabs",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Tabs retrieved successfully",
+		Data:    tabs,
+	})
+}
+
+// GetTab godoc
+// @Summary Get tab by ID
+// @Description Get an open tab with its rounds
+// @Tags tabs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Tab ID"
+// @Success 200 {object} dto.APIResponse{data=dto.TransactionResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /tabs/{id} [get]
+func (c *TabController) GetTab(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid tab ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	tab, err := c.tabService.GetTab(uint(id))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, dto.APIResponse{
+			Success: false,
+			Message: "Tab not found",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Tab retrieved successfully",
+		Data:    tab,
+	})
+}
+
+// AddRound godoc
+// @Summary Add round
+// @Description Add a round of items to an open tab
+// @Tags tabs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Tab ID"
+// @Param request body dto.AddRoundRequest true "Add round request"
+// @Success 200 {object} dto.APIResponse{data=dto.TransactionResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /tabs/{id}/rounds [post]
+func (c *TabController) AddRound(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid tab ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	var req dto.AddRoundRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	tab, err := c.tabService.AddRound(uint(id), &req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to add round",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Round added successfully",
+		Data:    tab,
+	})
+}
+
+// MoveTab godoc
+// @Summary Move tab
+// @Description Move an open tab to another free table
+// @Tags tabs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Tab ID"
+// @Param request body dto.MoveTabRequest true "Move tab request"
+// @Success 200 {object} dto.APIResponse{data=dto.TransactionResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /tabs/{id}/move [post]
+func (c *TabController) MoveTab(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid tab ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	var req dto.MoveTabRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	tab, err := c.tabService.MoveTab(uint(id), &req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to move tab",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Tab moved successfully",
+		Data:    tab,
+	})
+}
+
+// MergeTab godoc
+// @Summary Merge tabs
+// @Description Merge another open tab into this tab and free its table
+// @Tags tabs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Tab ID"
+// @Param request body dto.MergeTabRequest true "Merge tabs request"
+// @Success 200 {object} dto.APIResponse{data=dto.TransactionResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /tabs/{id}/merge [post]
+func (c *TabController) MergeTab(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid tab ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	var req dto.MergeTabRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	tab, err := c.tabService.MergeTab(uint(id), &req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to merge tabs",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Tabs merged successfully",
+		Data:    tab,
+	})
+}
+
+// RequestBill godoc
+// @Summary Request bill
+// @Description Mark the table of an open tab as bill requested
+// @Tags tabs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Tab ID"
+// @Success 200 {object} dto.APIResponse{data=dto.TransactionResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /tabs/{id}/request-bill [post]
+func (c *TabController) RequestBill(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid tab ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	tab, err := c.tabService.RequestBill(uint(id))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to request bill",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Bill requested successfully",
+		Data:    tab,
+	})
+}
+
+// CloseTab godoc
+// @Summary Close tab
+// @Description Pay an open tab, turning it into a completed transaction, and free its table
+// @Tags tabs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Tab ID"
+// @Param request body dto.FinalizeOrderRequest true "Close tab request"
+// @Success 200 {object} dto.APIResponse{data=dto.TransactionResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /tabs/{id}/close [post]
+func (c *TabController) CloseTab(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid tab ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	var req dto.FinalizeOrderRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	userID, role, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	req.UserID = userID
+	req.Role = role
+
+	transaction, err := c.tabService.CloseTab(uint(id), &req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to close tab",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Tab closed successfully",
+		Data:    transaction,
+	})
+}
+
+// VoidTab godoc
+// @Summary Void tab
+// @Description Discard an open tab opened by mistake and free its table
+// @Tags tabs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Tab ID"
+// @Success 200 {object} dto.APIResponse
+// @Failure 400 {object} dto.APIResponse
+// @Router /tabs/{id} [delete]
+func (c *TabController) VoidTab(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid tab ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	err = c.tabService.VoidTab(uint(id))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to void tab",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Tab voided successfully",
+	})
+}
@@ -0,0 +1,275 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/services"
+)
+
+type TableController struct {
+	tableService *services.TableService
+	tabService   *services.TabService
+}
+
+func NewTableController(tableService *services.TableService, tabService *services.TabService) *TableController {
+	return &TableController{
+		tableService: tableService,
+		tabService:   tabService,
+	}
+}
+
+// GetAllTables godoc
+// @Summary Get all tables
+// @Description Get the floor view: every table with its status (free, occupied, bill_requested) and open tab
+// @Tags tables
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.APIResponse{data=[]dto.TableResponse}
+// @Failure 500 {object} dto.APIResponse
+// @Router /tables [get]
+func (c *TableController) GetAllTables(ctx *gin.Context) {
+	tables, err := c.tableService.GetAllTables()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, dto.APIResponse{
+			Success: false,
+			Message: "Failed to get tables",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Tables retrieved successfully",
+		Data:    tables,
+	})
+}
+
+// GetTableByID godoc
+// @Summary Get table by ID
+// @Description Get table details by ID
+// @Tags tables
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Table ID"
+// @Success 200 {object} dto.APIResponse{data=dto.TableResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /tables/{id} [get]
+func (c *TableController) GetTableByID(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid table ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	table, err := c.tableService.GetTableByID(uint(id))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, dto.APIResponse{
+			Success: false,
+			Message: "Table not found",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Table retrieved successfully",
+		Data:    table,
+	})
+}
+
+// CreateTable godoc
+// @Summary Create new table
+// @Description Create a new table
+// @Tags tables
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateTableRequest true "Create table request"
+// @Success 201 {object} dto.APIResponse{data=dto.TableResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /tables [post]
+func (c *TableController) CreateTable(ctx *gin.Context) {
+	var req dto.CreateTableRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	table, err := c.tableService.CreateTable(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to create table",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.APIResponse{
+		Success: true,
+		Message: "Table created successfully",
+		Data:    table,
+	})
+}
+
+// UpdateTable godoc
+// @Summary Update table
+// @Description Update table details
+// @Tags tables
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Table ID"
+// @Param request body dto.UpdateTableRequest true "Update table request"
+// @Success 200 {object} dto.APIResponse{data=dto.TableResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /tables/{id} [put]
+func (c *TableController) UpdateTable(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid table ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	var req dto.UpdateTableRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	table, err := c.tableService.UpdateTable(uint(id), &req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to update table",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Table updated successfully",
+		Data:    table,
+	})
+}
+
+// DeleteTable godoc
+// @Summary Delete table
+// @Description Delete table by ID
+// @Tags tables
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Table ID"
+// @Success 200 {object} dto.APIResponse
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /tables/{id} [delete]
+func (c *TableController) DeleteTable(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid table ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	err = c.tableService.DeleteTable(uint(id))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, dto.APIResponse{
+			Success: false,
+			Message: "Failed to delete table",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Table deleted successfully",
+	})
+}
+
+// OpenTab godoc
+// @Summary Open tab on table
+// @Description Open a dine-in tab on a free table
+// @Tags tables
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Table ID"
+// @Param request body dto.OpenTabRequest true "Open tab request"
+// @Success 201 {object} dto.APIResponse{data=dto.TransactionResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /tables/{id}/open [post]
+func (c *TableController) OpenTab(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid table ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	var req dto.OpenTabRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	userID, _, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	req.UserID = userID
+
+	tab, err := c.tabService.OpenTab(uint(id), &req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to open tab",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.APIResponse{
+		Success: true,
+		Message: "Tab opened successfully",
+		Data:    tab,
+	})
+}
@@ -28,25 +28,33 @@ func NewTransactionController(transactionService *services.TransactionService) *
 // @Param start_date query string false "Start date (YYYY-MM-DD)"
 // @Param end_date query string false "End date (YYYY-MM-DD)"
 // @Param payment_method query string false "Filter by payment method"
+// @Param order_type query string false "Filter by order type (dine_in, takeaway, delivery)"
 // @Success 200 {object} dto.APIResponse{data=[]dto.TransactionResponse}
+// @Failure 400 {object} dto.APIResponse
 // @Failure 500 {object} dto.APIResponse
 // @Router /transactions [get]
 func (c *TransactionController) GetAllTransactions(ctx *gin.Context) {
-	startDateStr := ctx.Query("start_date")
-	endDateStr := ctx.Query("end_date")
-	paymentMethod := ctx.Query("payment_method")
+	var filter dto.TransactionFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid query parameters",
+			Error:   err.Error(),
+		})
+		return
+	}
 
 	var startDate, endDate *time.Time
 
-	if startDateStr != "" {
-		t, err := time.Parse("2006-01-02", startDateStr)
+	if filter.StartDate != "" {
+		t, err := time.Parse("2006-01-02", filter.StartDate)
 		if err == nil {
 			startDate = &t
 		}
 	}
 
-	if endDateStr != "" {
-		t, err := time.Parse("2006-01-02", endDateStr)
+	if filter.EndDate != "" {
+		t, err := time.Parse("2006-01-02", filter.EndDate)
 		if err == nil {
 			// Set to end of day
 			t = t.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
@@ -54,7 +62,7 @@ func (c *TransactionController) GetAllTransactions(ctx *gin.Context) {
 		}
 	}
 
-	transactions, err := c.transactionService.GetAllTransactions(startDate, endDate, paymentMethod)
+	transactions, err := c.transactionService.GetAllTransactions(startDate, endDate, filter.PaymentMethod, filter.OrderType)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, dto.APIResponse{
 			Success: false,
@@ -175,6 +183,11 @@ func (c *TransactionController) CreateTransaction(ctx *gin.Context) {
 	}
 	req.UserID = userID.(uint)
 
+	// The role caps how much manual discount the cashier may give
+	if role, exists := ctx.Get("role"); exists {
+		req.Role, _ = role.(string)
+	}
+
 	transaction, err := c.transactionService.CreateTransaction(&req)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
@@ -207,7 +220,7 @@ func (c *TransactionController) GetTodayTransactions(ctx *gin.Context) {
 	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 	endOfDay := time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 59, 999999999, now.Location())
 
-	transactions, err := c.transactionService.GetAllTransactions(&startOfDay, &endOfDay, "")
+	transactions, err := c.transactionService.GetAllTransactions(&startOfDay, &endOfDay, "", "")
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, dto.APIResponse{
 			Success: false,
@@ -266,7 +279,7 @@ func (c *TransactionController) GetTransactionsByUser(ctx *gin.Context) {
 
 // CancelTransaction godoc
 // @Summary Cancel transaction
-// @Description Cancel/void a transaction
+// @Description Cancel/void a transaction; cancelling a split order cancels all its bills
 // @Tags transactions
 // @Accept json
 // @Produce json
@@ -287,7 +300,12 @@ func (c *TransactionController) CancelTransaction(ctx *gin.Context) {
 		return
 	}
 
-	err = c.transactionService.CancelTransaction(uint(id))
+	userID, _, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	err = c.transactionService.CancelTransaction(uint(id), userID)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
 			Success: false,
@@ -94,6 +94,42 @@ func (c *UserController) Login(ctx *gin.Context) {
 	})
 }
 
+// CreateStreamToken godoc
+// @Summary Create stream token
+// @Description Short-lived token for Server-Sent Events routes, passed as the token query parameter because EventSource cannot send an Authorization header
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.APIResponse{data=dto.StreamTokenResponse}
+// @Failure 401 {object} dto.APIResponse
+// @Router /auth/stream-token [post]
+func (c *UserController) CreateStreamToken(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, dto.APIResponse{
+			Success: false,
+			Message: "Unauthorized",
+		})
+		return
+	}
+
+	response, err := c.userService.GenerateStreamToken(userID.(uint))
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, dto.APIResponse{
+			Success: false,
+			Message: "Failed to create stream token",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Stream token created successfully",
+		Data:    response,
+	})
+}
+
 // GetAllUsers godoc
 // @Summary Get all users
 // @Description Get list of all users
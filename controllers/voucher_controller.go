@@ -0,0 +1,294 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/services"
+)
+
+type VoucherController struct {
+	voucherService *services.VoucherService
+}
+
+func NewVoucherController(voucherService *services.VoucherService) *VoucherController {
+	return &VoucherController{voucherService: voucherService}
+}
+
+// GetAllVouchers godoc
+// @Summary Get all vouchers
+// @Description Get list of all vouchers, optionally only those of a generated batch
+// @Tags vouchers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param batch_code query string false "Filter by batch code"
+// @Success 200 {object} dto.APIResponse{data=[]dto.VoucherResponse}
+// @Failure 500 {object} dto.APIResponse
+// @Router /vouchers [get]
+func (c *VoucherController) GetAllVouchers(ctx *gin.Context) {
+	vouchers, err := c.voucherService.GetAllVouchers(ctx.Query("batch_code"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, dto.APIResponse{
+			Success: false,
+			Message: "Failed to get vouchers",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Vouchers retrieved successfully",
+		Data:    vouchers,
+	})
+}
+
+// GenerateVouchers godoc
+// @Summary Generate single-use vouchers
+// @Description Generate a batch of random single-use voucher codes
+// @Tags vouchers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.GenerateVouchersRequest true "Generate vouchers request"
+// @Success 201 {object} dto.APIResponse{data=[]dto.VoucherResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /vouchers/batch [post]
+func (c *VoucherController) GenerateVouchers(ctx *gin.Context) {
+	var req dto.GenerateVouchersRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	vouchers, err := c.voucherService.GenerateVouchers(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to generate vouchers",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.APIResponse{
+		Success: true,
+		Message: "Vouchers generated successfully",
+		Data:    vouchers,
+	})
+}
+
+// ValidateVoucher godoc
+// @Summary Validate voucher
+// @Description Check a voucher code and preview its discount before payment
+// @Tags vouchers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.ValidateVoucherRequest true "Validate voucher request"
+// @Success 200 {object} dto.APIResponse{data=dto.VoucherValidationResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /vouchers/validate [post]
+func (c *VoucherController) ValidateVoucher(ctx *gin.Context) {
+	var req dto.ValidateVoucherRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	result, err := c.voucherService.ValidateVoucher(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Voucher is not valid",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Voucher is valid",
+		Data:    result,
+	})
+}
+
+// GetVoucherByID godoc
+// @Summary Get voucher by ID
+// @Description Get voucher details by ID
+// @Tags vouchers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Voucher ID"
+// @Success 200 {object} dto.APIResponse{data=dto.VoucherResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /vouchers/{id} [get]
+func (c *VoucherController) GetVoucherByID(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid voucher ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	voucher, err := c.voucherService.GetVoucherByID(uint(id))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, dto.APIResponse{
+			Success: false,
+			Message: "Voucher not found",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Voucher retrieved successfully",
+		Data:    voucher,
+	})
+}
+
+// CreateVoucher godoc
+// @Summary Create new voucher
+// @Description Create a new voucher
+// @Tags vouchers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.VoucherRequest true "Create voucher request"
+// @Success 201 {object} dto.APIResponse{data=dto.VoucherResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /vouchers [post]
+func (c *VoucherController) CreateVoucher(ctx *gin.Context) {
+	var req dto.VoucherRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	voucher, err := c.voucherService.CreateVoucher(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to create voucher",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.APIResponse{
+		Success: true,
+		Message: "Voucher created successfully",
+		Data:    voucher,
+	})
+}
+
+// UpdateVoucher godoc
+// @Summary Update voucher
+// @Description Update voucher details
+// @Tags vouchers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Voucher ID"
+// @Param request body dto.VoucherRequest true "Update voucher request"
+// @Success 200 {object} dto.APIResponse{data=dto.VoucherResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /vouchers/{id} [put]
+func (c *VoucherController) UpdateVoucher(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid voucher ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	var req dto.VoucherRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	voucher, err := c.voucherService.UpdateVoucher(uint(id), &req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to update voucher",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Voucher updated successfully",
+		Data:    voucher,
+	})
+}
+
+// DeleteVoucher godoc
+// @Summary Delete voucher
+// @Description Delete voucher by ID
+// @Tags vouchers
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Voucher ID"
+// @Success 200 {object} dto.APIResponse
+// @Failure 400 {object} dto.APIResponse
+// @Failure 404 {object} dto.APIResponse
+// @Router /vouchers/{id} [delete]
+func (c *VoucherController) DeleteVoucher(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid voucher ID",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	err = c.voucherService.DeleteVoucher(uint(id))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, dto.APIResponse{
+			Success: false,
+			Message: "Failed to delete voucher",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Voucher deleted successfully",
+	})
+}
@@ -0,0 +1,195 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/services"
+)
+
+type WasteController struct {
+	wasteService *services.WasteService
+}
+
+func NewWasteController(wasteService *services.WasteService) *WasteController {
+	return &WasteController{wasteService: wasteService}
+}
+
+// RecordWaste godoc
+// @Summary Record waste
+// @Description Record a product (whole units) or an ingredient thrown away; stock goes down and the loss is valued at cost. Products made to a recipe use up their ingredients.
+// @Tags waste
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.WasteRequest true "Waste request"
+// @Success 201 {object} dto.APIResponse{data=dto.WasteEntryResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Router /waste [post]
+func (c *WasteController) RecordWaste(ctx *gin.Context) {
+	var req dto.WasteRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid request body",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	userID, _, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+	req.UserID = userID
+
+	entry, err := c.wasteService.RecordWaste(&req)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Failed to record waste",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, dto.APIResponse{
+		Success: true,
+		Message: "Waste recorded successfully",
+		Data:    entry,
+	})
+}
+
+// GetWasteEntries godoc
+// @Summary Get waste entries
+// @Description List recorded waste, newest first, with optional date and reason filters
+// @Tags waste
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param start_date query string false "Start date (YYYY-MM-DD)"
+// @Param end_date query string false "End date (YYYY-MM-DD)"
+// @Param reason query string false "Filter by reason (expired, spoiled, damaged, spilled, other)"
+// @Success 200 {object} dto.APIResponse{data=[]dto.WasteEntryResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 500 {object} dto.APIResponse
+// @Router /waste [get]
+func (c *WasteController) GetWasteEntries(ctx *gin.Context) {
+	var filter dto.WasteFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid query parameters",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	var startDate, endDate *time.Time
+
+	if filter.StartDate != "" {
+		t, err := time.Parse("2006-01-02", filter.StartDate)
+		if err == nil {
+			startDate = &t
+		}
+	}
+
+	if filter.EndDate != "" {
+		t, err := time.Parse("2006-01-02", filter.EndDate)
+		if err == nil {
+			// Set to end of day
+			t = t.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
+			endDate = &t
+		}
+	}
+
+	entries, err := c.wasteService.GetWasteEntries(startDate, endDate, filter.Reason)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, dto.APIResponse{
+			Success: false,
+			Message: "Failed to get waste entries",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Waste entries retrieved successfully",
+		Data:    entries,
+	})
+}
+
+// GetWasteReport godoc
+// @Summary Get waste report
+// @Description Waste cost by reason, by item and by day, next to the net revenue of the same period
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param start_date query string true "Start date (YYYY-MM-DD)"
+// @Param end_date query string true "End date (YYYY-MM-DD)"
+// @Success 200 {object} dto.APIResponse{data=dto.WasteReportResponse}
+// @Failure 400 {object} dto.APIResponse
+// @Failure 500 {object} dto.APIResponse
+// @Router /reports/waste [get]
+func (c *WasteController) GetWasteReport(ctx *gin.Context) {
+	startDateStr := ctx.Query("start_date")
+	endDateStr := ctx.Query("end_date")
+
+	if startDateStr == "" || endDateStr == "" {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "start_date and end_date are required",
+		})
+		return
+	}
+
+	startDate, err := time.Parse("2006-01-02", startDateStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid start_date format. Use YYYY-MM-DD",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	endDate, err := time.Parse("2006-01-02", endDateStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "Invalid end_date format. Use YYYY-MM-DD",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	if endDate.Before(startDate) {
+		ctx.JSON(http.StatusBadRequest, dto.APIResponse{
+			Success: false,
+			Message: "end_date cannot be before start_date",
+		})
+		return
+	}
+
+	endDate = endDate.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
+
+	report, err := c.wasteService.GetWasteReport(startDate, endDate)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, dto.APIResponse{
+			Success: false,
+			Message: "Failed to get waste report",
+			Error:   err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, dto.APIResponse{
+		Success: true,
+		Message: "Waste report retrieved successfully",
+		Data:    report,
+	})
+}
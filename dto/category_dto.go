@@ -1,14 +1,17 @@
 package dto
 
 type CreateCategoryRequest struct {
-	Name string `json:"name" binding:"required,min=2"`
+	Name    string `json:"name" binding:"required,min=2"`
+	Station string `json:"station" binding:"omitempty,max=30"` // Kitchen display station, defaults to kitchen
 }
 
 type UpdateCategoryRequest struct {
-	Name string `json:"name" binding:"required,min=2"`
+	Name    string `json:"name" binding:"required,min=2"`
+	Station string `json:"station" binding:"omitempty,max=30"` // Kitchen display station, defaults to kitchen
 }
 
 type CategoryResponse struct {
-	ID   uint   `json:"id"`
-	Name string `json:"name"`
+	ID      uint   `json:"id"`
+	Name    string `json:"name"`
+	Station string `json:"station"`
 }
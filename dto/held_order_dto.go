@@ -0,0 +1,24 @@
+package dto
+
+import "github.com/syrlramadhan/cashier-app/models"
+
+type HeldOrderRequest struct {
+	UserID    uint               `json:"-"` // Set by controller from auth
+	Items     []OrderItemRequest `json:"items" binding:"required,min=1,dive"`
+	Note      string             `json:"note" binding:"max=255"`                                         // e.g. customer name
+	OrderType string             `json:"order_type" binding:"omitempty,oneof=dine_in takeaway delivery"` // Defaults to takeaway
+}
+
+// FinalizeOrderRequest settles a held order; discounts and vouchers are
+// applied at this point, like on a direct checkout.
+type FinalizeOrderRequest struct {
+	UserID          uint             `json:"-"` // Set by controller from auth
+	Role            string           `json:"-"` // Set by controller from auth; caps manual discounts
+	PaymentMethod   string           `json:"payment_method" binding:"required_without=Payments,omitempty,oneof=cash card qris"`
+	Payments        []PaymentRequest `json:"payments" binding:"omitempty,dive"`
+	AmountTendered  *models.Money    `json:"amount_tendered" binding:"omitempty,gte=0"`
+	DiscountPercent float64          `json:"discount_percent" binding:"gte=0,lte=100"`
+	DiscountAmount  models.Money     `json:"discount_amount" binding:"gte=0"`
+	VoucherCode     string           `json:"voucher_code" binding:"max=50"`
+	CustomerRef     string           `json:"customer_ref" binding:"max=100"`
+}
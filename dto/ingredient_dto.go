@@ -0,0 +1,53 @@
+package dto
+
+import "github.com/syrlramadhan/cashier-app/models"
+
+type CreateIngredientRequest struct {
+	Name  string       `json:"name" binding:"required,max=100"`
+	Unit  string       `json:"unit" binding:"required,max=20"` // e.g. g, ml, pcs
+	Stock float64      `json:"stock" binding:"gte=0"`
+	Cost  models.Money `json:"cost" binding:"gte=0"` // Cost of one unit
+}
+
+// UpdateIngredientRequest leaves the stock alone; it only changes through PATCH /ingredients/:id/stock
+type UpdateIngredientRequest struct {
+	Name string       `json:"name" binding:"required,max=100"`
+	Unit string       `json:"unit" binding:"required,max=20"`
+	Cost models.Money `json:"cost" binding:"gte=0"`
+}
+
+// UpdateIngredientStockRequest adds Quantity to the stock; negative values take stock out
+type UpdateIngredientStockRequest struct {
+	Quantity float64 `json:"quantity" binding:"required"`
+}
+
+type IngredientResponse struct {
+	ID    uint         `json:"id"`
+	Name  string       `json:"name"`
+	Unit  string       `json:"unit"`
+	Stock float64      `json:"stock"`
+	Cost  models.Money `json:"cost"`
+}
+
+type RecipeItemRequest struct {
+	IngredientID uint    `json:"ingredient_id" binding:"required"`
+	Quantity     float64 `json:"quantity" binding:"required,gt=0"` // Per unit sold, in the ingredient unit
+}
+
+// RecipeRequest replaces a recipe; an empty list removes it
+type RecipeRequest struct {
+	Items []RecipeItemRequest `json:"items" binding:"omitempty,dive"`
+}
+
+type RecipeItemResponse struct {
+	IngredientID   uint         `json:"ingredient_id"`
+	IngredientName string       `json:"ingredient_name"`
+	Unit           string       `json:"unit"`
+	Quantity       float64      `json:"quantity"`
+	Cost           models.Money `json:"cost"`
+}
+
+type RecipeResponse struct {
+	Items []RecipeItemResponse `json:"items"`
+	Cost  models.Money         `json:"cost"` // Ingredient cost of one unit
+}
@@ -0,0 +1,27 @@
+package dto
+
+import "time"
+
+type KitchenTicketItemResponse struct {
+	TransactionItemID uint   `json:"transaction_item_id"`
+	ProductName       string `json:"product_name"`
+	VariantName       string `json:"variant_name,omitempty"`
+	Modifiers         string `json:"modifiers,omitempty"`
+	Quantity          int    `json:"quantity"`
+}
+
+type KitchenTicketResponse struct {
+	ID              uint                        `json:"id"`
+	TransactionID   uint                        `json:"transaction_id"`
+	TransactionCode string                      `json:"transaction_code"`
+	TableID         *uint                       `json:"table_id,omitempty"`
+	OrderType       string                      `json:"order_type"`
+	Station         string                      `json:"station"`
+	Round           int                         `json:"round,omitempty"`
+	Status          string                      `json:"status"`
+	Items           []KitchenTicketItemResponse `json:"items"`
+	StartedAt       *time.Time                  `json:"started_at,omitempty"`
+	ReadyAt         *time.Time                  `json:"ready_at,omitempty"`
+	ServedAt        *time.Time                  `json:"served_at,omitempty"`
+	CreatedAt       time.Time                   `json:"created_at"`
+}
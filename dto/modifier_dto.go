@@ -0,0 +1,47 @@
+package dto
+
+import "github.com/syrlramadhan/cashier-app/models"
+
+// ModifierOptionRequest is an add-on of a modifier group; options with an ID
+// are updated, options without one are added and options left out are removed.
+type ModifierOptionRequest struct {
+	ID       *uint        `json:"id"`
+	Name     string       `json:"name" binding:"required,max=100"`
+	Price    models.Money `json:"price" binding:"gte=0"`
+	IsActive *bool        `json:"is_active"` // Defaults to true
+}
+
+// ModifierGroupRequest creates or updates a modifier group. MaxSelect 0 means
+// any number of options may be chosen.
+type ModifierGroupRequest struct {
+	Name        string                  `json:"name" binding:"required,max=100"` // e.g. Extras, Toppings, Milk
+	MinSelect   int                     `json:"min_select" binding:"gte=0"`
+	MaxSelect   int                     `json:"max_select" binding:"gte=0"`
+	Options     []ModifierOptionRequest `json:"options" binding:"required,min=1,dive"`
+	ProductIDs  []uint                  `json:"product_ids"`
+	CategoryIDs []uint                  `json:"category_ids"`
+}
+
+type ModifierOptionResponse struct {
+	ID       uint         `json:"id"`
+	Name     string       `json:"name"`
+	Price    models.Money `json:"price"`
+	IsActive bool         `json:"is_active"`
+}
+
+type ModifierGroupResponse struct {
+	ID          uint                     `json:"id"`
+	Name        string                   `json:"name"`
+	MinSelect   int                      `json:"min_select"`
+	MaxSelect   int                      `json:"max_select"`
+	Options     []ModifierOptionResponse `json:"options"`
+	ProductIDs  []uint                   `json:"product_ids,omitempty"`
+	CategoryIDs []uint                   `json:"category_ids,omitempty"`
+}
+
+type TransactionItemModifierResponse struct {
+	ModifierOptionID uint         `json:"modifier_option_id"`
+	GroupName        string       `json:"group_name"`
+	Name             string       `json:"name"`
+	Price            models.Money `json:"price"`
+}
@@ -1,40 +1,67 @@
 package dto
 
+import "github.com/syrlramadhan/cashier-app/models"
+
+type ProductPriceRequest struct {
+	OrderType string       `json:"order_type" binding:"required,oneof=dine_in takeaway delivery"`
+	Price     models.Money `json:"price" binding:"required,gt=0"`
+}
+
 type CreateProductRequest struct {
-	Name       string  `json:"name" binding:"required,min=2"`
-	Price      float64 `json:"price" binding:"required,gt=0"`
-	Stock      int     `json:"stock" binding:"gte=0"`
-	CategoryID uint    `json:"category_id" binding:"required"`
-	Image      string  `json:"image"`
+	Name       string                `json:"name" binding:"required,min=2"`
+	Price      models.Money          `json:"price" binding:"required,gt=0"`
+	Cost       models.Money          `json:"cost" binding:"gte=0"` // Cost of one unit
+	Stock      int                   `json:"stock" binding:"gte=0"`
+	MinStock   *int                  `json:"min_stock" binding:"omitempty,gte=0"` // Leave out to use the low_stock_threshold setting
+	ReorderQty int                   `json:"reorder_qty" binding:"gte=0"`
+	SupplierID *uint                 `json:"supplier_id"` // Usual supplier
+	CategoryID uint                  `json:"category_id" binding:"required"`
+	Image      string                `json:"image"`
+	Prices     []ProductPriceRequest `json:"prices" binding:"omitempty,dive"` // Per order type overrides of price
+	UserID     uint                  `json:"-"`                               // Set by controller from auth
 }
 
+// UpdateProductRequest leaves the stock alone; it only changes through PATCH /products/:id/stock
 type UpdateProductRequest struct {
-	Name       string  `json:"name" binding:"required,min=2"`
-	Price      float64 `json:"price" binding:"required,gt=0"`
-	Stock      int     `json:"stock" binding:"gte=0"`
-	CategoryID uint    `json:"category_id" binding:"required"`
-	Image      string  `json:"image"`
+	Name       string                `json:"name" binding:"required,min=2"`
+	Price      models.Money          `json:"price" binding:"required,gt=0"`
+	Cost       *models.Money         `json:"cost" binding:"omitempty,gte=0"`      // Leave out to keep the cost kept up to date by goods receiving
+	MinStock   *int                  `json:"min_stock" binding:"omitempty,gte=0"` // Leave out to use the low_stock_threshold setting
+	ReorderQty int                   `json:"reorder_qty" binding:"gte=0"`
+	SupplierID *uint                 `json:"supplier_id"` // Usual supplier
+	CategoryID uint                  `json:"category_id" binding:"required"`
+	Image      string                `json:"image"`
+	Prices     []ProductPriceRequest `json:"prices" binding:"omitempty,dive"` // Per order type overrides of price
 }
 
-type UpdateStockRequest struct {
-	Stock int `json:"stock" binding:"gte=0"`
+type ProductPriceResponse struct {
+	OrderType string       `json:"order_type"`
+	Price     models.Money `json:"price"`
 }
 
 type ProductResponse struct {
-	ID         uint    `json:"id"`
-	Name       string  `json:"name"`
-	Price      float64 `json:"price"`
-	Stock      int     `json:"stock"`
-	CategoryID uint    `json:"category_id"`
-	Image      string  `json:"image,omitempty"`
+	ID            uint                     `json:"id"`
+	Name          string                   `json:"name"`
+	Price         models.Money             `json:"price"`
+	Cost          models.Money             `json:"cost"`
+	Prices        []ProductPriceResponse   `json:"prices,omitempty"`
+	Stock         int                      `json:"stock"`
+	MinStock      *int                     `json:"min_stock"` // Nil when the product uses the low_stock_threshold setting
+	ReorderQty    int                      `json:"reorder_qty"`
+	SupplierID    *uint                    `json:"supplier_id,omitempty"`
+	Available     *int                     `json:"available,omitempty"` // Units the ingredients in stock can make; only for products with a recipe
+	CategoryID    uint                     `json:"category_id"`
+	Image         string                   `json:"image,omitempty"`
+	VariantGroups []VariantGroupResponse   `json:"variant_groups,omitempty"`
+	Variants      []ProductVariantResponse `json:"variants,omitempty"`
 }
 
 type ProductListResponse struct {
-	ID           uint    `json:"id"`
-	Name         string  `json:"name"`
-	Price        float64 `json:"price"`
-	Stock        int     `json:"stock"`
-	CategoryID   uint    `json:"category_id"`
-	CategoryName string  `json:"category"`
-	Image        string  `json:"image,omitempty"`
+	ID           uint         `json:"id"`
+	Name         string       `json:"name"`
+	Price        models.Money `json:"price"`
+	Stock        int          `json:"stock"`
+	CategoryID   uint         `json:"category_id"`
+	CategoryName string       `json:"category"`
+	Image        string       `json:"image,omitempty"`
 }
@@ -0,0 +1,69 @@
+package dto
+
+import "github.com/syrlramadhan/cashier-app/models"
+
+// VariantOptionRequest is an option of a variant group; options with an ID
+// are renamed or reordered, options without one are added and options left
+// out are removed.
+type VariantOptionRequest struct {
+	ID   *uint  `json:"id"`
+	Name string `json:"name" binding:"required,max=50"`
+}
+
+type VariantGroupRequest struct {
+	Name     string                 `json:"name" binding:"required,max=50"` // e.g. Size, Temperature, Sugar Level
+	Position int                    `json:"position" binding:"gte=0"`
+	Options  []VariantOptionRequest `json:"options" binding:"required,min=1,dive"`
+}
+
+// ProductVariantRequest is a sellable variant: one option of every variant
+// group of the product. Price replaces the product price; without it the
+// variant costs the product price plus PriceDelta. With TrackStock the
+// variant keeps its own stock instead of drawing on the product's.
+type ProductVariantRequest struct {
+	SKU        string        `json:"sku" binding:"max=50"`
+	OptionIDs  []uint        `json:"option_ids" binding:"required,min=1"`
+	Price      *models.Money `json:"price" binding:"omitempty,gt=0"`
+	PriceDelta models.Money  `json:"price_delta"`
+	TrackStock bool          `json:"track_stock"`
+	IsActive   *bool         `json:"is_active"` // Defaults to true
+}
+
+// CreateProductVariantRequest adds the stock a variant with TrackStock starts
+// with; afterwards it only changes through PATCH /products/:id/stock
+type CreateProductVariantRequest struct {
+	ProductVariantRequest
+	Stock  int  `json:"stock" binding:"gte=0"`
+	UserID uint `json:"-"` // Set by controller from auth
+}
+
+type VariantOptionResponse struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+type VariantGroupResponse struct {
+	ID       uint                    `json:"id"`
+	Name     string                  `json:"name"`
+	Position int                     `json:"position"`
+	Options  []VariantOptionResponse `json:"options"`
+}
+
+type ProductVariantResponse struct {
+	ID         uint                    `json:"id"`
+	ProductID  uint                    `json:"product_id"`
+	SKU        string                  `json:"sku,omitempty"`
+	Name       string                  `json:"name"`
+	Price      *models.Money           `json:"price,omitempty"`
+	PriceDelta models.Money            `json:"price_delta"`
+	TrackStock bool                    `json:"track_stock"`
+	Stock      int                     `json:"stock"`
+	IsActive   bool                    `json:"is_active"`
+	Options    []VariantOptionResponse `json:"options"`
+}
+
+type ProductVariantsResponse struct {
+	ProductID uint                     `json:"product_id"`
+	Groups    []VariantGroupResponse   `json:"groups"`
+	Variants  []ProductVariantResponse `json:"variants"`
+}
@@ -0,0 +1,44 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/syrlramadhan/cashier-app/models"
+)
+
+type PromotionRequest struct {
+	Name            string       `json:"name" binding:"required,min=2,max=100"`
+	Type            string       `json:"type" binding:"required,oneof=percentage fixed buy_x_get_y"`
+	Scope           string       `json:"scope" binding:"required,oneof=order product category"`
+	DiscountPercent float64      `json:"discount_percent" binding:"gte=0,lte=100"`
+	DiscountAmount  models.Money `json:"discount_amount" binding:"gte=0"`
+	BuyQuantity     int          `json:"buy_quantity" binding:"gte=0"`
+	GetQuantity     int          `json:"get_quantity" binding:"gte=0"`
+	ProductID       *uint        `json:"product_id"`
+	CategoryID      *uint        `json:"category_id"`
+	MinSubtotal     models.Money `json:"min_subtotal" binding:"gte=0"`
+	StartsAt        *time.Time   `json:"starts_at"`
+	EndsAt          *time.Time   `json:"ends_at"`
+	StartTime       string       `json:"start_time" binding:"omitempty,datetime=15:04"` // happy hour start (HH:MM)
+	EndTime         string       `json:"end_time" binding:"omitempty,datetime=15:04"`   // happy hour end (HH:MM)
+	IsActive        *bool        `json:"is_active"`
+}
+
+type PromotionResponse struct {
+	ID              uint         `json:"id"`
+	Name            string       `json:"name"`
+	Type            string       `json:"type"`
+	Scope           string       `json:"scope"`
+	DiscountPercent float64      `json:"discount_percent"`
+	DiscountAmount  models.Money `json:"discount_amount"`
+	BuyQuantity     int          `json:"buy_quantity"`
+	GetQuantity     int          `json:"get_quantity"`
+	ProductID       *uint        `json:"product_id,omitempty"`
+	CategoryID      *uint        `json:"category_id,omitempty"`
+	MinSubtotal     models.Money `json:"min_subtotal"`
+	StartsAt        *time.Time   `json:"starts_at,omitempty"`
+	EndsAt          *time.Time   `json:"ends_at,omitempty"`
+	StartTime       string       `json:"start_time,omitempty"`
+	EndTime         string       `json:"end_time,omitempty"`
+	IsActive        bool         `json:"is_active"`
+}
@@ -0,0 +1,89 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/syrlramadhan/cashier-app/models"
+)
+
+type PurchaseOrderItemRequest struct {
+	ProductID uint         `json:"product_id" binding:"required"`
+	VariantID *uint        `json:"variant_id"` // A variant that keeps its own stock
+	Quantity  int          `json:"quantity" binding:"required,gt=0"`
+	UnitCost  models.Money `json:"unit_cost" binding:"gte=0"` // Defaults to the current cost of the product
+}
+
+type PurchaseOrderRequest struct {
+	SupplierID uint                       `json:"supplier_id" binding:"required"`
+	ExpectedAt *time.Time                 `json:"expected_at"`
+	Notes      string                     `json:"notes" binding:"max=255"`
+	Items      []PurchaseOrderItemRequest `json:"items" binding:"required,min=1,dive"`
+	UserID     uint                       `json:"-"` // Set by controller from auth
+}
+
+type ReceiveItemRequest struct {
+	PurchaseOrderItemID uint          `json:"purchase_order_item_id" binding:"required"`
+	Quantity            int           `json:"quantity" binding:"gte=0"`            // Units put in stock
+	RejectedQuantity    int           `json:"rejected_quantity" binding:"gte=0"`   // Units delivered but sent back (damaged, wrong item)
+	UnitCost            *models.Money `json:"unit_cost" binding:"omitempty,gte=0"` // Cost on the invoice; defaults to the ordered cost
+	Note                string        `json:"note" binding:"max=255"`
+}
+
+type ReceivePurchaseOrderRequest struct {
+	Items  []ReceiveItemRequest `json:"items" binding:"required,min=1,dive"`
+	Notes  string               `json:"notes" binding:"max=255"`
+	UserID uint                 `json:"-"` // Set by controller from auth
+}
+
+type PurchaseOrderItemResponse struct {
+	ID               uint         `json:"id"`
+	ProductID        uint         `json:"product_id"`
+	ProductName      string       `json:"product_name"`
+	VariantID        *uint        `json:"variant_id,omitempty"`
+	VariantName      string       `json:"variant_name,omitempty"`
+	Quantity         int          `json:"quantity"`
+	ReceivedQuantity int          `json:"received_quantity"`
+	RejectedQuantity int          `json:"rejected_quantity"`
+	Outstanding      int          `json:"outstanding"` // Ordered but not received yet
+	UnitCost         models.Money `json:"unit_cost"`
+	Subtotal         models.Money `json:"subtotal"`
+}
+
+type GoodsReceiptItemResponse struct {
+	PurchaseOrderItemID uint         `json:"purchase_order_item_id"`
+	ProductID           uint         `json:"product_id"`
+	ProductName         string       `json:"product_name"`
+	VariantID           *uint        `json:"variant_id,omitempty"`
+	Quantity            int          `json:"quantity"`
+	RejectedQuantity    int          `json:"rejected_quantity"`
+	UnitCost            models.Money `json:"unit_cost"`
+	CostVariance        models.Money `json:"cost_variance"` // Unit cost received less unit cost ordered
+	Note                string       `json:"note,omitempty"`
+}
+
+type GoodsReceiptResponse struct {
+	ID         uint                       `json:"id"`
+	ReceivedBy string                     `json:"received_by"`
+	Notes      string                     `json:"notes,omitempty"`
+	Total      models.Money               `json:"total"`
+	Items      []GoodsReceiptItemResponse `json:"items"`
+	CreatedAt  time.Time                  `json:"created_at"`
+}
+
+type PurchaseOrderResponse struct {
+	ID            uint                        `json:"id"`
+	PONumber      string                      `json:"po_number"`
+	SupplierID    uint                        `json:"supplier_id"`
+	SupplierName  string                      `json:"supplier_name"`
+	Status        string                      `json:"status"`
+	ExpectedAt    *time.Time                  `json:"expected_at,omitempty"`
+	Notes         string                      `json:"notes,omitempty"`
+	Total         models.Money                `json:"total"`
+	ReceivedTotal models.Money                `json:"received_total"` // Received units at the cost they were received at
+	CreatedBy     string                      `json:"created_by"`
+	SentAt        *time.Time                  `json:"sent_at,omitempty"`
+	ClosedAt      *time.Time                  `json:"closed_at,omitempty"`
+	Items         []PurchaseOrderItemResponse `json:"items"`
+	Receipts      []GoodsReceiptResponse      `json:"receipts,omitempty"`
+	CreatedAt     time.Time                   `json:"created_at"`
+}
@@ -0,0 +1,8 @@
+package dto
+
+// QueueBoardResponse is the public "order ready" board: the queue numbers of
+// today's counter orders still being prepared and those ready to collect
+type QueueBoardResponse struct {
+	Preparing []int `json:"preparing"`
+	Ready     []int `json:"ready"`
+}
@@ -0,0 +1,42 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/syrlramadhan/cashier-app/models"
+)
+
+type RefundItemRequest struct {
+	TransactionItemID uint `json:"transaction_item_id" binding:"required"`
+	Quantity          int  `json:"quantity" binding:"required,gt=0"`
+}
+
+type CreateRefundRequest struct {
+	ApprovedBy   uint                `json:"-"` // Set by controller from auth (manager or admin)
+	Items        []RefundItemRequest `json:"items" binding:"required,min=1,dive"`
+	Restock      bool                `json:"restock"`
+	RefundMethod string              `json:"refund_method" binding:"required,oneof=cash card qris"`
+	Reason       string              `json:"reason" binding:"required,min=3,max=255"`
+}
+
+type RefundItemResponse struct {
+	ID                uint         `json:"id"`
+	TransactionItemID uint         `json:"transaction_item_id"`
+	ProductID         uint         `json:"product_id"`
+	ProductName       string       `json:"product_name"`
+	Quantity          int          `json:"quantity"`
+	Amount            models.Money `json:"amount"`
+}
+
+type RefundResponse struct {
+	ID            uint                 `json:"id"`
+	RefundCode    string               `json:"refund_code"`
+	TransactionID uint                 `json:"transaction_id"`
+	ApprovedBy    string               `json:"approved_by"`
+	Reason        string               `json:"reason"`
+	RefundMethod  string               `json:"refund_method"`
+	Restock       bool                 `json:"restock"`
+	Total         models.Money         `json:"total"`
+	Items         []RefundItemResponse `json:"items"`
+	CreatedAt     time.Time            `json:"created_at"`
+}
@@ -0,0 +1,52 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/syrlramadhan/cashier-app/models"
+)
+
+type ReorderFilter struct {
+	Days       int  `form:"days"`        // Sales window; defaults to the reorder_window_days setting
+	CoverDays  int  `form:"cover_days"`  // Days of sales to order for on top of the lead time; defaults to the reorder_cover_days setting
+	SupplierID uint `form:"supplier_id"` // Only products of this supplier
+	All        bool `form:"all"`         // Also list products that need nothing
+}
+
+type ReorderSuggestionResponse struct {
+	ProductID         uint         `json:"product_id"`
+	ProductName       string       `json:"product_name"`
+	SupplierID        *uint        `json:"supplier_id,omitempty"`
+	SupplierName      string       `json:"supplier_name,omitempty"`
+	LeadTimeDays      int          `json:"lead_time_days"`
+	Stock             int          `json:"stock"`
+	MinStock          int          `json:"min_stock"`
+	OnOrder           int          `json:"on_order"` // Ordered on open purchase orders, not received yet
+	Sold              int          `json:"sold"`     // Units sold in the window
+	AverageDaily      float64      `json:"average_daily"`
+	DaysOfCover       *float64     `json:"days_of_cover"` // How long the stock lasts at the average; nil without sales
+	SuggestedQuantity int          `json:"suggested_quantity"`
+	UnitCost          models.Money `json:"unit_cost"`
+	EstimatedCost     models.Money `json:"estimated_cost"`
+}
+
+type ReorderReportResponse struct {
+	StartDate     time.Time                   `json:"start_date"`
+	EndDate       time.Time                   `json:"end_date"`
+	Days          int                         `json:"days"`
+	CoverDays     int                         `json:"cover_days"`
+	EstimatedCost models.Money                `json:"estimated_cost"`
+	Items         []ReorderSuggestionResponse `json:"items"`
+}
+
+// ReorderPurchaseOrderRequest turns the suggestions into a draft purchase
+// order. Without product_ids every product of the supplier that needs
+// reordering is taken.
+type ReorderPurchaseOrderRequest struct {
+	SupplierID uint   `json:"supplier_id" binding:"required"`
+	ProductIDs []uint `json:"product_ids"`
+	Days       int    `json:"days" binding:"gte=0"`
+	CoverDays  int    `json:"cover_days" binding:"gte=0"`
+	Notes      string `json:"notes" binding:"max=255"`
+	UserID     uint   `json:"-"` // Set by controller from auth
+}
@@ -1,43 +1,159 @@
 package dto
 
+import (
+	"time"
+
+	"github.com/syrlramadhan/cashier-app/models"
+)
+
 type DashboardResponse struct {
-	TodayRevenue      float64 `json:"today_revenue"`
-	TodayTransactions int     `json:"today_transactions"`
-	TotalProducts     int     `json:"total_products"`
-	LowStockCount     int     `json:"low_stock_count"`
+	TodayRevenue      models.Money `json:"today_revenue"`
+	TodayTransactions int          `json:"today_transactions"`
+	TotalProducts     int          `json:"total_products"`
+	LowStockCount     int          `json:"low_stock_count"`
+	TodayWaste        models.Money `json:"today_waste"` // Waste recorded today, at cost
 }
 
 type DailyRevenueResponse struct {
-	Date             string  `json:"date"`
-	Revenue          float64 `json:"revenue"`
-	TransactionCount int     `json:"transaction_count"`
+	Date             string       `json:"date"`
+	Revenue          models.Money `json:"revenue"`
+	TransactionCount int          `json:"transaction_count"`
 }
 
 type PaymentDistributionResponse struct {
-	PaymentMethod string  `json:"payment_method"`
-	Count         int     `json:"count"`
-	Percentage    float64 `json:"percentage"`
+	PaymentMethod string       `json:"payment_method"`
+	Count         int          `json:"count"`
+	TotalAmount   models.Money `json:"total_amount"`
+	Percentage    float64      `json:"percentage"`
+}
+
+type PaymentMethodStatData struct {
+	Method      string
+	Count       int
+	TotalAmount models.Money
 }
 
 type TopProductResponse struct {
-	ProductID    uint    `json:"product_id"`
-	ProductName  string  `json:"product_name"`
-	TotalSold    int     `json:"total_sold"`
-	TotalRevenue float64 `json:"total_revenue"`
+	ProductID    uint         `json:"product_id"`
+	ProductName  string       `json:"product_name"`
+	TotalSold    int          `json:"total_sold"`
+	TotalRevenue models.Money `json:"total_revenue"`
+}
+
+// DailyAmountData is an amount per day, e.g. the net revenue of each day
+type DailyAmountData struct {
+	Date   time.Time
+	Amount models.Money
+}
+
+// ProductQuantityData is a quantity per product, e.g. sold or on order
+type ProductQuantityData struct {
+	ProductID     uint
+	TotalQuantity int
 }
 
 type TopProductData struct {
 	ProductID     uint
 	ProductName   string
 	TotalQuantity int
-	TotalRevenue  float64
+	TotalRevenue  models.Money
+}
+
+type TopVariantResponse struct {
+	ProductID    uint         `json:"product_id"`
+	ProductName  string       `json:"product_name"`
+	VariantName  string       `json:"variant_name"`
+	TotalSold    int          `json:"total_sold"`
+	TotalRevenue models.Money `json:"total_revenue"`
+}
+
+type TopVariantData struct {
+	ProductID     uint
+	ProductName   string
+	VariantName   string
+	TotalQuantity int
+	TotalRevenue  models.Money
+}
+
+type TopModifierResponse struct {
+	ModifierOptionID uint         `json:"modifier_option_id"`
+	GroupName        string       `json:"group_name"`
+	Name             string       `json:"name"`
+	TotalSold        int          `json:"total_sold"`
+	TotalRevenue     models.Money `json:"total_revenue"`
+}
+
+type TopModifierData struct {
+	ModifierOptionID uint
+	GroupName        string
+	Name             string
+	TotalQuantity    int
+	TotalRevenue     models.Money
+}
+
+type TaxSummaryResponse struct {
+	TaxRate          float64      `json:"tax_rate"`
+	TaxInclusive     bool         `json:"tax_inclusive"`
+	TransactionCount int          `json:"transaction_count"`
+	Subtotal         models.Money `json:"subtotal"`
+	Tax              models.Money `json:"tax"`
+	Total            models.Money `json:"total"`
+}
+
+type TaxSummaryData struct {
+	TaxRate          float64
+	TaxInclusive     bool
+	TransactionCount int
+	Subtotal         models.Money
+	Tax              models.Money
+	Total            models.Money
+}
+
+type OrderTypeSummaryResponse struct {
+	OrderType        string       `json:"order_type"`
+	TransactionCount int          `json:"transaction_count"`
+	Subtotal         models.Money `json:"subtotal"`
+	Discount         models.Money `json:"discount"`
+	ServiceCharge    models.Money `json:"service_charge"`
+	Tax              models.Money `json:"tax"`
+	Total            models.Money `json:"total"`
+	RefundedTotal    models.Money `json:"refunded_total"`
+	NetRevenue       models.Money `json:"net_revenue"`
+	Percentage       float64      `json:"percentage"` // share of the net revenue of all order types
+}
+
+type OrderTypeSummaryData struct {
+	OrderType        string
+	TransactionCount int
+	Subtotal         models.Money
+	Discount         models.Money
+	ServiceCharge    models.Money
+	Tax              models.Money
+	Total            models.Money
+	RefundedTotal    models.Money
+}
+
+type DiscountSummaryResponse struct {
+	Source           string       `json:"source"`
+	PromotionID      *uint        `json:"promotion_id,omitempty"`
+	Name             string       `json:"name"`
+	TransactionCount int          `json:"transaction_count"`
+	TotalAmount      models.Money `json:"total_amount"`
+}
+
+type DiscountSummaryData struct {
+	Source           string
+	PromotionID      *uint
+	Name             string
+	TransactionCount int
+	TotalAmount      models.Money
 }
 
 type DashboardReport struct {
-	TotalRevenue       float64               `json:"total_revenue"`
+	TotalRevenue       models.Money          `json:"total_revenue"`
 	TotalTransactions  int64                 `json:"total_transactions"`
-	AverageTransaction float64               `json:"average_transaction"`
-	TotalTax           float64               `json:"total_tax"`
+	AverageTransaction models.Money          `json:"average_transaction"`
+	TotalTax           models.Money          `json:"total_tax"`
 	PaymentMethodStats []PaymentMethodStat   `json:"payment_method_stats"`
 	DailyRevenue       []DailyRevenueStat    `json:"daily_revenue"`
 	TopSellingProducts []TopProductStat      `json:"top_selling_products"`
@@ -45,23 +161,23 @@ type DashboardReport struct {
 }
 
 type PaymentMethodStat struct {
-	Method      string  `json:"method"`
-	Count       int64   `json:"count"`
-	TotalAmount float64 `json:"total_amount"`
-	Percentage  float64 `json:"percentage"`
+	Method      string       `json:"method"`
+	Count       int64        `json:"count"`
+	TotalAmount models.Money `json:"total_amount"`
+	Percentage  float64      `json:"percentage"`
 }
 
 type DailyRevenueStat struct {
-	Date    string  `json:"date"`
-	Revenue float64 `json:"revenue"`
-	Count   int64   `json:"count"`
+	Date    string       `json:"date"`
+	Revenue models.Money `json:"revenue"`
+	Count   int64        `json:"count"`
 }
 
 type TopProductStat struct {
-	ProductID   uint    `json:"product_id"`
-	ProductName string  `json:"product_name"`
-	Quantity    int64   `json:"quantity"`
-	Revenue     float64 `json:"revenue"`
+	ProductID   uint         `json:"product_id"`
+	ProductName string       `json:"product_name"`
+	Quantity    int64        `json:"quantity"`
+	Revenue     models.Money `json:"revenue"`
 }
 
 type LowStockProductStat struct {
@@ -75,4 +191,5 @@ type ReportFilter struct {
 	StartDate string `form:"start_date"`
 	EndDate   string `form:"end_date"`
 	Period    string `form:"period"` // daily, weekly, monthly
+	OrderType string `form:"order_type"`
 }
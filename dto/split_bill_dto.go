@@ -0,0 +1,34 @@
+package dto
+
+import "github.com/syrlramadhan/cashier-app/models"
+
+type SplitItemRequest struct {
+	TransactionItemID uint `json:"transaction_item_id" binding:"required"`
+	Quantity          int  `json:"quantity" binding:"required,gt=0"`
+}
+
+type SplitBillLinesRequest struct {
+	Items []SplitItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+// SplitOrderRequest splits an open tab into bills, either by assigning its
+// lines (mode "items", every quantity must be assigned) or into equal shares
+// of the total (mode "equal"). Order discounts and vouchers apply to the whole
+// order before it is split.
+type SplitOrderRequest struct {
+	UserID          uint                    `json:"-"` // Set by controller from auth
+	Role            string                  `json:"-"` // Set by controller from auth; caps manual discounts
+	Mode            string                  `json:"mode" binding:"required,oneof=items equal"`
+	Shares          int                     `json:"shares" binding:"omitempty,gte=2,lte=20"`
+	Bills           []SplitBillLinesRequest `json:"bills" binding:"omitempty,dive"`
+	DiscountPercent float64                 `json:"discount_percent" binding:"gte=0,lte=100"`
+	DiscountAmount  models.Money            `json:"discount_amount" binding:"gte=0"`
+	VoucherCode     string                  `json:"voucher_code" binding:"max=50"`
+	CustomerRef     string                  `json:"customer_ref" binding:"max=100"`
+}
+
+type PaySplitBillRequest struct {
+	PaymentMethod  string           `json:"payment_method" binding:"required_without=Payments,omitempty,oneof=cash card qris"`
+	Payments       []PaymentRequest `json:"payments" binding:"omitempty,dive"`
+	AmountTendered *models.Money    `json:"amount_tendered" binding:"omitempty,gte=0"`
+}
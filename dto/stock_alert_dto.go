@@ -0,0 +1,26 @@
+package dto
+
+import "time"
+
+type LowStockProductResponse struct {
+	ProductID    uint   `json:"product_id"`
+	ProductName  string `json:"product_name"`
+	CategoryName string `json:"category"`
+	Stock        int    `json:"stock"`
+	MinStock     int    `json:"min_stock"` // The product minimum, or the low_stock_threshold setting
+	ReorderQty   int    `json:"reorder_qty"`
+}
+
+type StockAlertResponse struct {
+	ID             uint       `json:"id"`
+	ProductID      uint       `json:"product_id"`
+	ProductName    string     `json:"product_name"`
+	Stock          int        `json:"stock"`
+	MinStock       int        `json:"min_stock"`
+	ReorderQty     int        `json:"reorder_qty"`
+	Reason         string     `json:"reason"`
+	ReferenceID    *uint      `json:"reference_id,omitempty"`
+	AcknowledgedBy string     `json:"acknowledged_by,omitempty"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
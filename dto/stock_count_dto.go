@@ -0,0 +1,75 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/syrlramadhan/cashier-app/models"
+)
+
+type StartStockCountRequest struct {
+	CategoryID *uint  `json:"category_id"` // Count one category only; all products when omitted
+	Notes      string `json:"notes" binding:"max=255"`
+	UserID     uint   `json:"-"` // Set by controller from auth
+}
+
+type StockCountEntryRequest struct {
+	ProductID uint   `json:"product_id" binding:"required"`
+	VariantID *uint  `json:"variant_id"` // For variants that keep their own stock
+	Quantity  int    `json:"quantity" binding:"gte=0"`
+	Note      string `json:"note" binding:"max=255"`
+}
+
+type RecordStockCountRequest struct {
+	Items []StockCountEntryRequest `json:"items" binding:"required,min=1,dive"`
+	// Add the quantities to what was already counted, e.g. when a product is
+	// kept in more than one place; otherwise they replace it
+	Add    bool `json:"add"`
+	UserID uint `json:"-"` // Set by controller from auth
+}
+
+type StockCountItemResponse struct {
+	ProductID     uint         `json:"product_id"`
+	ProductName   string       `json:"product_name"`
+	VariantID     *uint        `json:"variant_id,omitempty"`
+	VariantName   string       `json:"variant_name,omitempty"`
+	Expected      int          `json:"expected"`
+	Counted       *int         `json:"counted"`
+	Variance      int          `json:"variance"` // Counted less expected; 0 while not counted
+	UnitCost      models.Money `json:"unit_cost"`
+	VarianceValue models.Money `json:"variance_value"`
+	Adjustment    int          `json:"adjustment"`
+	CountedBy     string       `json:"counted_by,omitempty"`
+	CountedAt     *time.Time   `json:"counted_at,omitempty"`
+	Note          string       `json:"note,omitempty"`
+}
+
+type StockCountResponse struct {
+	ID           uint                     `json:"id"`
+	CountNumber  string                   `json:"count_number"`
+	Status       string                   `json:"status"`
+	CategoryID   *uint                    `json:"category_id,omitempty"`
+	Notes        string                   `json:"notes,omitempty"`
+	StartedBy    string                   `json:"started_by"`
+	PostedBy     string                   `json:"posted_by,omitempty"`
+	PostedAt     *time.Time               `json:"posted_at,omitempty"`
+	TotalItems   int                      `json:"total_items"`
+	CountedItems int                      `json:"counted_items"`
+	Items        []StockCountItemResponse `json:"items,omitempty"`
+	CreatedAt    time.Time                `json:"created_at"`
+}
+
+// StockCountVarianceResponse lists the counted products whose count differs
+// from the expected quantity, valued at cost
+type StockCountVarianceResponse struct {
+	ID            uint                     `json:"id"`
+	CountNumber   string                   `json:"count_number"`
+	Status        string                   `json:"status"`
+	TotalItems    int                      `json:"total_items"`
+	CountedItems  int                      `json:"counted_items"`
+	ShortageValue models.Money             `json:"shortage_value"` // Value of missing units, as a positive amount
+	SurplusValue  models.Money             `json:"surplus_value"`
+	NetValue      models.Money             `json:"net_value"` // Surplus less shortage
+	Items         []StockCountItemResponse `json:"items"`
+	CreatedAt     time.Time                `json:"created_at"`
+	PostedAt      *time.Time               `json:"posted_at,omitempty"`
+}
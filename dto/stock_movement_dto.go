@@ -0,0 +1,25 @@
+package dto
+
+import "time"
+
+// AdjustStockRequest moves the stock by quantity, or sets it to stock
+type AdjustStockRequest struct {
+	Quantity  int    `json:"quantity" binding:"required_without=Stock"` // Negative to take stock out
+	Stock     *int   `json:"stock" binding:"omitempty,gte=0"`           // Stock on hand to set instead of a quantity
+	VariantID *uint  `json:"variant_id"`                                // Adjust a variant that keeps its own stock instead of the product
+	Note      string `json:"note" binding:"max=255"`
+	UserID    uint   `json:"-"` // Set by controller from auth
+}
+
+type StockMovementResponse struct {
+	ID          uint      `json:"id"`
+	ProductID   uint      `json:"product_id"`
+	VariantID   *uint     `json:"variant_id,omitempty"`
+	Quantity    int       `json:"quantity"`
+	Balance     int       `json:"balance"`
+	Reason      string    `json:"reason"`
+	ReferenceID *uint     `json:"reference_id,omitempty"`
+	UserName    string    `json:"user_name,omitempty"`
+	Note        string    `json:"note,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
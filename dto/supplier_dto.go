@@ -0,0 +1,22 @@
+package dto
+
+type SupplierRequest struct {
+	Name         string `json:"name" binding:"required,min=2,max=150"`
+	ContactName  string `json:"contact_name" binding:"max=100"`
+	Phone        string `json:"phone" binding:"max=30"`
+	Email        string `json:"email" binding:"omitempty,email,max=100"`
+	Address      string `json:"address" binding:"max=255"`
+	LeadTimeDays int    `json:"lead_time_days" binding:"gte=0"`
+	IsActive     *bool  `json:"is_active"` // Defaults to true
+}
+
+type SupplierResponse struct {
+	ID           uint   `json:"id"`
+	Name         string `json:"name"`
+	ContactName  string `json:"contact_name,omitempty"`
+	Phone        string `json:"phone,omitempty"`
+	Email        string `json:"email,omitempty"`
+	Address      string `json:"address,omitempty"`
+	LeadTimeDays int    `json:"lead_time_days"`
+	IsActive     bool   `json:"is_active"`
+}
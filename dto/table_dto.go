@@ -0,0 +1,53 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/syrlramadhan/cashier-app/models"
+)
+
+type CreateTableRequest struct {
+	Name     string `json:"name" binding:"required,min=1,max=50"`
+	Area     string `json:"area" binding:"max=50"`
+	Capacity int    `json:"capacity" binding:"gte=0"`
+}
+
+type UpdateTableRequest struct {
+	Name     string `json:"name" binding:"omitempty,min=1,max=50"`
+	Area     string `json:"area" binding:"max=50"`
+	Capacity *int   `json:"capacity" binding:"omitempty,gte=0"`
+}
+
+type OpenTabRequest struct {
+	UserID uint   `json:"-"` // Set by controller from auth
+	Note   string `json:"note" binding:"max=255"`
+}
+
+type AddRoundRequest struct {
+	Items []OrderItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+type MoveTabRequest struct {
+	TableID uint `json:"table_id" binding:"required"`
+}
+
+type MergeTabRequest struct {
+	SourceTabID uint `json:"source_tab_id" binding:"required"` // tab merged into this one and closed
+}
+
+type TableTabSummary struct {
+	ID              uint         `json:"id"`
+	TransactionCode string       `json:"transaction_code"`
+	ItemCount       int          `json:"item_count"`
+	Total           models.Money `json:"total"`
+	OpenedAt        time.Time    `json:"opened_at"`
+}
+
+type TableResponse struct {
+	ID       uint             `json:"id"`
+	Name     string           `json:"name"`
+	Area     string           `json:"area"`
+	Capacity int              `json:"capacity"`
+	Status   string           `json:"status"`
+	OpenTab  *TableTabSummary `json:"open_tab,omitempty"`
+}
@@ -1,54 +1,127 @@
 package dto
 
-import "time"
+import (
+	"time"
+
+	"github.com/syrlramadhan/cashier-app/models"
+)
 
 type TransactionItemRequest struct {
-	ProductID uint `json:"product_id" binding:"required"`
-	Quantity  int  `json:"quantity" binding:"required,gt=0"`
+	ProductID       uint         `json:"product_id" binding:"required"`
+	VariantID       *uint        `json:"variant_id"`   // Required when the product has active variants
+	ModifierIDs     []uint       `json:"modifier_ids"` // Chosen modifier options, e.g. extra shot
+	Quantity        int          `json:"quantity" binding:"required,gt=0"`
+	DiscountPercent float64      `json:"discount_percent" binding:"gte=0,lte=100"` // Manual line discount, capped by role
+	DiscountAmount  models.Money `json:"discount_amount" binding:"gte=0"`
+}
+
+// OrderItemRequest is a line of an unpaid order (held order or tab round);
+// discounts are applied when the order is paid.
+type OrderItemRequest struct {
+	ProductID   uint   `json:"product_id" binding:"required"`
+	VariantID   *uint  `json:"variant_id"`
+	ModifierIDs []uint `json:"modifier_ids"`
+	Quantity    int    `json:"quantity" binding:"required,gt=0"`
+}
+
+type PaymentRequest struct {
+	Method    string       `json:"method" binding:"required,oneof=cash card qris"`
+	Amount    models.Money `json:"amount" binding:"required,gt=0"`
+	Reference string       `json:"reference" binding:"max=100"`
 }
 
 type CreateTransactionRequest struct {
-	UserID        uint                     `json:"-"` // Set by controller from auth
-	Items         []TransactionItemRequest `json:"items" binding:"required,min=1"`
-	PaymentMethod string                   `json:"payment_method" binding:"required,oneof=cash card qris"`
+	UserID          uint                     `json:"-"` // Set by controller from auth
+	Role            string                   `json:"-"` // Set by controller from auth; caps manual discounts
+	Items           []TransactionItemRequest `json:"items" binding:"required,min=1,dive"`
+	PaymentMethod   string                   `json:"payment_method" binding:"required_without=Payments,omitempty,oneof=cash card qris"`
+	Payments        []PaymentRequest         `json:"payments" binding:"omitempty,dive"`         // Split tenders; must add up to the total and agree with payment_method when both are sent
+	AmountTendered  *models.Money            `json:"amount_tendered" binding:"omitempty,gte=0"` // Cash only; defaults to the exact cash amount
+	DiscountPercent float64                  `json:"discount_percent" binding:"gte=0,lte=100"`  // Manual order discount, capped by role
+	DiscountAmount  models.Money             `json:"discount_amount" binding:"gte=0"`
+	VoucherCode     string                   `json:"voucher_code" binding:"max=50"`
+	CustomerRef     string                   `json:"customer_ref" binding:"max=100"`                                 // Phone or member number, for per-customer voucher limits
+	OrderType       string                   `json:"order_type" binding:"omitempty,oneof=dine_in takeaway delivery"` // Defaults to takeaway; picks the product prices and service charge
+}
+
+type TransactionDiscountResponse struct {
+	ID                uint         `json:"id"`
+	TransactionItemID *uint        `json:"transaction_item_id,omitempty"`
+	PromotionID       *uint        `json:"promotion_id,omitempty"`
+	VoucherID         *uint        `json:"voucher_id,omitempty"`
+	Source            string       `json:"source"`
+	Name              string       `json:"name"`
+	Amount            models.Money `json:"amount"`
+}
+
+type TransactionPaymentResponse struct {
+	ID        uint         `json:"id"`
+	Method    string       `json:"method"`
+	Amount    models.Money `json:"amount"`
+	Reference string       `json:"reference,omitempty"`
 }
 
 type TransactionItemResponse struct {
-	ID          uint    `json:"id"`
-	ProductID   uint    `json:"product_id"`
-	ProductName string  `json:"product_name"`
-	Price       float64 `json:"price"`
-	Quantity    int     `json:"quantity"`
-	Subtotal    float64 `json:"subtotal"`
+	ID          uint                              `json:"id"`
+	ProductID   uint                              `json:"product_id"`
+	ProductName string                            `json:"product_name"`
+	VariantID   *uint                             `json:"variant_id,omitempty"`
+	VariantName string                            `json:"variant_name,omitempty"`
+	Modifiers   []TransactionItemModifierResponse `json:"modifiers,omitempty"`
+	Price       models.Money                      `json:"price"` // Unit price including modifiers
+	Quantity    int                               `json:"quantity"`
+	RefundedQty int                               `json:"refunded_quantity"`
+	Round       int                               `json:"round,omitempty"`
+	Discount    models.Money                      `json:"discount"`
+	Subtotal    models.Money                      `json:"subtotal"`
 }
 
 type TransactionResponse struct {
-	ID              uint                      `json:"id"`
-	TransactionCode string                    `json:"transaction_code"`
-	CashierName     string                    `json:"cashier_name"`
-	Subtotal        float64                   `json:"subtotal"`
-	Tax             float64                   `json:"tax"`
-	Total           float64                   `json:"total"`
-	PaymentMethod   string                    `json:"payment_method"`
-	Status          string                    `json:"status"`
-	Items           []TransactionItemResponse `json:"items"`
-	CreatedAt       time.Time                 `json:"created_at"`
+	ID                uint                          `json:"id"`
+	TransactionCode   string                        `json:"transaction_code"`
+	CashierName       string                        `json:"cashier_name"`
+	TableID           *uint                         `json:"table_id,omitempty"`
+	ParentID          *uint                         `json:"parent_id,omitempty"`
+	OrderType         string                        `json:"order_type"`
+	Subtotal          models.Money                  `json:"subtotal"`
+	Discount          models.Money                  `json:"discount"`
+	ServiceChargeRate float64                       `json:"service_charge_rate"`
+	ServiceCharge     models.Money                  `json:"service_charge"`
+	TaxRate           float64                       `json:"tax_rate"`
+	TaxInclusive      bool                          `json:"tax_inclusive"`
+	Tax               models.Money                  `json:"tax"`
+	Rounding          models.Money                  `json:"rounding"`
+	Total             models.Money                  `json:"total"`
+	AmountTendered    models.Money                  `json:"amount_tendered"`
+	ChangeDue         models.Money                  `json:"change_due"`
+	RefundedTotal     models.Money                  `json:"refunded_total"`
+	PaymentMethod     string                        `json:"payment_method"`
+	Status            string                        `json:"status"`
+	QueueNumber       int                           `json:"queue_number,omitempty"`
+	FulfillmentStatus string                        `json:"fulfillment_status,omitempty"`
+	Note              string                        `json:"note,omitempty"`
+	Items             []TransactionItemResponse     `json:"items"`
+	Payments          []TransactionPaymentResponse  `json:"payments"`
+	Discounts         []TransactionDiscountResponse `json:"discounts"`
+	Children          []TransactionResponse         `json:"children,omitempty"` // split bills
+	CreatedAt         time.Time                     `json:"created_at"`
 }
 
 type TransactionListResponse struct {
-	ID              uint      `json:"id"`
-	TransactionCode string    `json:"transaction_code"`
-	CashierName     string    `json:"cashier_name"`
-	Total           float64   `json:"total"`
-	PaymentMethod   string    `json:"payment_method"`
-	ItemCount       int       `json:"item_count"`
-	CreatedAt       time.Time `json:"created_at"`
+	ID              uint         `json:"id"`
+	TransactionCode string       `json:"transaction_code"`
+	CashierName     string       `json:"cashier_name"`
+	Total           models.Money `json:"total"`
+	PaymentMethod   string       `json:"payment_method"`
+	ItemCount       int          `json:"item_count"`
+	CreatedAt       time.Time    `json:"created_at"`
 }
 
 type TransactionFilter struct {
 	StartDate     string `form:"start_date"`
 	EndDate       string `form:"end_date"`
 	PaymentMethod string `form:"payment_method"`
+	OrderType     string `form:"order_type" binding:"omitempty,oneof=dine_in takeaway delivery"`
 	Page          int    `form:"page"`
 	Limit         int    `form:"limit"`
 }
@@ -0,0 +1,70 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/syrlramadhan/cashier-app/models"
+)
+
+type VoucherRequest struct {
+	Code             string       `json:"code" binding:"required,min=3,max=50,alphanum"`
+	Name             string       `json:"name" binding:"max=100"`
+	Type             string       `json:"type" binding:"required,oneof=percentage fixed"`
+	DiscountPercent  float64      `json:"discount_percent" binding:"gte=0,lte=100"`
+	DiscountAmount   models.Money `json:"discount_amount" binding:"gte=0"`
+	MaxDiscount      models.Money `json:"max_discount" binding:"gte=0"`
+	MinSubtotal      models.Money `json:"min_subtotal" binding:"gte=0"`
+	StartsAt         *time.Time   `json:"starts_at"`
+	EndsAt           *time.Time   `json:"ends_at"`
+	UsageLimit       int          `json:"usage_limit" binding:"gte=0"`        // 0 means unlimited
+	PerCustomerLimit int          `json:"per_customer_limit" binding:"gte=0"` // 0 means unlimited
+	Stackable        bool         `json:"stackable"`
+	IsActive         *bool        `json:"is_active"`
+}
+
+type GenerateVouchersRequest struct {
+	Prefix          string       `json:"prefix" binding:"required,min=2,max=20,alphanum"`
+	Quantity        int          `json:"quantity" binding:"required,gt=0,lte=500"`
+	Name            string       `json:"name" binding:"max=100"`
+	Type            string       `json:"type" binding:"required,oneof=percentage fixed"`
+	DiscountPercent float64      `json:"discount_percent" binding:"gte=0,lte=100"`
+	DiscountAmount  models.Money `json:"discount_amount" binding:"gte=0"`
+	MaxDiscount     models.Money `json:"max_discount" binding:"gte=0"`
+	MinSubtotal     models.Money `json:"min_subtotal" binding:"gte=0"`
+	StartsAt        *time.Time   `json:"starts_at"`
+	EndsAt          *time.Time   `json:"ends_at"`
+	Stackable       bool         `json:"stackable"`
+}
+
+type ValidateVoucherRequest struct {
+	Code        string       `json:"code" binding:"required"`
+	Subtotal    models.Money `json:"subtotal" binding:"gte=0"` // cart subtotal after line discounts
+	CustomerRef string       `json:"customer_ref" binding:"max=100"`
+}
+
+type VoucherResponse struct {
+	ID               uint         `json:"id"`
+	Code             string       `json:"code"`
+	Name             string       `json:"name"`
+	BatchCode        string       `json:"batch_code,omitempty"`
+	Type             string       `json:"type"`
+	DiscountPercent  float64      `json:"discount_percent"`
+	DiscountAmount   models.Money `json:"discount_amount"`
+	MaxDiscount      models.Money `json:"max_discount"`
+	MinSubtotal      models.Money `json:"min_subtotal"`
+	StartsAt         *time.Time   `json:"starts_at,omitempty"`
+	EndsAt           *time.Time   `json:"ends_at,omitempty"`
+	UsageLimit       int          `json:"usage_limit"`
+	PerCustomerLimit int          `json:"per_customer_limit"`
+	UsedCount        int          `json:"used_count"`
+	Stackable        bool         `json:"stackable"`
+	IsActive         bool         `json:"is_active"`
+	CreatedAt        time.Time    `json:"created_at"`
+}
+
+type VoucherValidationResponse struct {
+	Code      string       `json:"code"`
+	Name      string       `json:"name"`
+	Discount  models.Money `json:"discount"`
+	Stackable bool         `json:"stackable"`
+}
@@ -0,0 +1,86 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/syrlramadhan/cashier-app/models"
+)
+
+// WasteRequest records waste of either a product (optionally one of its
+// variants) or an ingredient. Products are wasted in whole units.
+type WasteRequest struct {
+	ProductID    *uint   `json:"product_id" binding:"required_without=IngredientID"`
+	VariantID    *uint   `json:"variant_id"`
+	IngredientID *uint   `json:"ingredient_id" binding:"required_without=ProductID"`
+	Quantity     float64 `json:"quantity" binding:"required,gt=0"`
+	Reason       string  `json:"reason" binding:"required,oneof=expired spoiled damaged spilled other"`
+	Note         string  `json:"note" binding:"max=255"`
+	UserID       uint    `json:"-"` // Set by controller from auth
+}
+
+type WasteFilter struct {
+	StartDate string `form:"start_date"`
+	EndDate   string `form:"end_date"`
+	Reason    string `form:"reason"`
+}
+
+type WasteEntryResponse struct {
+	ID           uint         `json:"id"`
+	ProductID    *uint        `json:"product_id,omitempty"`
+	VariantID    *uint        `json:"variant_id,omitempty"`
+	IngredientID *uint        `json:"ingredient_id,omitempty"`
+	ItemName     string       `json:"item_name"`
+	Quantity     float64      `json:"quantity"`
+	Unit         string       `json:"unit"`
+	Reason       string       `json:"reason"`
+	Cost         models.Money `json:"cost"`
+	FromRecipe   bool         `json:"from_recipe"`
+	Note         string       `json:"note,omitempty"`
+	UserName     string       `json:"user_name,omitempty"`
+	CreatedAt    time.Time    `json:"created_at"`
+
+	Ingredients []WasteEntryIngredientResponse `json:"ingredients,omitempty"`
+}
+
+// WasteEntryIngredientResponse is an ingredient quantity a waste entry took out of stock
+type WasteEntryIngredientResponse struct {
+	IngredientID   uint         `json:"ingredient_id"`
+	IngredientName string       `json:"ingredient_name"`
+	Quantity       float64      `json:"quantity"`
+	Unit           string       `json:"unit"`
+	Cost           models.Money `json:"cost"`
+}
+
+type WasteReasonSummary struct {
+	Reason     string       `json:"reason"`
+	EntryCount int          `json:"entry_count"`
+	Cost       models.Money `json:"cost"`
+}
+
+type WasteItemSummary struct {
+	ProductID    *uint        `json:"product_id,omitempty"`
+	VariantID    *uint        `json:"variant_id,omitempty"`
+	IngredientID *uint        `json:"ingredient_id,omitempty"`
+	ItemName     string       `json:"item_name"`
+	Quantity     float64      `json:"quantity"`
+	Unit         string       `json:"unit"`
+	EntryCount   int          `json:"entry_count"`
+	Cost         models.Money `json:"cost"`
+}
+
+type WasteDailySummary struct {
+	Date    string       `json:"date"`
+	Cost    models.Money `json:"cost"`
+	Revenue models.Money `json:"revenue"`
+}
+
+type WasteReportResponse struct {
+	StartDate    time.Time            `json:"start_date"`
+	EndDate      time.Time            `json:"end_date"`
+	TotalCost    models.Money         `json:"total_cost"`
+	Revenue      models.Money         `json:"revenue"`       // Net revenue of the same period
+	WastePercent float64              `json:"waste_percent"` // Waste cost as a percentage of revenue
+	ByReason     []WasteReasonSummary `json:"by_reason"`
+	ByItem       []WasteItemSummary   `json:"by_item"`
+	ByDay        []WasteDailySummary  `json:"by_day"`
+}
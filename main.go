@@ -3,6 +3,7 @@ package main
 import (
 	"log"
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/syrlramadhan/cashier-app/config"
@@ -32,31 +33,108 @@ func main() {
 	transactionRepo := repositories.NewTransactionRepository(db)
 	transactionItemRepo := repositories.NewTransactionItemRepository(db)
 	settingRepo := repositories.NewSettingRepository(db)
+	idempotencyRepo := repositories.NewIdempotencyKeyRepository(db)
+	sequenceRepo := repositories.NewSequenceRepository(db)
+	refundRepo := repositories.NewRefundRepository(db)
+	promotionRepo := repositories.NewPromotionRepository(db)
+	voucherRepo := repositories.NewVoucherRepository(db)
+	tableRepo := repositories.NewTableRepository(db)
+	kitchenTicketRepo := repositories.NewKitchenTicketRepository(db)
+	productVariantRepo := repositories.NewProductVariantRepository(db)
+	modifierRepo := repositories.NewModifierRepository(db)
+	ingredientRepo := repositories.NewIngredientRepository(db)
+	stockMovementRepo := repositories.NewStockMovementRepository(db)
+	supplierRepo := repositories.NewSupplierRepository(db)
+	purchaseOrderRepo := repositories.NewPurchaseOrderRepository(db)
+	stockCountRepo := repositories.NewStockCountRepository(db)
+	stockAlertRepo := repositories.NewStockAlertRepository(db)
+	wasteRepo := repositories.NewWasteRepository(db)
 
 	// Initialize services
+	eventHub := services.NewEventHub()
 	userService := services.NewUserService(userRepo)
 	categoryService := services.NewCategoryService(categoryRepo)
-	productService := services.NewProductService(productRepo, categoryRepo)
-	transactionService := services.NewTransactionService(transactionRepo, transactionItemRepo, productRepo)
 	settingService := services.NewSettingService(settingRepo)
-	reportService := services.NewReportService(transactionRepo, transactionItemRepo, productRepo, categoryRepo)
+	stockAlertService := services.NewStockAlertService(stockAlertRepo, productRepo, settingService, eventHub)
+	stockService := services.NewStockService(db, productRepo, productVariantRepo, ingredientRepo, stockMovementRepo, stockAlertService)
+	productService := services.NewProductService(db, productRepo, categoryRepo, ingredientRepo, supplierRepo, stockService)
+	productVariantService := services.NewProductVariantService(db, productRepo, productVariantRepo, stockService)
+	modifierService := services.NewModifierService(db, modifierRepo, productRepo, categoryRepo)
+	ingredientService := services.NewIngredientService(db, ingredientRepo, productRepo, productVariantRepo, modifierRepo)
+	sequenceService := services.NewSequenceService(sequenceRepo, settingService)
+	promotionService := services.NewPromotionService(promotionRepo, productRepo, categoryRepo)
+	voucherService := services.NewVoucherService(db, voucherRepo)
+	queueService := services.NewQueueService(transactionRepo, kitchenTicketRepo, eventHub)
+	kitchenService := services.NewKitchenService(db, kitchenTicketRepo, queueService, eventHub)
+	transactionService := services.NewTransactionService(db, transactionRepo, transactionItemRepo, productRepo, productVariantRepo, tableRepo, sequenceService, settingService, promotionService, voucherService, modifierService, ingredientService, stockService, kitchenService)
+	heldOrderService := services.NewHeldOrderService(db, transactionRepo, transactionItemRepo, sequenceService, settingService, transactionService, kitchenService, stockService)
+	tableService := services.NewTableService(tableRepo, transactionRepo)
+	tabService := services.NewTabService(db, tableRepo, transactionRepo, transactionItemRepo, sequenceService, transactionService, kitchenService, stockService)
+	splitBillService := services.NewSplitBillService(db, tableRepo, transactionRepo, sequenceService, transactionService, stockService)
+	supplierService := services.NewSupplierService(supplierRepo, purchaseOrderRepo)
+	purchaseOrderService := services.NewPurchaseOrderService(db, purchaseOrderRepo, supplierRepo, productRepo, productVariantRepo, ingredientRepo, sequenceService, stockService)
+	reorderService := services.NewReorderService(productRepo, supplierRepo, transactionItemRepo, purchaseOrderRepo, settingService, stockAlertService, purchaseOrderService)
+	stockCountService := services.NewStockCountService(db, stockCountRepo, productRepo, productVariantRepo, ingredientRepo, categoryRepo, sequenceService, stockService)
+	wasteService := services.NewWasteService(db, wasteRepo, productRepo, productVariantRepo, ingredientRepo, transactionRepo, ingredientService, stockService)
+	refundService := services.NewRefundService(db, refundRepo, transactionRepo, transactionItemRepo, sequenceService, stockService)
+	reportService := services.NewReportService(transactionRepo, transactionItemRepo, productRepo, categoryRepo, wasteRepo, stockAlertService)
+	idempotencyService := services.NewIdempotencyService(idempotencyRepo, idempotencyKeyTTL())
 
 	// Initialize controllers
 	userController := controllers.NewUserController(userService)
 	categoryController := controllers.NewCategoryController(categoryService)
 	productController := controllers.NewProductController(productService)
+	productVariantController := controllers.NewProductVariantController(productVariantService)
+	modifierController := controllers.NewModifierController(modifierService)
+	ingredientController := controllers.NewIngredientController(ingredientService)
+	stockController := controllers.NewStockController(stockService)
+	supplierController := controllers.NewSupplierController(supplierService)
+	purchaseOrderController := controllers.NewPurchaseOrderController(purchaseOrderService)
+	stockCountController := controllers.NewStockCountController(stockCountService)
+	stockAlertController := controllers.NewStockAlertController(stockAlertService)
+	reorderController := controllers.NewReorderController(reorderService)
+	wasteController := controllers.NewWasteController(wasteService)
 	transactionController := controllers.NewTransactionController(transactionService)
 	settingController := controllers.NewSettingController(settingService)
 	reportController := controllers.NewReportController(reportService)
+	refundController := controllers.NewRefundController(refundService)
+	promotionController := controllers.NewPromotionController(promotionService)
+	voucherController := controllers.NewVoucherController(voucherService)
+	heldOrderController := controllers.NewHeldOrderController(heldOrderService)
+	tableController := controllers.NewTableController(tableService, tabService)
+	tabController := controllers.NewTabController(tabService)
+	splitBillController := controllers.NewSplitBillController(splitBillService)
+	kitchenController := controllers.NewKitchenController(kitchenService)
+	queueController := controllers.NewQueueController(queueService)
 
 	// Initialize routes
 	r := routes.NewRoutes(
 		userController,
 		categoryController,
 		productController,
+		productVariantController,
+		modifierController,
+		ingredientController,
+		stockController,
+		supplierController,
+		purchaseOrderController,
+		stockCountController,
+		stockAlertController,
+		reorderController,
+		wasteController,
 		transactionController,
 		settingController,
 		reportController,
+		refundController,
+		promotionController,
+		voucherController,
+		heldOrderController,
+		tableController,
+		tabController,
+		splitBillController,
+		kitchenController,
+		queueController,
+		idempotencyService,
 	)
 
 	// Setup router
@@ -77,3 +155,12 @@ func main() {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
+
+// idempotencyKeyTTL reads how long Idempotency-Key responses are kept (default 24h)
+func idempotencyKeyTTL() time.Duration {
+	ttl, err := time.ParseDuration(os.Getenv("IDEMPOTENCY_KEY_TTL"))
+	if err != nil || ttl <= 0 {
+		return 24 * time.Hour
+	}
+	return ttl
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/services"
 )
 
 func AuthMiddleware() gin.HandlerFunc {
@@ -31,99 +32,128 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		tokenString := tokenParts[1]
-		jwtSecret := os.Getenv("JWT_SECRET")
-		if jwtSecret == "" {
-			jwtSecret = "your-secret-key"
+		authenticate(ctx, tokenParts[1], "")
+	}
+}
+
+// StreamAuthMiddleware guards Server-Sent Events routes. EventSource cannot
+// send an Authorization header, so a stream token (POST /auth/stream-token)
+// is accepted in the "token" query parameter; without one the header is
+// checked as usual.
+func StreamAuthMiddleware() gin.HandlerFunc {
+	headerAuth := AuthMiddleware()
+	return func(ctx *gin.Context) {
+		tokenString := ctx.Query("token")
+		if tokenString == "" {
+			headerAuth(ctx)
+			return
 		}
+		authenticate(ctx, tokenString, services.StreamTokenScope)
+	}
+}
 
-		// Parse and validate token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(jwtSecret), nil
+// authenticate validates the token and sets the user info in the context.
+// The token's scope claim must equal scope; login tokens have none.
+func authenticate(ctx *gin.Context, tokenString, scope string) {
+	jwtSecret := os.Getenv("JWT_SECRET")
+	if jwtSecret == "" {
+		jwtSecret = "your-secret-key"
+	}
+
+	// Parse and validate token
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return []byte(jwtSecret), nil
+	})
+
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, dto.APIResponse{
+			Success: false,
+			Message: "Invalid token: " + err.Error(),
 		})
+		return
+	}
 
-		if err != nil {
+	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
+		if tokenScope, _ := claims["scope"].(string); tokenScope != scope {
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, dto.APIResponse{
 				Success: false,
-				Message: "Invalid token: " + err.Error(),
+				Message: "Invalid token: not valid for this route",
 			})
 			return
 		}
 
-		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			// Extract user info from claims with nil checks
-			userIDClaim, exists := claims["user_id"]
-			if !exists || userIDClaim == nil {
-				ctx.AbortWithStatusJSON(http.StatusUnauthorized, dto.APIResponse{
-					Success: false,
-					Message: "Invalid token: user_id not found",
-				})
-				return
-			}
-
-			userIDFloat, ok := userIDClaim.(float64)
-			if !ok {
-				ctx.AbortWithStatusJSON(http.StatusUnauthorized, dto.APIResponse{
-					Success: false,
-					Message: "Invalid token: invalid user_id format",
-				})
-				return
-			}
-
-			emailClaim, exists := claims["email"]
-			if !exists || emailClaim == nil {
-				ctx.AbortWithStatusJSON(http.StatusUnauthorized, dto.APIResponse{
-					Success: false,
-					Message: "Invalid token: email not found",
-				})
-				return
-			}
-
-			email, ok := emailClaim.(string)
-			if !ok {
-				ctx.AbortWithStatusJSON(http.StatusUnauthorized, dto.APIResponse{
-					Success: false,
-					Message: "Invalid token: invalid email format",
-				})
-				return
-			}
+		// Extract user info from claims with nil checks
+		userIDClaim, exists := claims["user_id"]
+		if !exists || userIDClaim == nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, dto.APIResponse{
+				Success: false,
+				Message: "Invalid token: user_id not found",
+			})
+			return
+		}
 
-			roleClaim, exists := claims["role"]
-			if !exists || roleClaim == nil {
-				ctx.AbortWithStatusJSON(http.StatusUnauthorized, dto.APIResponse{
-					Success: false,
-					Message: "Invalid token: role not found",
-				})
-				return
-			}
+		userIDFloat, ok := userIDClaim.(float64)
+		if !ok {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, dto.APIResponse{
+				Success: false,
+				Message: "Invalid token: invalid user_id format",
+			})
+			return
+		}
 
-			role, ok := roleClaim.(string)
-			if !ok {
-				ctx.AbortWithStatusJSON(http.StatusUnauthorized, dto.APIResponse{
-					Success: false,
-					Message: "Invalid token: invalid role format",
-				})
-				return
-			}
+		emailClaim, exists := claims["email"]
+		if !exists || emailClaim == nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, dto.APIResponse{
+				Success: false,
+				Message: "Invalid token: email not found",
+			})
+			return
+		}
 
-			userID := uint(userIDFloat)
+		email, ok := emailClaim.(string)
+		if !ok {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, dto.APIResponse{
+				Success: false,
+				Message: "Invalid token: invalid email format",
+			})
+			return
+		}
 
-			// Set user info in context
-			ctx.Set("userID", userID)
-			ctx.Set("email", email)
-			ctx.Set("role", role)
+		roleClaim, exists := claims["role"]
+		if !exists || roleClaim == nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, dto.APIResponse{
+				Success: false,
+				Message: "Invalid token: role not found",
+			})
+			return
+		}
 
-			ctx.Next()
-		} else {
+		role, ok := roleClaim.(string)
+		if !ok {
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, dto.APIResponse{
 				Success: false,
-				Message: "Invalid token claims",
+				Message: "Invalid token: invalid role format",
 			})
 			return
 		}
+
+		userID := uint(userIDFloat)
+
+		// Set user info in context
+		ctx.Set("userID", userID)
+		ctx.Set("email", email)
+		ctx.Set("role", role)
+
+		ctx.Next()
+	} else {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, dto.APIResponse{
+			Success: false,
+			Message: "Invalid token claims",
+		})
+		return
 	}
 }
 
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/syrlramadhan/cashier-app/services"
+)
+
+func signTestToken(t *testing.T, scope string) string {
+	t.Helper()
+	claims := jwt.MapClaims{
+		"user_id": 1,
+		"email":   "kitchen@example.com",
+		"role":    "cashier",
+		"exp":     time.Now().Add(time.Minute).Unix(),
+	}
+	if scope != "" {
+		claims["scope"] = scope
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test-secret"))
+	if err != nil {
+		t.Fatalf("SignedString() error = %v", err)
+	}
+	return token
+}
+
+func TestStreamAuthMiddleware(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	ok := func(ctx *gin.Context) { ctx.Status(http.StatusOK) }
+	router.GET("/stream", StreamAuthMiddleware(), ok)
+	router.GET("/tickets", AuthMiddleware(), ok)
+
+	loginToken := signTestToken(t, "")
+	streamToken := signTestToken(t, services.StreamTokenScope)
+
+	tests := []struct {
+		name   string
+		path   string
+		header string
+		want   int
+	}{
+		{name: "stream token in the query", path: "/stream?token=" + streamToken, want: http.StatusOK},
+		{name: "login token in the header", path: "/stream", header: "Bearer " + loginToken, want: http.StatusOK},
+		{name: "login token in the query", path: "/stream?token=" + loginToken, want: http.StatusUnauthorized},
+		{name: "no token", path: "/stream", want: http.StatusUnauthorized},
+		{name: "stream token on another route", path: "/tickets", header: "Bearer " + streamToken, want: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.header != "" {
+				request.Header.Set("Authorization", tt.header)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.want {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.want)
+			}
+		})
+	}
+}
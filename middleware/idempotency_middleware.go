@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/services"
+)
+
+// idempotencyResponseWriter keeps a copy of the response body so it can be stored for replays
+type idempotencyResponseWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *idempotencyResponseWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *idempotencyResponseWriter) WriteString(data string) (int, error) {
+	w.body.WriteString(data)
+	return w.ResponseWriter.WriteString(data)
+}
+
+// IdempotencyMiddleware replays the stored response when a mutating request is
+// retried with the same Idempotency-Key header. Must run after AuthMiddleware.
+func IdempotencyMiddleware(idempotencyService *services.IdempotencyService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader("Idempotency-Key")
+		if key == "" || !isMutatingMethod(ctx.Request.Method) {
+			ctx.Next()
+			return
+		}
+
+		if len(key) > 255 {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.APIResponse{
+				Success: false,
+				Message: "Idempotency-Key must not exceed 255 characters",
+			})
+			return
+		}
+
+		userID, exists := ctx.Get("userID")
+		if !exists {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, dto.APIResponse{
+				Success: false,
+				Message: "Unauthorized",
+			})
+			return
+		}
+
+		body, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, dto.APIResponse{
+				Success: false,
+				Message: "Failed to read request body",
+				Error:   err.Error(),
+			})
+			return
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		record, err := idempotencyService.Begin(userID.(uint), key, ctx.Request.Method, ctx.Request.URL.Path, ctx.Request.URL.RawQuery, body)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, services.ErrIdempotencyKeyInProgress) {
+				status = http.StatusConflict
+			} else if errors.Is(err, services.ErrIdempotencyKeyMismatch) {
+				status = http.StatusUnprocessableEntity
+			}
+			ctx.AbortWithStatusJSON(status, dto.APIResponse{
+				Success: false,
+				Message: "Idempotency key rejected",
+				Error:   err.Error(),
+			})
+			return
+		}
+
+		// Replay the stored response of the original request
+		if record.StatusCode != 0 {
+			ctx.Header("Idempotent-Replayed", "true")
+			ctx.Data(record.StatusCode, "application/json; charset=utf-8", []byte(record.ResponseBody))
+			ctx.Abort()
+			return
+		}
+
+		writer := &idempotencyResponseWriter{ResponseWriter: ctx.Writer, body: &bytes.Buffer{}}
+		ctx.Writer = writer
+
+		// A panicking handler never completes the key; release it so retries
+		// are not rejected as in progress until it expires
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				if err := idempotencyService.Release(record); err != nil {
+					log.Printf("Failed to release idempotency key %s: %v", key, err)
+				}
+				panic(recovered)
+			}
+		}()
+
+		ctx.Next()
+
+		if err := idempotencyService.Complete(record, writer.Status(), writer.body.Bytes()); err != nil {
+			log.Printf("Failed to store idempotent response for key %s: %v", key, err)
+		}
+	}
+}
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,253 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/syrlramadhan/cashier-app/models"
+	"github.com/syrlramadhan/cashier-app/repositories"
+	"github.com/syrlramadhan/cashier-app/services"
+)
+
+// fakeIdempotencyKeyRepository keeps keys in memory and, like the unique
+// index, refuses a second key of the same user
+type fakeIdempotencyKeyRepository struct {
+	repositories.IdempotencyKeyRepository
+	mu      sync.Mutex
+	nextID  uint
+	records map[uint]models.IdempotencyKey
+	sweeps  int
+}
+
+func newFakeIdempotencyKeyRepository() *fakeIdempotencyKeyRepository {
+	return &fakeIdempotencyKeyRepository{records: make(map[uint]models.IdempotencyKey)}
+}
+
+func (r *fakeIdempotencyKeyRepository) FindByUserAndKey(userID uint, key string) (*models.IdempotencyKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, record := range r.records {
+		if record.UserID == userID && record.Key == key {
+			return &record, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (r *fakeIdempotencyKeyRepository) Create(record *models.IdempotencyKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.records {
+		if existing.UserID == record.UserID && existing.Key == record.Key {
+			return errors.New("duplicate key")
+		}
+	}
+	r.nextID++
+	record.ID = r.nextID
+	r.records[record.ID] = *record
+	return nil
+}
+
+func (r *fakeIdempotencyKeyRepository) Update(record *models.IdempotencyKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records[record.ID] = *record
+	return nil
+}
+
+func (r *fakeIdempotencyKeyRepository) Delete(id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.records, id)
+	return nil
+}
+
+func (r *fakeIdempotencyKeyRepository) DeleteExpired(now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sweeps++
+	for id, record := range r.records {
+		if record.ExpiresAt.Before(now) {
+			delete(r.records, id)
+		}
+	}
+	return nil
+}
+
+// idempotencyTestServer counts how often the handler runs. The handler
+// answers with the status in the "status" query parameter, and waits for
+// release when one is set.
+type idempotencyTestServer struct {
+	router  *gin.Engine
+	repo    *fakeIdempotencyKeyRepository
+	mu      sync.Mutex
+	calls   int
+	release chan struct{}
+}
+
+func newIdempotencyTestServer() *idempotencyTestServer {
+	gin.SetMode(gin.TestMode)
+	server := &idempotencyTestServer{repo: newFakeIdempotencyKeyRepository()}
+
+	server.router = gin.New()
+	server.router.Use(func(ctx *gin.Context) { ctx.Set("userID", uint(1)) })
+	server.router.Use(IdempotencyMiddleware(services.NewIdempotencyService(server.repo, time.Hour)))
+	server.router.POST("/transactions", func(ctx *gin.Context) {
+		server.mu.Lock()
+		server.calls++
+		calls := server.calls
+		server.mu.Unlock()
+
+		if server.release != nil {
+			<-server.release
+		}
+		if ctx.Query("status") == "500" {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"call": calls})
+			return
+		}
+		ctx.JSON(http.StatusCreated, gin.H{"call": calls})
+	})
+	return server
+}
+
+func (s *idempotencyTestServer) post(key, query, body string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodPost, "/transactions"+query, strings.NewReader(body))
+	if key != "" {
+		request.Header.Set("Idempotency-Key", key)
+	}
+	recorder := httptest.NewRecorder()
+	s.router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	t.Run("replays the stored response", func(t *testing.T) {
+		server := newIdempotencyTestServer()
+
+		first := server.post("key-1", "", `{"total":1000}`)
+		retry := server.post("key-1", "", `{"total":1000}`)
+
+		if server.calls != 1 {
+			t.Errorf("handler ran %d times, want 1", server.calls)
+		}
+		if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() {
+			t.Errorf("retry = %d %s, want %d %s", retry.Code, retry.Body, first.Code, first.Body)
+		}
+		if retry.Header().Get("Idempotent-Replayed") != "true" {
+			t.Error("retry is missing the Idempotent-Replayed header")
+		}
+	})
+
+	t.Run("runs requests without a key every time", func(t *testing.T) {
+		server := newIdempotencyTestServer()
+
+		server.post("", "", `{"total":1000}`)
+		server.post("", "", `{"total":1000}`)
+
+		if server.calls != 2 {
+			t.Errorf("handler ran %d times, want 2", server.calls)
+		}
+	})
+
+	mismatches := []struct {
+		name  string
+		query string
+		body  string
+	}{
+		{name: "different body", body: `{"total":2000}`},
+		{name: "different query string", query: "?table=2", body: `{"total":1000}`},
+	}
+	for _, tt := range mismatches {
+		t.Run("rejects a reused key with a "+tt.name, func(t *testing.T) {
+			server := newIdempotencyTestServer()
+
+			server.post("key-1", "", `{"total":1000}`)
+			reused := server.post("key-1", tt.query, tt.body)
+
+			if reused.Code != http.StatusUnprocessableEntity {
+				t.Errorf("status = %d, want %d", reused.Code, http.StatusUnprocessableEntity)
+			}
+			if server.calls != 1 {
+				t.Errorf("handler ran %d times, want 1", server.calls)
+			}
+		})
+	}
+
+	t.Run("rejects a retry while the request is in progress", func(t *testing.T) {
+		server := newIdempotencyTestServer()
+		server.release = make(chan struct{})
+
+		done := make(chan *httptest.ResponseRecorder)
+		go func() { done <- server.post("key-1", "", `{"total":1000}`) }()
+		for {
+			server.mu.Lock()
+			started := server.calls == 1
+			server.mu.Unlock()
+			if started {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+
+		retry := server.post("key-1", "", `{"total":1000}`)
+		close(server.release)
+		first := <-done
+
+		if retry.Code != http.StatusConflict {
+			t.Errorf("retry status = %d, want %d", retry.Code, http.StatusConflict)
+		}
+		if first.Code != http.StatusCreated {
+			t.Errorf("first status = %d, want %d", first.Code, http.StatusCreated)
+		}
+	})
+
+	t.Run("releases the key after a server error", func(t *testing.T) {
+		server := newIdempotencyTestServer()
+
+		failed := server.post("key-1", "?status=500", `{"total":1000}`)
+		retry := server.post("key-1", "?status=500", `{"total":1000}`)
+
+		if failed.Code != http.StatusInternalServerError || retry.Code != http.StatusInternalServerError {
+			t.Errorf("statuses = %d, %d, want both %d", failed.Code, retry.Code, http.StatusInternalServerError)
+		}
+		if server.calls != 2 {
+			t.Errorf("handler ran %d times, want 2", server.calls)
+		}
+	})
+
+	t.Run("reuses an expired key", func(t *testing.T) {
+		server := newIdempotencyTestServer()
+
+		server.post("key-1", "", `{"total":1000}`)
+		for id, record := range server.repo.records {
+			record.ExpiresAt = time.Now().Add(-time.Minute)
+			server.repo.records[id] = record
+		}
+		again := server.post("key-1", "", `{"total":2000}`)
+
+		if again.Code != http.StatusCreated {
+			t.Errorf("status = %d, want %d", again.Code, http.StatusCreated)
+		}
+		if server.calls != 2 {
+			t.Errorf("handler ran %d times, want 2", server.calls)
+		}
+	})
+
+	t.Run("sweeps expired keys once per interval", func(t *testing.T) {
+		server := newIdempotencyTestServer()
+
+		server.post("key-1", "", `{"total":1000}`)
+		server.post("key-2", "", `{"total":1000}`)
+		server.post("key-3", "", `{"total":1000}`)
+
+		if server.repo.sweeps != 1 {
+			t.Errorf("expired keys swept %d times, want 1", server.repo.sweeps)
+		}
+	})
+}
@@ -9,6 +9,7 @@ import (
 type Category struct {
 	ID        uint           `gorm:"primaryKey" json:"id"`
 	Name      string         `gorm:"size:100;not null" json:"name"`
+	Station   string         `gorm:"size:30;not null;default:'kitchen'" json:"station"` // kitchen display station its products are prepared at, e.g. kitchen, bar
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Ingredient is a stocked raw material such as milk, coffee beans or avocado.
+// Stock is counted in Unit (e.g. g, ml, pcs) and Cost is the cost of one unit.
+type Ingredient struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	Name      string         `gorm:"size:100;not null" json:"name"`
+	Unit      string         `gorm:"size:20;not null" json:"unit"`
+	Stock     float64        `gorm:"type:decimal(14,3);not null;default:0" json:"stock"`
+	Cost      Money          `gorm:"not null;default:0" json:"cost"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (Ingredient) TableName() string {
+	return "ingredients"
+}
+
+// RecipeItem is the quantity of an ingredient used to make one unit of a
+// product, a variant or a modifier option; exactly one of the three is set.
+// A variant recipe replaces the recipe of its product, and modifier recipes
+// are used on top (an extra shot adds beans).
+type RecipeItem struct {
+	ID               uint       `gorm:"primaryKey" json:"id"`
+	ProductID        *uint      `gorm:"index" json:"product_id,omitempty"`
+	VariantID        *uint      `gorm:"index" json:"variant_id,omitempty"`
+	ModifierOptionID *uint      `gorm:"index" json:"modifier_option_id,omitempty"`
+	IngredientID     uint       `gorm:"not null;index" json:"ingredient_id"`
+	Ingredient       Ingredient `gorm:"foreignKey:IngredientID" json:"ingredient,omitempty"`
+	Quantity         float64    `gorm:"type:decimal(14,3);not null" json:"quantity"`
+}
+
+func (RecipeItem) TableName() string {
+	return "recipe_items"
+}
+
+// TransactionItemIngredient is the ingredient quantity a sold line used, so
+// cancellations and refunds put back exactly what was taken
+type TransactionItemIngredient struct {
+	ID                uint    `gorm:"primaryKey" json:"id"`
+	TransactionItemID uint    `gorm:"not null;index" json:"transaction_item_id"`
+	IngredientID      uint    `gorm:"not null;index" json:"ingredient_id"`
+	Quantity          float64 `gorm:"type:decimal(14,3);not null" json:"quantity"`
+}
+
+func (TransactionItemIngredient) TableName() string {
+	return "transaction_item_ingredients"
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// KitchenTicket is the part of an order one station has to prepare. A sale
+// produces one ticket per station; every round of a tab produces new tickets.
+type KitchenTicket struct {
+	ID              uint                `gorm:"primaryKey" json:"id"`
+	TransactionID   uint                `gorm:"not null;index" json:"transaction_id"`
+	TransactionCode string              `gorm:"size:50;not null" json:"transaction_code"`
+	TableID         *uint               `gorm:"index" json:"table_id,omitempty"`
+	OrderType       string              `gorm:"size:20;not null" json:"order_type"`
+	Station         string              `gorm:"size:30;not null;index" json:"station"`
+	Round           int                 `gorm:"not null;default:0" json:"round"`
+	Status          string              `gorm:"size:20;not null;default:'new';index" json:"status"` // new, in_progress, ready, served, cancelled
+	StartedAt       *time.Time          `json:"started_at,omitempty"`
+	ReadyAt         *time.Time          `json:"ready_at,omitempty"`
+	ServedAt        *time.Time          `json:"served_at,omitempty"`
+	CreatedAt       time.Time           `json:"created_at"`
+	UpdatedAt       time.Time           `json:"updated_at"`
+	Items           []KitchenTicketItem `gorm:"foreignKey:KitchenTicketID" json:"items,omitempty"`
+}
+
+func (KitchenTicket) TableName() string {
+	return "kitchen_tickets"
+}
+
+type KitchenTicketItem struct {
+	ID                uint   `gorm:"primaryKey" json:"id"`
+	KitchenTicketID   uint   `gorm:"not null;index" json:"kitchen_ticket_id"`
+	TransactionItemID uint   `gorm:"not null;index" json:"transaction_item_id"`
+	ProductName       string `gorm:"size:150;not null" json:"product_name"`
+	VariantName       string `gorm:"size:150" json:"variant_name,omitempty"`
+	Modifiers         string `gorm:"size:255" json:"modifiers,omitempty"` // e.g. "Extra Shot, Oat Milk"
+	Quantity          int    `gorm:"not null" json:"quantity"`
+}
+
+func (KitchenTicketItem) TableName() string {
+	return "kitchen_ticket_items"
+}
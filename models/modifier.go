@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ModifierGroup is a set of optional add-ons, e.g. Extras (Extra Shot, Oat
+// Milk) or Toppings, offered with the products and categories it is attached
+// to. At least MinSelect options must be chosen and at most MaxSelect (0 for
+// no limit).
+type ModifierGroup struct {
+	ID         uint             `gorm:"primaryKey" json:"id"`
+	Name       string           `gorm:"size:100;not null" json:"name"`
+	MinSelect  int              `gorm:"not null;default:0" json:"min_select"`
+	MaxSelect  int              `gorm:"not null;default:0" json:"max_select"`
+	Options    []ModifierOption `gorm:"foreignKey:GroupID" json:"options,omitempty"`
+	Products   []Product        `gorm:"many2many:product_modifier_groups;" json:"products,omitempty"`
+	Categories []Category       `gorm:"many2many:category_modifier_groups;" json:"categories,omitempty"`
+	CreatedAt  time.Time        `json:"created_at"`
+	UpdatedAt  time.Time        `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt   `gorm:"index" json:"-"`
+}
+
+func (ModifierGroup) TableName() string {
+	return "modifier_groups"
+}
+
+type ModifierOption struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	GroupID   uint           `gorm:"not null;index" json:"group_id"`
+	Name      string         `gorm:"size:100;not null" json:"name"`
+	Price     Money          `gorm:"not null;default:0" json:"price"`
+	Position  int            `gorm:"not null;default:0" json:"position"`
+	IsActive  bool           `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (ModifierOption) TableName() string {
+	return "modifier_options"
+}
+
+// TransactionItemModifier is an add-on chosen for a line. The names and the
+// price are copied so receipts and reports keep what was sold.
+type TransactionItemModifier struct {
+	ID                uint   `gorm:"primaryKey" json:"id"`
+	TransactionItemID uint   `gorm:"not null;index" json:"transaction_item_id"`
+	ModifierOptionID  uint   `gorm:"not null;index" json:"modifier_option_id"`
+	GroupName         string `gorm:"size:100;not null" json:"group_name"`
+	Name              string `gorm:"size:100;not null" json:"name"`
+	Price             Money  `gorm:"not null" json:"price"` // per unit of the line
+}
+
+func (TransactionItemModifier) TableName() string {
+	return "transaction_item_modifiers"
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"math"
+	"math/big"
+)
+
+// Money is an amount in the smallest unit the store works with (whole rupiah
+// for IDR). Amounts are kept as integers so sums never drift; every operation
+// that can produce a fraction rounds half-up explicitly.
+type Money int64
+
+// Mul multiplies the amount by a quantity
+func (m Money) Mul(quantity int) Money {
+	return m * Money(quantity)
+}
+
+// MulRate returns m * rate rounded half-up, where rate is a fraction (0.11 for 11%).
+// Rates are applied with basis point precision.
+func (m Money) MulRate(rate float64) Money {
+	return Money(roundDiv(int64(m)*rateToBasisPoints(rate), 10000))
+}
+
+// InclusiveTax back-calculates the tax contained in a tax-inclusive amount,
+// rounded half-up: m * rate / (1 + rate).
+func (m Money) InclusiveTax(rate float64) Money {
+	bps := rateToBasisPoints(rate)
+	return Money(roundDiv(int64(m)*bps, 10000+bps))
+}
+
+// RoundTo rounds the amount half-up to the nearest multiple of unit (e.g. 100
+// or 500 for cash). A unit of zero or less leaves the amount unchanged.
+func (m Money) RoundTo(unit Money) Money {
+	if unit <= 0 {
+		return m
+	}
+	return Money(roundDiv(int64(m), int64(unit))) * unit
+}
+
+// MulDiv returns m * num / den (den > 0) rounded half-up. It is used to
+// prorate amounts and works with arbitrary precision so it cannot overflow.
+func (m Money) MulDiv(num, den int64) Money {
+	n := new(big.Int).Mul(big.NewInt(int64(m)), big.NewInt(num))
+	d := big.NewInt(den)
+	q, r := new(big.Int).QuoRem(n, d, new(big.Int))
+	if r.Sign() != 0 && new(big.Int).Mul(new(big.Int).Abs(r), big.NewInt(2)).Cmp(d) >= 0 {
+		q.Add(q, big.NewInt(int64(n.Sign())))
+	}
+	return Money(q.Int64())
+}
+
+func rateToBasisPoints(rate float64) int64 {
+	return int64(math.Round(rate * 10000))
+}
+
+// roundDiv divides n by d (d > 0) rounding half away from zero
+func roundDiv(n, d int64) int64 {
+	if n < 0 {
+		return -((-n*2 + d) / (d * 2))
+	}
+	return (n*2 + d) / (d * 2)
+}
@@ -0,0 +1,98 @@
+package models
+
+import "testing"
+
+func TestMoneyMulRate(t *testing.T) {
+	tests := []struct {
+		name  string
+		money Money
+		rate  float64
+		want  Money
+	}{
+		{"whole result", 100000, 0.11, 11000},
+		{"rounds half up", 50, 0.11, 6}, // 5.5
+		{"rounds down below half", 40, 0.11, 4},
+		{"negative rounds half away from zero", -50, 0.11, -6},
+		{"basis point precision", 10000, 0.1234, 1234},
+		{"zero rate", 12345, 0, 0},
+		{"full rate", 12345, 1, 12345},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.money.MulRate(tt.rate); got != tt.want {
+				t.Errorf("Money(%d).MulRate(%v) = %d, want %d", tt.money, tt.rate, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyInclusiveTax(t *testing.T) {
+	tests := []struct {
+		name  string
+		money Money
+		rate  float64
+		want  Money
+	}{
+		{"exact", 111000, 0.11, 11000},
+		{"rounds down below half", 25000, 0.11, 2477}, // 2477.48
+		{"rounds up above half", 7, 0.11, 1},          // 0.69
+		{"rounds half up", 5, 1, 3},                   // 2.5
+		{"ten percent", 11000, 0.1, 1000},
+		{"zero rate", 50000, 0, 0},
+		{"zero amount", 0, 0.11, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.money.InclusiveTax(tt.rate); got != tt.want {
+				t.Errorf("Money(%d).InclusiveTax(%v) = %d, want %d", tt.money, tt.rate, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyRoundTo(t *testing.T) {
+	tests := []struct {
+		name              string
+		money, unit, want Money
+	}{
+		{"rounds down below half", 12345, 100, 12300},
+		{"rounds half up", 12350, 100, 12400},
+		{"rounds up above half", 12345, 500, 12500},
+		{"zero unit leaves the amount", 12345, 0, 12345},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.money.RoundTo(tt.unit); got != tt.want {
+				t.Errorf("Money(%d).RoundTo(%d) = %d, want %d", tt.money, tt.unit, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyMulDiv(t *testing.T) {
+	tests := []struct {
+		name     string
+		money    Money
+		num, den int64
+		want     Money
+	}{
+		{"exact", 30000, 1, 3, 10000},
+		{"rounds down below half", 10000, 1, 3, 3333},
+		{"rounds up above half", 20000, 1, 3, 6667},
+		{"rounds half up", 5, 1, 2, 3},
+		{"negative rounds half away from zero", -5, 1, 2, -3},
+		{"no overflow on large amounts", 9_000_000_000_000_000, 3, 4, 6_750_000_000_000_000},
+		{"zero numerator", 12345, 0, 7, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.money.MulDiv(tt.num, tt.den); got != tt.want {
+				t.Errorf("Money(%d).MulDiv(%d, %d) = %d, want %d", tt.money, tt.num, tt.den, got, tt.want)
+			}
+		})
+	}
+}
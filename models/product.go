@@ -7,18 +7,60 @@ import (
 )
 
 type Product struct {
-	ID         uint           `gorm:"primaryKey" json:"id"`
-	Name       string         `gorm:"size:150;not null" json:"name"`
-	Price      float64        `gorm:"not null" json:"price"`
-	Stock      int            `gorm:"not null;default:0" json:"stock"`
-	CategoryID uint           `gorm:"not null" json:"category_id"`
-	Category   Category       `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
-	Image      string         `gorm:"size:255" json:"image,omitempty"`
-	CreatedAt  time.Time      `json:"created_at"`
-	UpdatedAt  time.Time      `json:"updated_at"`
-	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+	ID            uint                  `gorm:"primaryKey" json:"id"`
+	Name          string                `gorm:"size:150;not null" json:"name"`
+	Price         Money                 `gorm:"not null" json:"price"`
+	Cost          Money                 `gorm:"not null;default:0" json:"cost"` // Average cost of one unit, kept up to date by goods receiving
+	Stock         int                   `gorm:"not null;default:0" json:"stock"`
+	MinStock      *int                  `json:"min_stock"`                             // Low on stock below this; the low_stock_threshold setting when nil
+	ReorderQty    int                   `gorm:"not null;default:0" json:"reorder_qty"` // Usual quantity to order when low on stock
+	SupplierID    *uint                 `gorm:"index" json:"supplier_id,omitempty"`    // Usual supplier, used for reorder suggestions
+	CategoryID    uint                  `gorm:"not null" json:"category_id"`
+	Category      Category              `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+	Image         string                `gorm:"size:255" json:"image,omitempty"`
+	Prices        []ProductPrice        `gorm:"foreignKey:ProductID" json:"prices,omitempty"` // per order type overrides of Price
+	VariantGroups []ProductVariantGroup `gorm:"foreignKey:ProductID" json:"variant_groups,omitempty"`
+	Variants      []ProductVariant      `gorm:"foreignKey:ProductID" json:"variants,omitempty"`
+	CreatedAt     time.Time             `json:"created_at"`
+	UpdatedAt     time.Time             `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt        `gorm:"index" json:"-"`
 }
 
 func (Product) TableName() string {
 	return "products"
 }
+
+// PriceFor returns the price of the product for an order type, falling back
+// to Price when the type has no override
+func (p Product) PriceFor(orderType string) Money {
+	for _, price := range p.Prices {
+		if price.OrderType == orderType {
+			return price.Price
+		}
+	}
+	return p.Price
+}
+
+// MinStockOr returns the minimum stock of the product, or defaultMin when
+// the product does not set its own
+func (p Product) MinStockOr(defaultMin int) int {
+	if p.MinStock != nil {
+		return *p.MinStock
+	}
+	return defaultMin
+}
+
+// ProductPrice overrides the price of a product for one order type, e.g. a
+// higher delivery price to cover the platform commission
+type ProductPrice struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	ProductID uint      `gorm:"not null;uniqueIndex:idx_product_order_type" json:"product_id"`
+	OrderType string    `gorm:"size:20;not null;uniqueIndex:idx_product_order_type" json:"order_type"` // dine_in, takeaway, delivery
+	Price     Money     `gorm:"not null" json:"price"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (ProductPrice) TableName() string {
+	return "product_prices"
+}
@@ -0,0 +1,33 @@
+package models
+
+import "testing"
+
+func TestProductPriceFor(t *testing.T) {
+	product := Product{
+		Price: 20000,
+		Prices: []ProductPrice{
+			{OrderType: "dine_in", Price: 22000},
+			{OrderType: "delivery", Price: 25000},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		product   Product
+		orderType string
+		want      Money
+	}{
+		{"dine-in override", product, "dine_in", 22000},
+		{"takeaway without override", product, "takeaway", 20000},
+		{"delivery override", product, "delivery", 25000},
+		{"no overrides", Product{Price: 20000}, "delivery", 20000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.product.PriceFor(tt.orderType); got != tt.want {
+				t.Errorf("PriceFor(%q) = %d, want %d", tt.orderType, got, tt.want)
+			}
+		})
+	}
+}
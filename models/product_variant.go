@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ProductVariantGroup is a choice every variant of a product makes, e.g.
+// Size (Regular, Large) or Temperature (Hot, Iced)
+type ProductVariantGroup struct {
+	ID        uint                   `gorm:"primaryKey" json:"id"`
+	ProductID uint                   `gorm:"not null;index" json:"product_id"`
+	Name      string                 `gorm:"size:50;not null" json:"name"`
+	Position  int                    `gorm:"not null;default:0" json:"position"`
+	Options   []ProductVariantOption `gorm:"foreignKey:GroupID" json:"options,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+	UpdatedAt time.Time              `json:"updated_at"`
+}
+
+func (ProductVariantGroup) TableName() string {
+	return "product_variant_groups"
+}
+
+type ProductVariantOption struct {
+	ID       uint   `gorm:"primaryKey" json:"id"`
+	GroupID  uint   `gorm:"not null;index" json:"group_id"`
+	Name     string `gorm:"size:50;not null" json:"name"`
+	Position int    `gorm:"not null;default:0" json:"position"`
+}
+
+func (ProductVariantOption) TableName() string {
+	return "product_variant_options"
+}
+
+// ProductVariant is a sellable SKU of a product: one option of every variant
+// group. It either has its own Price or adds PriceDelta to the product price,
+// and with TrackStock it keeps its own stock instead of the product's.
+type ProductVariant struct {
+	ID         uint                   `gorm:"primaryKey" json:"id"`
+	ProductID  uint                   `gorm:"not null;index" json:"product_id"`
+	SKU        string                 `gorm:"size:50;index" json:"sku,omitempty"`
+	Name       string                 `gorm:"size:150;not null" json:"name"` // option names, e.g. "Large, Iced"
+	Price      *Money                 `json:"price,omitempty"`               // absolute price, replaces the product price
+	PriceDelta Money                  `gorm:"not null;default:0" json:"price_delta"`
+	TrackStock bool                   `gorm:"not null;default:false" json:"track_stock"`
+	Stock      int                    `gorm:"not null;default:0" json:"stock"`
+	IsActive   bool                   `gorm:"not null;default:true" json:"is_active"`
+	Options    []ProductVariantOption `gorm:"many2many:product_variant_option_values;" json:"options,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+	UpdatedAt  time.Time              `json:"updated_at"`
+	DeletedAt  gorm.DeletedAt         `gorm:"index" json:"-"`
+}
+
+func (ProductVariant) TableName() string {
+	return "product_variants"
+}
+
+// PriceFrom returns the variant price given the product price for the order type
+func (v ProductVariant) PriceFrom(base Money) Money {
+	if v.Price != nil {
+		return *v.Price
+	}
+	return base + v.PriceDelta
+}
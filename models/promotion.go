@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Promotion is an automatic discount applied at checkout.
+//
+//   - Type "percentage" takes DiscountPercent off, "fixed" takes DiscountAmount
+//     off each unit (line scopes) or the order, and "buy_x_get_y" gives GetQuantity
+//     units free for every BuyQuantity units bought (line scopes only).
+//   - Scope "product"/"category" applies per line to ProductID/CategoryID,
+//     "order" applies once to the order when MinSubtotal is reached.
+//   - StartTime/EndTime ("HH:MM") restrict the promotion to a daily window
+//     (happy hour) on top of the StartsAt/EndsAt validity period.
+type Promotion struct {
+	ID              uint           `gorm:"primaryKey" json:"id"`
+	Name            string         `gorm:"size:100;not null" json:"name"`
+	Type            string         `gorm:"size:20;not null" json:"type"`  // percentage, fixed, buy_x_get_y
+	Scope           string         `gorm:"size:20;not null" json:"scope"` // order, product, category
+	DiscountPercent float64        `gorm:"not null;default:0" json:"discount_percent"`
+	DiscountAmount  Money          `gorm:"not null;default:0" json:"discount_amount"`
+	BuyQuantity     int            `gorm:"not null;default:0" json:"buy_quantity"`
+	GetQuantity     int            `gorm:"not null;default:0" json:"get_quantity"`
+	ProductID       *uint          `gorm:"index" json:"product_id,omitempty"`
+	CategoryID      *uint          `gorm:"index" json:"category_id,omitempty"`
+	MinSubtotal     Money          `gorm:"not null;default:0" json:"min_subtotal"`
+	StartsAt        *time.Time     `json:"starts_at,omitempty"`
+	EndsAt          *time.Time     `json:"ends_at,omitempty"`
+	StartTime       string         `gorm:"size:5" json:"start_time,omitempty"`
+	EndTime         string         `gorm:"size:5" json:"end_time,omitempty"`
+	IsActive        bool           `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (Promotion) TableName() string {
+	return "promotions"
+}
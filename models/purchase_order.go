@@ -0,0 +1,107 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Supplier delivers stock. LeadTimeDays is how long an order usually takes
+// to arrive.
+type Supplier struct {
+	ID           uint           `gorm:"primaryKey" json:"id"`
+	Name         string         `gorm:"size:150;not null" json:"name"`
+	ContactName  string         `gorm:"size:100" json:"contact_name,omitempty"`
+	Phone        string         `gorm:"size:30" json:"phone,omitempty"`
+	Email        string         `gorm:"size:100" json:"email,omitempty"`
+	Address      string         `gorm:"size:255" json:"address,omitempty"`
+	LeadTimeDays int            `gorm:"not null;default:0" json:"lead_time_days"`
+	IsActive     bool           `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (Supplier) TableName() string {
+	return "suppliers"
+}
+
+// PurchaseOrder orders stock from a supplier. It moves from draft to sent,
+// then to partially_received and received as goods arrive, and is closed
+// once nothing more is expected. Drafts and sent orders can be cancelled.
+type PurchaseOrder struct {
+	ID            uint                `gorm:"primaryKey" json:"id"`
+	PONumber      string              `gorm:"size:50;uniqueIndex;not null" json:"po_number"`
+	SupplierID    uint                `gorm:"not null;index" json:"supplier_id"`
+	Supplier      Supplier            `gorm:"foreignKey:SupplierID" json:"supplier,omitempty"`
+	Status        string              `gorm:"size:20;not null;default:'draft';index" json:"status"` // draft, sent, partially_received, received, closed, cancelled
+	ExpectedAt    *time.Time          `json:"expected_at,omitempty"`
+	Notes         string              `gorm:"size:255" json:"notes,omitempty"`
+	Total         Money               `gorm:"not null;default:0" json:"total"`          // Ordered quantities at ordered cost
+	ReceivedTotal Money               `gorm:"not null;default:0" json:"received_total"` // Received quantities at received cost
+	CreatedBy     uint                `gorm:"not null" json:"created_by"`
+	Creator       User                `gorm:"foreignKey:CreatedBy" json:"creator,omitempty"`
+	SentAt        *time.Time          `json:"sent_at,omitempty"`
+	ClosedAt      *time.Time          `json:"closed_at,omitempty"`
+	Items         []PurchaseOrderItem `gorm:"foreignKey:PurchaseOrderID" json:"items,omitempty"`
+	Receipts      []GoodsReceipt      `gorm:"foreignKey:PurchaseOrderID" json:"receipts,omitempty"`
+	CreatedAt     time.Time           `json:"created_at"`
+	UpdatedAt     time.Time           `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt      `gorm:"index" json:"-"`
+}
+
+func (PurchaseOrder) TableName() string {
+	return "purchase_orders"
+}
+
+// PurchaseOrderItem orders a product, or a variant of it that keeps its own
+// stock. Products made to a recipe are not ordered; their ingredients are.
+type PurchaseOrderItem struct {
+	ID               uint   `gorm:"primaryKey" json:"id"`
+	PurchaseOrderID  uint   `gorm:"not null;index" json:"purchase_order_id"`
+	ProductID        uint   `gorm:"not null;index" json:"product_id"`
+	ProductName      string `gorm:"size:150;not null" json:"product_name"`
+	VariantID        *uint  `gorm:"index" json:"variant_id,omitempty"`
+	VariantName      string `gorm:"size:150" json:"variant_name,omitempty"`
+	Quantity         int    `gorm:"not null" json:"quantity"`
+	ReceivedQuantity int    `gorm:"not null;default:0" json:"received_quantity"`
+	RejectedQuantity int    `gorm:"not null;default:0" json:"rejected_quantity"`
+	UnitCost         Money  `gorm:"not null" json:"unit_cost"` // Agreed cost; the cost actually paid is on the receipt
+}
+
+func (PurchaseOrderItem) TableName() string {
+	return "purchase_order_items"
+}
+
+// GoodsReceipt is one delivery against a purchase order
+type GoodsReceipt struct {
+	ID              uint               `gorm:"primaryKey" json:"id"`
+	PurchaseOrderID uint               `gorm:"not null;index" json:"purchase_order_id"`
+	ReceivedBy      uint               `gorm:"not null" json:"received_by"`
+	Receiver        User               `gorm:"foreignKey:ReceivedBy" json:"receiver,omitempty"`
+	Notes           string             `gorm:"size:255" json:"notes,omitempty"`
+	Items           []GoodsReceiptItem `gorm:"foreignKey:GoodsReceiptID" json:"items,omitempty"`
+	CreatedAt       time.Time          `json:"created_at"`
+}
+
+func (GoodsReceipt) TableName() string {
+	return "goods_receipts"
+}
+
+// GoodsReceiptItem is what arrived for one purchase order line. Rejected
+// units (damaged, wrong item) are recorded but not put in stock.
+type GoodsReceiptItem struct {
+	ID                  uint   `gorm:"primaryKey" json:"id"`
+	GoodsReceiptID      uint   `gorm:"not null;index" json:"goods_receipt_id"`
+	PurchaseOrderItemID uint   `gorm:"not null;index" json:"purchase_order_item_id"`
+	ProductID           uint   `gorm:"not null" json:"product_id"`
+	VariantID           *uint  `json:"variant_id,omitempty"`
+	Quantity            int    `gorm:"not null" json:"quantity"`
+	RejectedQuantity    int    `gorm:"not null;default:0" json:"rejected_quantity"`
+	UnitCost            Money  `gorm:"not null" json:"unit_cost"`
+	Note                string `gorm:"size:255" json:"note,omitempty"`
+}
+
+func (GoodsReceiptItem) TableName() string {
+	return "goods_receipt_items"
+}
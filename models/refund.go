@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Refund returns part (or all) of a completed transaction. Amounts are the
+// prorated share of the transaction total, so tax and rounding are included.
+type Refund struct {
+	ID            uint           `gorm:"primaryKey" json:"id"`
+	RefundCode    string         `gorm:"size:50;uniqueIndex;not null" json:"refund_code"`
+	TransactionID uint           `gorm:"not null;index" json:"transaction_id"`
+	Transaction   Transaction    `gorm:"foreignKey:TransactionID" json:"-"`
+	ApprovedBy    uint           `gorm:"not null" json:"approved_by"`
+	Approver      User           `gorm:"foreignKey:ApprovedBy" json:"approver,omitempty"`
+	Reason        string         `gorm:"size:255;not null" json:"reason"`
+	RefundMethod  string         `gorm:"size:20;not null" json:"refund_method"` // cash, card, qris
+	Restock       bool           `gorm:"not null;default:false" json:"restock"`
+	Total         Money          `gorm:"not null" json:"total"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
+	Items         []RefundItem   `gorm:"foreignKey:RefundID" json:"items,omitempty"`
+}
+
+func (Refund) TableName() string {
+	return "refunds"
+}
+
+type RefundItem struct {
+	ID                uint      `gorm:"primaryKey" json:"id"`
+	RefundID          uint      `gorm:"not null;index" json:"refund_id"`
+	TransactionItemID uint      `gorm:"not null;index" json:"transaction_item_id"`
+	ProductID         uint      `gorm:"not null" json:"product_id"`
+	ProductName       string    `gorm:"size:150;not null" json:"product_name"`
+	Quantity          int       `gorm:"not null" json:"quantity"`
+	Amount            Money     `gorm:"not null" json:"amount"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+func (RefundItem) TableName() string {
+	return "refund_items"
+}
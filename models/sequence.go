@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Sequence is a gapless counter that restarts every business day, scoped by
+// name (e.g. "transaction") and outlet. It is only incremented inside the
+// database transaction that consumes the number, so a rollback never leaves gaps.
+type Sequence struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	Name         string    `gorm:"size:50;not null;uniqueIndex:idx_sequence_scope" json:"name"`
+	OutletCode   string    `gorm:"size:20;not null;uniqueIndex:idx_sequence_scope" json:"outlet_code"`
+	BusinessDate string    `gorm:"size:10;not null;uniqueIndex:idx_sequence_scope" json:"business_date"` // YYYY-MM-DD
+	LastNumber   int       `gorm:"not null;default:0" json:"last_number"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func (Sequence) TableName() string {
+	return "sequences"
+}
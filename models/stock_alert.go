@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// StockAlert is raised once when the stock of a product drops below its
+// minimum. It is written together with the stock movement that caused it and
+// announced to the subscribed screens after that change has committed.
+type StockAlert struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	ProductID      uint       `gorm:"not null;index" json:"product_id"`
+	ProductName    string     `gorm:"size:150;not null" json:"product_name"`
+	Stock          int        `gorm:"not null" json:"stock"`     // Stock left after the movement
+	MinStock       int        `gorm:"not null" json:"min_stock"` // Minimum in force at the time
+	ReorderQty     int        `gorm:"not null;default:0" json:"reorder_qty"`
+	Reason         string     `gorm:"size:20;not null" json:"reason"` // Reason of the stock movement, e.g. sale
+	ReferenceID    *uint      `json:"reference_id,omitempty"`
+	NotifiedAt     *time.Time `gorm:"index" json:"notified_at,omitempty"`
+	AcknowledgedBy *uint      `json:"acknowledged_by,omitempty"`
+	Acknowledger   *User      `gorm:"foreignKey:AcknowledgedBy" json:"acknowledger,omitempty"`
+	AcknowledgedAt *time.Time `gorm:"index" json:"acknowledged_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+func (StockAlert) TableName() string {
+	return "stock_alerts"
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// StockCount is a physical count (stock opname) of the products on the
+// shelf. Starting a count freezes the expected quantity of every product it
+// covers; counted quantities are entered while the count is open, and
+// posting it books the differences as "count" stock movements.
+type StockCount struct {
+	ID          uint             `gorm:"primaryKey" json:"id"`
+	CountNumber string           `gorm:"size:50;uniqueIndex;not null" json:"count_number"`
+	Status      string           `gorm:"size:20;not null;default:'counting';index" json:"status"` // counting, posted, cancelled
+	CategoryID  *uint            `json:"category_id,omitempty"`                                   // Only count this category; all products when nil
+	Notes       string           `gorm:"size:255" json:"notes,omitempty"`
+	StartedBy   uint             `gorm:"not null" json:"started_by"`
+	Starter     User             `gorm:"foreignKey:StartedBy" json:"starter,omitempty"`
+	PostedBy    *uint            `json:"posted_by,omitempty"`
+	Poster      *User            `gorm:"foreignKey:PostedBy" json:"poster,omitempty"`
+	PostedAt    *time.Time       `json:"posted_at,omitempty"`
+	Items       []StockCountItem `gorm:"foreignKey:StockCountID" json:"items,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+func (StockCount) TableName() string {
+	return "stock_counts"
+}
+
+// StockCountItem is one product, or one variant that keeps its own stock, in
+// a count. Expected and UnitCost are frozen when the count starts; Counted
+// stays nil until someone counts it.
+type StockCountItem struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	StockCountID uint       `gorm:"not null;index" json:"stock_count_id"`
+	ProductID    uint       `gorm:"not null;index" json:"product_id"`
+	ProductName  string     `gorm:"size:150;not null" json:"product_name"`
+	VariantID    *uint      `gorm:"index" json:"variant_id,omitempty"`
+	VariantName  string     `gorm:"size:150" json:"variant_name,omitempty"`
+	Expected     int        `gorm:"not null" json:"expected"`
+	Counted      *int       `json:"counted,omitempty"`
+	UnitCost     Money      `gorm:"not null;default:0" json:"unit_cost"`
+	Adjustment   int        `gorm:"not null;default:0" json:"adjustment"` // Quantity booked when the count was posted
+	CountedBy    *uint      `json:"counted_by,omitempty"`
+	Counter      *User      `gorm:"foreignKey:CountedBy" json:"counter,omitempty"`
+	CountedAt    *time.Time `json:"counted_at,omitempty"`
+	Note         string     `gorm:"size:255" json:"note,omitempty"`
+}
+
+func (StockCountItem) TableName() string {
+	return "stock_count_items"
+}
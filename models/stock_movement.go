@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// StockMovement is one change to the stock of a product, or of one of its
+// variants when VariantID is set. Movements are only ever appended, and the
+// stock of a product (or variant) is the balance left by its last movement.
+type StockMovement struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	ProductID   uint      `gorm:"not null;index:idx_stock_movement_product" json:"product_id"`
+	VariantID   *uint     `gorm:"index" json:"variant_id,omitempty"`
+	Quantity    int       `gorm:"not null" json:"quantity"`             // Negative when stock goes out
+	Balance     int       `gorm:"not null" json:"balance"`              // Stock after the movement
+	Reason      string    `gorm:"size:20;not null;index" json:"reason"` // opening, sale, cancel, refund, adjustment, receive, waste, count
+	ReferenceID *uint     `json:"reference_id,omitempty"`               // Transaction, refund, purchase order, ... depending on Reason
+	UserID      *uint     `json:"user_id,omitempty"`
+	User        *User     `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Note        string    `gorm:"size:255" json:"note,omitempty"`
+	CreatedAt   time.Time `gorm:"index:idx_stock_movement_product" json:"created_at"`
+}
+
+func (StockMovement) TableName() string {
+	return "stock_movements"
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Table is a dine-in table on the floor plan. Status follows its open tab:
+// free, occupied, or bill_requested.
+type Table struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	Name      string         `gorm:"size:50;uniqueIndex;not null" json:"name"`
+	Area      string         `gorm:"size:50" json:"area"`
+	Capacity  int            `gorm:"not null;default:0" json:"capacity"`
+	Status    string         `gorm:"size:20;not null;default:'free'" json:"status"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (Table) TableName() string {
+	return "tables"
+}
@@ -7,20 +7,37 @@ import (
 )
 
 type Transaction struct {
-	ID              uint              `gorm:"primaryKey" json:"id"`
-	TransactionCode string            `gorm:"size:50;uniqueIndex;not null" json:"transaction_code"`
-	UserID          uint              `gorm:"not null" json:"user_id"`
-	User            User              `gorm:"foreignKey:UserID" json:"user,omitempty"`
-	Subtotal        float64           `gorm:"not null" json:"subtotal"`
-	TaxRate         float64           `gorm:"not null;default:0.11" json:"tax_rate"`
-	Tax             float64           `gorm:"not null" json:"tax"`
-	Total           float64           `gorm:"not null" json:"total"`
-	PaymentMethod   string            `gorm:"size:20;not null" json:"payment_method"` // cash, card, qris
-	Status          string            `gorm:"size:20;default:'completed'" json:"status"`
-	CreatedAt       time.Time         `json:"created_at"`
-	UpdatedAt       time.Time         `json:"updated_at"`
-	DeletedAt       gorm.DeletedAt    `gorm:"index" json:"-"`
-	Items           []TransactionItem `gorm:"foreignKey:TransactionID" json:"items,omitempty"`
+	ID                uint                  `gorm:"primaryKey" json:"id"`
+	TransactionCode   string                `gorm:"size:50;uniqueIndex;not null" json:"transaction_code"`
+	UserID            uint                  `gorm:"not null" json:"user_id"`
+	User              User                  `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	TableID           *uint                 `gorm:"index" json:"table_id,omitempty"`                             // dine-in table of an open tab
+	ParentID          *uint                 `gorm:"index" json:"parent_id,omitempty"`                            // split bill: the order it was split from
+	OrderType         string                `gorm:"size:20;not null;default:'takeaway';index" json:"order_type"` // dine_in, takeaway, delivery
+	Subtotal          Money                 `gorm:"not null" json:"subtotal"`
+	Discount          Money                 `gorm:"not null;default:0" json:"discount"`            // order-level discounts, deducted from Subtotal before tax
+	ServiceChargeRate float64               `gorm:"not null;default:0" json:"service_charge_rate"` // fraction charged on dine-in orders, e.g. 0.05
+	ServiceCharge     Money                 `gorm:"not null;default:0" json:"service_charge"`      // charged on Subtotal - Discount, taxed with it
+	TaxRate           float64               `gorm:"not null;default:0" json:"tax_rate"`            // fraction applied at sale time, e.g. 0.11
+	TaxInclusive      bool                  `gorm:"not null;default:false" json:"tax_inclusive"`   // prices already contained the tax
+	Tax               Money                 `gorm:"not null" json:"tax"`
+	Rounding          Money                 `gorm:"not null;default:0" json:"rounding"` // cash rounding adjustment included in Total
+	Total             Money                 `gorm:"not null" json:"total"`
+	AmountTendered    Money                 `gorm:"not null;default:0" json:"amount_tendered"` // cash handed over by the customer
+	ChangeDue         Money                 `gorm:"not null;default:0" json:"change_due"`
+	RefundedTotal     Money                 `gorm:"not null;default:0" json:"refunded_total"`
+	PaymentMethod     string                `gorm:"size:20;not null" json:"payment_method"`                 // cash, card, qris, or split when several tenders were used
+	Status            string                `gorm:"size:20;default:'completed'" json:"status"`              // completed, cancelled, held, expired, open, split, unpaid
+	QueueNumber       int                   `gorm:"not null;default:0;index" json:"queue_number,omitempty"` // daily number customers are called by, counter orders only
+	FulfillmentStatus string                `gorm:"size:20" json:"fulfillment_status,omitempty"`            // preparing, ready, collected; follows the kitchen tickets
+	Note              string                `gorm:"size:255" json:"note,omitempty"`                         // e.g. customer name on a held order
+	CreatedAt         time.Time             `json:"created_at"`
+	UpdatedAt         time.Time             `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt        `gorm:"index" json:"-"`
+	Items             []TransactionItem     `gorm:"foreignKey:TransactionID" json:"items,omitempty"`
+	Payments          []TransactionPayment  `gorm:"foreignKey:TransactionID" json:"payments,omitempty"`
+	Discounts         []TransactionDiscount `gorm:"foreignKey:TransactionID" json:"discounts,omitempty"`
+	Children          []Transaction         `gorm:"foreignKey:ParentID" json:"children,omitempty"`
 }
 
 func (Transaction) TableName() string {
@@ -28,18 +45,56 @@ func (Transaction) TableName() string {
 }
 
 type TransactionItem struct {
-	ID            uint        `gorm:"primaryKey" json:"id"`
-	TransactionID uint        `gorm:"not null" json:"transaction_id"`
-	Transaction   Transaction `gorm:"foreignKey:TransactionID" json:"-"`
-	ProductID     uint        `gorm:"not null" json:"product_id"`
-	Product       Product     `gorm:"foreignKey:ProductID" json:"product,omitempty"`
-	ProductName   string      `gorm:"size:150;not null" json:"product_name"`
-	Price         float64     `gorm:"not null" json:"price"`
-	Quantity      int         `gorm:"not null" json:"quantity"`
-	Subtotal      float64     `gorm:"not null" json:"subtotal"`
-	CreatedAt     time.Time   `json:"created_at"`
+	ID            uint                      `gorm:"primaryKey" json:"id"`
+	TransactionID uint                      `gorm:"not null" json:"transaction_id"`
+	Transaction   Transaction               `gorm:"foreignKey:TransactionID" json:"-"`
+	ProductID     uint                      `gorm:"not null" json:"product_id"`
+	Product       Product                   `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+	ProductName   string                    `gorm:"size:150;not null" json:"product_name"`
+	VariantID     *uint                     `gorm:"index" json:"variant_id,omitempty"`
+	VariantName   string                    `gorm:"size:150" json:"variant_name,omitempty"` // e.g. "Large, Iced", kept for receipts and reports
+	Modifiers     []TransactionItemModifier `gorm:"foreignKey:TransactionItemID" json:"modifiers,omitempty"`
+	Price         Money                     `gorm:"not null" json:"price"` // unit price including modifiers
+	Quantity      int                       `gorm:"not null" json:"quantity"`
+	RefundedQty   int                       `gorm:"not null;default:0" json:"refunded_quantity"`
+	Round         int                       `gorm:"not null;default:0" json:"round"` // order round on a tab, 0 for direct sales
+	Discount      Money                     `gorm:"not null;default:0" json:"discount"`
+	Subtotal      Money                     `gorm:"not null" json:"subtotal"`        // Price * Quantity - Discount
+	FromRecipe    bool                      `gorm:"not null;default:false" json:"-"` // stock was taken from the recipe ingredients instead of the product
+	CreatedAt     time.Time                 `json:"created_at"`
 }
 
 func (TransactionItem) TableName() string {
 	return "transaction_items"
 }
+
+type TransactionPayment struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	TransactionID uint      `gorm:"not null;index" json:"transaction_id"`
+	Method        string    `gorm:"size:20;not null" json:"method"` // cash, card, qris
+	Amount        Money     `gorm:"not null" json:"amount"`
+	Reference     string    `gorm:"size:100" json:"reference,omitempty"` // card approval code, QRIS reference, ...
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func (TransactionPayment) TableName() string {
+	return "transaction_payments"
+}
+
+// TransactionDiscount records every discount applied at checkout. Line
+// discounts carry the TransactionItemID, order discounts leave it empty.
+type TransactionDiscount struct {
+	ID                uint      `gorm:"primaryKey" json:"id"`
+	TransactionID     uint      `gorm:"not null;index" json:"transaction_id"`
+	TransactionItemID *uint     `gorm:"index" json:"transaction_item_id,omitempty"`
+	PromotionID       *uint     `gorm:"index" json:"promotion_id,omitempty"`
+	VoucherID         *uint     `gorm:"index" json:"voucher_id,omitempty"`
+	Source            string    `gorm:"size:20;not null" json:"source"` // promotion, voucher, manual
+	Name              string    `gorm:"size:100;not null" json:"name"`
+	Amount            Money     `gorm:"not null" json:"amount"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+func (TransactionDiscount) TableName() string {
+	return "transaction_discounts"
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Voucher is a code the customer presents at checkout. UsageLimit caps the
+// redemptions over all customers (1 for single-use codes, 0 for unlimited) and
+// PerCustomerLimit the redemptions per CustomerRef. A voucher that is not
+// Stackable cannot be combined with automatic promotions.
+type Voucher struct {
+	ID               uint           `gorm:"primaryKey" json:"id"`
+	Code             string         `gorm:"size:50;uniqueIndex;not null" json:"code"`
+	Name             string         `gorm:"size:100" json:"name"`
+	BatchCode        string         `gorm:"size:50;index" json:"batch_code,omitempty"` // set for generated single-use batches
+	Type             string         `gorm:"size:20;not null" json:"type"`              // percentage, fixed
+	DiscountPercent  float64        `gorm:"not null;default:0" json:"discount_percent"`
+	DiscountAmount   Money          `gorm:"not null;default:0" json:"discount_amount"`
+	MaxDiscount      Money          `gorm:"not null;default:0" json:"max_discount"` // cap for percentage vouchers, 0 means no cap
+	MinSubtotal      Money          `gorm:"not null;default:0" json:"min_subtotal"`
+	StartsAt         *time.Time     `json:"starts_at,omitempty"`
+	EndsAt           *time.Time     `json:"ends_at,omitempty"`
+	UsageLimit       int            `gorm:"not null;default:0" json:"usage_limit"`
+	PerCustomerLimit int            `gorm:"not null;default:0" json:"per_customer_limit"`
+	UsedCount        int            `gorm:"not null;default:0" json:"used_count"`
+	Stackable        bool           `gorm:"not null;default:false" json:"stackable"`
+	IsActive         bool           `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (Voucher) TableName() string {
+	return "vouchers"
+}
+
+// VoucherRedemption records a voucher used on a transaction; it is removed
+// again when the transaction is cancelled.
+type VoucherRedemption struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	VoucherID     uint      `gorm:"not null;index" json:"voucher_id"`
+	TransactionID uint      `gorm:"not null;index" json:"transaction_id"`
+	CustomerRef   string    `gorm:"size:100;index" json:"customer_ref,omitempty"`
+	Amount        Money     `gorm:"not null" json:"amount"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func (VoucherRedemption) TableName() string {
+	return "voucher_redemptions"
+}
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// WasteEntry records stock thrown away (expired, spoiled, damaged, spilled)
+// and what it cost. It is for a product, optionally one of its variants, or
+// for an ingredient. Wasting a product made to a recipe uses up its
+// ingredients instead of product stock; the ingredients taken out of stock
+// are kept with the entry.
+type WasteEntry struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	ProductID    *uint     `gorm:"index" json:"product_id,omitempty"`
+	VariantID    *uint     `json:"variant_id,omitempty"`
+	IngredientID *uint     `gorm:"index" json:"ingredient_id,omitempty"`
+	ItemName     string    `gorm:"size:200;not null" json:"item_name"` // Product (and variant) or ingredient name at the time
+	Quantity     float64   `gorm:"type:decimal(14,3);not null" json:"quantity"`
+	Unit         string    `gorm:"size:20;not null" json:"unit"`              // pcs for products, the ingredient unit otherwise
+	Reason       string    `gorm:"size:20;not null;index" json:"reason"`      // expired, spoiled, damaged, spilled, other
+	Cost         Money     `gorm:"not null;default:0" json:"cost"`            // Value of the waste at cost
+	FromRecipe   bool      `gorm:"not null;default:false" json:"from_recipe"` // Ingredients were used up instead of product stock
+	Note         string    `gorm:"size:255" json:"note,omitempty"`
+	UserID       uint      `gorm:"not null" json:"user_id"`
+	User         User      `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	CreatedAt    time.Time `gorm:"index" json:"created_at"`
+
+	Ingredients []WasteEntryIngredient `gorm:"foreignKey:WasteEntryID" json:"ingredients,omitempty"`
+}
+
+func (WasteEntry) TableName() string {
+	return "waste_entries"
+}
+
+// WasteEntryIngredient is the ingredient quantity a waste entry took out of
+// stock: the ingredient itself, or each ingredient of a recipe product
+type WasteEntryIngredient struct {
+	ID             uint    `gorm:"primaryKey" json:"id"`
+	WasteEntryID   uint    `gorm:"not null;index" json:"waste_entry_id"`
+	IngredientID   uint    `gorm:"not null;index" json:"ingredient_id"`
+	IngredientName string  `gorm:"size:100;not null" json:"ingredient_name"` // Name at the time
+	Quantity       float64 `gorm:"type:decimal(14,3);not null" json:"quantity"`
+	Unit           string  `gorm:"size:20;not null" json:"unit"`
+	Cost           Money   `gorm:"not null;default:0" json:"cost"` // Value of the quantity at cost
+}
+
+func (WasteEntryIngredient) TableName() string {
+	return "waste_entry_ingredients"
+}
@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/syrlramadhan/cashier-app/models"
+	"gorm.io/gorm"
+)
+
+type IdempotencyKeyRepository interface {
+	FindByUserAndKey(userID uint, key string) (*models.IdempotencyKey, error)
+	Create(record *models.IdempotencyKey) error
+	Update(record *models.IdempotencyKey) error
+	Delete(id uint) error
+	DeleteExpired(now time.Time) error
+	WithTx(tx *gorm.DB) IdempotencyKeyRepository
+}
+
+type idempotencyKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyKeyRepository(db *gorm.DB) IdempotencyKeyRepository {
+	return &idempotencyKeyRepository{db: db}
+}
+
+func (r *idempotencyKeyRepository) WithTx(tx *gorm.DB) IdempotencyKeyRepository {
+	return &idempotencyKeyRepository{db: tx}
+}
+
+func (r *idempotencyKeyRepository) FindByUserAndKey(userID uint, key string) (*models.IdempotencyKey, error) {
+	var record models.IdempotencyKey
+	err := r.db.Where("user_id = ? AND `key` = ?", userID, key).First(&record).Error
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (r *idempotencyKeyRepository) Create(record *models.IdempotencyKey) error {
+	return r.db.Create(record).Error
+}
+
+func (r *idempotencyKeyRepository) Update(record *models.IdempotencyKey) error {
+	return r.db.Save(record).Error
+}
+
+func (r *idempotencyKeyRepository) Delete(id uint) error {
+	return r.db.Delete(&models.IdempotencyKey{}, id).Error
+}
+
+func (r *idempotencyKeyRepository) DeleteExpired(now time.Time) error {
+	return r.db.Where("expires_at < ?", now).Delete(&models.IdempotencyKey{}).Error
+}
@@ -0,0 +1,152 @@
+package repositories
+
+import (
+	"github.com/syrlramadhan/cashier-app/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IngredientRepository interface {
+	FindAll() ([]models.Ingredient, error)
+	FindByID(id uint) (*models.Ingredient, error)
+	FindByIDsForUpdate(ids []uint) ([]models.Ingredient, error)
+	Create(ingredient *models.Ingredient) error
+	Update(ingredient *models.Ingredient) error
+	UpdateStock(id uint, stock float64) error
+	Delete(id uint) error
+	CountRecipeItems(ingredientID uint) (int64, error)
+	FindRecipe(owner string, ownerID uint) ([]models.RecipeItem, error)
+	FindRecipes(productIDs, variantIDs, modifierOptionIDs []uint) ([]models.RecipeItem, error)
+	ReplaceRecipe(owner string, ownerID uint, items []models.RecipeItem) error
+	CreateUsage(usages []models.TransactionItemIngredient) error
+	FindUsageByItemIDs(itemIDs []uint) ([]models.TransactionItemIngredient, error)
+	WithTx(tx *gorm.DB) IngredientRepository
+}
+
+type ingredientRepository struct {
+	db *gorm.DB
+}
+
+func NewIngredientRepository(db *gorm.DB) IngredientRepository {
+	return &ingredientRepository{db: db}
+}
+
+func (r *ingredientRepository) WithTx(tx *gorm.DB) IngredientRepository {
+	return &ingredientRepository{db: tx}
+}
+
+func (r *ingredientRepository) FindAll() ([]models.Ingredient, error) {
+	var ingredients []models.Ingredient
+	err := r.db.Order("name ASC").Find(&ingredients).Error
+	return ingredients, err
+}
+
+func (r *ingredientRepository) FindByID(id uint) (*models.Ingredient, error) {
+	var ingredient models.Ingredient
+	err := r.db.First(&ingredient, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &ingredient, nil
+}
+
+// FindByIDsForUpdate locks the ingredient rows in ascending ID order. Checkouts
+// lock products, then variants, then ingredients.
+// It must be called on a repository bound to a transaction via WithTx.
+func (r *ingredientRepository) FindByIDsForUpdate(ids []uint) ([]models.Ingredient, error) {
+	var ingredients []models.Ingredient
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id IN ?", ids).Order("id ASC").Find(&ingredients).Error
+	return ingredients, err
+}
+
+func (r *ingredientRepository) Create(ingredient *models.Ingredient) error {
+	return r.db.Create(ingredient).Error
+}
+
+// Update saves everything but the stock, which only moves through UpdateStock
+func (r *ingredientRepository) Update(ingredient *models.Ingredient) error {
+	return r.db.Omit("stock").Save(ingredient).Error
+}
+
+func (r *ingredientRepository) UpdateStock(id uint, stock float64) error {
+	return r.db.Model(&models.Ingredient{}).Where("id = ?", id).Update("stock", stock).Error
+}
+
+func (r *ingredientRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Ingredient{}, id).Error
+}
+
+func (r *ingredientRepository) CountRecipeItems(ingredientID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.RecipeItem{}).Where("ingredient_id = ?", ingredientID).Count(&count).Error
+	return count, err
+}
+
+// recipeOwnerColumn maps a recipe owner ("product", "variant" or "modifier") to its column
+func recipeOwnerColumn(owner string) string {
+	switch owner {
+	case "variant":
+		return "variant_id"
+	case "modifier":
+		return "modifier_option_id"
+	default:
+		return "product_id"
+	}
+}
+
+// FindRecipe returns the recipe of one product, variant or modifier option
+func (r *ingredientRepository) FindRecipe(owner string, ownerID uint) ([]models.RecipeItem, error) {
+	var items []models.RecipeItem
+	err := r.db.Preload("Ingredient").Where(recipeOwnerColumn(owner)+" = ?", ownerID).Order("id ASC").Find(&items).Error
+	return items, err
+}
+
+// FindRecipes returns the recipes of the given products, variants and modifier options at once
+func (r *ingredientRepository) FindRecipes(productIDs, variantIDs, modifierOptionIDs []uint) ([]models.RecipeItem, error) {
+	var items []models.RecipeItem
+	if len(productIDs) == 0 && len(variantIDs) == 0 && len(modifierOptionIDs) == 0 {
+		return items, nil
+	}
+
+	query := r.db.Preload("Ingredient").Where("1 = 0")
+	if len(productIDs) > 0 {
+		query = query.Or("product_id IN ?", productIDs)
+	}
+	if len(variantIDs) > 0 {
+		query = query.Or("variant_id IN ?", variantIDs)
+	}
+	if len(modifierOptionIDs) > 0 {
+		query = query.Or("modifier_option_id IN ?", modifierOptionIDs)
+	}
+	err := query.Order("id ASC").Find(&items).Error
+	return items, err
+}
+
+// ReplaceRecipe swaps the recipe of a product, variant or modifier option for the given items
+func (r *ingredientRepository) ReplaceRecipe(owner string, ownerID uint, items []models.RecipeItem) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where(recipeOwnerColumn(owner)+" = ?", ownerID).Delete(&models.RecipeItem{}).Error; err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		return tx.Omit("Ingredient").Create(&items).Error
+	})
+}
+
+func (r *ingredientRepository) CreateUsage(usages []models.TransactionItemIngredient) error {
+	if len(usages) == 0 {
+		return nil
+	}
+	return r.db.Create(&usages).Error
+}
+
+func (r *ingredientRepository) FindUsageByItemIDs(itemIDs []uint) ([]models.TransactionItemIngredient, error) {
+	var usages []models.TransactionItemIngredient
+	if len(itemIDs) == 0 {
+		return usages, nil
+	}
+	err := r.db.Where("transaction_item_id IN ?", itemIDs).Find(&usages).Error
+	return usages, err
+}
@@ -0,0 +1,122 @@
+package repositories
+
+import (
+	"github.com/syrlramadhan/cashier-app/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// activeTicketStatuses are the tickets still shown on the kitchen displays
+var activeTicketStatuses = []string{"new", "in_progress", "ready"}
+
+type KitchenTicketRepository interface {
+	FindAll(station, status string) ([]models.KitchenTicket, error)
+	FindByID(id uint) (*models.KitchenTicket, error)
+	FindByIDForUpdate(id uint) (*models.KitchenTicket, error)
+	FindByTransactionID(transactionID uint) ([]models.KitchenTicket, error)
+	FindStations(productIDs []uint) (map[uint]string, error)
+	CreateBatch(tickets []models.KitchenTicket) error
+	Update(ticket *models.KitchenTicket) error
+	CancelByTransactionID(transactionID uint) error
+	MoveToTransaction(fromTransactionID, toTransactionID uint, transactionCode string, tableID *uint) error
+	WithTx(tx *gorm.DB) KitchenTicketRepository
+}
+
+type kitchenTicketRepository struct {
+	db *gorm.DB
+}
+
+func NewKitchenTicketRepository(db *gorm.DB) KitchenTicketRepository {
+	return &kitchenTicketRepository{db: db}
+}
+
+func (r *kitchenTicketRepository) WithTx(tx *gorm.DB) KitchenTicketRepository {
+	return &kitchenTicketRepository{db: tx}
+}
+
+// FindAll returns the tickets of a station (every station when empty), oldest
+// first. Without a status only the tickets still on the displays are returned.
+func (r *kitchenTicketRepository) FindAll(station, status string) ([]models.KitchenTicket, error) {
+	var tickets []models.KitchenTicket
+	query := r.db.Preload("Items")
+	if station != "" {
+		query = query.Where("station = ?", station)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	} else {
+		query = query.Where("status IN ?", activeTicketStatuses)
+	}
+	err := query.Order("created_at ASC, id ASC").Find(&tickets).Error
+	return tickets, err
+}
+
+func (r *kitchenTicketRepository) FindByID(id uint) (*models.KitchenTicket, error) {
+	var ticket models.KitchenTicket
+	err := r.db.Preload("Items").First(&ticket, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &ticket, nil
+}
+
+// FindByIDForUpdate locks the ticket row (SELECT ... FOR UPDATE).
+// It must be called on a repository bound to a transaction via WithTx.
+func (r *kitchenTicketRepository) FindByIDForUpdate(id uint) (*models.KitchenTicket, error) {
+	var ticket models.KitchenTicket
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Items").First(&ticket, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &ticket, nil
+}
+
+func (r *kitchenTicketRepository) FindByTransactionID(transactionID uint) ([]models.KitchenTicket, error) {
+	var tickets []models.KitchenTicket
+	err := r.db.Preload("Items").Where("transaction_id = ?", transactionID).Order("id ASC").Find(&tickets).Error
+	return tickets, err
+}
+
+// FindStations maps each product to the station of its category
+func (r *kitchenTicketRepository) FindStations(productIDs []uint) (map[uint]string, error) {
+	var rows []struct {
+		ProductID uint
+		Station   string
+	}
+	err := r.db.Model(&models.Product{}).Unscoped().
+		Select("products.id as product_id, categories.station").
+		Joins("JOIN categories ON categories.id = products.category_id").
+		Where("products.id IN ?", productIDs).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	stations := make(map[uint]string, len(rows))
+	for _, row := range rows {
+		stations[row.ProductID] = row.Station
+	}
+	return stations, nil
+}
+
+func (r *kitchenTicketRepository) CreateBatch(tickets []models.KitchenTicket) error {
+	return r.db.Create(&tickets).Error
+}
+
+func (r *kitchenTicketRepository) Update(ticket *models.KitchenTicket) error {
+	return r.db.Omit("Items").Save(ticket).Error
+}
+
+// CancelByTransactionID takes the unserved tickets of a voided order off the displays
+func (r *kitchenTicketRepository) CancelByTransactionID(transactionID uint) error {
+	return r.db.Model(&models.KitchenTicket{}).Where("transaction_id = ? AND status IN ?", transactionID, activeTicketStatuses).Update("status", "cancelled").Error
+}
+
+// MoveToTransaction hands the tickets of a merged tab over to the tab it was merged into
+func (r *kitchenTicketRepository) MoveToTransaction(fromTransactionID, toTransactionID uint, transactionCode string, tableID *uint) error {
+	return r.db.Model(&models.KitchenTicket{}).Where("transaction_id = ?", fromTransactionID).Updates(map[string]interface{}{
+		"transaction_id":   toTransactionID,
+		"transaction_code": transactionCode,
+		"table_id":         tableID,
+	}).Error
+}
@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"github.com/syrlramadhan/cashier-app/models"
+	"gorm.io/gorm"
+)
+
+type ModifierRepository interface {
+	FindAll() ([]models.ModifierGroup, error)
+	FindByID(id uint) (*models.ModifierGroup, error)
+	FindForProduct(productID, categoryID uint) ([]models.ModifierGroup, error)
+	Create(group *models.ModifierGroup) error
+	Update(group *models.ModifierGroup) error
+	Delete(id uint) error
+	CreateOption(option *models.ModifierOption) error
+	UpdateOption(option *models.ModifierOption) error
+	DeleteOption(id uint) error
+	WithTx(tx *gorm.DB) ModifierRepository
+}
+
+type modifierRepository struct {
+	db *gorm.DB
+}
+
+func NewModifierRepository(db *gorm.DB) ModifierRepository {
+	return &modifierRepository{db: db}
+}
+
+func (r *modifierRepository) WithTx(tx *gorm.DB) ModifierRepository {
+	return &modifierRepository{db: tx}
+}
+
+func (r *modifierRepository) FindAll() ([]models.ModifierGroup, error) {
+	var groups []models.ModifierGroup
+	err := r.db.Preload("Options", orderByPosition).Preload("Products").Preload("Categories").Order("name ASC").Find(&groups).Error
+	return groups, err
+}
+
+func (r *modifierRepository) FindByID(id uint) (*models.ModifierGroup, error) {
+	var group models.ModifierGroup
+	err := r.db.Preload("Options", orderByPosition).Preload("Products").Preload("Categories").First(&group, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// FindForProduct returns the modifier groups attached to the product or to its category
+func (r *modifierRepository) FindForProduct(productID, categoryID uint) ([]models.ModifierGroup, error) {
+	var groups []models.ModifierGroup
+	err := r.db.Preload("Options", orderByPosition).
+		Where("id IN (?) OR id IN (?)",
+			r.db.Table("product_modifier_groups").Select("modifier_group_id").Where("product_id = ?", productID),
+			r.db.Table("category_modifier_groups").Select("modifier_group_id").Where("category_id = ?", categoryID),
+		).
+		Order("id ASC").
+		Find(&groups).Error
+	return groups, err
+}
+
+// Create saves the group with its options and links it to its products and categories
+func (r *modifierRepository) Create(group *models.ModifierGroup) error {
+	return r.db.Omit("Products.*", "Categories.*").Create(group).Error
+}
+
+// Update saves the group and replaces its products and categories; options have their own methods
+func (r *modifierRepository) Update(group *models.ModifierGroup) error {
+	if err := r.db.Omit("Options", "Products", "Categories").Save(group).Error; err != nil {
+		return err
+	}
+	if err := r.db.Model(group).Omit("Products.*").Association("Products").Replace(group.Products); err != nil {
+		return err
+	}
+	return r.db.Model(group).Omit("Categories.*").Association("Categories").Replace(group.Categories)
+}
+
+func (r *modifierRepository) Delete(id uint) error {
+	return r.db.Delete(&models.ModifierGroup{}, id).Error
+}
+
+func (r *modifierRepository) CreateOption(option *models.ModifierOption) error {
+	return r.db.Create(option).Error
+}
+
+func (r *modifierRepository) UpdateOption(option *models.ModifierOption) error {
+	return r.db.Save(option).Error
+}
+
+func (r *modifierRepository) DeleteOption(id uint) error {
+	return r.db.Delete(&models.ModifierOption{}, id).Error
+}
@@ -3,6 +3,7 @@ package repositories
 import (
 	"github.com/syrlramadhan/cashier-app/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ProductRepository interface {
@@ -10,14 +11,19 @@ type ProductRepository interface {
 	FindAllWithCategory() ([]models.Product, error)
 	FindByID(id uint) (*models.Product, error)
 	FindByIDWithCategory(id uint) (*models.Product, error)
+	FindByIDsForUpdate(ids []uint) ([]models.Product, error)
 	FindByCategoryID(categoryID uint) ([]models.Product, error)
-	FindLowStock(threshold int) ([]models.Product, error)
+	FindLowStock(defaultMin int) ([]models.Product, error)
+	FindStocked() ([]models.Product, error)
 	Create(product *models.Product) error
 	Update(product *models.Product) error
 	UpdateStock(id uint, stock int) error
+	UpdateCost(id uint, cost models.Money) error
+	ReplacePrices(productID uint, prices []models.ProductPrice) error
 	Delete(id uint) error
 	Count() (int64, error)
 	Search(keyword string) ([]models.Product, error)
+	WithTx(tx *gorm.DB) ProductRepository
 }
 
 type productRepository struct {
@@ -28,21 +34,33 @@ func NewProductRepository(db *gorm.DB) ProductRepository {
 	return &productRepository{db: db}
 }
 
+func (r *productRepository) WithTx(tx *gorm.DB) ProductRepository {
+	return &productRepository{db: tx}
+}
+
 func (r *productRepository) FindAll() ([]models.Product, error) {
 	var products []models.Product
-	err := r.db.Find(&products).Error
+	err := r.db.Preload("Prices").Find(&products).Error
 	return products, err
 }
 
 func (r *productRepository) FindAllWithCategory() ([]models.Product, error) {
 	var products []models.Product
-	err := r.db.Preload("Category").Find(&products).Error
+	err := r.db.Preload("Category").Preload("Prices").Find(&products).Error
 	return products, err
 }
 
+// preloadVariants loads the variant groups and variants of a product with their options
+func preloadVariants(db *gorm.DB) *gorm.DB {
+	return db.Preload("VariantGroups", orderByPosition).
+		Preload("VariantGroups.Options", orderByPosition).
+		Preload("Variants", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Variants.Options")
+}
+
 func (r *productRepository) FindByID(id uint) (*models.Product, error) {
 	var product models.Product
-	err := r.db.First(&product, id).Error
+	err := r.db.Preload("Prices").Scopes(preloadVariants).First(&product, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -51,37 +69,88 @@ func (r *productRepository) FindByID(id uint) (*models.Product, error) {
 
 func (r *productRepository) FindByIDWithCategory(id uint) (*models.Product, error) {
 	var product models.Product
-	err := r.db.Preload("Category").First(&product, id).Error
+	err := r.db.Preload("Category").Preload("Prices").Scopes(preloadVariants).First(&product, id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &product, nil
 }
 
+// FindByIDsForUpdate locks the given product rows (SELECT ... FOR UPDATE) in
+// ascending ID order so concurrent checkouts cannot deadlock each other. The
+// variants are loaded without a lock; lock them with the variant repository.
+// It must be called on a repository bound to a transaction via WithTx.
+func (r *productRepository) FindByIDsForUpdate(ids []uint) ([]models.Product, error) {
+	var products []models.Product
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Prices").Preload("Variants").Where("id IN ?", ids).Order("id ASC").Find(&products).Error
+	return products, err
+}
+
 func (r *productRepository) FindByCategoryID(categoryID uint) ([]models.Product, error) {
 	var products []models.Product
-	err := r.db.Where("category_id = ?", categoryID).Find(&products).Error
+	err := r.db.Preload("Prices").Where("category_id = ?", categoryID).Find(&products).Error
+	return products, err
+}
+
+// FindLowStock lists the products whose stock is below their minimum, or
+// below defaultMin when they have none, lowest stock first. Products made to
+// a recipe do not use their own stock and are left out.
+func (r *productRepository) FindLowStock(defaultMin int) ([]models.Product, error) {
+	var products []models.Product
+	err := r.db.Preload("Category").Scopes(r.withoutRecipe).
+		Where("stock < COALESCE(min_stock, ?)", defaultMin).
+		Order("stock ASC").Find(&products).Error
 	return products, err
 }
 
-func (r *productRepository) FindLowStock(threshold int) ([]models.Product, error) {
+// FindStocked lists the products that keep their own stock, i.e. are not
+// made to a recipe, by name
+func (r *productRepository) FindStocked() ([]models.Product, error) {
 	var products []models.Product
-	err := r.db.Preload("Category").Where("stock <= ?", threshold).Order("stock ASC").Find(&products).Error
+	err := r.db.Scopes(r.withoutRecipe).Order("name ASC").Find(&products).Error
 	return products, err
 }
 
+// withoutRecipe leaves out the products made to a recipe
+func (r *productRepository) withoutRecipe(db *gorm.DB) *gorm.DB {
+	return db.Where("id NOT IN (?)", r.db.Model(&models.RecipeItem{}).Select("product_id").Where("product_id IS NOT NULL"))
+}
+
 func (r *productRepository) Create(product *models.Product) error {
 	return r.db.Create(product).Error
 }
 
+// Update saves the product row only: prices and variants have their own
+// methods, and stock only changes through UpdateStock together with a stock
+// movement
 func (r *productRepository) Update(product *models.Product) error {
-	return r.db.Save(product).Error
+	return r.db.Omit(clause.Associations, "Stock").Save(product).Error
+}
+
+func (r *productRepository) UpdateCost(id uint, cost models.Money) error {
+	return r.db.Model(&models.Product{}).Where("id = ?", id).Update("cost", cost).Error
 }
 
 func (r *productRepository) UpdateStock(id uint, stock int) error {
 	return r.db.Model(&models.Product{}).Where("id = ?", id).Update("stock", stock).Error
 }
 
+// ReplacePrices swaps the order type prices of a product for the given set
+func (r *productRepository) ReplacePrices(productID uint, prices []models.ProductPrice) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("product_id = ?", productID).Delete(&models.ProductPrice{}).Error; err != nil {
+			return err
+		}
+		if len(prices) == 0 {
+			return nil
+		}
+		for i := range prices {
+			prices[i].ProductID = productID
+		}
+		return tx.Create(&prices).Error
+	})
+}
+
 func (r *productRepository) Delete(id uint) error {
 	return r.db.Delete(&models.Product{}, id).Error
 }
@@ -94,6 +163,6 @@ func (r *productRepository) Count() (int64, error) {
 
 func (r *productRepository) Search(keyword string) ([]models.Product, error) {
 	var products []models.Product
-	err := r.db.Preload("Category").Where("name ILIKE ?", "%"+keyword+"%").Find(&products).Error
+	err := r.db.Preload("Category").Preload("Prices").Where("name ILIKE ?", "%"+keyword+"%").Find(&products).Error
 	return products, err
 }
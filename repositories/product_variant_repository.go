@@ -0,0 +1,152 @@
+package repositories
+
+import (
+	"github.com/syrlramadhan/cashier-app/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ProductVariantRepository interface {
+	FindGroupsByProductID(productID uint) ([]models.ProductVariantGroup, error)
+	FindGroupByID(id uint) (*models.ProductVariantGroup, error)
+	CreateGroup(group *models.ProductVariantGroup) error
+	UpdateGroup(group *models.ProductVariantGroup) error
+	DeleteGroup(id uint) error
+	CreateOption(option *models.ProductVariantOption) error
+	UpdateOption(option *models.ProductVariantOption) error
+	DeleteOption(id uint) error
+	CountByOptionIDs(optionIDs []uint) (int64, error)
+	FindByProductID(productID uint) ([]models.ProductVariant, error)
+	FindByID(id uint) (*models.ProductVariant, error)
+	FindByIDsForUpdate(ids []uint) ([]models.ProductVariant, error)
+	FindTrackedByProductIDs(productIDs []uint) ([]models.ProductVariant, error)
+	Create(variant *models.ProductVariant) error
+	Update(variant *models.ProductVariant) error
+	UpdateStock(id uint, stock int) error
+	Delete(id uint) error
+	WithTx(tx *gorm.DB) ProductVariantRepository
+}
+
+type productVariantRepository struct {
+	db *gorm.DB
+}
+
+func NewProductVariantRepository(db *gorm.DB) ProductVariantRepository {
+	return &productVariantRepository{db: db}
+}
+
+func (r *productVariantRepository) WithTx(tx *gorm.DB) ProductVariantRepository {
+	return &productVariantRepository{db: tx}
+}
+
+func orderByPosition(db *gorm.DB) *gorm.DB {
+	return db.Order("position ASC, id ASC")
+}
+
+func (r *productVariantRepository) FindGroupsByProductID(productID uint) ([]models.ProductVariantGroup, error) {
+	var groups []models.ProductVariantGroup
+	err := r.db.Preload("Options", orderByPosition).Where("product_id = ?", productID).Scopes(orderByPosition).Find(&groups).Error
+	return groups, err
+}
+
+func (r *productVariantRepository) FindGroupByID(id uint) (*models.ProductVariantGroup, error) {
+	var group models.ProductVariantGroup
+	err := r.db.Preload("Options", orderByPosition).First(&group, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+func (r *productVariantRepository) CreateGroup(group *models.ProductVariantGroup) error {
+	return r.db.Create(group).Error
+}
+
+func (r *productVariantRepository) UpdateGroup(group *models.ProductVariantGroup) error {
+	return r.db.Omit("Options").Save(group).Error
+}
+
+func (r *productVariantRepository) DeleteGroup(id uint) error {
+	if err := r.db.Where("group_id = ?", id).Delete(&models.ProductVariantOption{}).Error; err != nil {
+		return err
+	}
+	return r.db.Delete(&models.ProductVariantGroup{}, id).Error
+}
+
+func (r *productVariantRepository) CreateOption(option *models.ProductVariantOption) error {
+	return r.db.Create(option).Error
+}
+
+func (r *productVariantRepository) UpdateOption(option *models.ProductVariantOption) error {
+	return r.db.Save(option).Error
+}
+
+func (r *productVariantRepository) DeleteOption(id uint) error {
+	return r.db.Delete(&models.ProductVariantOption{}, id).Error
+}
+
+// CountByOptionIDs counts the variants (not deleted) that use any of the options
+func (r *productVariantRepository) CountByOptionIDs(optionIDs []uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.ProductVariant{}).
+		Joins("JOIN product_variant_option_values ON product_variant_option_values.product_variant_id = product_variants.id").
+		Where("product_variant_option_values.product_variant_option_id IN ?", optionIDs).
+		Distinct("product_variants.id").
+		Count(&count).Error
+	return count, err
+}
+
+func (r *productVariantRepository) FindByProductID(productID uint) ([]models.ProductVariant, error) {
+	var variants []models.ProductVariant
+	err := r.db.Preload("Options").Where("product_id = ?", productID).Order("id ASC").Find(&variants).Error
+	return variants, err
+}
+
+func (r *productVariantRepository) FindByID(id uint) (*models.ProductVariant, error) {
+	var variant models.ProductVariant
+	err := r.db.Preload("Options").First(&variant, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &variant, nil
+}
+
+// FindByIDsForUpdate locks the variant rows in ascending ID order. Checkouts
+// lock the products first and their variants second.
+// It must be called on a repository bound to a transaction via WithTx.
+func (r *productVariantRepository) FindByIDsForUpdate(ids []uint) ([]models.ProductVariant, error) {
+	var variants []models.ProductVariant
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id IN ?", ids).Order("id ASC").Find(&variants).Error
+	return variants, err
+}
+
+// FindTrackedByProductIDs returns the variants of the products that keep their own stock
+func (r *productVariantRepository) FindTrackedByProductIDs(productIDs []uint) ([]models.ProductVariant, error) {
+	var variants []models.ProductVariant
+	if len(productIDs) == 0 {
+		return variants, nil
+	}
+	err := r.db.Where("product_id IN ? AND track_stock = ?", productIDs, true).Order("id ASC").Find(&variants).Error
+	return variants, err
+}
+
+func (r *productVariantRepository) Create(variant *models.ProductVariant) error {
+	return r.db.Omit("Options.*").Create(variant).Error
+}
+
+// Update saves the variant and replaces its options. Stock only changes
+// through UpdateStock together with a stock movement.
+func (r *productVariantRepository) Update(variant *models.ProductVariant) error {
+	if err := r.db.Omit("Options", "Stock").Save(variant).Error; err != nil {
+		return err
+	}
+	return r.db.Model(variant).Omit("Options.*").Association("Options").Replace(variant.Options)
+}
+
+func (r *productVariantRepository) UpdateStock(id uint, stock int) error {
+	return r.db.Model(&models.ProductVariant{}).Where("id = ?", id).Update("stock", stock).Error
+}
+
+func (r *productVariantRepository) Delete(id uint) error {
+	return r.db.Delete(&models.ProductVariant{}, id).Error
+}
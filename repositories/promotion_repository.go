@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/syrlramadhan/cashier-app/models"
+	"gorm.io/gorm"
+)
+
+type PromotionRepository interface {
+	FindAll() ([]models.Promotion, error)
+	FindByID(id uint) (*models.Promotion, error)
+	FindActive(now time.Time) ([]models.Promotion, error)
+	Create(promotion *models.Promotion) error
+	Update(promotion *models.Promotion) error
+	Delete(id uint) error
+	WithTx(tx *gorm.DB) PromotionRepository
+}
+
+type promotionRepository struct {
+	db *gorm.DB
+}
+
+func NewPromotionRepository(db *gorm.DB) PromotionRepository {
+	return &promotionRepository{db: db}
+}
+
+func (r *promotionRepository) WithTx(tx *gorm.DB) PromotionRepository {
+	return &promotionRepository{db: tx}
+}
+
+func (r *promotionRepository) FindAll() ([]models.Promotion, error) {
+	var promotions []models.Promotion
+	err := r.db.Order("created_at DESC").Find(&promotions).Error
+	return promotions, err
+}
+
+func (r *promotionRepository) FindByID(id uint) (*models.Promotion, error) {
+	var promotion models.Promotion
+	err := r.db.First(&promotion, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+// FindActive returns enabled promotions within their validity period; daily
+// time windows are checked by the caller.
+func (r *promotionRepository) FindActive(now time.Time) ([]models.Promotion, error) {
+	var promotions []models.Promotion
+	err := r.db.Where("is_active = ?", true).
+		Where("starts_at IS NULL OR starts_at <= ?", now).
+		Where("ends_at IS NULL OR ends_at >= ?", now).
+		Order("id ASC").
+		Find(&promotions).Error
+	return promotions, err
+}
+
+func (r *promotionRepository) Create(promotion *models.Promotion) error {
+	return r.db.Create(promotion).Error
+}
+
+func (r *promotionRepository) Update(promotion *models.Promotion) error {
+	return r.db.Save(promotion).Error
+}
+
+func (r *promotionRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Promotion{}, id).Error
+}
@@ -0,0 +1,126 @@
+package repositories
+
+import (
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// openPurchaseOrderStatuses are purchase orders that still expect goods
+var openPurchaseOrderStatuses = []string{"draft", "sent", "partially_received"}
+
+type PurchaseOrderRepository interface {
+	FindAll(status string, supplierID uint) ([]models.PurchaseOrder, error)
+	FindByID(id uint) (*models.PurchaseOrder, error)
+	FindByIDForUpdate(id uint) (*models.PurchaseOrder, error)
+	CountOpenBySupplierID(supplierID uint) (int64, error)
+	GetOnOrderQuantities() ([]dto.ProductQuantityData, error)
+	Create(order *models.PurchaseOrder) error
+	Update(order *models.PurchaseOrder) error
+	ReplaceItems(orderID uint, items []models.PurchaseOrderItem) error
+	UpdateItem(item *models.PurchaseOrderItem) error
+	CreateReceipt(receipt *models.GoodsReceipt) error
+	WithTx(tx *gorm.DB) PurchaseOrderRepository
+}
+
+type purchaseOrderRepository struct {
+	db *gorm.DB
+}
+
+func NewPurchaseOrderRepository(db *gorm.DB) PurchaseOrderRepository {
+	return &purchaseOrderRepository{db: db}
+}
+
+func (r *purchaseOrderRepository) WithTx(tx *gorm.DB) PurchaseOrderRepository {
+	return &purchaseOrderRepository{db: tx}
+}
+
+// FindAll lists purchase orders newest first, optionally by status and supplier
+func (r *purchaseOrderRepository) FindAll(status string, supplierID uint) ([]models.PurchaseOrder, error) {
+	var orders []models.PurchaseOrder
+	query := r.db.Preload("Supplier").Preload("Creator").Preload("Items")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if supplierID != 0 {
+		query = query.Where("supplier_id = ?", supplierID)
+	}
+	err := query.Order("created_at DESC").Find(&orders).Error
+	return orders, err
+}
+
+func (r *purchaseOrderRepository) FindByID(id uint) (*models.PurchaseOrder, error) {
+	var order models.PurchaseOrder
+	err := r.db.Preload("Supplier").Preload("Creator").Preload("Items").
+		Preload("Receipts", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		Preload("Receipts.Receiver").Preload("Receipts.Items").
+		First(&order, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// FindByIDForUpdate locks the purchase order row together with its items.
+// It must be called on a repository bound to a transaction via WithTx.
+func (r *purchaseOrderRepository) FindByIDForUpdate(id uint) (*models.PurchaseOrder, error) {
+	var order models.PurchaseOrder
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Items").First(&order, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (r *purchaseOrderRepository) CountOpenBySupplierID(supplierID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.PurchaseOrder{}).Where("supplier_id = ? AND status IN ?", supplierID, openPurchaseOrderStatuses).Count(&count).Error
+	return count, err
+}
+
+// GetOnOrderQuantities sums the units of each product ordered on open purchase
+// orders (drafts included) and not received yet. Lines for a variant that
+// keeps its own stock are left out, since they do not go into product stock.
+func (r *purchaseOrderRepository) GetOnOrderQuantities() ([]dto.ProductQuantityData, error) {
+	var results []dto.ProductQuantityData
+	err := r.db.Model(&models.PurchaseOrderItem{}).
+		Select("purchase_order_items.product_id, SUM(purchase_order_items.quantity - purchase_order_items.received_quantity) as total_quantity").
+		Joins("JOIN purchase_orders ON purchase_orders.id = purchase_order_items.purchase_order_id AND purchase_orders.deleted_at IS NULL").
+		Where("purchase_orders.status IN ?", openPurchaseOrderStatuses).
+		Where("purchase_order_items.quantity > purchase_order_items.received_quantity").
+		Where("purchase_order_items.variant_id IS NULL").
+		Group("purchase_order_items.product_id").
+		Scan(&results).Error
+	return results, err
+}
+
+func (r *purchaseOrderRepository) Create(order *models.PurchaseOrder) error {
+	return r.db.Omit("Supplier", "Creator").Create(order).Error
+}
+
+func (r *purchaseOrderRepository) Update(order *models.PurchaseOrder) error {
+	return r.db.Omit(clause.Associations).Save(order).Error
+}
+
+// ReplaceItems swaps the lines of a draft purchase order for the given set
+func (r *purchaseOrderRepository) ReplaceItems(orderID uint, items []models.PurchaseOrderItem) error {
+	if err := r.db.Where("purchase_order_id = ?", orderID).Delete(&models.PurchaseOrderItem{}).Error; err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return nil
+	}
+	for i := range items {
+		items[i].PurchaseOrderID = orderID
+	}
+	return r.db.Create(&items).Error
+}
+
+func (r *purchaseOrderRepository) UpdateItem(item *models.PurchaseOrderItem) error {
+	return r.db.Save(item).Error
+}
+
+func (r *purchaseOrderRepository) CreateReceipt(receipt *models.GoodsReceipt) error {
+	return r.db.Create(receipt).Error
+}
@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/syrlramadhan/cashier-app/models"
+	"gorm.io/gorm"
+)
+
+type RefundRepository interface {
+	FindByID(id uint) (*models.Refund, error)
+	FindByTransactionID(transactionID uint) ([]models.Refund, error)
+	Create(refund *models.Refund) error
+	GetTotalRefunded(startDate, endDate time.Time) (models.Money, error)
+	WithTx(tx *gorm.DB) RefundRepository
+}
+
+type refundRepository struct {
+	db *gorm.DB
+}
+
+func NewRefundRepository(db *gorm.DB) RefundRepository {
+	return &refundRepository{db: db}
+}
+
+func (r *refundRepository) WithTx(tx *gorm.DB) RefundRepository {
+	return &refundRepository{db: tx}
+}
+
+func (r *refundRepository) FindByID(id uint) (*models.Refund, error) {
+	var refund models.Refund
+	err := r.db.Preload("Approver").Preload("Items").First(&refund, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &refund, nil
+}
+
+func (r *refundRepository) FindByTransactionID(transactionID uint) ([]models.Refund, error) {
+	var refunds []models.Refund
+	err := r.db.Preload("Approver").Preload("Items").Where("transaction_id = ?", transactionID).Order("created_at ASC").Find(&refunds).Error
+	return refunds, err
+}
+
+func (r *refundRepository) Create(refund *models.Refund) error {
+	return r.db.Create(refund).Error
+}
+
+func (r *refundRepository) GetTotalRefunded(startDate, endDate time.Time) (models.Money, error) {
+	var total models.Money
+	err := r.db.Model(&models.Refund{}).Where("created_at BETWEEN ? AND ?", startDate, endDate).Select("COALESCE(SUM(total), 0)").Scan(&total).Error
+	return total, err
+}
@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"github.com/syrlramadhan/cashier-app/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SequenceRepository interface {
+	Next(name, outletCode, businessDate string) (int, error)
+	WithTx(tx *gorm.DB) SequenceRepository
+}
+
+type sequenceRepository struct {
+	db *gorm.DB
+}
+
+func NewSequenceRepository(db *gorm.DB) SequenceRepository {
+	return &sequenceRepository{db: db}
+}
+
+func (r *sequenceRepository) WithTx(tx *gorm.DB) SequenceRepository {
+	return &sequenceRepository{db: tx}
+}
+
+// Next increments the counter and returns the new value. The upsert keeps the
+// row locked until the surrounding transaction ends, so callers must use a
+// repository bound to a transaction via WithTx.
+func (r *sequenceRepository) Next(name, outletCode, businessDate string) (int, error) {
+	sequence := models.Sequence{
+		Name:         name,
+		OutletCode:   outletCode,
+		BusinessDate: businessDate,
+		LastNumber:   1,
+	}
+
+	err := r.db.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]interface{}{"last_number": gorm.Expr("last_number + 1")}),
+	}).Create(&sequence).Error
+	if err != nil {
+		return 0, err
+	}
+
+	err = r.db.Where("name = ? AND outlet_code = ? AND business_date = ?", name, outletCode, businessDate).First(&sequence).Error
+	if err != nil {
+		return 0, err
+	}
+	return sequence.LastNumber, nil
+}
@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/syrlramadhan/cashier-app/models"
+	"gorm.io/gorm"
+)
+
+type StockAlertRepository interface {
+	FindAll(status string) ([]models.StockAlert, error)
+	FindByID(id uint) (*models.StockAlert, error)
+	FindUnnotified() ([]models.StockAlert, error)
+	Create(alerts []models.StockAlert) error
+	MarkNotified(id uint, at time.Time) (bool, error)
+	Acknowledge(id, userID uint, at time.Time) (bool, error)
+	WithTx(tx *gorm.DB) StockAlertRepository
+}
+
+type stockAlertRepository struct {
+	db *gorm.DB
+}
+
+func NewStockAlertRepository(db *gorm.DB) StockAlertRepository {
+	return &stockAlertRepository{db: db}
+}
+
+func (r *stockAlertRepository) WithTx(tx *gorm.DB) StockAlertRepository {
+	return &stockAlertRepository{db: tx}
+}
+
+// FindAll lists alerts newest first: "open" (not acknowledged, the default),
+// "acknowledged" or "all"
+func (r *stockAlertRepository) FindAll(status string) ([]models.StockAlert, error) {
+	var alerts []models.StockAlert
+	query := r.db.Preload("Acknowledger")
+	switch status {
+	case "all":
+	case "acknowledged":
+		query = query.Where("acknowledged_at IS NOT NULL")
+	default:
+		query = query.Where("acknowledged_at IS NULL")
+	}
+	err := query.Order("created_at DESC").Find(&alerts).Error
+	return alerts, err
+}
+
+func (r *stockAlertRepository) FindByID(id uint) (*models.StockAlert, error) {
+	var alert models.StockAlert
+	err := r.db.Preload("Acknowledger").First(&alert, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &alert, nil
+}
+
+// FindUnnotified lists the alerts not announced yet, oldest first
+func (r *stockAlertRepository) FindUnnotified() ([]models.StockAlert, error) {
+	var alerts []models.StockAlert
+	err := r.db.Where("notified_at IS NULL").Order("id ASC").Find(&alerts).Error
+	return alerts, err
+}
+
+func (r *stockAlertRepository) Create(alerts []models.StockAlert) error {
+	if len(alerts) == 0 {
+		return nil
+	}
+	return r.db.Create(&alerts).Error
+}
+
+// MarkNotified claims an alert for announcing. It reports false when the
+// alert was already claimed, so every alert is announced only once.
+func (r *stockAlertRepository) MarkNotified(id uint, at time.Time) (bool, error) {
+	result := r.db.Model(&models.StockAlert{}).Where("id = ? AND notified_at IS NULL", id).Update("notified_at", at)
+	return result.RowsAffected == 1, result.Error
+}
+
+// Acknowledge marks an open alert as seen. It reports false when the alert
+// was already acknowledged.
+func (r *stockAlertRepository) Acknowledge(id, userID uint, at time.Time) (bool, error) {
+	result := r.db.Model(&models.StockAlert{}).Where("id = ? AND acknowledged_at IS NULL", id).
+		Updates(map[string]interface{}{"acknowledged_by": userID, "acknowledged_at": at})
+	return result.RowsAffected == 1, result.Error
+}
@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"github.com/syrlramadhan/cashier-app/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StockCountRepository interface {
+	FindAll(status string) ([]models.StockCount, error)
+	FindByID(id uint) (*models.StockCount, error)
+	FindByIDForUpdate(id uint) (*models.StockCount, error)
+	CountByStatus(status string) (int64, error)
+	Create(count *models.StockCount) error
+	Update(count *models.StockCount) error
+	UpdateItems(items []models.StockCountItem) error
+	WithTx(tx *gorm.DB) StockCountRepository
+}
+
+type stockCountRepository struct {
+	db *gorm.DB
+}
+
+func NewStockCountRepository(db *gorm.DB) StockCountRepository {
+	return &stockCountRepository{db: db}
+}
+
+func (r *stockCountRepository) WithTx(tx *gorm.DB) StockCountRepository {
+	return &stockCountRepository{db: tx}
+}
+
+// FindAll lists stock counts newest first, optionally by status
+func (r *stockCountRepository) FindAll(status string) ([]models.StockCount, error) {
+	var counts []models.StockCount
+	query := r.db.Preload("Starter").Preload("Poster").Preload("Items")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("created_at DESC").Find(&counts).Error
+	return counts, err
+}
+
+func (r *stockCountRepository) FindByID(id uint) (*models.StockCount, error) {
+	var count models.StockCount
+	err := r.db.Preload("Starter").Preload("Poster").
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("product_name ASC, variant_name ASC") }).
+		Preload("Items.Counter").
+		First(&count, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &count, nil
+}
+
+// FindByIDForUpdate locks the stock count row together with its items.
+// It must be called on a repository bound to a transaction via WithTx.
+func (r *stockCountRepository) FindByIDForUpdate(id uint) (*models.StockCount, error) {
+	var count models.StockCount
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Items").First(&count, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &count, nil
+}
+
+func (r *stockCountRepository) CountByStatus(status string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.StockCount{}).Where("status = ?", status).Count(&count).Error
+	return count, err
+}
+
+func (r *stockCountRepository) Create(count *models.StockCount) error {
+	return r.db.Omit("Starter", "Poster").Create(count).Error
+}
+
+func (r *stockCountRepository) Update(count *models.StockCount) error {
+	return r.db.Omit(clause.Associations).Save(count).Error
+}
+
+func (r *stockCountRepository) UpdateItems(items []models.StockCountItem) error {
+	for i := range items {
+		if err := r.db.Omit("Counter").Save(&items[i]).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"github.com/syrlramadhan/cashier-app/models"
+	"gorm.io/gorm"
+)
+
+type StockMovementRepository interface {
+	Create(movements []models.StockMovement) error
+	FindByProductID(productID uint, variantID *uint, reason string, limit int) ([]models.StockMovement, error)
+	WithTx(tx *gorm.DB) StockMovementRepository
+}
+
+type stockMovementRepository struct {
+	db *gorm.DB
+}
+
+func NewStockMovementRepository(db *gorm.DB) StockMovementRepository {
+	return &stockMovementRepository{db: db}
+}
+
+func (r *stockMovementRepository) WithTx(tx *gorm.DB) StockMovementRepository {
+	return &stockMovementRepository{db: tx}
+}
+
+func (r *stockMovementRepository) Create(movements []models.StockMovement) error {
+	return r.db.Create(&movements).Error
+}
+
+// FindByProductID returns the latest movements of a product, newest first,
+// optionally limited to one variant and one reason
+func (r *stockMovementRepository) FindByProductID(productID uint, variantID *uint, reason string, limit int) ([]models.StockMovement, error) {
+	var movements []models.StockMovement
+	query := r.db.Preload("User").Where("product_id = ?", productID)
+	if variantID != nil {
+		query = query.Where("variant_id = ?", *variantID)
+	}
+	if reason != "" {
+		query = query.Where("reason = ?", reason)
+	}
+	err := query.Order("created_at DESC, id DESC").Limit(limit).Find(&movements).Error
+	return movements, err
+}
@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"github.com/syrlramadhan/cashier-app/models"
+	"gorm.io/gorm"
+)
+
+type SupplierRepository interface {
+	FindAll() ([]models.Supplier, error)
+	FindByID(id uint) (*models.Supplier, error)
+	Create(supplier *models.Supplier) error
+	Update(supplier *models.Supplier) error
+	Delete(id uint) error
+	WithTx(tx *gorm.DB) SupplierRepository
+}
+
+type supplierRepository struct {
+	db *gorm.DB
+}
+
+func NewSupplierRepository(db *gorm.DB) SupplierRepository {
+	return &supplierRepository{db: db}
+}
+
+func (r *supplierRepository) WithTx(tx *gorm.DB) SupplierRepository {
+	return &supplierRepository{db: tx}
+}
+
+func (r *supplierRepository) FindAll() ([]models.Supplier, error) {
+	var suppliers []models.Supplier
+	err := r.db.Order("name ASC").Find(&suppliers).Error
+	return suppliers, err
+}
+
+func (r *supplierRepository) FindByID(id uint) (*models.Supplier, error) {
+	var supplier models.Supplier
+	err := r.db.First(&supplier, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &supplier, nil
+}
+
+func (r *supplierRepository) Create(supplier *models.Supplier) error {
+	return r.db.Create(supplier).Error
+}
+
+func (r *supplierRepository) Update(supplier *models.Supplier) error {
+	return r.db.Save(supplier).Error
+}
+
+func (r *supplierRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Supplier{}, id).Error
+}
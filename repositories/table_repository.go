@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"github.com/syrlramadhan/cashier-app/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TableRepository interface {
+	FindAll() ([]models.Table, error)
+	FindByID(id uint) (*models.Table, error)
+	FindByIDsForUpdate(ids []uint) ([]models.Table, error)
+	FindByName(name string) (*models.Table, error)
+	Create(table *models.Table) error
+	Update(table *models.Table) error
+	UpdateStatus(id uint, status string) error
+	Delete(id uint) error
+	WithTx(tx *gorm.DB) TableRepository
+}
+
+type tableRepository struct {
+	db *gorm.DB
+}
+
+func NewTableRepository(db *gorm.DB) TableRepository {
+	return &tableRepository{db: db}
+}
+
+func (r *tableRepository) WithTx(tx *gorm.DB) TableRepository {
+	return &tableRepository{db: tx}
+}
+
+func (r *tableRepository) FindAll() ([]models.Table, error) {
+	var tables []models.Table
+	err := r.db.Order("area ASC, name ASC").Find(&tables).Error
+	return tables, err
+}
+
+func (r *tableRepository) FindByID(id uint) (*models.Table, error) {
+	var table models.Table
+	err := r.db.First(&table, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &table, nil
+}
+
+// FindByIDsForUpdate locks the tables in ascending ID order.
+// It must be called on a repository bound to a transaction via WithTx.
+func (r *tableRepository) FindByIDsForUpdate(ids []uint) ([]models.Table, error) {
+	var tables []models.Table
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id IN ?", ids).Order("id ASC").Find(&tables).Error
+	return tables, err
+}
+
+func (r *tableRepository) FindByName(name string) (*models.Table, error) {
+	var table models.Table
+	err := r.db.Where("name = ?", name).First(&table).Error
+	if err != nil {
+		return nil, err
+	}
+	return &table, nil
+}
+
+func (r *tableRepository) Create(table *models.Table) error {
+	return r.db.Create(table).Error
+}
+
+func (r *tableRepository) Update(table *models.Table) error {
+	return r.db.Save(table).Error
+}
+
+func (r *tableRepository) UpdateStatus(id uint, status string) error {
+	return r.db.Model(&models.Table{}).Where("id = ?", id).Update("status", status).Error
+}
+
+func (r *tableRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Table{}, id).Error
+}
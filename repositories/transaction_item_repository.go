@@ -1,18 +1,28 @@
 package repositories
 
 import (
+	"time"
+
 	"github.com/syrlramadhan/cashier-app/dto"
 	"github.com/syrlramadhan/cashier-app/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TransactionItemRepository interface {
 	FindByTransactionID(transactionID uint) ([]models.TransactionItem, error)
 	Create(item *models.TransactionItem) error
+	Update(item *models.TransactionItem) error
+	ReplaceModifiers(itemID uint, modifiers []models.TransactionItemModifier) error
 	CreateBatch(items []models.TransactionItem) error
 	Delete(id uint) error
 	DeleteByTransactionID(transactionID uint) error
+	MoveToTransaction(fromTransactionID, toTransactionID uint, roundOffset int) error
 	GetTopProducts(limit int) ([]dto.TopProductData, error)
+	GetSoldQuantities(startDate, endDate time.Time) ([]dto.ProductQuantityData, error)
+	GetTopVariants(limit int) ([]dto.TopVariantData, error)
+	GetTopModifiers(limit int) ([]dto.TopModifierData, error)
+	WithTx(tx *gorm.DB) TransactionItemRepository
 }
 
 type transactionItemRepository struct {
@@ -23,9 +33,13 @@ func NewTransactionItemRepository(db *gorm.DB) TransactionItemRepository {
 	return &transactionItemRepository{db: db}
 }
 
+func (r *transactionItemRepository) WithTx(tx *gorm.DB) TransactionItemRepository {
+	return &transactionItemRepository{db: tx}
+}
+
 func (r *transactionItemRepository) FindByTransactionID(transactionID uint) ([]models.TransactionItem, error) {
 	var items []models.TransactionItem
-	err := r.db.Preload("Product").Where("transaction_id = ?", transactionID).Find(&items).Error
+	err := r.db.Preload("Product").Preload("Modifiers").Where("transaction_id = ?", transactionID).Find(&items).Error
 	return items, err
 }
 
@@ -33,6 +47,26 @@ func (r *transactionItemRepository) Create(item *models.TransactionItem) error {
 	return r.db.Create(item).Error
 }
 
+// Update saves the line only; use ReplaceModifiers for its modifiers
+func (r *transactionItemRepository) Update(item *models.TransactionItem) error {
+	return r.db.Omit(clause.Associations).Save(item).Error
+}
+
+// ReplaceModifiers swaps the modifiers of a line for the given set
+func (r *transactionItemRepository) ReplaceModifiers(itemID uint, modifiers []models.TransactionItemModifier) error {
+	if err := r.db.Where("transaction_item_id = ?", itemID).Delete(&models.TransactionItemModifier{}).Error; err != nil {
+		return err
+	}
+	if len(modifiers) == 0 {
+		return nil
+	}
+	for i := range modifiers {
+		modifiers[i].ID = 0
+		modifiers[i].TransactionItemID = itemID
+	}
+	return r.db.Create(&modifiers).Error
+}
+
 func (r *transactionItemRepository) CreateBatch(items []models.TransactionItem) error {
 	return r.db.Create(&items).Error
 }
@@ -42,14 +76,84 @@ func (r *transactionItemRepository) Delete(id uint) error {
 }
 
 func (r *transactionItemRepository) DeleteByTransactionID(transactionID uint) error {
+	err := r.db.Where("transaction_item_id IN (?)", r.db.Model(&models.TransactionItem{}).Select("id").Where("transaction_id = ?", transactionID)).
+		Delete(&models.TransactionItemModifier{}).Error
+	if err != nil {
+		return err
+	}
 	return r.db.Where("transaction_id = ?", transactionID).Delete(&models.TransactionItem{}).Error
 }
 
+// MoveToTransaction moves every line of one order to another, shifting their
+// rounds by roundOffset so they follow the rounds already on the target.
+func (r *transactionItemRepository) MoveToTransaction(fromTransactionID, toTransactionID uint, roundOffset int) error {
+	return r.db.Model(&models.TransactionItem{}).Where("transaction_id = ?", fromTransactionID).
+		Updates(map[string]interface{}{
+			"transaction_id": toTransactionID,
+			"round":          gorm.Expr("round + ?", roundOffset),
+		}).Error
+}
+
+// GetTopProducts ranks products sold in completed transactions, net of refunded
+// quantities. Split orders count through their own lines once all their bills
+// are paid; the copies on their split bills are skipped so nothing is counted
+// twice.
 func (r *transactionItemRepository) GetTopProducts(limit int) ([]dto.TopProductData, error) {
 	var results []dto.TopProductData
 	err := r.db.Model(&models.TransactionItem{}).
-		Select("product_id, product_name, SUM(quantity) as total_quantity, SUM(subtotal) as total_revenue").
-		Group("product_id, product_name").
+		Select("transaction_items.product_id, transaction_items.product_name, SUM(transaction_items.quantity - transaction_items.refunded_qty) as total_quantity, ROUND(SUM(transaction_items.subtotal * (transaction_items.quantity - transaction_items.refunded_qty) / transaction_items.quantity)) as total_revenue").
+		Joins("JOIN transactions ON transactions.id = transaction_items.transaction_id AND transactions.deleted_at IS NULL").
+		Scopes(soldOrders).
+		Group("transaction_items.product_id, transaction_items.product_name").
+		Order("total_quantity DESC").
+		Limit(limit).
+		Scan(&results).Error
+	return results, err
+}
+
+// GetSoldQuantities sums the units of each product sold between startDate and
+// endDate, counted the same way as GetTopProducts. Lines made to a recipe are
+// left out since they did not take the product's own stock.
+func (r *transactionItemRepository) GetSoldQuantities(startDate, endDate time.Time) ([]dto.ProductQuantityData, error) {
+	var results []dto.ProductQuantityData
+	err := r.db.Model(&models.TransactionItem{}).
+		Select("transaction_items.product_id, SUM(transaction_items.quantity - transaction_items.refunded_qty) as total_quantity").
+		Joins("JOIN transactions ON transactions.id = transaction_items.transaction_id AND transactions.deleted_at IS NULL").
+		Where("transactions.created_at BETWEEN ? AND ?", startDate, endDate).
+		Where("transaction_items.from_recipe = ?", false).
+		Scopes(soldOrders).
+		Group("transaction_items.product_id").
+		Scan(&results).Error
+	return results, err
+}
+
+// GetTopVariants ranks the variants sold, by the variant name stored on the
+// line, counted the same way as GetTopProducts
+func (r *transactionItemRepository) GetTopVariants(limit int) ([]dto.TopVariantData, error) {
+	var results []dto.TopVariantData
+	err := r.db.Model(&models.TransactionItem{}).
+		Select("transaction_items.product_id, transaction_items.product_name, transaction_items.variant_name, SUM(transaction_items.quantity - transaction_items.refunded_qty) as total_quantity, ROUND(SUM(transaction_items.subtotal * (transaction_items.quantity - transaction_items.refunded_qty) / transaction_items.quantity)) as total_revenue").
+		Joins("JOIN transactions ON transactions.id = transaction_items.transaction_id AND transactions.deleted_at IS NULL").
+		Scopes(soldOrders).
+		Where("transaction_items.variant_id IS NOT NULL").
+		Group("transaction_items.product_id, transaction_items.product_name, transaction_items.variant_name").
+		Order("total_quantity DESC").
+		Limit(limit).
+		Scan(&results).Error
+	return results, err
+}
+
+// GetTopModifiers ranks the modifiers chosen, by the units of the lines they
+// were added to, counted the same way as GetTopProducts. Revenue is at the
+// modifier price, before discounts.
+func (r *transactionItemRepository) GetTopModifiers(limit int) ([]dto.TopModifierData, error) {
+	var results []dto.TopModifierData
+	err := r.db.Model(&models.TransactionItemModifier{}).
+		Select("transaction_item_modifiers.modifier_option_id, transaction_item_modifiers.group_name, transaction_item_modifiers.name, SUM(transaction_items.quantity - transaction_items.refunded_qty) as total_quantity, SUM(transaction_item_modifiers.price * (transaction_items.quantity - transaction_items.refunded_qty)) as total_revenue").
+		Joins("JOIN transaction_items ON transaction_items.id = transaction_item_modifiers.transaction_item_id").
+		Joins("JOIN transactions ON transactions.id = transaction_items.transaction_id AND transactions.deleted_at IS NULL").
+		Scopes(soldOrders).
+		Group("transaction_item_modifiers.modifier_option_id, transaction_item_modifiers.group_name, transaction_item_modifiers.name").
 		Order("total_quantity DESC").
 		Limit(limit).
 		Scan(&results).Error
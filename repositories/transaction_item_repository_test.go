@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestItemReportsCountSalesLikeTotalRevenue(t *testing.T) {
+	tests := []struct {
+		name  string
+		query func(repo TransactionItemRepository)
+	}{
+		{name: "GetTopProducts", query: func(repo TransactionItemRepository) { repo.GetTopProducts(10) }},
+		{name: "GetTopVariants", query: func(repo TransactionItemRepository) { repo.GetTopVariants(10) }},
+		{name: "GetTopModifiers", query: func(repo TransactionItemRepository) { repo.GetTopModifiers(10) }},
+		{name: "GetSoldQuantities", query: func(repo TransactionItemRepository) {
+			repo.GetSoldQuantities(time.Now().AddDate(0, 0, -30), time.Now())
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := capturedSQL(t, func(db *gorm.DB) { tt.query(NewTransactionItemRepository(db)) })[0]
+			assertSameSales(t, got)
+		})
+	}
+}
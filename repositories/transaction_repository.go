@@ -3,31 +3,69 @@ package repositories
 import (
 	"time"
 
+	"github.com/syrlramadhan/cashier-app/dto"
 	"github.com/syrlramadhan/cashier-app/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// nonSaleStatuses are orders that are not receipts of their own: unpaid
+// orders, and split orders whose payments are receipted by their split bills.
+// Listings skip them; reports count sales through soldOrders instead.
+var nonSaleStatuses = []string{"held", "expired", "open", "split", "unpaid"}
+
+// soldOrders keeps the orders that count as sales in reports: completed
+// sales, and split orders once none of their bills is unpaid. Split bills
+// themselves are left out since they copy the lines and amounts of their
+// order, so every report counts a split order once, on the order.
+func soldOrders(db *gorm.DB) *gorm.DB {
+	return db.Where("transactions.parent_id IS NULL").
+		Where("(transactions.status = ? OR (transactions.status = ? AND NOT EXISTS (SELECT 1 FROM transactions bills WHERE bills.parent_id = transactions.id AND bills.status = ? AND bills.deleted_at IS NULL)))", "completed", "split", "unpaid")
+}
+
+// collectedPayments keeps the transactions whose payments count in reports:
+// completed sales, and the bills of a split order once none of its bills is
+// unpaid, matching soldOrders.
+func collectedPayments(db *gorm.DB) *gorm.DB {
+	return db.Where("transactions.status = ?", "completed").
+		Where("(transactions.parent_id IS NULL OR NOT EXISTS (SELECT 1 FROM transactions bills WHERE bills.parent_id = transactions.parent_id AND bills.status = ? AND bills.deleted_at IS NULL))", "unpaid")
+}
+
 type TransactionRepository interface {
 	FindAll() ([]models.Transaction, error)
 	FindAllWithDetails() ([]models.Transaction, error)
 	FindByID(id uint) (*models.Transaction, error)
 	FindByIDWithDetails(id uint) (*models.Transaction, error)
+	FindByIDForUpdate(id uint) (*models.Transaction, error)
 	FindByCode(code string) (*models.Transaction, error)
 	FindByUserID(userID uint) ([]models.Transaction, error)
 	FindByDateRange(startDate, endDate time.Time) ([]models.Transaction, error)
 	FindByPaymentMethod(method string) ([]models.Transaction, error)
-	FindWithFilters(startDate, endDate *time.Time, paymentMethod string, limit, offset int) ([]models.Transaction, int64, error)
+	FindWithFilters(startDate, endDate *time.Time, paymentMethod, orderType string, limit, offset int) ([]models.Transaction, int64, error)
 	Create(transaction *models.Transaction) error
 	Update(transaction *models.Transaction) error
 	Delete(id uint) error
 	Count() (int64, error)
 	CountByDateRange(startDate, endDate time.Time) (int64, error)
 	CountByPaymentMethod(method string) (int64, error)
-	GetTotalRevenue(startDate, endDate time.Time) (float64, error)
-	GetTotalRevenueByDateRange(startDate, endDate time.Time) (float64, error)
-	GetRevenueByPaymentMethod() ([]map[string]interface{}, error)
+	GetTotalRevenue(startDate, endDate time.Time, orderType string) (models.Money, error)
+	GetTotalRevenueByDateRange(startDate, endDate time.Time) (models.Money, error)
+	GetDailyNetRevenue(startDate, endDate time.Time) ([]dto.DailyAmountData, error)
+	GetPaymentMethodStats() ([]dto.PaymentMethodStatData, error)
 	GetDailyRevenue(days int) ([]map[string]interface{}, error)
-	GenerateTransactionCode() (string, error)
+	GetTaxSummary(startDate, endDate time.Time, orderType string) ([]dto.TaxSummaryData, error)
+	GetOrderTypeSummary(startDate, endDate time.Time) ([]dto.OrderTypeSummaryData, error)
+	GetDiscountSummary(startDate, endDate time.Time) ([]dto.DiscountSummaryData, error)
+	CreateDiscounts(discounts []models.TransactionDiscount) error
+	FindHeld(userID uint) ([]models.Transaction, error)
+	ExpireHeldOrders(before time.Time) error
+	FindOpenTabs() ([]models.Transaction, error)
+	FindOpenTabByTable(tableID uint) (*models.Transaction, error)
+	CountUnpaidChildren(parentID uint) (int64, error)
+	FindChildrenForUpdate(parentID uint) ([]models.Transaction, error)
+	FindQueueBoard(since time.Time) ([]models.Transaction, error)
+	UpdateFulfillmentStatus(id uint, status string) error
+	WithTx(tx *gorm.DB) TransactionRepository
 }
 
 type transactionRepository struct {
@@ -38,15 +76,19 @@ func NewTransactionRepository(db *gorm.DB) TransactionRepository {
 	return &transactionRepository{db: db}
 }
 
+func (r *transactionRepository) WithTx(tx *gorm.DB) TransactionRepository {
+	return &transactionRepository{db: tx}
+}
+
 func (r *transactionRepository) FindAll() ([]models.Transaction, error) {
 	var transactions []models.Transaction
-	err := r.db.Preload("User").Preload("Items").Order("created_at DESC").Find(&transactions).Error
+	err := r.db.Preload("User").Preload("Items.Modifiers").Preload("Payments").Preload("Discounts").Where("status NOT IN ?", nonSaleStatuses).Order("created_at DESC").Find(&transactions).Error
 	return transactions, err
 }
 
 func (r *transactionRepository) FindAllWithDetails() ([]models.Transaction, error) {
 	var transactions []models.Transaction
-	err := r.db.Preload("User").Preload("Items").Preload("Items.Product").Order("created_at DESC").Find(&transactions).Error
+	err := r.db.Preload("User").Preload("Items.Modifiers").Preload("Payments").Preload("Discounts").Preload("Items.Product").Where("status NOT IN ?", nonSaleStatuses).Order("created_at DESC").Find(&transactions).Error
 	return transactions, err
 }
 
@@ -61,7 +103,18 @@ func (r *transactionRepository) FindByID(id uint) (*models.Transaction, error) {
 
 func (r *transactionRepository) FindByIDWithDetails(id uint) (*models.Transaction, error) {
 	var transaction models.Transaction
-	err := r.db.Preload("User").Preload("Items").Preload("Items.Product").First(&transaction, id).Error
+	err := r.db.Preload("User").Preload("Items.Modifiers").Preload("Payments").Preload("Discounts").Preload("Items.Product").Preload("Children").First(&transaction, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// FindByIDForUpdate locks the transaction row together with its items.
+// It must be called on a repository bound to a transaction via WithTx.
+func (r *transactionRepository) FindByIDForUpdate(id uint) (*models.Transaction, error) {
+	var transaction models.Transaction
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Items.Modifiers").First(&transaction, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -70,7 +123,7 @@ func (r *transactionRepository) FindByIDWithDetails(id uint) (*models.Transactio
 
 func (r *transactionRepository) FindByCode(code string) (*models.Transaction, error) {
 	var transaction models.Transaction
-	err := r.db.Preload("User").Preload("Items").Where("transaction_code = ?", code).First(&transaction).Error
+	err := r.db.Preload("User").Preload("Items.Modifiers").Preload("Payments").Preload("Discounts").Where("transaction_code = ?", code).First(&transaction).Error
 	if err != nil {
 		return nil, err
 	}
@@ -79,34 +132,38 @@ func (r *transactionRepository) FindByCode(code string) (*models.Transaction, er
 
 func (r *transactionRepository) FindByUserID(userID uint) ([]models.Transaction, error) {
 	var transactions []models.Transaction
-	err := r.db.Preload("User").Preload("Items").Where("user_id = ?", userID).Order("created_at DESC").Find(&transactions).Error
+	err := r.db.Preload("User").Preload("Items.Modifiers").Preload("Payments").Preload("Discounts").Where("user_id = ? AND status NOT IN ?", userID, nonSaleStatuses).Order("created_at DESC").Find(&transactions).Error
 	return transactions, err
 }
 
 func (r *transactionRepository) FindByDateRange(startDate, endDate time.Time) ([]models.Transaction, error) {
 	var transactions []models.Transaction
-	err := r.db.Preload("User").Preload("Items").Where("created_at BETWEEN ? AND ?", startDate, endDate).Order("created_at DESC").Find(&transactions).Error
+	err := r.db.Preload("User").Preload("Items.Modifiers").Preload("Payments").Preload("Discounts").Where("created_at BETWEEN ? AND ? AND status NOT IN ?", startDate, endDate, nonSaleStatuses).Order("created_at DESC").Find(&transactions).Error
 	return transactions, err
 }
 
 func (r *transactionRepository) FindByPaymentMethod(method string) ([]models.Transaction, error) {
 	var transactions []models.Transaction
-	err := r.db.Preload("User").Preload("Items").Where("payment_method = ?", method).Order("created_at DESC").Find(&transactions).Error
+	err := r.db.Preload("User").Preload("Items.Modifiers").Preload("Payments").Preload("Discounts").Where("payment_method = ? AND status NOT IN ?", method, nonSaleStatuses).Order("created_at DESC").Find(&transactions).Error
 	return transactions, err
 }
 
-func (r *transactionRepository) FindWithFilters(startDate, endDate *time.Time, paymentMethod string, limit, offset int) ([]models.Transaction, int64, error) {
+func (r *transactionRepository) FindWithFilters(startDate, endDate *time.Time, paymentMethod, orderType string, limit, offset int) ([]models.Transaction, int64, error) {
 	var transactions []models.Transaction
 	var total int64
 
-	query := r.db.Model(&models.Transaction{})
+	query := r.db.Model(&models.Transaction{}).Where("status NOT IN ?", nonSaleStatuses)
 
 	if startDate != nil && endDate != nil {
 		query = query.Where("created_at BETWEEN ? AND ?", startDate, endDate)
 	}
 
 	if paymentMethod != "" {
-		query = query.Where("payment_method = ?", paymentMethod)
+		query = query.Where("payment_method = ? OR EXISTS (SELECT 1 FROM transaction_payments WHERE transaction_payments.transaction_id = transactions.id AND transaction_payments.method = ?)", paymentMethod, paymentMethod)
+	}
+
+	if orderType != "" {
+		query = query.Where("order_type = ?", orderType)
 	}
 
 	err := query.Count(&total).Error
@@ -114,14 +171,63 @@ func (r *transactionRepository) FindWithFilters(startDate, endDate *time.Time, p
 		return nil, 0, err
 	}
 
-	err = query.Preload("User").Preload("Items").Order("created_at DESC").Limit(limit).Offset(offset).Find(&transactions).Error
+	err = query.Preload("User").Preload("Items.Modifiers").Preload("Payments").Preload("Discounts").Order("created_at DESC").Limit(limit).Offset(offset).Find(&transactions).Error
 	return transactions, total, err
 }
 
+// FindHeld returns the parked orders of userID, or of every cashier when userID is 0
+func (r *transactionRepository) FindHeld(userID uint) ([]models.Transaction, error) {
+	var transactions []models.Transaction
+	query := r.db.Preload("User").Preload("Items.Modifiers").Where("status = ?", "held")
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	err := query.Order("updated_at DESC").Find(&transactions).Error
+	return transactions, err
+}
+
+// ExpireHeldOrders marks parked orders untouched since before as expired
+func (r *transactionRepository) ExpireHeldOrders(before time.Time) error {
+	return r.db.Model(&models.Transaction{}).Where("status = ? AND updated_at < ?", "held", before).UpdateColumn("status", "expired").Error
+}
+
+func (r *transactionRepository) FindOpenTabs() ([]models.Transaction, error) {
+	var transactions []models.Transaction
+	err := r.db.Preload("User").Preload("Items.Modifiers").Where("status = ?", "open").Order("created_at ASC").Find(&transactions).Error
+	return transactions, err
+}
+
+func (r *transactionRepository) FindOpenTabByTable(tableID uint) (*models.Transaction, error) {
+	var transaction models.Transaction
+	err := r.db.Where("status = ? AND table_id = ?", "open", tableID).First(&transaction).Error
+	if err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+func (r *transactionRepository) CountUnpaidChildren(parentID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Transaction{}).Where("parent_id = ? AND status = ?", parentID, "unpaid").Count(&count).Error
+	return count, err
+}
+
+// FindChildrenForUpdate locks the split bills of an order. Lock the order
+// first, as paying a bill does.
+func (r *transactionRepository) FindChildrenForUpdate(parentID uint) ([]models.Transaction, error) {
+	var transactions []models.Transaction
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("parent_id = ?", parentID).Order("id ASC").Find(&transactions).Error
+	return transactions, err
+}
+
 func (r *transactionRepository) Create(transaction *models.Transaction) error {
 	return r.db.Create(transaction).Error
 }
 
+func (r *transactionRepository) CreateDiscounts(discounts []models.TransactionDiscount) error {
+	return r.db.Create(&discounts).Error
+}
+
 func (r *transactionRepository) Update(transaction *models.Transaction) error {
 	return r.db.Save(transaction).Error
 }
@@ -138,68 +244,238 @@ func (r *transactionRepository) Count() (int64, error) {
 
 func (r *transactionRepository) CountByDateRange(startDate, endDate time.Time) (int64, error) {
 	var count int64
-	err := r.db.Model(&models.Transaction{}).Where("created_at BETWEEN ? AND ?", startDate, endDate).Count(&count).Error
+	err := r.db.Model(&models.Transaction{}).Where("transactions.created_at BETWEEN ? AND ?", startDate, endDate).Scopes(soldOrders).Count(&count).Error
 	return count, err
 }
 
 func (r *transactionRepository) CountByPaymentMethod(method string) (int64, error) {
 	var count int64
-	err := r.db.Model(&models.Transaction{}).Where("payment_method = ? AND status = ?", method, "completed").Count(&count).Error
+	err := r.db.Model(&models.Transaction{}).Where("transactions.payment_method = ?", method).Scopes(collectedPayments).Count(&count).Error
 	return count, err
 }
 
-func (r *transactionRepository) GetTotalRevenue(startDate, endDate time.Time) (float64, error) {
-	var total float64
-	err := r.db.Model(&models.Transaction{}).Where("created_at BETWEEN ? AND ? AND status = ?", startDate, endDate, "completed").Select("COALESCE(SUM(total), 0)").Scan(&total).Error
-	return total, err
+// GetTotalRevenue is the net revenue of a date range: sales less the refunds
+// issued in the same range, whenever the refunded sale was made. With
+// orderType only sales and refunds of that order type are counted.
+func (r *transactionRepository) GetTotalRevenue(startDate, endDate time.Time, orderType string) (models.Money, error) {
+	var total models.Money
+	query := r.db.Model(&models.Transaction{}).Where("transactions.created_at BETWEEN ? AND ?", startDate, endDate).Scopes(soldOrders)
+	if orderType != "" {
+		query = query.Where("transactions.order_type = ?", orderType)
+	}
+	err := query.Select("COALESCE(SUM(transactions.total), 0)").Scan(&total).Error
+	if err != nil {
+		return 0, err
+	}
+
+	var refunded models.Money
+	refunds := r.db.Model(&models.Refund{}).Where("refunds.created_at BETWEEN ? AND ?", startDate, endDate)
+	if orderType != "" {
+		refunds = refunds.Joins("JOIN transactions ON transactions.id = refunds.transaction_id").Where("transactions.order_type = ?", orderType)
+	}
+	err = refunds.Select("COALESCE(SUM(refunds.total), 0)").Scan(&refunded).Error
+	return total - refunded, err
 }
 
-func (r *transactionRepository) GetTotalRevenueByDateRange(startDate, endDate time.Time) (float64, error) {
-	var total float64
-	err := r.db.Model(&models.Transaction{}).Where("created_at BETWEEN ? AND ?", startDate, endDate).Select("COALESCE(SUM(total), 0)").Scan(&total).Error
+// GetDailyNetRevenue is GetTotalRevenue for each day of a date range that had
+// sales or refunds, with refunds counted on the day they were issued
+func (r *transactionRepository) GetDailyNetRevenue(startDate, endDate time.Time) ([]dto.DailyAmountData, error) {
+	var sales []dto.DailyAmountData
+	err := r.db.Model(&models.Transaction{}).
+		Select("DATE(transactions.created_at) as date, COALESCE(SUM(transactions.total), 0) as amount").
+		Where("transactions.created_at BETWEEN ? AND ?", startDate, endDate).
+		Scopes(soldOrders).
+		Group("DATE(transactions.created_at)").
+		Scan(&sales).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var refunds []dto.DailyAmountData
+	err = r.db.Model(&models.Refund{}).
+		Select("DATE(created_at) as date, COALESCE(SUM(total), 0) as amount").
+		Where("created_at BETWEEN ? AND ?", startDate, endDate).
+		Group("DATE(created_at)").
+		Scan(&refunds).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, refund := range refunds {
+		refund.Amount = -refund.Amount
+		sales = append(sales, refund)
+	}
+	return sales, nil
+}
+
+func (r *transactionRepository) GetTotalRevenueByDateRange(startDate, endDate time.Time) (models.Money, error) {
+	var total models.Money
+	err := r.db.Model(&models.Transaction{}).Where("transactions.created_at BETWEEN ? AND ?", startDate, endDate).Scopes(soldOrders).Select("COALESCE(SUM(transactions.total), 0)").Scan(&total).Error
 	return total, err
 }
 
-func (r *transactionRepository) GetRevenueByPaymentMethod() ([]map[string]interface{}, error) {
-	var results []map[string]interface{}
-	err := r.db.Model(&models.Transaction{}).
-		Select("payment_method, COUNT(*) as count, COALESCE(SUM(total), 0) as total_amount").
-		Group("payment_method").
-		Find(&results).Error
-	return results, err
+// GetPaymentMethodStats sums the payment lines of completed transactions per
+// tender, so a split payment counts towards every method it used. The bills of
+// a split order count once they are all paid, like the order in the other
+// reports. Refunds are taken off the method they were paid out with, like
+// GetTotalRevenue does.
+func (r *transactionRepository) GetPaymentMethodStats() ([]dto.PaymentMethodStatData, error) {
+	var results []dto.PaymentMethodStatData
+	err := r.db.Model(&models.TransactionPayment{}).
+		Select("transaction_payments.method, COUNT(DISTINCT transaction_payments.transaction_id) as count, COALESCE(SUM(transaction_payments.amount), 0) as total_amount").
+		Joins("JOIN transactions ON transactions.id = transaction_payments.transaction_id AND transactions.deleted_at IS NULL").
+		Scopes(collectedPayments).
+		Group("transaction_payments.method").
+		Scan(&results).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var refunds []struct {
+		RefundMethod string
+		Total        models.Money
+	}
+	err = r.db.Model(&models.Refund{}).
+		Select("refund_method, COALESCE(SUM(total), 0) as total").
+		Group("refund_method").
+		Scan(&refunds).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, refund := range refunds {
+		found := false
+		for i := range results {
+			if results[i].Method == refund.RefundMethod {
+				results[i].TotalAmount -= refund.Total
+				found = true
+			}
+		}
+		if !found {
+			results = append(results, dto.PaymentMethodStatData{Method: refund.RefundMethod, TotalAmount: -refund.Total})
+		}
+	}
+	return results, nil
 }
 
 func (r *transactionRepository) GetDailyRevenue(days int) ([]map[string]interface{}, error) {
 	var results []map[string]interface{}
 	err := r.db.Model(&models.Transaction{}).
-		Select("DATE(created_at) as date, COUNT(*) as count, COALESCE(SUM(total), 0) as revenue").
-		Where("created_at >= ?", time.Now().AddDate(0, 0, -days)).
-		Group("DATE(created_at)").
+		Select("DATE(transactions.created_at) as date, COUNT(*) as count, COALESCE(SUM(transactions.total), 0) as revenue").
+		Where("transactions.created_at >= ?", time.Now().AddDate(0, 0, -days)).
+		Scopes(soldOrders).
+		Group("DATE(transactions.created_at)").
 		Order("date ASC").
 		Find(&results).Error
 	return results, err
 }
 
-func (r *transactionRepository) GenerateTransactionCode() (string, error) {
-	var count int64
-	today := time.Now().Format("20060102")
-	r.db.Model(&models.Transaction{}).Where("DATE(created_at) = CURRENT_DATE").Count(&count)
-	code := "TRX-" + today + "-" + padNumber(int(count+1), 4)
-	return code, nil
+func (r *transactionRepository) GetTaxSummary(startDate, endDate time.Time, orderType string) ([]dto.TaxSummaryData, error) {
+	var results []dto.TaxSummaryData
+	query := r.db.Model(&models.Transaction{}).
+		Select("transactions.tax_rate, transactions.tax_inclusive, COUNT(*) as transaction_count, COALESCE(SUM(transactions.subtotal), 0) as subtotal, COALESCE(SUM(transactions.tax), 0) as tax, COALESCE(SUM(transactions.total), 0) as total").
+		Where("transactions.created_at BETWEEN ? AND ?", startDate, endDate).
+		Scopes(soldOrders)
+	if orderType != "" {
+		query = query.Where("transactions.order_type = ?", orderType)
+	}
+	err := query.
+		Group("transactions.tax_rate, transactions.tax_inclusive").
+		Order("transactions.tax_rate ASC").
+		Scan(&results).Error
+	return results, err
 }
 
-func padNumber(num int, length int) string {
-	result := ""
-	for i := 0; i < length; i++ {
-		result = "0" + result
+// GetOrderTypeSummary totals sales per order type. Discounts are every line
+// and order discount, as in GetDiscountSummary, and refunds are counted by the
+// date they were issued, as in GetTotalRevenue, so the net revenue of all
+// order types adds up to the revenue of the range.
+func (r *transactionRepository) GetOrderTypeSummary(startDate, endDate time.Time) ([]dto.OrderTypeSummaryData, error) {
+	var results []dto.OrderTypeSummaryData
+	err := r.db.Model(&models.Transaction{}).
+		Select("transactions.order_type, COUNT(*) as transaction_count, COALESCE(SUM(transactions.subtotal), 0) as subtotal, COALESCE(SUM(transactions.service_charge), 0) as service_charge, COALESCE(SUM(transactions.tax), 0) as tax, COALESCE(SUM(transactions.total), 0) as total").
+		Where("transactions.created_at BETWEEN ? AND ?", startDate, endDate).
+		Scopes(soldOrders).
+		Group("transactions.order_type").
+		Order("transactions.order_type ASC").
+		Scan(&results).Error
+	if err != nil {
+		return nil, err
 	}
-	numStr := ""
-	for num > 0 {
-		numStr = string(rune('0'+num%10)) + numStr
-		num /= 10
+
+	var discounts []dto.OrderTypeSummaryData
+	err = r.db.Model(&models.TransactionDiscount{}).
+		Select("transactions.order_type, COALESCE(SUM(transaction_discounts.amount), 0) as discount").
+		Joins("JOIN transactions ON transactions.id = transaction_discounts.transaction_id AND transactions.deleted_at IS NULL").
+		Where("transactions.created_at BETWEEN ? AND ?", startDate, endDate).
+		Scopes(soldOrders).
+		Group("transactions.order_type").
+		Scan(&discounts).Error
+	if err != nil {
+		return nil, err
 	}
-	if len(numStr) >= length {
-		return numStr
+
+	for _, discount := range discounts {
+		for i := range results {
+			if results[i].OrderType == discount.OrderType {
+				results[i].Discount = discount.Discount
+			}
+		}
 	}
-	return result[:length-len(numStr)] + numStr
+
+	var refunds []dto.OrderTypeSummaryData
+	err = r.db.Model(&models.Refund{}).
+		Select("transactions.order_type, COALESCE(SUM(refunds.total), 0) as refunded_total").
+		Joins("JOIN transactions ON transactions.id = refunds.transaction_id").
+		Where("refunds.created_at BETWEEN ? AND ?", startDate, endDate).
+		Group("transactions.order_type").
+		Scan(&refunds).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, refund := range refunds {
+		found := false
+		for i := range results {
+			if results[i].OrderType == refund.OrderType {
+				results[i].RefundedTotal = refund.RefundedTotal
+				found = true
+			}
+		}
+		if !found {
+			results = append(results, refund)
+		}
+	}
+	return results, nil
+}
+
+// GetDiscountSummary totals the discounts given on sales per source and
+// promotion. Discounts of a split order are recorded on the order itself.
+func (r *transactionRepository) GetDiscountSummary(startDate, endDate time.Time) ([]dto.DiscountSummaryData, error) {
+	var results []dto.DiscountSummaryData
+	err := r.db.Model(&models.TransactionDiscount{}).
+		Select("transaction_discounts.source, transaction_discounts.promotion_id, transaction_discounts.name, COUNT(DISTINCT transaction_discounts.transaction_id) as transaction_count, COALESCE(SUM(transaction_discounts.amount), 0) as total_amount").
+		Joins("JOIN transactions ON transactions.id = transaction_discounts.transaction_id AND transactions.deleted_at IS NULL").
+		Where("transactions.created_at BETWEEN ? AND ?", startDate, endDate).
+		Scopes(soldOrders).
+		Group("transaction_discounts.source, transaction_discounts.promotion_id, transaction_discounts.name").
+		Order("total_amount DESC").
+		Scan(&results).Error
+	return results, err
+}
+
+// FindQueueBoard returns the counter orders since the start of the day that
+// are still being prepared or waiting to be collected
+func (r *transactionRepository) FindQueueBoard(since time.Time) ([]models.Transaction, error) {
+	var transactions []models.Transaction
+	err := r.db.Select("id, queue_number, fulfillment_status, updated_at").
+		Where("created_at >= ? AND status = ? AND queue_number > 0 AND fulfillment_status IN ?", since, "completed", []string{"preparing", "ready"}).
+		Order("queue_number ASC").
+		Find(&transactions).Error
+	return transactions, err
+}
+
+// UpdateFulfillmentStatus moves a counter order along the queue board
+func (r *transactionRepository) UpdateFulfillmentStatus(id uint, status string) error {
+	return r.db.Model(&models.Transaction{}).Where("id = ? AND queue_number > 0", id).Update("fulfillment_status", status).Error
 }
@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// statement is a query built by a repository method
+type statement struct {
+	SQL  string
+	Vars []interface{}
+}
+
+// where returns the conditions of the statement, without grouping or ordering
+func (s statement) where() string {
+	where := s.SQL[strings.Index(s.SQL, " WHERE ")+len(" WHERE "):]
+	for _, clause := range []string{" GROUP BY ", " ORDER BY ", " LIMIT "} {
+		if i := strings.Index(where, clause); i >= 0 {
+			where = where[:i]
+		}
+	}
+	return where
+}
+
+// capturedSQL runs query against a dry-run MySQL handle and returns the
+// statements it built, so report queries can be checked to filter alike
+// without a database
+func capturedSQL(t *testing.T, query func(db *gorm.DB)) []statement {
+	t.Helper()
+	db, err := gorm.Open(mysql.New(mysql.Config{SkipInitializeWithVersion: true}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+		Logger:               logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("open dry-run database: %v", err)
+	}
+
+	var statements []statement
+	capture := func(db *gorm.DB) {
+		statements = append(statements, statement{SQL: db.Statement.SQL.String(), Vars: db.Statement.Vars})
+	}
+	db.Callback().Query().After("gorm:query").Register("test:capture", capture)
+	db.Callback().Row().After("gorm:row").Register("test:capture", capture)
+
+	query(db)
+	if len(statements) == 0 {
+		t.Fatal("query built no statement")
+	}
+	return statements
+}
+
+// assertSameSales checks that a report query counts the same orders as GetTotalRevenue
+func assertSameSales(t *testing.T, got statement) {
+	t.Helper()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)
+	want := capturedSQL(t, func(db *gorm.DB) { NewTransactionRepository(db).GetTotalRevenue(start, end, "") })[0]
+
+	// GetTotalRevenue adds the date range and the soft delete of the order to
+	// soldOrders; item reports check the soft delete when they join
+	sales := strings.TrimPrefix(want.where(), "(transactions.created_at BETWEEN ? AND ?) AND ")
+	sales = strings.TrimSuffix(sales, " AND `transactions`.`deleted_at` IS NULL")
+	if !strings.Contains(got.where(), sales) {
+		t.Errorf("conditions = %s, want them to include %s", got.where(), sales)
+	}
+	if !reflect.DeepEqual(got.Vars[len(got.Vars)-3:], want.Vars[len(want.Vars)-3:]) {
+		t.Errorf("sale statuses = %v, want %v", got.Vars[len(got.Vars)-3:], want.Vars[len(want.Vars)-3:])
+	}
+}
+
+func TestGetDailyNetRevenueCountsSalesLikeTotalRevenue(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)
+
+	total := capturedSQL(t, func(db *gorm.DB) { NewTransactionRepository(db).GetTotalRevenue(start, end, "") })[0]
+	daily := capturedSQL(t, func(db *gorm.DB) { NewTransactionRepository(db).GetDailyNetRevenue(start, end) })[0]
+
+	// Split bills with parent_id set, and split orders with an unpaid bill,
+	// stay out of both
+	if daily.where() != total.where() {
+		t.Errorf("daily sales conditions = %s, want %s", daily.where(), total.where())
+	}
+	if !reflect.DeepEqual(daily.Vars, total.Vars) {
+		t.Errorf("daily sales vars = %v, want %v", daily.Vars, total.Vars)
+	}
+}
+
+func TestGetOrderTypeSummaryCountsSalesLikeTotalRevenue(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)
+
+	total := capturedSQL(t, func(db *gorm.DB) { NewTransactionRepository(db).GetTotalRevenue(start, end, "") })[0]
+	sales := capturedSQL(t, func(db *gorm.DB) { NewTransactionRepository(db).GetOrderTypeSummary(start, end) })[0]
+
+	// The net revenue of the order types adds up to GetTotalRevenue only when
+	// the sales are the same ones
+	if sales.where() != total.where() {
+		t.Errorf("sales conditions = %s, want %s", sales.where(), total.where())
+	}
+	if !reflect.DeepEqual(sales.Vars, total.Vars) {
+		t.Errorf("sales vars = %v, want %v", sales.Vars, total.Vars)
+	}
+}
@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"github.com/syrlramadhan/cashier-app/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type VoucherRepository interface {
+	FindAll(batchCode string) ([]models.Voucher, error)
+	FindByID(id uint) (*models.Voucher, error)
+	FindByCode(code string) (*models.Voucher, error)
+	FindByCodeForUpdate(code string) (*models.Voucher, error)
+	Create(voucher *models.Voucher) error
+	CreateBatch(vouchers []models.Voucher) error
+	Update(voucher *models.Voucher) error
+	Delete(id uint) error
+	AddUsage(id uint, delta int) error
+	CreateRedemption(redemption *models.VoucherRedemption) error
+	FindRedemptionsByTransactionID(transactionID uint) ([]models.VoucherRedemption, error)
+	DeleteRedemptionsByTransactionID(transactionID uint) error
+	CountRedemptionsByCustomer(voucherID uint, customerRef string) (int64, error)
+	WithTx(tx *gorm.DB) VoucherRepository
+}
+
+type voucherRepository struct {
+	db *gorm.DB
+}
+
+func NewVoucherRepository(db *gorm.DB) VoucherRepository {
+	return &voucherRepository{db: db}
+}
+
+func (r *voucherRepository) WithTx(tx *gorm.DB) VoucherRepository {
+	return &voucherRepository{db: tx}
+}
+
+func (r *voucherRepository) FindAll(batchCode string) ([]models.Voucher, error) {
+	var vouchers []models.Voucher
+	query := r.db.Order("created_at DESC")
+	if batchCode != "" {
+		query = query.Where("batch_code = ?", batchCode)
+	}
+	err := query.Find(&vouchers).Error
+	return vouchers, err
+}
+
+func (r *voucherRepository) FindByID(id uint) (*models.Voucher, error) {
+	var voucher models.Voucher
+	err := r.db.First(&voucher, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &voucher, nil
+}
+
+func (r *voucherRepository) FindByCode(code string) (*models.Voucher, error) {
+	var voucher models.Voucher
+	err := r.db.Where("code = ?", code).First(&voucher).Error
+	if err != nil {
+		return nil, err
+	}
+	return &voucher, nil
+}
+
+// FindByCodeForUpdate locks the voucher row so concurrent checkouts redeem it
+// one at a time. It must be called on a repository bound to a transaction via WithTx.
+func (r *voucherRepository) FindByCodeForUpdate(code string) (*models.Voucher, error) {
+	var voucher models.Voucher
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("code = ?", code).First(&voucher).Error
+	if err != nil {
+		return nil, err
+	}
+	return &voucher, nil
+}
+
+func (r *voucherRepository) Create(voucher *models.Voucher) error {
+	return r.db.Create(voucher).Error
+}
+
+func (r *voucherRepository) CreateBatch(vouchers []models.Voucher) error {
+	return r.db.CreateInBatches(&vouchers, 100).Error
+}
+
+func (r *voucherRepository) Update(voucher *models.Voucher) error {
+	return r.db.Save(voucher).Error
+}
+
+func (r *voucherRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Voucher{}, id).Error
+}
+
+func (r *voucherRepository) AddUsage(id uint, delta int) error {
+	return r.db.Model(&models.Voucher{}).Where("id = ?", id).UpdateColumn("used_count", gorm.Expr("used_count + ?", delta)).Error
+}
+
+func (r *voucherRepository) CreateRedemption(redemption *models.VoucherRedemption) error {
+	return r.db.Create(redemption).Error
+}
+
+func (r *voucherRepository) FindRedemptionsByTransactionID(transactionID uint) ([]models.VoucherRedemption, error) {
+	var redemptions []models.VoucherRedemption
+	err := r.db.Where("transaction_id = ?", transactionID).Find(&redemptions).Error
+	return redemptions, err
+}
+
+func (r *voucherRepository) DeleteRedemptionsByTransactionID(transactionID uint) error {
+	return r.db.Where("transaction_id = ?", transactionID).Delete(&models.VoucherRedemption{}).Error
+}
+
+func (r *voucherRepository) CountRedemptionsByCustomer(voucherID uint, customerRef string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.VoucherRedemption{}).Where("voucher_id = ? AND customer_ref = ?", voucherID, customerRef).Count(&count).Error
+	return count, err
+}
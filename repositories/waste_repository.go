@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/syrlramadhan/cashier-app/models"
+	"gorm.io/gorm"
+)
+
+type WasteRepository interface {
+	FindAll(startDate, endDate *time.Time, reason string) ([]models.WasteEntry, error)
+	FindByID(id uint) (*models.WasteEntry, error)
+	Create(entry *models.WasteEntry) error
+	GetTotalCost(startDate, endDate time.Time) (models.Money, error)
+	WithTx(tx *gorm.DB) WasteRepository
+}
+
+type wasteRepository struct {
+	db *gorm.DB
+}
+
+func NewWasteRepository(db *gorm.DB) WasteRepository {
+	return &wasteRepository{db: db}
+}
+
+func (r *wasteRepository) WithTx(tx *gorm.DB) WasteRepository {
+	return &wasteRepository{db: tx}
+}
+
+// FindAll lists waste entries newest first, optionally within a date range and by reason
+func (r *wasteRepository) FindAll(startDate, endDate *time.Time, reason string) ([]models.WasteEntry, error) {
+	var entries []models.WasteEntry
+	query := r.db.Preload("User").Preload("Ingredients")
+	if startDate != nil {
+		query = query.Where("created_at >= ?", *startDate)
+	}
+	if endDate != nil {
+		query = query.Where("created_at <= ?", *endDate)
+	}
+	if reason != "" {
+		query = query.Where("reason = ?", reason)
+	}
+	err := query.Order("created_at DESC").Find(&entries).Error
+	return entries, err
+}
+
+func (r *wasteRepository) FindByID(id uint) (*models.WasteEntry, error) {
+	var entry models.WasteEntry
+	err := r.db.Preload("User").Preload("Ingredients").First(&entry, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *wasteRepository) Create(entry *models.WasteEntry) error {
+	return r.db.Omit("User").Create(entry).Error
+}
+
+// GetTotalCost is the value of the waste recorded in a date range
+func (r *wasteRepository) GetTotalCost(startDate, endDate time.Time) (models.Money, error) {
+	var total models.Money
+	err := r.db.Model(&models.WasteEntry{}).
+		Select("COALESCE(SUM(cost), 0)").
+		Where("created_at BETWEEN ? AND ?", startDate, endDate).
+		Scan(&total).Error
+	return total, err
+}
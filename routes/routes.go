@@ -4,32 +4,93 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/syrlramadhan/cashier-app/controllers"
 	"github.com/syrlramadhan/cashier-app/middleware"
+	"github.com/syrlramadhan/cashier-app/services"
 )
 
 type Routes struct {
-	userController        *controllers.UserController
-	categoryController    *controllers.CategoryController
-	productController     *controllers.ProductController
-	transactionController *controllers.TransactionController
-	settingController     *controllers.SettingController
-	reportController      *controllers.ReportController
+	userController           *controllers.UserController
+	categoryController       *controllers.CategoryController
+	productController        *controllers.ProductController
+	productVariantController *controllers.ProductVariantController
+	modifierController       *controllers.ModifierController
+	ingredientController     *controllers.IngredientController
+	stockController          *controllers.StockController
+	supplierController       *controllers.SupplierController
+	purchaseOrderController  *controllers.PurchaseOrderController
+	stockCountController     *controllers.StockCountController
+	stockAlertController     *controllers.StockAlertController
+	reorderController        *controllers.ReorderController
+	wasteController          *controllers.WasteController
+	transactionController    *controllers.TransactionController
+	settingController        *controllers.SettingController
+	reportController         *controllers.ReportController
+	refundController         *controllers.RefundController
+	promotionController      *controllers.PromotionController
+	voucherController        *controllers.VoucherController
+	heldOrderController      *controllers.HeldOrderController
+	tableController          *controllers.TableController
+	tabController            *controllers.TabController
+	splitBillController      *controllers.SplitBillController
+	kitchenController        *controllers.KitchenController
+	queueController          *controllers.QueueController
+	idempotencyService       *services.IdempotencyService
 }
 
 func NewRoutes(
 	userController *controllers.UserController,
 	categoryController *controllers.CategoryController,
 	productController *controllers.ProductController,
+	productVariantController *controllers.ProductVariantController,
+	modifierController *controllers.ModifierController,
+	ingredientController *controllers.IngredientController,
+	stockController *controllers.StockController,
+	supplierController *controllers.SupplierController,
+	purchaseOrderController *controllers.PurchaseOrderController,
+	stockCountController *controllers.StockCountController,
+	stockAlertController *controllers.StockAlertController,
+	reorderController *controllers.ReorderController,
+	wasteController *controllers.WasteController,
 	transactionController *controllers.TransactionController,
 	settingController *controllers.SettingController,
 	reportController *controllers.ReportController,
+	refundController *controllers.RefundController,
+	promotionController *controllers.PromotionController,
+	voucherController *controllers.VoucherController,
+	heldOrderController *controllers.HeldOrderController,
+	tableController *controllers.TableController,
+	tabController *controllers.TabController,
+	splitBillController *controllers.SplitBillController,
+	kitchenController *controllers.KitchenController,
+	queueController *controllers.QueueController,
+	idempotencyService *services.IdempotencyService,
 ) *Routes {
 	return &Routes{
-		userController:        userController,
-		categoryController:    categoryController,
-		productController:     productController,
-		transactionController: transactionController,
-		settingController:     settingController,
-		reportController:      reportController,
+		userController:           userController,
+		categoryController:       categoryController,
+		productController:        productController,
+		productVariantController: productVariantController,
+		modifierController:       modifierController,
+		ingredientController:     ingredientController,
+		stockController:          stockController,
+		supplierController:       supplierController,
+		purchaseOrderController:  purchaseOrderController,
+		stockCountController:     stockCountController,
+		stockAlertController:     stockAlertController,
+		reorderController:        reorderController,
+		wasteController:          wasteController,
+		transactionController:    transactionController,
+		settingController:        settingController,
+		reportController:         reportController,
+		refundController:         refundController,
+		promotionController:      promotionController,
+		voucherController:        voucherController,
+		heldOrderController:      heldOrderController,
+		tableController:          tableController,
+		tabController:            tabController,
+		splitBillController:      splitBillController,
+		kitchenController:        kitchenController,
+		queueController:          queueController,
+		idempotencyService:       idempotencyService,
 	}
 }
 
@@ -60,10 +121,23 @@ func (r *Routes) SetupRouter() *gin.Engine {
 			auth.POST("/register", r.userController.Register)
 		}
 
+		// Stream routes (EventSource cannot send headers; see StreamAuthMiddleware)
+		api.GET("/kitchen/stream", middleware.StreamAuthMiddleware(), r.kitchenController.StreamTickets)
+		api.GET("/stock-alerts/stream", middleware.StreamAuthMiddleware(), middleware.ManagerOrAdmin(), r.stockAlertController.StreamAlerts)
+
+		// Queue board routes (public, read-only customer display)
+		queue := api.Group("/queue")
+		{
+			queue.GET("/board", r.queueController.GetBoard)
+			queue.GET("/stream", r.queueController.StreamBoard)
+		}
+
 		// Protected routes
 		protected := api.Group("")
-		protected.Use(middleware.AuthMiddleware())
+		protected.Use(middleware.AuthMiddleware(), middleware.IdempotencyMiddleware(r.idempotencyService))
 		{
+			protected.POST("/auth/stream-token", r.userController.CreateStreamToken)
+
 			// User routes
 			users := protected.Group("/users")
 			{
@@ -88,13 +162,102 @@ func (r *Routes) SetupRouter() *gin.Engine {
 			products := protected.Group("/products")
 			{
 				products.GET("", r.productController.GetAllProducts)
+				products.GET("/low-stock", r.stockAlertController.GetLowStockProducts)
 				products.GET("/:id", r.productController.GetProductByID)
 				products.GET("/category/:category_id", r.productController.GetProductsByCategory)
 				products.POST("", middleware.ManagerOrAdmin(), r.productController.CreateProduct)
 				products.POST("/upload", middleware.ManagerOrAdmin(), r.productController.UploadProductImage)
 				products.PUT("/:id", middleware.ManagerOrAdmin(), r.productController.UpdateProduct)
-				products.PATCH("/:id/stock", middleware.ManagerOrAdmin(), r.productController.UpdateStock)
+				products.PATCH("/:id/stock", middleware.ManagerOrAdmin(), r.stockController.AdjustStock)
+				products.GET("/:id/stock-movements", r.stockController.GetMovements)
 				products.DELETE("/:id", middleware.AdminOnly(), r.productController.DeleteProduct)
+
+				// Variant groups and variants of a product
+				products.GET("/:id/variants", r.productVariantController.GetVariants)
+				products.POST("/:id/variant-groups", middleware.ManagerOrAdmin(), r.productVariantController.CreateGroup)
+				products.PUT("/:id/variant-groups/:groupId", middleware.ManagerOrAdmin(), r.productVariantController.UpdateGroup)
+				products.DELETE("/:id/variant-groups/:groupId", middleware.ManagerOrAdmin(), r.productVariantController.DeleteGroup)
+				products.POST("/:id/variants", middleware.ManagerOrAdmin(), r.productVariantController.CreateVariant)
+				products.PUT("/:id/variants/:variantId", middleware.ManagerOrAdmin(), r.productVariantController.UpdateVariant)
+				products.DELETE("/:id/variants/:variantId", middleware.ManagerOrAdmin(), r.productVariantController.DeleteVariant)
+				products.GET("/:id/modifiers", r.modifierController.GetProductModifiers)
+				products.GET("/:id/recipe", r.ingredientController.GetProductRecipe)
+				products.PUT("/:id/recipe", middleware.ManagerOrAdmin(), r.ingredientController.SetProductRecipe)
+				products.GET("/:id/variants/:variantId/recipe", r.ingredientController.GetVariantRecipe)
+				products.PUT("/:id/variants/:variantId/recipe", middleware.ManagerOrAdmin(), r.ingredientController.SetVariantRecipe)
+			}
+
+			// Modifier group routes
+			modifierGroups := protected.Group("/modifier-groups")
+			{
+				modifierGroups.GET("", r.modifierController.GetAllGroups)
+				modifierGroups.GET("/:id", r.modifierController.GetGroupByID)
+				modifierGroups.POST("", middleware.ManagerOrAdmin(), r.modifierController.CreateGroup)
+				modifierGroups.PUT("/:id", middleware.ManagerOrAdmin(), r.modifierController.UpdateGroup)
+				modifierGroups.DELETE("/:id", middleware.ManagerOrAdmin(), r.modifierController.DeleteGroup)
+				modifierGroups.GET("/:id/options/:optionId/recipe", r.ingredientController.GetModifierRecipe)
+				modifierGroups.PUT("/:id/options/:optionId/recipe", middleware.ManagerOrAdmin(), r.ingredientController.SetModifierRecipe)
+			}
+
+			// Ingredient routes
+			ingredients := protected.Group("/ingredients")
+			{
+				ingredients.GET("", r.ingredientController.GetAllIngredients)
+				ingredients.GET("/:id", r.ingredientController.GetIngredientByID)
+				ingredients.POST("", middleware.ManagerOrAdmin(), r.ingredientController.CreateIngredient)
+				ingredients.PUT("/:id", middleware.ManagerOrAdmin(), r.ingredientController.UpdateIngredient)
+				ingredients.PATCH("/:id/stock", middleware.ManagerOrAdmin(), r.ingredientController.UpdateStock)
+				ingredients.DELETE("/:id", middleware.ManagerOrAdmin(), r.ingredientController.DeleteIngredient)
+			}
+
+			// Supplier routes
+			suppliers := protected.Group("/suppliers")
+			{
+				suppliers.GET("", middleware.ManagerOrAdmin(), r.supplierController.GetAllSuppliers)
+				suppliers.GET("/:id", middleware.ManagerOrAdmin(), r.supplierController.GetSupplierByID)
+				suppliers.POST("", middleware.ManagerOrAdmin(), r.supplierController.CreateSupplier)
+				suppliers.PUT("/:id", middleware.ManagerOrAdmin(), r.supplierController.UpdateSupplier)
+				suppliers.DELETE("/:id", middleware.ManagerOrAdmin(), r.supplierController.DeleteSupplier)
+			}
+
+			// Purchase order routes
+			purchaseOrders := protected.Group("/purchase-orders")
+			{
+				purchaseOrders.GET("", middleware.ManagerOrAdmin(), r.purchaseOrderController.GetAllPurchaseOrders)
+				purchaseOrders.GET("/:id", middleware.ManagerOrAdmin(), r.purchaseOrderController.GetPurchaseOrderByID)
+				purchaseOrders.POST("", middleware.ManagerOrAdmin(), r.purchaseOrderController.CreatePurchaseOrder)
+				purchaseOrders.POST("/from-suggestions", middleware.ManagerOrAdmin(), r.reorderController.CreatePurchaseOrder)
+				purchaseOrders.PUT("/:id", middleware.ManagerOrAdmin(), r.purchaseOrderController.UpdatePurchaseOrder)
+				purchaseOrders.POST("/:id/send", middleware.ManagerOrAdmin(), r.purchaseOrderController.SendPurchaseOrder)
+				purchaseOrders.POST("/:id/receive", middleware.ManagerOrAdmin(), r.purchaseOrderController.ReceivePurchaseOrder)
+				purchaseOrders.POST("/:id/close", middleware.ManagerOrAdmin(), r.purchaseOrderController.ClosePurchaseOrder)
+				purchaseOrders.POST("/:id/cancel", middleware.ManagerOrAdmin(), r.purchaseOrderController.CancelPurchaseOrder)
+			}
+
+			// Stock alert routes
+			stockAlerts := protected.Group("/stock-alerts")
+			{
+				stockAlerts.GET("", middleware.ManagerOrAdmin(), r.stockAlertController.GetAlerts)
+				stockAlerts.POST("/:id/acknowledge", middleware.ManagerOrAdmin(), r.stockAlertController.AcknowledgeAlert)
+			}
+
+			// Stock count routes
+			stockCounts := protected.Group("/stock-counts")
+			{
+				stockCounts.GET("", r.stockCountController.GetAllStockCounts)
+				stockCounts.GET("/:id", r.stockCountController.GetStockCountByID)
+				stockCounts.POST("", middleware.ManagerOrAdmin(), r.stockCountController.StartStockCount)
+				stockCounts.PUT("/:id/items", r.stockCountController.RecordCounts)
+				stockCounts.GET("/:id/variance", middleware.ManagerOrAdmin(), r.stockCountController.GetVarianceReport)
+				stockCounts.POST("/:id/post", middleware.ManagerOrAdmin(), r.stockCountController.PostStockCount)
+				stockCounts.POST("/:id/cancel", middleware.ManagerOrAdmin(), r.stockCountController.CancelStockCount)
+			}
+
+			// Waste routes
+			waste := protected.Group("/waste")
+			{
+				waste.GET("", middleware.ManagerOrAdmin(), r.wasteController.GetWasteEntries)
+				waste.POST("", r.wasteController.RecordWaste)
 			}
 
 			// Transaction routes
@@ -107,6 +270,83 @@ func (r *Routes) SetupRouter() *gin.Engine {
 				transactions.GET("/user/:user_id", r.transactionController.GetTransactionsByUser)
 				transactions.POST("", r.transactionController.CreateTransaction)
 				transactions.POST("/:id/cancel", middleware.ManagerOrAdmin(), r.transactionController.CancelTransaction)
+				transactions.GET("/:id/refunds", r.refundController.GetRefundsByTransaction)
+				transactions.POST("/:id/refunds", middleware.ManagerOrAdmin(), r.refundController.CreateRefund)
+			}
+
+			// Held order routes (parked carts, scoped to the cashier)
+			heldOrders := protected.Group("/held-orders")
+			{
+				heldOrders.GET("", r.heldOrderController.GetHeldOrders)
+				heldOrders.GET("/:id", r.heldOrderController.GetHeldOrder)
+				heldOrders.POST("", r.heldOrderController.HoldOrder)
+				heldOrders.PUT("/:id", r.heldOrderController.UpdateHeldOrder)
+				heldOrders.POST("/:id/finalize", r.heldOrderController.FinalizeHeldOrder)
+				heldOrders.DELETE("/:id", r.heldOrderController.DeleteHeldOrder)
+			}
+
+			// Table routes (floor view)
+			tables := protected.Group("/tables")
+			{
+				tables.GET("", r.tableController.GetAllTables)
+				tables.GET("/:id", r.tableController.GetTableByID)
+				tables.POST("", middleware.ManagerOrAdmin(), r.tableController.CreateTable)
+				tables.PUT("/:id", middleware.ManagerOrAdmin(), r.tableController.UpdateTable)
+				tables.DELETE("/:id", middleware.ManagerOrAdmin(), r.tableController.DeleteTable)
+				tables.POST("/:id/open", r.tableController.OpenTab)
+			}
+
+			// Tab routes (dine-in orders)
+			tabs := protected.Group("/tabs")
+			{
+				tabs.GET("", r.tabController.GetOpenTabs)
+				tabs.GET("/:id", r.tabController.GetTab)
+				tabs.POST("/:id/rounds", r.tabController.AddRound)
+				tabs.POST("/:id/move", r.tabController.MoveTab)
+				tabs.POST("/:id/merge", r.tabController.MergeTab)
+				tabs.POST("/:id/request-bill", r.tabController.RequestBill)
+				tabs.POST("/:id/close", r.tabController.CloseTab)
+				tabs.POST("/:id/split", r.splitBillController.SplitTab)
+				tabs.DELETE("/:id", middleware.ManagerOrAdmin(), r.tabController.VoidTab)
+			}
+
+			// Split bill routes (bills of a split tab, paid one by one)
+			splitBills := protected.Group("/split-bills")
+			{
+				splitBills.GET("/:id", r.splitBillController.GetSplitBill)
+				splitBills.POST("/:id/pay", r.splitBillController.PaySplitBill)
+			}
+
+			// Kitchen display routes (tickets per station)
+			kitchen := protected.Group("/kitchen")
+			{
+				kitchen.GET("/tickets", r.kitchenController.GetTickets)
+				kitchen.GET("/tickets/:id", r.kitchenController.GetTicket)
+				kitchen.POST("/tickets/:id/bump", r.kitchenController.BumpTicket)
+				kitchen.POST("/tickets/:id/recall", r.kitchenController.RecallTicket)
+			}
+
+			// Promotion routes
+			promotions := protected.Group("/promotions")
+			{
+				promotions.GET("", r.promotionController.GetAllPromotions)
+				promotions.GET("/active", r.promotionController.GetActivePromotions)
+				promotions.GET("/:id", r.promotionController.GetPromotionByID)
+				promotions.POST("", middleware.ManagerOrAdmin(), r.promotionController.CreatePromotion)
+				promotions.PUT("/:id", middleware.ManagerOrAdmin(), r.promotionController.UpdatePromotion)
+				promotions.DELETE("/:id", middleware.ManagerOrAdmin(), r.promotionController.DeletePromotion)
+			}
+
+			// Voucher routes (validate is used by the POS before payment)
+			vouchers := protected.Group("/vouchers")
+			{
+				vouchers.POST("/validate", r.voucherController.ValidateVoucher)
+				vouchers.GET("", middleware.ManagerOrAdmin(), r.voucherController.GetAllVouchers)
+				vouchers.GET("/:id", middleware.ManagerOrAdmin(), r.voucherController.GetVoucherByID)
+				vouchers.POST("", middleware.ManagerOrAdmin(), r.voucherController.CreateVoucher)
+				vouchers.POST("/batch", middleware.ManagerOrAdmin(), r.voucherController.GenerateVouchers)
+				vouchers.PUT("/:id", middleware.ManagerOrAdmin(), r.voucherController.UpdateVoucher)
+				vouchers.DELETE("/:id", middleware.ManagerOrAdmin(), r.voucherController.DeleteVoucher)
 			}
 
 			// Setting routes
@@ -128,8 +368,15 @@ func (r *Routes) SetupRouter() *gin.Engine {
 				reports.GET("/revenue/range", r.reportController.GetRevenueByDateRange)
 				reports.GET("/payment-distribution", r.reportController.GetPaymentDistribution)
 				reports.GET("/products/top", r.reportController.GetTopProducts)
+				reports.GET("/products/top-variants", r.reportController.GetTopVariants)
+				reports.GET("/products/top-modifiers", r.reportController.GetTopModifiers)
 				reports.GET("/summary/monthly", r.reportController.GetMonthlySummary)
+				reports.GET("/tax", r.reportController.GetTaxSummary)
+				reports.GET("/discounts", r.reportController.GetDiscountSummary)
+				reports.GET("/order-types", r.reportController.GetOrderTypeSummary)
 				reports.GET("/export/transactions", middleware.ManagerOrAdmin(), r.reportController.ExportTransactions)
+				reports.GET("/reorder-suggestions", middleware.ManagerOrAdmin(), r.reorderController.GetSuggestions)
+				reports.GET("/waste", middleware.ManagerOrAdmin(), r.wasteController.GetWasteReport)
 			}
 		}
 	}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"errors"
+	"strings"
 
 	"github.com/syrlramadhan/cashier-app/dto"
 	"github.com/syrlramadhan/cashier-app/models"
@@ -27,8 +28,9 @@ func (s *CategoryService) GetAllCategories() ([]dto.CategoryResponse, error) {
 	var response []dto.CategoryResponse
 	for _, category := range categories {
 		response = append(response, dto.CategoryResponse{
-			ID:   category.ID,
-			Name: category.Name,
+			ID:      category.ID,
+			Name:    category.Name,
+			Station: category.Station,
 		})
 	}
 
@@ -42,8 +44,9 @@ func (s *CategoryService) GetCategoryByID(id uint) (*dto.CategoryResponse, error
 	}
 
 	response := &dto.CategoryResponse{
-		ID:   category.ID,
-		Name: category.Name,
+		ID:      category.ID,
+		Name:    category.Name,
+		Station: category.Station,
 	}
 
 	return response, nil
@@ -57,7 +60,8 @@ func (s *CategoryService) CreateCategory(req *dto.CreateCategoryRequest) (*dto.C
 	}
 
 	category := &models.Category{
-		Name: req.Name,
+		Name:    req.Name,
+		Station: stationOrDefault(req.Station),
 	}
 
 	err := s.categoryRepo.Create(category)
@@ -66,8 +70,9 @@ func (s *CategoryService) CreateCategory(req *dto.CreateCategoryRequest) (*dto.C
 	}
 
 	response := &dto.CategoryResponse{
-		ID:   category.ID,
-		Name: category.Name,
+		ID:      category.ID,
+		Name:    category.Name,
+		Station: category.Station,
 	}
 
 	return response, nil
@@ -88,14 +93,16 @@ func (s *CategoryService) UpdateCategory(id uint, req *dto.UpdateCategoryRequest
 	}
 
 	category.Name = req.Name
+	category.Station = stationOrDefault(req.Station)
 	err = s.categoryRepo.Update(category)
 	if err != nil {
 		return nil, errors.New("failed to update category")
 	}
 
 	response := &dto.CategoryResponse{
-		ID:   category.ID,
-		Name: category.Name,
+		ID:      category.ID,
+		Name:    category.Name,
+		Station: category.Station,
 	}
 
 	return response, nil
@@ -109,3 +116,12 @@ func (s *CategoryService) DeleteCategory(id uint) error {
 
 	return s.categoryRepo.Delete(id)
 }
+
+// stationOrDefault sends categories without a station to the kitchen
+func stationOrDefault(station string) string {
+	station = strings.ToLower(strings.TrimSpace(station))
+	if station == "" {
+		return "kitchen"
+	}
+	return station
+}
@@ -0,0 +1,58 @@
+package services
+
+import "sync"
+
+// Event is a change pushed to the screens subscribed to a topic
+type Event struct {
+	Topic string      `json:"-"`
+	Type  string      `json:"type"`
+	Data  interface{} `json:"data"`
+}
+
+// EventHub fans events out to in-process subscribers, e.g. the Server-Sent
+// Events streams of the kitchen displays. Events are not persisted: a screen
+// loads the current state first and then applies the events it receives.
+type EventHub struct {
+	mu          sync.RWMutex
+	subscribers map[chan Event]string
+}
+
+func NewEventHub() *EventHub {
+	return &EventHub{subscribers: make(map[chan Event]string)}
+}
+
+// Subscribe returns the events published to topic until unsubscribe is called
+func (h *EventHub) Subscribe(topic string) (<-chan Event, func()) {
+	events := make(chan Event, 32)
+
+	h.mu.Lock()
+	h.subscribers[events] = topic
+	h.mu.Unlock()
+
+	unsubscribe := func() {
+		h.mu.Lock()
+		if _, ok := h.subscribers[events]; ok {
+			delete(h.subscribers, events)
+			close(events)
+		}
+		h.mu.Unlock()
+	}
+	return events, unsubscribe
+}
+
+// Publish never blocks; a subscriber that is too slow to keep up misses the
+// event and catches up on its next reload.
+func (h *EventHub) Publish(event Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for events, topic := range h.subscribers {
+		if topic != event.Topic {
+			continue
+		}
+		select {
+		case events <- event:
+		default:
+		}
+	}
+}
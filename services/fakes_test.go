@@ -0,0 +1,1139 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/models"
+	"github.com/syrlramadhan/cashier-app/repositories"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+// fakeDB stands in for the database in tests. Services open real gorm
+// transactions on it, and the fake repositories below keep their rows in
+// memory: writes made through a repository bound to a transaction only show
+// once it commits, and rows read FOR UPDATE stay locked until it ends, as
+// they would in InnoDB.
+type fakeDB struct {
+	mu        sync.Mutex
+	locks     map[string]*sync.Mutex
+	fail      map[string]bool // Operations that return an error, e.g. "products.UpdateStock"
+	nextID    uint
+	commits   int
+	rollbacks int
+
+	products     map[uint]models.Product
+	transactions map[uint]models.Transaction
+	items        map[uint]models.TransactionItem // Lines of open orders, stored apart from the order like in MySQL
+	tables       map[uint]models.Table
+	sequences    map[string]int
+	vouchers     map[uint]models.Voucher
+	redemptions  map[uint]models.VoucherRedemption
+	tickets      map[uint]models.KitchenTicket
+	variants     map[uint]models.ProductVariant
+	ingredients  map[uint]models.Ingredient
+	recipes      []models.RecipeItem
+	usages       []models.TransactionItemIngredient
+	movements    []models.StockMovement
+	stockCounts  map[uint]models.StockCount
+	alerts       map[uint]models.StockAlert
+	waste        map[uint]models.WasteEntry
+	orders       map[uint]models.PurchaseOrder
+}
+
+func newFakeDB() *fakeDB {
+	return &fakeDB{
+		locks:        make(map[string]*sync.Mutex),
+		fail:         make(map[string]bool),
+		products:     make(map[uint]models.Product),
+		transactions: make(map[uint]models.Transaction),
+		items:        make(map[uint]models.TransactionItem),
+		tables:       make(map[uint]models.Table),
+		sequences:    make(map[string]int),
+		vouchers:     make(map[uint]models.Voucher),
+		redemptions:  make(map[uint]models.VoucherRedemption),
+		tickets:      make(map[uint]models.KitchenTicket),
+		variants:     make(map[uint]models.ProductVariant),
+		ingredients:  make(map[uint]models.Ingredient),
+		stockCounts:  make(map[uint]models.StockCount),
+		alerts:       make(map[uint]models.StockAlert),
+		waste:        make(map[uint]models.WasteEntry),
+		orders:       make(map[uint]models.PurchaseOrder),
+	}
+}
+
+// open returns a gorm handle whose transactions run on the fake
+func (f *fakeDB) open(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(fakeDialector{db: f}, &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open fake database: %v", err)
+	}
+	return db
+}
+
+// id hands out the next primary key
+func (f *fakeDB) id() uint {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextID++
+	return f.nextID
+}
+
+// failing reports whether op was set up to fail
+func (f *fakeDB) failing(op string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.fail[op] {
+		return fmt.Errorf("%s failed", op)
+	}
+	return nil
+}
+
+// write applies change now, or when tx commits. It yields first so that
+// concurrent transactions get the chance to interleave.
+func (f *fakeDB) write(tx *fakeTx, change func()) {
+	time.Sleep(time.Millisecond)
+	if tx == nil {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		change()
+		return
+	}
+	tx.writes = append(tx.writes, change)
+}
+
+// read runs fn on the committed rows
+func (f *fakeDB) read(fn func()) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	fn()
+}
+
+// itemsOf returns the lines of an order as tx sees them: the committed lines
+// with the ones tx has written over them, in ID order
+func (f *fakeDB) itemsOf(tx *fakeTx, transactionID uint) []models.TransactionItem {
+	lines := make(map[uint]models.TransactionItem)
+	f.read(func() {
+		for id, item := range f.items {
+			lines[id] = item
+		}
+	})
+	if tx != nil {
+		for id, item := range tx.items {
+			lines[id] = item
+		}
+	}
+
+	var items []models.TransactionItem
+	for _, item := range lines {
+		if item.TransactionID == transactionID {
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+	return items
+}
+
+// lock takes the row lock of key for tx until it ends
+func (f *fakeDB) lock(tx *fakeTx, key string) {
+	if tx == nil || tx.held[key] {
+		return
+	}
+	f.mu.Lock()
+	row, ok := f.locks[key]
+	if !ok {
+		row = &sync.Mutex{}
+		f.locks[key] = row
+	}
+	f.mu.Unlock()
+
+	row.Lock()
+	tx.held[key] = true
+}
+
+type fakeDialector struct {
+	db *fakeDB
+}
+
+func (d fakeDialector) Name() string { return "fake" }
+
+func (d fakeDialector) Initialize(db *gorm.DB) error {
+	db.ConnPool = &fakeConnPool{db: d.db}
+	return nil
+}
+
+func (d fakeDialector) Migrator(db *gorm.DB) gorm.Migrator { return nil }
+
+func (d fakeDialector) DataTypeOf(field *schema.Field) string { return "" }
+
+func (d fakeDialector) DefaultValueOf(field *schema.Field) clause.Expression { return nil }
+
+func (d fakeDialector) BindVarTo(writer clause.Writer, stmt *gorm.Statement, v interface{}) {
+	writer.WriteByte('?')
+}
+
+func (d fakeDialector) QuoteTo(writer clause.Writer, str string) { writer.WriteString(str) }
+
+func (d fakeDialector) Explain(sql string, vars ...interface{}) string { return sql }
+
+// fakeConnPool only begins transactions; running SQL on it panics through
+// the nil ConnPool, since the fake repositories never do
+type fakeConnPool struct {
+	gorm.ConnPool
+	db *fakeDB
+}
+
+func (p *fakeConnPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	return &fakeTx{db: p.db, held: make(map[string]bool)}, nil
+}
+
+type fakeTx struct {
+	gorm.ConnPool
+	db     *fakeDB
+	writes []func()
+	held   map[string]bool
+	items  map[uint]models.TransactionItem // Order lines written so far, which the transaction reads back
+}
+
+func (tx *fakeTx) Commit() error {
+	tx.db.mu.Lock()
+	for _, change := range tx.writes {
+		change()
+	}
+	tx.db.commits++
+	tx.db.mu.Unlock()
+	tx.release()
+	return nil
+}
+
+func (tx *fakeTx) Rollback() error {
+	tx.db.mu.Lock()
+	tx.db.rollbacks++
+	tx.db.mu.Unlock()
+	tx.release()
+	return nil
+}
+
+func (tx *fakeTx) release() {
+	tx.db.mu.Lock()
+	defer tx.db.mu.Unlock()
+	for key := range tx.held {
+		tx.db.locks[key].Unlock()
+	}
+	tx.held = nil
+}
+
+// fakeTxOf returns the fake transaction a repository is bound to with WithTx
+func fakeTxOf(db *gorm.DB) *fakeTx {
+	tx, _ := db.Statement.ConnPool.(*fakeTx)
+	return tx
+}
+
+type fakeProductRepository struct {
+	repositories.ProductRepository
+	db *fakeDB
+	tx *fakeTx
+}
+
+func (r *fakeProductRepository) WithTx(tx *gorm.DB) repositories.ProductRepository {
+	return &fakeProductRepository{db: r.db, tx: fakeTxOf(tx)}
+}
+
+func (r *fakeProductRepository) FindAll() ([]models.Product, error) {
+	var products []models.Product
+	r.db.read(func() {
+		for _, product := range r.db.products {
+			products = append(products, product)
+		}
+	})
+	sort.Slice(products, func(i, j int) bool { return products[i].ID < products[j].ID })
+	return products, nil
+}
+
+func (r *fakeProductRepository) FindByID(id uint) (*models.Product, error) {
+	var product models.Product
+	var ok bool
+	r.db.read(func() { product, ok = r.db.products[id] })
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	return &product, nil
+}
+
+func (r *fakeProductRepository) FindStocked() ([]models.Product, error) {
+	var products []models.Product
+	r.db.read(func() {
+		made := make(map[uint]bool)
+		for _, recipe := range r.db.recipes {
+			if recipe.ProductID != nil {
+				made[*recipe.ProductID] = true
+			}
+		}
+		for _, product := range r.db.products {
+			if !made[product.ID] {
+				products = append(products, product)
+			}
+		}
+	})
+	sort.Slice(products, func(i, j int) bool { return products[i].Name < products[j].Name })
+	return products, nil
+}
+
+func (r *fakeProductRepository) FindByIDsForUpdate(ids []uint) ([]models.Product, error) {
+	if err := r.db.failing("products.FindByIDsForUpdate"); err != nil {
+		return nil, err
+	}
+
+	var products []models.Product
+	for _, id := range ids {
+		r.db.lock(r.tx, fmt.Sprintf("products/%d", id))
+		r.db.read(func() {
+			if product, ok := r.db.products[id]; ok {
+				products = append(products, product)
+			}
+		})
+	}
+	return products, nil
+}
+
+func (r *fakeProductRepository) UpdateCost(id uint, cost models.Money) error {
+	r.db.write(r.tx, func() {
+		product := r.db.products[id]
+		product.Cost = cost
+		r.db.products[id] = product
+	})
+	return nil
+}
+
+func (r *fakeProductRepository) UpdateStock(id uint, stock int) error {
+	if err := r.db.failing("products.UpdateStock"); err != nil {
+		return err
+	}
+
+	r.db.write(r.tx, func() {
+		product := r.db.products[id]
+		product.Stock = stock
+		r.db.products[id] = product
+	})
+	return nil
+}
+
+type fakeProductVariantRepository struct {
+	repositories.ProductVariantRepository
+	db *fakeDB
+	tx *fakeTx
+}
+
+func (r *fakeProductVariantRepository) WithTx(tx *gorm.DB) repositories.ProductVariantRepository {
+	return &fakeProductVariantRepository{db: r.db, tx: fakeTxOf(tx)}
+}
+
+func (r *fakeProductVariantRepository) FindByID(id uint) (*models.ProductVariant, error) {
+	var variant models.ProductVariant
+	var ok bool
+	r.db.read(func() { variant, ok = r.db.variants[id] })
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	return &variant, nil
+}
+
+func (r *fakeProductVariantRepository) FindTrackedByProductIDs(productIDs []uint) ([]models.ProductVariant, error) {
+	var variants []models.ProductVariant
+	r.db.read(func() {
+		for _, productID := range productIDs {
+			for _, variant := range r.db.variants {
+				if variant.ProductID == productID && variant.TrackStock {
+					variants = append(variants, variant)
+				}
+			}
+		}
+	})
+	return variants, nil
+}
+
+func (r *fakeProductVariantRepository) FindByIDsForUpdate(ids []uint) ([]models.ProductVariant, error) {
+	var variants []models.ProductVariant
+	for _, id := range ids {
+		r.db.lock(r.tx, fmt.Sprintf("product_variants/%d", id))
+		r.db.read(func() {
+			if variant, ok := r.db.variants[id]; ok {
+				variants = append(variants, variant)
+			}
+		})
+	}
+	return variants, nil
+}
+
+func (r *fakeProductVariantRepository) UpdateStock(id uint, stock int) error {
+	r.db.write(r.tx, func() {
+		variant := r.db.variants[id]
+		variant.Stock = stock
+		r.db.variants[id] = variant
+	})
+	return nil
+}
+
+// fakeModifierRepository offers no modifiers, so lines are sold plain
+type fakeModifierRepository struct {
+	repositories.ModifierRepository
+}
+
+func (r *fakeModifierRepository) FindForProduct(productID, categoryID uint) ([]models.ModifierGroup, error) {
+	return nil, nil
+}
+
+// fakeIngredientRepository keeps ingredients and recipes in the fake database
+type fakeIngredientRepository struct {
+	repositories.IngredientRepository
+	db *fakeDB
+	tx *fakeTx
+}
+
+func (r *fakeIngredientRepository) WithTx(tx *gorm.DB) repositories.IngredientRepository {
+	return &fakeIngredientRepository{db: r.db, tx: fakeTxOf(tx)}
+}
+
+func (r *fakeIngredientRepository) FindByIDsForUpdate(ids []uint) ([]models.Ingredient, error) {
+	var ingredients []models.Ingredient
+	for _, id := range ids {
+		r.db.lock(r.tx, fmt.Sprintf("ingredients/%d", id))
+		r.db.read(func() {
+			if ingredient, ok := r.db.ingredients[id]; ok {
+				ingredients = append(ingredients, ingredient)
+			}
+		})
+	}
+	return ingredients, nil
+}
+
+func (r *fakeIngredientRepository) UpdateStock(id uint, stock float64) error {
+	r.db.write(r.tx, func() {
+		ingredient := r.db.ingredients[id]
+		ingredient.Stock = stock
+		r.db.ingredients[id] = ingredient
+	})
+	return nil
+}
+
+func (r *fakeIngredientRepository) FindRecipes(productIDs, variantIDs, modifierOptionIDs []uint) ([]models.RecipeItem, error) {
+	contains := func(ids []uint, id *uint) bool {
+		for _, candidate := range ids {
+			if id != nil && *id == candidate {
+				return true
+			}
+		}
+		return false
+	}
+
+	var recipes []models.RecipeItem
+	r.db.read(func() {
+		for _, recipe := range r.db.recipes {
+			if contains(productIDs, recipe.ProductID) || contains(variantIDs, recipe.VariantID) || contains(modifierOptionIDs, recipe.ModifierOptionID) {
+				recipes = append(recipes, recipe)
+			}
+		}
+	})
+	return recipes, nil
+}
+
+func (r *fakeIngredientRepository) CreateUsage(usages []models.TransactionItemIngredient) error {
+	stored := append([]models.TransactionItemIngredient(nil), usages...)
+	r.db.write(r.tx, func() { r.db.usages = append(r.db.usages, stored...) })
+	return nil
+}
+
+func (r *fakeIngredientRepository) FindUsageByItemIDs(itemIDs []uint) ([]models.TransactionItemIngredient, error) {
+	var usages []models.TransactionItemIngredient
+	r.db.read(func() {
+		for _, usage := range r.db.usages {
+			for _, id := range itemIDs {
+				if usage.TransactionItemID == id {
+					usages = append(usages, usage)
+				}
+			}
+		}
+	})
+	return usages, nil
+}
+
+type fakeStockMovementRepository struct {
+	repositories.StockMovementRepository
+	db *fakeDB
+	tx *fakeTx
+}
+
+func (r *fakeStockMovementRepository) WithTx(tx *gorm.DB) repositories.StockMovementRepository {
+	return &fakeStockMovementRepository{db: r.db, tx: fakeTxOf(tx)}
+}
+
+func (r *fakeStockMovementRepository) Create(movements []models.StockMovement) error {
+	for i := range movements {
+		movements[i].ID = r.db.id()
+	}
+	stored := append([]models.StockMovement(nil), movements...)
+	r.db.write(r.tx, func() { r.db.movements = append(r.db.movements, stored...) })
+	return nil
+}
+
+// fakeStockAlertRepository stores stock alerts
+type fakeStockAlertRepository struct {
+	repositories.StockAlertRepository
+	db *fakeDB
+	tx *fakeTx
+}
+
+func (r *fakeStockAlertRepository) WithTx(tx *gorm.DB) repositories.StockAlertRepository {
+	return &fakeStockAlertRepository{db: r.db, tx: fakeTxOf(tx)}
+}
+
+func (r *fakeStockAlertRepository) Create(alerts []models.StockAlert) error {
+	for i := range alerts {
+		alerts[i].ID = r.db.id()
+	}
+	stored := append([]models.StockAlert(nil), alerts...)
+	r.db.write(r.tx, func() {
+		for _, alert := range stored {
+			r.db.alerts[alert.ID] = alert
+		}
+	})
+	return nil
+}
+
+func (r *fakeStockAlertRepository) FindUnnotified() ([]models.StockAlert, error) {
+	var alerts []models.StockAlert
+	r.db.read(func() {
+		for _, alert := range r.db.alerts {
+			if alert.NotifiedAt == nil {
+				alerts = append(alerts, alert)
+			}
+		}
+	})
+	return alerts, nil
+}
+
+func (r *fakeStockAlertRepository) MarkNotified(id uint, at time.Time) (bool, error) {
+	claimed := false
+	r.db.read(func() {
+		alert := r.db.alerts[id]
+		if alert.NotifiedAt == nil {
+			alert.NotifiedAt = &at
+			r.db.alerts[id] = alert
+			claimed = true
+		}
+	})
+	return claimed, nil
+}
+
+// newTestStockService returns a stock service whose alerts go to a fresh
+// event hub
+func newTestStockService(db *fakeDB) *StockService {
+	productRepo := &fakeProductRepository{db: db}
+	return NewStockService(
+		nil,
+		productRepo,
+		&fakeProductVariantRepository{db: db},
+		&fakeIngredientRepository{db: db},
+		&fakeStockMovementRepository{db: db},
+		NewStockAlertService(&fakeStockAlertRepository{db: db}, productRepo, newTestSettingService(nil), NewEventHub()),
+	)
+}
+
+type fakeTransactionRepository struct {
+	repositories.TransactionRepository
+	db *fakeDB
+	tx *fakeTx
+}
+
+func (r *fakeTransactionRepository) WithTx(tx *gorm.DB) repositories.TransactionRepository {
+	return &fakeTransactionRepository{db: r.db, tx: fakeTxOf(tx)}
+}
+
+func (r *fakeTransactionRepository) FindByIDForUpdate(id uint) (*models.Transaction, error) {
+	r.db.lock(r.tx, fmt.Sprintf("transactions/%d", id))
+
+	var transaction models.Transaction
+	var ok bool
+	r.db.read(func() { transaction, ok = r.db.transactions[id] })
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	if items := r.db.itemsOf(r.tx, id); len(items) > 0 {
+		transaction.Items = items
+	}
+	return &transaction, nil
+}
+
+func (r *fakeTransactionRepository) Create(transaction *models.Transaction) error {
+	if err := r.db.failing("transactions.Create"); err != nil {
+		return err
+	}
+
+	transaction.ID = r.db.id()
+	for i := range transaction.Items {
+		transaction.Items[i].ID = r.db.id()
+		transaction.Items[i].TransactionID = transaction.ID
+	}
+	stored := *transaction
+	r.db.write(r.tx, func() { r.db.transactions[stored.ID] = stored })
+	return nil
+}
+
+func (r *fakeTransactionRepository) Update(transaction *models.Transaction) error {
+	if err := r.db.failing("transactions.Update"); err != nil {
+		return err
+	}
+
+	stored := *transaction
+	r.db.write(r.tx, func() { r.db.transactions[stored.ID] = stored })
+	return nil
+}
+
+func (r *fakeTransactionRepository) FindByID(id uint) (*models.Transaction, error) {
+	var transaction models.Transaction
+	var ok bool
+	r.db.read(func() { transaction, ok = r.db.transactions[id] })
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	return &transaction, nil
+}
+
+func (r *fakeTransactionRepository) Delete(id uint) error {
+	r.db.write(r.tx, func() { delete(r.db.transactions, id) })
+	return nil
+}
+
+func (r *fakeTransactionRepository) CountUnpaidChildren(parentID uint) (int64, error) {
+	var count int64
+	r.db.read(func() {
+		for _, transaction := range r.db.transactions {
+			if transaction.ParentID != nil && *transaction.ParentID == parentID && transaction.Status == "unpaid" {
+				count++
+			}
+		}
+	})
+	return count, nil
+}
+
+// CreateDiscounts only hands out IDs; the tests read discounts from the
+// transaction they were made for
+func (r *fakeTransactionRepository) CreateDiscounts(discounts []models.TransactionDiscount) error {
+	for i := range discounts {
+		discounts[i].ID = r.db.id()
+	}
+	return nil
+}
+
+// FindQueueBoard shows an empty board; no test reads it
+func (r *fakeTransactionRepository) FindQueueBoard(since time.Time) ([]models.Transaction, error) {
+	return nil, nil
+}
+
+type fakeTableRepository struct {
+	repositories.TableRepository
+	db *fakeDB
+	tx *fakeTx
+}
+
+func (r *fakeTableRepository) WithTx(tx *gorm.DB) repositories.TableRepository {
+	return &fakeTableRepository{db: r.db, tx: fakeTxOf(tx)}
+}
+
+func (r *fakeTableRepository) FindByIDsForUpdate(ids []uint) ([]models.Table, error) {
+	var tables []models.Table
+	for _, id := range ids {
+		r.db.lock(r.tx, fmt.Sprintf("tables/%d", id))
+		r.db.read(func() {
+			if table, ok := r.db.tables[id]; ok {
+				tables = append(tables, table)
+			}
+		})
+	}
+	return tables, nil
+}
+
+func (r *fakeTableRepository) UpdateStatus(id uint, status string) error {
+	r.db.write(r.tx, func() {
+		table := r.db.tables[id]
+		table.Status = status
+		r.db.tables[id] = table
+	})
+	return nil
+}
+
+// fakeSettingRepository serves settings from a map; the other repository
+// methods are not used by the tests and panic through the nil interface.
+type fakeSettingRepository struct {
+	repositories.SettingRepository
+	values map[string]string
+}
+
+func (r *fakeSettingRepository) FindByKey(key string) (*models.Setting, error) {
+	value, ok := r.values[key]
+	if !ok {
+		return nil, errors.New("setting not found")
+	}
+	return &models.Setting{Key: key, Value: value}, nil
+}
+
+func newTestSettingService(values map[string]string) *SettingService {
+	return NewSettingService(&fakeSettingRepository{values: values})
+}
+
+// fakeSequenceRepository keeps one counter per name, outlet and business
+// day, like the unique index of the sequences table
+type fakeSequenceRepository struct {
+	repositories.SequenceRepository
+	db *fakeDB
+	tx *fakeTx
+}
+
+func (r *fakeSequenceRepository) WithTx(tx *gorm.DB) repositories.SequenceRepository {
+	return &fakeSequenceRepository{db: r.db, tx: fakeTxOf(tx)}
+}
+
+func (r *fakeSequenceRepository) Next(name, outletCode, businessDate string) (int, error) {
+	key := name + "/" + outletCode + "/" + businessDate
+	r.db.lock(r.tx, "sequences/"+key)
+
+	var number int
+	r.db.read(func() { number = r.db.sequences[key] + 1 })
+	r.db.write(r.tx, func() { r.db.sequences[key] = number })
+	return number, nil
+}
+
+func newTestSequenceService(db *fakeDB, settings map[string]string) *SequenceService {
+	return NewSequenceService(&fakeSequenceRepository{db: db}, newTestSettingService(settings))
+}
+
+// fakePromotionRepository serves a fixed list of active promotions
+type fakePromotionRepository struct {
+	repositories.PromotionRepository
+	promotions []models.Promotion
+}
+
+func (r *fakePromotionRepository) FindActive(now time.Time) ([]models.Promotion, error) {
+	return r.promotions, nil
+}
+
+type fakeVoucherRepository struct {
+	repositories.VoucherRepository
+	db *fakeDB
+	tx *fakeTx
+}
+
+func (r *fakeVoucherRepository) WithTx(tx *gorm.DB) repositories.VoucherRepository {
+	return &fakeVoucherRepository{db: r.db, tx: fakeTxOf(tx)}
+}
+
+func (r *fakeVoucherRepository) FindByCodeForUpdate(code string) (*models.Voucher, error) {
+	var id uint
+	r.db.read(func() {
+		for _, voucher := range r.db.vouchers {
+			if voucher.Code == code {
+				id = voucher.ID
+			}
+		}
+	})
+	if id == 0 {
+		return nil, errors.New("record not found")
+	}
+
+	r.db.lock(r.tx, fmt.Sprintf("vouchers/%d", id))
+	var voucher models.Voucher
+	r.db.read(func() { voucher = r.db.vouchers[id] })
+	return &voucher, nil
+}
+
+func (r *fakeVoucherRepository) AddUsage(id uint, delta int) error {
+	r.db.write(r.tx, func() {
+		voucher := r.db.vouchers[id]
+		voucher.UsedCount += delta
+		r.db.vouchers[id] = voucher
+	})
+	return nil
+}
+
+func (r *fakeVoucherRepository) CreateRedemption(redemption *models.VoucherRedemption) error {
+	redemption.ID = r.db.id()
+	stored := *redemption
+	r.db.write(r.tx, func() { r.db.redemptions[stored.ID] = stored })
+	return nil
+}
+
+func (r *fakeVoucherRepository) FindRedemptionsByTransactionID(transactionID uint) ([]models.VoucherRedemption, error) {
+	var redemptions []models.VoucherRedemption
+	r.db.read(func() {
+		for _, redemption := range r.db.redemptions {
+			if redemption.TransactionID == transactionID {
+				redemptions = append(redemptions, redemption)
+			}
+		}
+	})
+	return redemptions, nil
+}
+
+func (r *fakeVoucherRepository) DeleteRedemptionsByTransactionID(transactionID uint) error {
+	r.db.write(r.tx, func() {
+		for id, redemption := range r.db.redemptions {
+			if redemption.TransactionID == transactionID {
+				delete(r.db.redemptions, id)
+			}
+		}
+	})
+	return nil
+}
+
+func (r *fakeVoucherRepository) CountRedemptionsByCustomer(voucherID uint, customerRef string) (int64, error) {
+	var count int64
+	r.db.read(func() {
+		for _, redemption := range r.db.redemptions {
+			if redemption.VoucherID == voucherID && redemption.CustomerRef == customerRef {
+				count++
+			}
+		}
+	})
+	return count, nil
+}
+
+// fakeKitchenTicketRepository routes every product to the default station
+type fakeKitchenTicketRepository struct {
+	repositories.KitchenTicketRepository
+	db *fakeDB
+	tx *fakeTx
+}
+
+func (r *fakeKitchenTicketRepository) WithTx(tx *gorm.DB) repositories.KitchenTicketRepository {
+	return &fakeKitchenTicketRepository{db: r.db, tx: fakeTxOf(tx)}
+}
+
+func (r *fakeKitchenTicketRepository) FindStations(productIDs []uint) (map[uint]string, error) {
+	return map[uint]string{}, nil
+}
+
+func (r *fakeKitchenTicketRepository) CreateBatch(tickets []models.KitchenTicket) error {
+	for i := range tickets {
+		tickets[i].ID = r.db.id()
+		stored := tickets[i]
+		r.db.write(r.tx, func() { r.db.tickets[stored.ID] = stored })
+	}
+	return nil
+}
+
+func (r *fakeKitchenTicketRepository) CancelByTransactionID(transactionID uint) error {
+	r.db.write(r.tx, func() {
+		for id, ticket := range r.db.tickets {
+			if ticket.TransactionID == transactionID && ticket.Status != "served" {
+				ticket.Status = "cancelled"
+				r.db.tickets[id] = ticket
+			}
+		}
+	})
+	return nil
+}
+
+func (r *fakeKitchenTicketRepository) MoveToTransaction(fromTransactionID, toTransactionID uint, transactionCode string, tableID *uint) error {
+	r.db.write(r.tx, func() {
+		for id, ticket := range r.db.tickets {
+			if ticket.TransactionID == fromTransactionID {
+				ticket.TransactionID = toTransactionID
+				ticket.TransactionCode = transactionCode
+				ticket.TableID = tableID
+				r.db.tickets[id] = ticket
+			}
+		}
+	})
+	return nil
+}
+
+func (r *fakeKitchenTicketRepository) FindByTransactionID(transactionID uint) ([]models.KitchenTicket, error) {
+	var tickets []models.KitchenTicket
+	r.db.read(func() {
+		for _, ticket := range r.db.tickets {
+			if ticket.TransactionID == transactionID {
+				tickets = append(tickets, ticket)
+			}
+		}
+	})
+	return tickets, nil
+}
+
+// fakeStockCountRepository keeps stock counts, with their items, in the fake database
+type fakeStockCountRepository struct {
+	repositories.StockCountRepository
+	db *fakeDB
+	tx *fakeTx
+}
+
+func (r *fakeStockCountRepository) WithTx(tx *gorm.DB) repositories.StockCountRepository {
+	return &fakeStockCountRepository{db: r.db, tx: fakeTxOf(tx)}
+}
+
+func (r *fakeStockCountRepository) FindByID(id uint) (*models.StockCount, error) {
+	var count models.StockCount
+	var ok bool
+	r.db.read(func() {
+		count, ok = r.db.stockCounts[id]
+		count.Items = append([]models.StockCountItem(nil), count.Items...)
+	})
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	return &count, nil
+}
+
+func (r *fakeStockCountRepository) FindByIDForUpdate(id uint) (*models.StockCount, error) {
+	r.db.lock(r.tx, fmt.Sprintf("stock_counts/%d", id))
+	return r.FindByID(id)
+}
+
+func (r *fakeStockCountRepository) CountByStatus(status string) (int64, error) {
+	var count int64
+	r.db.read(func() {
+		for _, stockCount := range r.db.stockCounts {
+			if stockCount.Status == status {
+				count++
+			}
+		}
+	})
+	return count, nil
+}
+
+func (r *fakeStockCountRepository) Create(count *models.StockCount) error {
+	count.ID = r.db.id()
+	for i := range count.Items {
+		count.Items[i].ID = r.db.id()
+		count.Items[i].StockCountID = count.ID
+	}
+	stored := *count
+	stored.Items = append([]models.StockCountItem(nil), count.Items...)
+	r.db.write(r.tx, func() { r.db.stockCounts[stored.ID] = stored })
+	return nil
+}
+
+func (r *fakeStockCountRepository) Update(count *models.StockCount) error {
+	updated := *count
+	r.db.write(r.tx, func() {
+		updated.Items = r.db.stockCounts[updated.ID].Items
+		r.db.stockCounts[updated.ID] = updated
+	})
+	return nil
+}
+
+func (r *fakeStockCountRepository) UpdateItems(items []models.StockCountItem) error {
+	changed := append([]models.StockCountItem(nil), items...)
+	r.db.write(r.tx, func() {
+		for _, item := range changed {
+			count := r.db.stockCounts[item.StockCountID]
+			for i := range count.Items {
+				if count.Items[i].ID == item.ID {
+					count.Items[i] = item
+				}
+			}
+			r.db.stockCounts[count.ID] = count
+		}
+	})
+	return nil
+}
+
+type fakeSupplierRepository struct {
+	repositories.SupplierRepository
+	suppliers []models.Supplier
+}
+
+func (r *fakeSupplierRepository) FindAll() ([]models.Supplier, error) {
+	return r.suppliers, nil
+}
+
+func (r *fakeSupplierRepository) FindByID(id uint) (*models.Supplier, error) {
+	for _, supplier := range r.suppliers {
+		if supplier.ID == id {
+			return &supplier, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+// fakeTransactionItemRepository keeps the lines of open orders in the fake
+// database and serves fixed sold quantities to the reorder report
+type fakeTransactionItemRepository struct {
+	repositories.TransactionItemRepository
+	db   *fakeDB
+	tx   *fakeTx
+	sold []dto.ProductQuantityData
+}
+
+func (r *fakeTransactionItemRepository) WithTx(tx *gorm.DB) repositories.TransactionItemRepository {
+	return &fakeTransactionItemRepository{db: r.db, tx: fakeTxOf(tx), sold: r.sold}
+}
+
+// save stores item when the transaction commits, and lets it read the item back before then
+func (r *fakeTransactionItemRepository) save(item models.TransactionItem) {
+	if r.tx != nil {
+		if r.tx.items == nil {
+			r.tx.items = make(map[uint]models.TransactionItem)
+		}
+		r.tx.items[item.ID] = item
+	}
+	r.db.write(r.tx, func() { r.db.items[item.ID] = item })
+}
+
+func (r *fakeTransactionItemRepository) FindByTransactionID(transactionID uint) ([]models.TransactionItem, error) {
+	return r.db.itemsOf(r.tx, transactionID), nil
+}
+
+func (r *fakeTransactionItemRepository) CreateBatch(items []models.TransactionItem) error {
+	for i := range items {
+		items[i].ID = r.db.id()
+		r.save(items[i])
+	}
+	return nil
+}
+
+func (r *fakeTransactionItemRepository) Update(item *models.TransactionItem) error {
+	r.save(*item)
+	return nil
+}
+
+// ReplaceModifiers has nothing to do; the modifiers are saved with the line
+func (r *fakeTransactionItemRepository) ReplaceModifiers(itemID uint, modifiers []models.TransactionItemModifier) error {
+	return nil
+}
+
+func (r *fakeTransactionItemRepository) MoveToTransaction(fromTransactionID, toTransactionID uint, roundOffset int) error {
+	for _, item := range r.db.itemsOf(r.tx, fromTransactionID) {
+		item.TransactionID = toTransactionID
+		item.Round += roundOffset
+		r.save(item)
+	}
+	return nil
+}
+
+func (r *fakeTransactionItemRepository) GetSoldQuantities(startDate, endDate time.Time) ([]dto.ProductQuantityData, error) {
+	return r.sold, nil
+}
+
+// fakeReportRepository serves fixed sales totals to the reports
+type fakeReportRepository struct {
+	repositories.TransactionRepository
+	orderTypes []dto.OrderTypeSummaryData
+}
+
+func (r *fakeReportRepository) GetOrderTypeSummary(startDate, endDate time.Time) ([]dto.OrderTypeSummaryData, error) {
+	return r.orderTypes, nil
+}
+
+// fakePurchaseOrderRepository keeps purchase orders, with their items and
+// receipts, in the fake database and serves fixed on-order quantities
+type fakePurchaseOrderRepository struct {
+	repositories.PurchaseOrderRepository
+	db      *fakeDB
+	tx      *fakeTx
+	onOrder []dto.ProductQuantityData
+}
+
+func (r *fakePurchaseOrderRepository) WithTx(tx *gorm.DB) repositories.PurchaseOrderRepository {
+	return &fakePurchaseOrderRepository{db: r.db, tx: fakeTxOf(tx), onOrder: r.onOrder}
+}
+
+func (r *fakePurchaseOrderRepository) FindByID(id uint) (*models.PurchaseOrder, error) {
+	var order models.PurchaseOrder
+	var ok bool
+	r.db.read(func() {
+		order, ok = r.db.orders[id]
+		order.Items = append([]models.PurchaseOrderItem(nil), order.Items...)
+		order.Receipts = append([]models.GoodsReceipt(nil), order.Receipts...)
+	})
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	return &order, nil
+}
+
+func (r *fakePurchaseOrderRepository) FindByIDForUpdate(id uint) (*models.PurchaseOrder, error) {
+	r.db.lock(r.tx, fmt.Sprintf("purchase_orders/%d", id))
+	return r.FindByID(id)
+}
+
+func (r *fakePurchaseOrderRepository) Update(order *models.PurchaseOrder) error {
+	updated := *order
+	r.db.write(r.tx, func() {
+		stored := r.db.orders[updated.ID]
+		updated.Items, updated.Receipts = stored.Items, stored.Receipts
+		r.db.orders[updated.ID] = updated
+	})
+	return nil
+}
+
+func (r *fakePurchaseOrderRepository) UpdateItem(item *models.PurchaseOrderItem) error {
+	updated := *item
+	r.db.write(r.tx, func() {
+		order := r.db.orders[updated.PurchaseOrderID]
+		for i := range order.Items {
+			if order.Items[i].ID == updated.ID {
+				order.Items[i] = updated
+			}
+		}
+		r.db.orders[order.ID] = order
+	})
+	return nil
+}
+
+func (r *fakePurchaseOrderRepository) CreateReceipt(receipt *models.GoodsReceipt) error {
+	receipt.ID = r.db.id()
+	stored := *receipt
+	r.db.write(r.tx, func() {
+		order := r.db.orders[stored.PurchaseOrderID]
+		order.Receipts = append(order.Receipts, stored)
+		r.db.orders[order.ID] = order
+	})
+	return nil
+}
+
+func (r *fakePurchaseOrderRepository) GetOnOrderQuantities() ([]dto.ProductQuantityData, error) {
+	return r.onOrder, nil
+}
+
+// fakeWasteRepository keeps waste entries, with their ingredients, in the fake database
+type fakeWasteRepository struct {
+	repositories.WasteRepository
+	db *fakeDB
+	tx *fakeTx
+}
+
+func (r *fakeWasteRepository) WithTx(tx *gorm.DB) repositories.WasteRepository {
+	return &fakeWasteRepository{db: r.db, tx: fakeTxOf(tx)}
+}
+
+func (r *fakeWasteRepository) Create(entry *models.WasteEntry) error {
+	entry.ID = r.db.id()
+	for i := range entry.Ingredients {
+		entry.Ingredients[i].ID = r.db.id()
+		entry.Ingredients[i].WasteEntryID = entry.ID
+	}
+	stored := *entry
+	stored.Ingredients = append([]models.WasteEntryIngredient(nil), entry.Ingredients...)
+	r.db.write(r.tx, func() { r.db.waste[stored.ID] = stored })
+	return nil
+}
+
+func (r *fakeWasteRepository) FindByID(id uint) (*models.WasteEntry, error) {
+	var entry models.WasteEntry
+	var ok bool
+	r.db.read(func() { entry, ok = r.db.waste[id] })
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	return &entry, nil
+}
@@ -0,0 +1,297 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/models"
+	"github.com/syrlramadhan/cashier-app/repositories"
+	"gorm.io/gorm"
+)
+
+// HeldOrderService parks carts (status "held") so the cashier can serve the
+// next customer. Stock is only taken when the order is finalized.
+type HeldOrderService struct {
+	db                  *gorm.DB
+	transactionRepo     repositories.TransactionRepository
+	transactionItemRepo repositories.TransactionItemRepository
+	sequenceService     *SequenceService
+	settingService      *SettingService
+	transactionService  *TransactionService
+	kitchenService      *KitchenService
+	stockService        *StockService
+}
+
+func NewHeldOrderService(
+	db *gorm.DB,
+	transactionRepo repositories.TransactionRepository,
+	transactionItemRepo repositories.TransactionItemRepository,
+	sequenceService *SequenceService,
+	settingService *SettingService,
+	transactionService *TransactionService,
+	kitchenService *KitchenService,
+	stockService *StockService,
+) *HeldOrderService {
+	return &HeldOrderService{
+		db:                  db,
+		transactionRepo:     transactionRepo,
+		transactionItemRepo: transactionItemRepo,
+		sequenceService:     sequenceService,
+		settingService:      settingService,
+		transactionService:  transactionService,
+		kitchenService:      kitchenService,
+		stockService:        stockService,
+	}
+}
+
+// GetHeldOrders lists the parked orders of the cashier; managers and admins see every cashier's
+func (s *HeldOrderService) GetHeldOrders(userID uint, role string) ([]dto.TransactionResponse, error) {
+	if err := s.expireHeldOrders(); err != nil {
+		return nil, err
+	}
+
+	if role == "admin" || role == "manager" {
+		userID = 0
+	}
+
+	orders, err := s.transactionRepo.FindHeld(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var response []dto.TransactionResponse
+	for _, order := range orders {
+		response = append(response, toTransactionResponse(&order))
+	}
+
+	return response, nil
+}
+
+func (s *HeldOrderService) GetHeldOrder(id, userID uint, role string) (*dto.TransactionResponse, error) {
+	if err := s.expireHeldOrders(); err != nil {
+		return nil, err
+	}
+
+	order, err := s.transactionRepo.FindByIDWithDetails(id)
+	if err != nil {
+		return nil, errors.New("held order not found")
+	}
+
+	if err := checkHeldOrder(order, userID, role); err != nil {
+		return nil, err
+	}
+
+	response := toTransactionResponse(order)
+	return &response, nil
+}
+
+func (s *HeldOrderService) HoldOrder(req *dto.HeldOrderRequest) (*dto.TransactionResponse, error) {
+	if err := s.expireHeldOrders(); err != nil {
+		return nil, err
+	}
+
+	orderType := orderTypeOrDefault(req.OrderType)
+
+	var order *models.Transaction
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		items, err := s.transactionService.orderItems(req.Items, orderType)
+		if err != nil {
+			return err
+		}
+
+		code, err := s.sequenceService.NextCode(tx, "held", "HLD", time.Now())
+		if err != nil {
+			return err
+		}
+
+		order = &models.Transaction{
+			TransactionCode: code,
+			UserID:          req.UserID,
+			OrderType:       orderType,
+			Status:          "held",
+			Note:            req.Note,
+			Items:           items,
+		}
+		s.transactionService.estimateTotals(order)
+
+		if err := s.transactionRepo.WithTx(tx).Create(order); err != nil {
+			return errors.New("failed to hold order")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := toTransactionResponse(order)
+	return &response, nil
+}
+
+// UpdateHeldOrder replaces the lines (and note) of a parked order
+func (s *HeldOrderService) UpdateHeldOrder(id, userID uint, role string, req *dto.HeldOrderRequest) (*dto.TransactionResponse, error) {
+	if err := s.expireHeldOrders(); err != nil {
+		return nil, err
+	}
+
+	var order *models.Transaction
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		transactionRepo := s.transactionRepo.WithTx(tx)
+
+		var err error
+		order, err = transactionRepo.FindByIDForUpdate(id)
+		if err != nil {
+			return errors.New("held order not found")
+		}
+
+		if err := checkHeldOrder(order, userID, role); err != nil {
+			return err
+		}
+
+		if req.OrderType != "" {
+			order.OrderType = req.OrderType
+		}
+
+		items, err := s.transactionService.orderItems(req.Items, orderTypeOrDefault(order.OrderType))
+		if err != nil {
+			return err
+		}
+
+		if err := s.transactionItemRepo.WithTx(tx).DeleteByTransactionID(order.ID); err != nil {
+			return errors.New("failed to update held order")
+		}
+
+		order.Items = items
+		order.Note = req.Note
+		s.transactionService.estimateTotals(order)
+
+		if err := transactionRepo.Update(order); err != nil {
+			return errors.New("failed to update held order")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := toTransactionResponse(order)
+	return &response, nil
+}
+
+// FinalizeHeldOrder turns a parked order into a completed sale, taking the
+// stock and a receipt number at this point.
+func (s *HeldOrderService) FinalizeHeldOrder(id uint, req *dto.FinalizeOrderRequest) (*dto.TransactionResponse, error) {
+	if err := s.expireHeldOrders(); err != nil {
+		return nil, err
+	}
+
+	var transaction *models.Transaction
+	var tickets []models.KitchenTicket
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		order, err := s.transactionRepo.WithTx(tx).FindByIDForUpdate(id)
+		if err != nil {
+			return errors.New("held order not found")
+		}
+
+		if err := checkHeldOrder(order, req.UserID, req.Role); err != nil {
+			return err
+		}
+
+		transaction, err = s.transactionService.checkout(tx, checkoutRequest(order.Items, req), order, "completed")
+		if err != nil {
+			return err
+		}
+
+		// A parked order reaches the kitchen once it is paid
+		tickets, err = s.kitchenService.createTickets(tx, transaction, transaction.Items)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.kitchenService.publish("ticket.created", tickets)
+	s.stockService.publishAlerts()
+
+	response := toTransactionResponse(transaction)
+	return &response, nil
+}
+
+// DeleteHeldOrder discards a parked order
+func (s *HeldOrderService) DeleteHeldOrder(id, userID uint, role string) error {
+	order, err := s.transactionRepo.FindByID(id)
+	if err != nil {
+		return errors.New("held order not found")
+	}
+
+	// Expired orders can be cleared too, but only by their cashier or a manager
+	if order.Status != "held" && order.Status != "expired" {
+		return errors.New("held order not found")
+	}
+	if err := checkHeldOrderOwner(order, userID, role); err != nil {
+		return err
+	}
+
+	return s.transactionRepo.Delete(id)
+}
+
+// expireHeldOrders expires orders parked longer than held_order_expiry_minutes (0 disables)
+func (s *HeldOrderService) expireHeldOrders() error {
+	minutes := s.settingService.GetInt("held_order_expiry_minutes", 120)
+	if minutes <= 0 {
+		return nil
+	}
+
+	before := time.Now().Add(-time.Duration(minutes) * time.Minute)
+	if err := s.transactionRepo.ExpireHeldOrders(before); err != nil {
+		return errors.New("failed to expire held orders")
+	}
+	return nil
+}
+
+// checkHeldOrder makes sure order is still parked and belongs to the cashier
+func checkHeldOrder(order *models.Transaction, userID uint, role string) error {
+	if order.Status == "expired" {
+		return errors.New("held order has expired")
+	}
+	if order.Status != "held" {
+		return errors.New("held order not found")
+	}
+	return checkHeldOrderOwner(order, userID, role)
+}
+
+// checkHeldOrderOwner lets only the cashier who parked the order, or a manager, touch it
+func checkHeldOrderOwner(order *models.Transaction, userID uint, role string) error {
+	if order.UserID != userID && role != "admin" && role != "manager" {
+		return errors.New("held order belongs to another cashier")
+	}
+	return nil
+}
+
+// checkoutRequest builds the checkout of the parked lines with the payment of req
+func checkoutRequest(items []models.TransactionItem, req *dto.FinalizeOrderRequest) *dto.CreateTransactionRequest {
+	checkout := &dto.CreateTransactionRequest{
+		UserID:          req.UserID,
+		Role:            req.Role,
+		PaymentMethod:   req.PaymentMethod,
+		Payments:        req.Payments,
+		AmountTendered:  req.AmountTendered,
+		DiscountPercent: req.DiscountPercent,
+		DiscountAmount:  req.DiscountAmount,
+		VoucherCode:     req.VoucherCode,
+		CustomerRef:     req.CustomerRef,
+	}
+	for _, item := range items {
+		var modifierIDs []uint
+		for _, modifier := range item.Modifiers {
+			modifierIDs = append(modifierIDs, modifier.ModifierOptionID)
+		}
+
+		checkout.Items = append(checkout.Items, dto.TransactionItemRequest{
+			ProductID:   item.ProductID,
+			VariantID:   item.VariantID,
+			ModifierIDs: modifierIDs,
+			Quantity:    item.Quantity,
+		})
+	}
+	return checkout
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/syrlramadhan/cashier-app/models"
+)
+
+func TestDeleteHeldOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  string
+		userID  uint
+		role    string
+		wantErr bool
+	}{
+		{name: "own held order", status: "held", userID: 1, role: "cashier"},
+		{name: "held order of another cashier", status: "held", userID: 2, role: "cashier", wantErr: true},
+		{name: "manager deletes another cashier's order", status: "held", userID: 2, role: "manager"},
+		{name: "own expired order", status: "expired", userID: 1, role: "cashier"},
+		{name: "expired order of another cashier", status: "expired", userID: 2, role: "cashier", wantErr: true},
+		{name: "admin deletes another cashier's expired order", status: "expired", userID: 2, role: "admin"},
+		{name: "completed transaction", status: "completed", userID: 1, role: "admin", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newFakeDB()
+			db.transactions[1] = models.Transaction{ID: 1, UserID: 1, Status: tt.status}
+			service := NewHeldOrderService(nil, &fakeTransactionRepository{db: db}, nil, nil, nil, nil, nil, nil)
+
+			err := service.DeleteHeldOrder(1, tt.userID, tt.role)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DeleteHeldOrder() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if _, kept := db.transactions[1]; kept != tt.wantErr {
+				t.Errorf("order kept = %v, want %v", kept, tt.wantErr)
+			}
+		})
+	}
+}
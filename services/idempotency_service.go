@@ -0,0 +1,134 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/syrlramadhan/cashier-app/models"
+	"github.com/syrlramadhan/cashier-app/repositories"
+)
+
+var (
+	// ErrIdempotencyKeyInProgress is returned while the original request for a key has not finished yet.
+	ErrIdempotencyKeyInProgress = errors.New("a request with this idempotency key is still being processed")
+	// ErrIdempotencyKeyMismatch is returned when a key is reused with a different request.
+	ErrIdempotencyKeyMismatch = errors.New("idempotency key was already used with a different request")
+)
+
+// idempotencySweepInterval is how often expired keys are purged
+const idempotencySweepInterval = 10 * time.Minute
+
+type IdempotencyService struct {
+	idempotencyRepo repositories.IdempotencyKeyRepository
+	ttl             time.Duration
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+func NewIdempotencyService(idempotencyRepo repositories.IdempotencyKeyRepository, ttl time.Duration) *IdempotencyService {
+	return &IdempotencyService{
+		idempotencyRepo: idempotencyRepo,
+		ttl:             ttl,
+	}
+}
+
+// Begin reserves the key for a new request. When the key has already been
+// completed for the same request, the stored record is returned and the
+// caller should replay it (StatusCode != 0) instead of executing the handler.
+func (s *IdempotencyService) Begin(userID uint, key, method, path, query string, body []byte) (*models.IdempotencyKey, error) {
+	now := time.Now()
+	hash := hashRequest(method, path, query, body)
+
+	s.sweepExpired(now)
+
+	record := &models.IdempotencyKey{
+		Key:         key,
+		UserID:      userID,
+		Method:      method,
+		Path:        path,
+		RequestHash: hash,
+		ExpiresAt:   now.Add(s.ttl),
+	}
+
+	if err := s.idempotencyRepo.Create(record); err == nil {
+		return record, nil
+	}
+
+	// The key already exists (or the insert lost a race with a concurrent retry)
+	existing, err := s.idempotencyRepo.FindByUserAndKey(userID, key)
+	if err != nil {
+		return nil, errors.New("failed to store idempotency key")
+	}
+
+	// An expired key that has not been swept yet can be used again
+	if now.After(existing.ExpiresAt) {
+		if err := s.idempotencyRepo.Delete(existing.ID); err != nil {
+			return nil, errors.New("failed to store idempotency key")
+		}
+		if err := s.idempotencyRepo.Create(record); err != nil {
+			// A concurrent retry took the key first
+			return nil, ErrIdempotencyKeyInProgress
+		}
+		return record, nil
+	}
+
+	if existing.RequestHash != hash {
+		return nil, ErrIdempotencyKeyMismatch
+	}
+
+	if existing.StatusCode == 0 {
+		return nil, ErrIdempotencyKeyInProgress
+	}
+
+	return existing, nil
+}
+
+// Complete stores the response for a reserved key. Server errors release the
+// key instead so that the client can safely retry the request.
+func (s *IdempotencyService) Complete(record *models.IdempotencyKey, statusCode int, body []byte) error {
+	if statusCode >= 500 {
+		return s.idempotencyRepo.Delete(record.ID)
+	}
+
+	record.StatusCode = statusCode
+	record.ResponseBody = string(body)
+	return s.idempotencyRepo.Update(record)
+}
+
+// Release frees a reserved key whose request never completed, e.g. because
+// the handler panicked, so that the client can retry it
+func (s *IdempotencyService) Release(record *models.IdempotencyKey) error {
+	return s.idempotencyRepo.Delete(record.ID)
+}
+
+// sweepExpired purges expired keys at most once per idempotencySweepInterval
+// instead of on every request. The purge is only housekeeping: Begin frees an
+// expired key itself when it is reused, so a failed sweep is not an error.
+func (s *IdempotencyService) sweepExpired(now time.Time) {
+	s.mu.Lock()
+	due := now.Sub(s.lastSweep) >= idempotencySweepInterval
+	if due {
+		s.lastSweep = now
+	}
+	s.mu.Unlock()
+
+	if due {
+		_ = s.idempotencyRepo.DeleteExpired(now)
+	}
+}
+
+func hashRequest(method, path, query string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method))
+	hash.Write([]byte{0})
+	hash.Write([]byte(path))
+	hash.Write([]byte{0})
+	hash.Write([]byte(query))
+	hash.Write([]byte{0})
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,414 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/models"
+	"github.com/syrlramadhan/cashier-app/repositories"
+	"gorm.io/gorm"
+)
+
+// IngredientService manages ingredients and the recipes that use them.
+// Products made to a recipe take their stock from the ingredients at
+// checkout instead of from the product.
+type IngredientService struct {
+	db             *gorm.DB
+	ingredientRepo repositories.IngredientRepository
+	productRepo    repositories.ProductRepository
+	variantRepo    repositories.ProductVariantRepository
+	modifierRepo   repositories.ModifierRepository
+}
+
+func NewIngredientService(
+	db *gorm.DB,
+	ingredientRepo repositories.IngredientRepository,
+	productRepo repositories.ProductRepository,
+	variantRepo repositories.ProductVariantRepository,
+	modifierRepo repositories.ModifierRepository,
+) *IngredientService {
+	return &IngredientService{
+		db:             db,
+		ingredientRepo: ingredientRepo,
+		productRepo:    productRepo,
+		variantRepo:    variantRepo,
+		modifierRepo:   modifierRepo,
+	}
+}
+
+func (s *IngredientService) GetAllIngredients() ([]dto.IngredientResponse, error) {
+	ingredients, err := s.ingredientRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var response []dto.IngredientResponse
+	for _, ingredient := range ingredients {
+		response = append(response, toIngredientResponse(&ingredient))
+	}
+
+	return response, nil
+}
+
+func (s *IngredientService) GetIngredientByID(id uint) (*dto.IngredientResponse, error) {
+	ingredient, err := s.ingredientRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("ingredient not found")
+	}
+
+	response := toIngredientResponse(ingredient)
+	return &response, nil
+}
+
+func (s *IngredientService) CreateIngredient(req *dto.CreateIngredientRequest) (*dto.IngredientResponse, error) {
+	ingredient := &models.Ingredient{
+		Name:  req.Name,
+		Unit:  req.Unit,
+		Stock: roundQuantity(req.Stock),
+		Cost:  req.Cost,
+	}
+
+	if err := s.ingredientRepo.Create(ingredient); err != nil {
+		return nil, errors.New("failed to create ingredient")
+	}
+
+	response := toIngredientResponse(ingredient)
+	return &response, nil
+}
+
+func (s *IngredientService) UpdateIngredient(id uint, req *dto.UpdateIngredientRequest) (*dto.IngredientResponse, error) {
+	ingredient, err := s.ingredientRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("ingredient not found")
+	}
+
+	ingredient.Name = req.Name
+	ingredient.Unit = req.Unit
+	ingredient.Cost = req.Cost
+
+	if err := s.ingredientRepo.Update(ingredient); err != nil {
+		return nil, errors.New("failed to update ingredient")
+	}
+
+	response := toIngredientResponse(ingredient)
+	return &response, nil
+}
+
+// UpdateStock adds quantity to the stock of an ingredient, e.g. after a
+// delivery. The row is locked like at checkout, so a sale in between is not
+// overwritten.
+func (s *IngredientService) UpdateStock(id uint, quantity float64) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		ingredientRepo := s.ingredientRepo.WithTx(tx)
+
+		ingredients, err := ingredientRepo.FindByIDsForUpdate([]uint{id})
+		if err != nil {
+			return errors.New("failed to lock ingredient")
+		}
+		if len(ingredients) == 0 {
+			return errors.New("ingredient not found")
+		}
+
+		newStock := roundQuantity(ingredients[0].Stock + quantity)
+		if newStock < 0 {
+			return errors.New("insufficient stock")
+		}
+
+		return ingredientRepo.UpdateStock(id, newStock)
+	})
+}
+
+func (s *IngredientService) DeleteIngredient(id uint) error {
+	_, err := s.ingredientRepo.FindByID(id)
+	if err != nil {
+		return errors.New("ingredient not found")
+	}
+
+	count, err := s.ingredientRepo.CountRecipeItems(id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("ingredient is used by %d recipe item(s)", count)
+	}
+
+	return s.ingredientRepo.Delete(id)
+}
+
+func (s *IngredientService) GetProductRecipe(productID uint) (*dto.RecipeResponse, error) {
+	if _, err := s.productRepo.FindByID(productID); err != nil {
+		return nil, errors.New("product not found")
+	}
+	return s.getRecipe("product", productID)
+}
+
+func (s *IngredientService) SetProductRecipe(productID uint, req *dto.RecipeRequest) (*dto.RecipeResponse, error) {
+	if _, err := s.productRepo.FindByID(productID); err != nil {
+		return nil, errors.New("product not found")
+	}
+	return s.setRecipe("product", productID, req)
+}
+
+func (s *IngredientService) GetVariantRecipe(productID, variantID uint) (*dto.RecipeResponse, error) {
+	if err := s.checkVariant(productID, variantID); err != nil {
+		return nil, err
+	}
+	return s.getRecipe("variant", variantID)
+}
+
+func (s *IngredientService) SetVariantRecipe(productID, variantID uint, req *dto.RecipeRequest) (*dto.RecipeResponse, error) {
+	if err := s.checkVariant(productID, variantID); err != nil {
+		return nil, err
+	}
+	return s.setRecipe("variant", variantID, req)
+}
+
+func (s *IngredientService) GetModifierRecipe(groupID, optionID uint) (*dto.RecipeResponse, error) {
+	if err := s.checkModifierOption(groupID, optionID); err != nil {
+		return nil, err
+	}
+	return s.getRecipe("modifier", optionID)
+}
+
+func (s *IngredientService) SetModifierRecipe(groupID, optionID uint, req *dto.RecipeRequest) (*dto.RecipeResponse, error) {
+	if err := s.checkModifierOption(groupID, optionID); err != nil {
+		return nil, err
+	}
+	return s.setRecipe("modifier", optionID, req)
+}
+
+func (s *IngredientService) getRecipe(owner string, ownerID uint) (*dto.RecipeResponse, error) {
+	items, err := s.ingredientRepo.FindRecipe(owner, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	return toRecipeResponse(items), nil
+}
+
+func (s *IngredientService) setRecipe(owner string, ownerID uint, req *dto.RecipeRequest) (*dto.RecipeResponse, error) {
+	seen := make(map[uint]bool)
+	var items []models.RecipeItem
+	for _, itemReq := range req.Items {
+		if seen[itemReq.IngredientID] {
+			return nil, fmt.Errorf("ingredient %d is listed more than once", itemReq.IngredientID)
+		}
+		seen[itemReq.IngredientID] = true
+
+		if _, err := s.ingredientRepo.FindByID(itemReq.IngredientID); err != nil {
+			return nil, fmt.Errorf("ingredient not found: %d", itemReq.IngredientID)
+		}
+
+		item := models.RecipeItem{IngredientID: itemReq.IngredientID, Quantity: roundQuantity(itemReq.Quantity)}
+		id := ownerID
+		switch owner {
+		case "variant":
+			item.VariantID = &id
+		case "modifier":
+			item.ModifierOptionID = &id
+		default:
+			item.ProductID = &id
+		}
+		items = append(items, item)
+	}
+
+	if err := s.ingredientRepo.ReplaceRecipe(owner, ownerID, items); err != nil {
+		return nil, errors.New("failed to save recipe")
+	}
+
+	return s.getRecipe(owner, ownerID)
+}
+
+func (s *IngredientService) checkVariant(productID, variantID uint) error {
+	variant, err := s.variantRepo.FindByID(variantID)
+	if err != nil || variant.ProductID != productID {
+		return errors.New("variant not found")
+	}
+	return nil
+}
+
+func (s *IngredientService) checkModifierOption(groupID, optionID uint) error {
+	group, err := s.modifierRepo.FindByID(groupID)
+	if err != nil {
+		return errors.New("modifier group not found")
+	}
+	for _, option := range group.Options {
+		if option.ID == optionID {
+			return nil
+		}
+	}
+	return errors.New("modifier option not found")
+}
+
+// recipeBook holds the recipes of the products, variants and modifiers in a cart
+type recipeBook struct {
+	products  map[uint][]models.RecipeItem
+	variants  map[uint][]models.RecipeItem
+	modifiers map[uint][]models.RecipeItem
+}
+
+// recipeBook loads the recipes used by the cart
+func (s *IngredientService) recipeBook(tx *gorm.DB, items []dto.TransactionItemRequest) (*recipeBook, error) {
+	var productIDs, variantIDs, modifierIDs []uint
+	for _, item := range items {
+		productIDs = append(productIDs, item.ProductID)
+		if item.VariantID != nil {
+			variantIDs = append(variantIDs, *item.VariantID)
+		}
+		modifierIDs = append(modifierIDs, item.ModifierIDs...)
+	}
+
+	recipes, err := s.ingredientRepo.WithTx(tx).FindRecipes(productIDs, variantIDs, modifierIDs)
+	if err != nil {
+		return nil, errors.New("failed to load recipes")
+	}
+
+	book := &recipeBook{
+		products:  make(map[uint][]models.RecipeItem),
+		variants:  make(map[uint][]models.RecipeItem),
+		modifiers: make(map[uint][]models.RecipeItem),
+	}
+	for _, recipe := range recipes {
+		switch {
+		case recipe.ProductID != nil:
+			book.products[*recipe.ProductID] = append(book.products[*recipe.ProductID], recipe)
+		case recipe.VariantID != nil:
+			book.variants[*recipe.VariantID] = append(book.variants[*recipe.VariantID], recipe)
+		case recipe.ModifierOptionID != nil:
+			book.modifiers[*recipe.ModifierOptionID] = append(book.modifiers[*recipe.ModifierOptionID], recipe)
+		}
+	}
+	return book, nil
+}
+
+// forLine returns the ingredients one unit of a line uses, keyed by
+// ingredient ID, and whether the line itself is made to a recipe (and so
+// does not draw on product or variant stock). A variant recipe replaces the
+// product recipe; modifier recipes are added on top.
+func (b *recipeBook) forLine(productID uint, variant *models.ProductVariant, modifiers []models.TransactionItemModifier) (map[uint]float64, bool) {
+	base := b.products[productID]
+	if variant != nil && len(b.variants[variant.ID]) > 0 {
+		base = b.variants[variant.ID]
+	}
+
+	perUnit := make(map[uint]float64)
+	for _, recipe := range base {
+		perUnit[recipe.IngredientID] += recipe.Quantity
+	}
+	for _, modifier := range modifiers {
+		for _, recipe := range b.modifiers[modifier.ModifierOptionID] {
+			perUnit[recipe.IngredientID] += recipe.Quantity
+		}
+	}
+	return perUnit, len(base) > 0
+}
+
+// lockIngredients locks the ingredients a checkout needs and checks there is enough of each
+func (s *IngredientService) lockIngredients(tx *gorm.DB, needed map[uint]float64) (map[uint]models.Ingredient, error) {
+	result := make(map[uint]models.Ingredient, len(needed))
+	if len(needed) == 0 {
+		return result, nil
+	}
+
+	ids := sortedIngredientIDs(needed)
+	ingredients, err := s.ingredientRepo.WithTx(tx).FindByIDsForUpdate(ids)
+	if err != nil {
+		return nil, errors.New("failed to lock ingredients")
+	}
+	for _, ingredient := range ingredients {
+		result[ingredient.ID] = ingredient
+	}
+
+	for _, id := range ids {
+		ingredient, ok := result[id]
+		if !ok {
+			return nil, fmt.Errorf("ingredient not found: %d", id)
+		}
+		if ingredient.Stock < roundQuantity(needed[id]) {
+			return nil, fmt.Errorf("insufficient stock of ingredient: %s", ingredient.Name)
+		}
+	}
+	return result, nil
+}
+
+// consumeIngredients takes the used quantities off the locked ingredients and
+// records what each line used
+func (s *IngredientService) consumeIngredients(tx *gorm.DB, ingredients map[uint]models.Ingredient, usages []models.TransactionItemIngredient) error {
+	ingredientRepo := s.ingredientRepo.WithTx(tx)
+
+	used := make(map[uint]float64)
+	for _, usage := range usages {
+		used[usage.IngredientID] += usage.Quantity
+	}
+	for _, id := range sortedIngredientIDs(used) {
+		newStock := roundQuantity(ingredients[id].Stock - used[id])
+		if err := ingredientRepo.UpdateStock(id, newStock); err != nil {
+			return errors.New("failed to update ingredient stock")
+		}
+	}
+
+	if err := ingredientRepo.CreateUsage(usages); err != nil {
+		return errors.New("failed to record ingredient usage")
+	}
+	return nil
+}
+
+// availableUnits is how many units the ingredients in stock can make of a recipe
+func availableUnits(recipe []models.RecipeItem) int {
+	available := math.MaxInt32
+	for _, item := range recipe {
+		if item.Quantity <= 0 {
+			continue
+		}
+		units := int(math.Floor(roundQuantity(item.Ingredient.Stock/item.Quantity) + 1e-9))
+		if units < available {
+			available = units
+		}
+	}
+	if available < 0 {
+		return 0
+	}
+	return available
+}
+
+// roundQuantity rounds an ingredient quantity to the three decimals it is stored with
+func roundQuantity(quantity float64) float64 {
+	return math.Round(quantity*1000) / 1000
+}
+
+func sortedIngredientIDs(quantities map[uint]float64) []uint {
+	ids := make([]uint, 0, len(quantities))
+	for id := range quantities {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func toIngredientResponse(ingredient *models.Ingredient) dto.IngredientResponse {
+	return dto.IngredientResponse{
+		ID:    ingredient.ID,
+		Name:  ingredient.Name,
+		Unit:  ingredient.Unit,
+		Stock: ingredient.Stock,
+		Cost:  ingredient.Cost,
+	}
+}
+
+func toRecipeResponse(items []models.RecipeItem) *dto.RecipeResponse {
+	response := &dto.RecipeResponse{Items: []dto.RecipeItemResponse{}}
+	for _, item := range items {
+		cost := models.Money(math.Round(float64(item.Ingredient.Cost) * item.Quantity))
+		response.Items = append(response.Items, dto.RecipeItemResponse{
+			IngredientID:   item.IngredientID,
+			IngredientName: item.Ingredient.Name,
+			Unit:           item.Ingredient.Unit,
+			Quantity:       item.Quantity,
+			Cost:           cost,
+		})
+		response.Cost += cost
+	}
+	return response
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/syrlramadhan/cashier-app/models"
+)
+
+func TestIngredientServiceUpdateStock(t *testing.T) {
+	newService := func(t *testing.T, stock float64) (*fakeDB, *IngredientService) {
+		db := newFakeDB()
+		db.ingredients[1] = models.Ingredient{ID: 1, Name: "Milk", Unit: "ml", Stock: stock}
+		return db, NewIngredientService(db.open(t), &fakeIngredientRepository{db: db}, nil, nil, nil)
+	}
+
+	t.Run("concurrent deliveries all count", func(t *testing.T) {
+		db, service := newService(t, 100)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := service.UpdateStock(1, 250); err != nil {
+					t.Errorf("UpdateStock() error = %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if stock := db.ingredients[1].Stock; stock != 2600 {
+			t.Errorf("stock = %v, want 2600", stock)
+		}
+	})
+
+	tests := []struct {
+		name      string
+		id        uint
+		quantity  float64
+		wantErr   bool
+		wantStock float64
+	}{
+		{name: "takes stock out", id: 1, quantity: -40.5, wantStock: 59.5},
+		{name: "rejects going below zero", id: 1, quantity: -100.001, wantErr: true, wantStock: 100},
+		{name: "unknown ingredient", id: 2, quantity: 10, wantErr: true, wantStock: 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, service := newService(t, 100)
+
+			err := service.UpdateStock(tt.id, tt.quantity)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UpdateStock() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if stock := db.ingredients[1].Stock; stock != tt.wantStock {
+				t.Errorf("stock = %v, want %v", stock, tt.wantStock)
+			}
+		})
+	}
+}
@@ -0,0 +1,291 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/models"
+	"github.com/syrlramadhan/cashier-app/repositories"
+	"gorm.io/gorm"
+)
+
+// kitchenTopic is the event hub topic the kitchen displays subscribe to
+const kitchenTopic = "kitchen"
+
+// nextTicketStatus is where a bump moves a ticket; a recall moves it back
+var nextTicketStatus = map[string]string{
+	"new":         "in_progress",
+	"in_progress": "ready",
+	"ready":       "served",
+}
+
+var previousTicketStatus = map[string]string{
+	"in_progress": "new",
+	"ready":       "in_progress",
+	"served":      "ready",
+}
+
+// KitchenService routes the lines of an order to the stations that prepare
+// them and keeps the kitchen displays up to date through the event hub.
+type KitchenService struct {
+	db           *gorm.DB
+	ticketRepo   repositories.KitchenTicketRepository
+	queueService *QueueService
+	eventHub     *EventHub
+}
+
+func NewKitchenService(
+	db *gorm.DB,
+	ticketRepo repositories.KitchenTicketRepository,
+	queueService *QueueService,
+	eventHub *EventHub,
+) *KitchenService {
+	return &KitchenService{
+		db:           db,
+		ticketRepo:   ticketRepo,
+		queueService: queueService,
+		eventHub:     eventHub,
+	}
+}
+
+// GetTickets returns the tickets of a station, by default those still on the displays
+func (s *KitchenService) GetTickets(station, status string) ([]dto.KitchenTicketResponse, error) {
+	tickets, err := s.ticketRepo.FindAll(station, status)
+	if err != nil {
+		return nil, err
+	}
+
+	response := []dto.KitchenTicketResponse{}
+	for _, ticket := range tickets {
+		response = append(response, toKitchenTicketResponse(&ticket))
+	}
+
+	return response, nil
+}
+
+func (s *KitchenService) GetTicket(id uint) (*dto.KitchenTicketResponse, error) {
+	ticket, err := s.ticketRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("ticket not found")
+	}
+
+	response := toKitchenTicketResponse(ticket)
+	return &response, nil
+}
+
+// BumpTicket moves a ticket to its next status: new, in progress, ready, served
+func (s *KitchenService) BumpTicket(id uint) (*dto.KitchenTicketResponse, error) {
+	return s.moveTicket(id, nextTicketStatus, "ticket is already served")
+}
+
+// RecallTicket moves a ticket bumped by mistake back to its previous status
+func (s *KitchenService) RecallTicket(id uint) (*dto.KitchenTicketResponse, error) {
+	return s.moveTicket(id, previousTicketStatus, "ticket has not been started")
+}
+
+// Subscribe returns the ticket events for a kitchen display stream
+func (s *KitchenService) Subscribe() (<-chan Event, func()) {
+	return s.eventHub.Subscribe(kitchenTopic)
+}
+
+func (s *KitchenService) moveTicket(id uint, transitions map[string]string, endMessage string) (*dto.KitchenTicketResponse, error) {
+	var ticket *models.KitchenTicket
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		ticketRepo := s.ticketRepo.WithTx(tx)
+
+		var err error
+		ticket, err = ticketRepo.FindByIDForUpdate(id)
+		if err != nil {
+			return errors.New("ticket not found")
+		}
+		if ticket.Status == "cancelled" {
+			return errors.New("ticket is cancelled")
+		}
+
+		status, ok := transitions[ticket.Status]
+		if !ok {
+			return errors.New(endMessage)
+		}
+		setTicketStatus(ticket, status, time.Now())
+
+		if err := ticketRepo.Update(ticket); err != nil {
+			return errors.New("failed to update ticket")
+		}
+
+		return s.queueService.refreshFulfillment(tx, ticket.TransactionID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.publish("ticket.updated", []models.KitchenTicket{*ticket})
+
+	response := toKitchenTicketResponse(ticket)
+	return &response, nil
+}
+
+// setTicketStatus records when the ticket reached status; moving back clears
+// the times of the steps that are undone
+func setTicketStatus(ticket *models.KitchenTicket, status string, now time.Time) {
+	ticket.Status = status
+	switch status {
+	case "new":
+		ticket.StartedAt = nil
+	case "in_progress":
+		if ticket.StartedAt == nil {
+			ticket.StartedAt = &now
+		}
+		ticket.ReadyAt = nil
+	case "ready":
+		if ticket.ReadyAt == nil {
+			ticket.ReadyAt = &now
+		}
+		ticket.ServedAt = nil
+	case "served":
+		ticket.ServedAt = &now
+	}
+}
+
+// createTickets splits items into one ticket per station inside tx. The
+// tickets are announced with publish once tx has committed.
+func (s *KitchenService) createTickets(tx *gorm.DB, transaction *models.Transaction, items []models.TransactionItem) ([]models.KitchenTicket, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	var productIDs []uint
+	for _, item := range items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+
+	ticketRepo := s.ticketRepo.WithTx(tx)
+	stations, err := ticketRepo.FindStations(productIDs)
+	if err != nil {
+		return nil, errors.New("failed to route kitchen tickets")
+	}
+
+	byStation := make(map[string]*models.KitchenTicket)
+	for _, item := range items {
+		station := stations[item.ProductID]
+		if station == "" {
+			station = "kitchen"
+		}
+
+		ticket, ok := byStation[station]
+		if !ok {
+			ticket = &models.KitchenTicket{
+				TransactionID:   transaction.ID,
+				TransactionCode: transaction.TransactionCode,
+				TableID:         transaction.TableID,
+				OrderType:       transaction.OrderType,
+				Station:         station,
+				Round:           item.Round,
+				Status:          "new",
+			}
+			byStation[station] = ticket
+		}
+		ticket.Items = append(ticket.Items, models.KitchenTicketItem{
+			TransactionItemID: item.ID,
+			ProductName:       item.ProductName,
+			VariantName:       item.VariantName,
+			Modifiers:         modifierNames(item.Modifiers),
+			Quantity:          item.Quantity,
+		})
+	}
+
+	var tickets []models.KitchenTicket
+	for _, station := range sortedStations(byStation) {
+		tickets = append(tickets, *byStation[station])
+	}
+
+	if err := ticketRepo.CreateBatch(tickets); err != nil {
+		return nil, errors.New("failed to create kitchen tickets")
+	}
+	return tickets, nil
+}
+
+// cancelTickets takes the unserved tickets of a voided order off the displays
+func (s *KitchenService) cancelTickets(tx *gorm.DB, transactionID uint) ([]models.KitchenTicket, error) {
+	ticketRepo := s.ticketRepo.WithTx(tx)
+	if err := ticketRepo.CancelByTransactionID(transactionID); err != nil {
+		return nil, errors.New("failed to cancel kitchen tickets")
+	}
+
+	tickets, err := ticketRepo.FindByTransactionID(transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var cancelled []models.KitchenTicket
+	for _, ticket := range tickets {
+		if ticket.Status == "cancelled" {
+			cancelled = append(cancelled, ticket)
+		}
+	}
+	return cancelled, nil
+}
+
+// moveTickets points the tickets of a tab at the tab and table that now serve them
+func (s *KitchenService) moveTickets(tx *gorm.DB, fromTransactionID uint, to *models.Transaction) ([]models.KitchenTicket, error) {
+	ticketRepo := s.ticketRepo.WithTx(tx)
+	if err := ticketRepo.MoveToTransaction(fromTransactionID, to.ID, to.TransactionCode, to.TableID); err != nil {
+		return nil, errors.New("failed to move kitchen tickets")
+	}
+	return ticketRepo.FindByTransactionID(to.ID)
+}
+
+// publish announces ticket changes to the kitchen displays and refreshes the queue board
+func (s *KitchenService) publish(eventType string, tickets []models.KitchenTicket) {
+	if len(tickets) == 0 {
+		return
+	}
+
+	for i := range tickets {
+		s.eventHub.Publish(Event{
+			Topic: kitchenTopic,
+			Type:  eventType,
+			Data:  toKitchenTicketResponse(&tickets[i]),
+		})
+	}
+	s.queueService.publishBoard()
+}
+
+func sortedStations(tickets map[string]*models.KitchenTicket) []string {
+	stations := make([]string, 0, len(tickets))
+	for station := range tickets {
+		stations = append(stations, station)
+	}
+	sort.Strings(stations)
+	return stations
+}
+
+func toKitchenTicketResponse(ticket *models.KitchenTicket) dto.KitchenTicketResponse {
+	items := []dto.KitchenTicketItemResponse{}
+	for _, item := range ticket.Items {
+		items = append(items, dto.KitchenTicketItemResponse{
+			TransactionItemID: item.TransactionItemID,
+			ProductName:       item.ProductName,
+			VariantName:       item.VariantName,
+			Modifiers:         item.Modifiers,
+			Quantity:          item.Quantity,
+		})
+	}
+
+	return dto.KitchenTicketResponse{
+		ID:              ticket.ID,
+		TransactionID:   ticket.TransactionID,
+		TransactionCode: ticket.TransactionCode,
+		TableID:         ticket.TableID,
+		OrderType:       ticket.OrderType,
+		Station:         ticket.Station,
+		Round:           ticket.Round,
+		Status:          ticket.Status,
+		Items:           items,
+		StartedAt:       ticket.StartedAt,
+		ReadyAt:         ticket.ReadyAt,
+		ServedAt:        ticket.ServedAt,
+		CreatedAt:       ticket.CreatedAt,
+	}
+}
@@ -0,0 +1,328 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/models"
+	"github.com/syrlramadhan/cashier-app/repositories"
+	"gorm.io/gorm"
+)
+
+// ModifierService manages modifier groups (add-ons such as an extra shot or
+// oat milk) and validates the modifiers chosen for a line at checkout.
+type ModifierService struct {
+	db           *gorm.DB
+	modifierRepo repositories.ModifierRepository
+	productRepo  repositories.ProductRepository
+	categoryRepo repositories.CategoryRepository
+}
+
+func NewModifierService(
+	db *gorm.DB,
+	modifierRepo repositories.ModifierRepository,
+	productRepo repositories.ProductRepository,
+	categoryRepo repositories.CategoryRepository,
+) *ModifierService {
+	return &ModifierService{
+		db:           db,
+		modifierRepo: modifierRepo,
+		productRepo:  productRepo,
+		categoryRepo: categoryRepo,
+	}
+}
+
+func (s *ModifierService) GetAllGroups() ([]dto.ModifierGroupResponse, error) {
+	groups, err := s.modifierRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var response []dto.ModifierGroupResponse
+	for _, group := range groups {
+		response = append(response, toModifierGroupResponse(&group))
+	}
+
+	return response, nil
+}
+
+func (s *ModifierService) GetGroupByID(id uint) (*dto.ModifierGroupResponse, error) {
+	group, err := s.modifierRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("modifier group not found")
+	}
+
+	response := toModifierGroupResponse(group)
+	return &response, nil
+}
+
+// GetProductModifiers lists the modifier groups offered with a product,
+// directly or through its category
+func (s *ModifierService) GetProductModifiers(productID uint) ([]dto.ModifierGroupResponse, error) {
+	product, err := s.productRepo.FindByID(productID)
+	if err != nil {
+		return nil, errors.New("product not found")
+	}
+
+	groups, err := s.modifierRepo.FindForProduct(product.ID, product.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := []dto.ModifierGroupResponse{}
+	for _, group := range groups {
+		response = append(response, toModifierGroupResponse(&group))
+	}
+
+	return response, nil
+}
+
+func (s *ModifierService) CreateGroup(req *dto.ModifierGroupRequest) (*dto.ModifierGroupResponse, error) {
+	if err := validateModifierGroup(req); err != nil {
+		return nil, err
+	}
+
+	group := &models.ModifierGroup{
+		Name:      req.Name,
+		MinSelect: req.MinSelect,
+		MaxSelect: req.MaxSelect,
+	}
+	for i, option := range req.Options {
+		if option.ID != nil {
+			return nil, errors.New("new modifier groups cannot reference existing options")
+		}
+		group.Options = append(group.Options, models.ModifierOption{
+			Name:     option.Name,
+			Price:    option.Price,
+			Position: i,
+			IsActive: option.IsActive == nil || *option.IsActive,
+		})
+	}
+
+	if err := s.attach(group, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.modifierRepo.Create(group); err != nil {
+		return nil, errors.New("failed to create modifier group")
+	}
+
+	response := toModifierGroupResponse(group)
+	return &response, nil
+}
+
+// UpdateGroup updates the group, its options and where it is offered.
+// Options left out are removed; past sales keep their names and prices.
+func (s *ModifierService) UpdateGroup(id uint, req *dto.ModifierGroupRequest) (*dto.ModifierGroupResponse, error) {
+	group, err := s.modifierRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("modifier group not found")
+	}
+
+	if err := validateModifierGroup(req); err != nil {
+		return nil, err
+	}
+
+	existing := make(map[uint]models.ModifierOption)
+	for _, option := range group.Options {
+		existing[option.ID] = option
+	}
+
+	group.Name = req.Name
+	group.MinSelect = req.MinSelect
+	group.MaxSelect = req.MaxSelect
+	if err := s.attach(group, req); err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		modifierRepo := s.modifierRepo.WithTx(tx)
+
+		if err := modifierRepo.Update(group); err != nil {
+			return errors.New("failed to update modifier group")
+		}
+
+		var options []models.ModifierOption
+		for i, optionReq := range req.Options {
+			option := models.ModifierOption{GroupID: group.ID}
+			if optionReq.ID != nil {
+				var ok bool
+				option, ok = existing[*optionReq.ID]
+				if !ok {
+					return fmt.Errorf("option %d does not belong to this modifier group", *optionReq.ID)
+				}
+				delete(existing, option.ID)
+			}
+
+			option.Name = optionReq.Name
+			option.Price = optionReq.Price
+			option.Position = i
+			option.IsActive = optionReq.IsActive == nil || *optionReq.IsActive
+
+			if option.ID == 0 {
+				err = modifierRepo.CreateOption(&option)
+			} else {
+				err = modifierRepo.UpdateOption(&option)
+			}
+			if err != nil {
+				return errors.New("failed to save modifier option")
+			}
+			options = append(options, option)
+		}
+
+		// Whatever is left was removed from the group
+		for _, option := range existing {
+			if err := modifierRepo.DeleteOption(option.ID); err != nil {
+				return errors.New("failed to delete modifier option")
+			}
+		}
+		group.Options = options
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := toModifierGroupResponse(group)
+	return &response, nil
+}
+
+func (s *ModifierService) DeleteGroup(id uint) error {
+	_, err := s.modifierRepo.FindByID(id)
+	if err != nil {
+		return errors.New("modifier group not found")
+	}
+
+	return s.modifierRepo.Delete(id)
+}
+
+// resolveModifiers checks the chosen options against the modifier groups of
+// product and their selection rules, returning the modifiers to store on the line
+func (s *ModifierService) resolveModifiers(product *models.Product, optionIDs []uint) ([]models.TransactionItemModifier, error) {
+	groups, err := s.modifierRepo.FindForProduct(product.ID, product.CategoryID)
+	if err != nil {
+		return nil, errors.New("failed to load modifiers")
+	}
+
+	type choice struct {
+		group  *models.ModifierGroup
+		option models.ModifierOption
+	}
+	choices := make(map[uint]choice)
+	for i := range groups {
+		for _, option := range groups[i].Options {
+			choices[option.ID] = choice{group: &groups[i], option: option}
+		}
+	}
+
+	chosen := make(map[uint]int)
+	seen := make(map[uint]bool)
+	var modifiers []models.TransactionItemModifier
+	for _, optionID := range optionIDs {
+		if seen[optionID] {
+			return nil, fmt.Errorf("modifier %d is chosen more than once", optionID)
+		}
+		seen[optionID] = true
+
+		c, ok := choices[optionID]
+		if !ok || !c.option.IsActive {
+			return nil, fmt.Errorf("modifier %d is not available for product: %s", optionID, product.Name)
+		}
+
+		chosen[c.group.ID]++
+		modifiers = append(modifiers, models.TransactionItemModifier{
+			ModifierOptionID: c.option.ID,
+			GroupName:        c.group.Name,
+			Name:             c.option.Name,
+			Price:            c.option.Price,
+		})
+	}
+
+	for _, group := range groups {
+		if chosen[group.ID] < group.MinSelect {
+			return nil, fmt.Errorf("choose at least %d of %s for %s", group.MinSelect, group.Name, product.Name)
+		}
+		if group.MaxSelect > 0 && chosen[group.ID] > group.MaxSelect {
+			return nil, fmt.Errorf("choose at most %d of %s for %s", group.MaxSelect, group.Name, product.Name)
+		}
+	}
+
+	return modifiers, nil
+}
+
+// attach sets the products and categories the group is offered with
+func (s *ModifierService) attach(group *models.ModifierGroup, req *dto.ModifierGroupRequest) error {
+	group.Products = nil
+	for _, productID := range req.ProductIDs {
+		product, err := s.productRepo.FindByID(productID)
+		if err != nil {
+			return fmt.Errorf("product not found: %d", productID)
+		}
+		group.Products = append(group.Products, models.Product{ID: product.ID})
+	}
+
+	group.Categories = nil
+	for _, categoryID := range req.CategoryIDs {
+		category, err := s.categoryRepo.FindByID(categoryID)
+		if err != nil {
+			return fmt.Errorf("category not found: %d", categoryID)
+		}
+		group.Categories = append(group.Categories, models.Category{ID: category.ID})
+	}
+	return nil
+}
+
+func validateModifierGroup(req *dto.ModifierGroupRequest) error {
+	if req.MaxSelect > 0 && req.MinSelect > req.MaxSelect {
+		return errors.New("min_select cannot be greater than max_select")
+	}
+	if req.MinSelect > len(req.Options) {
+		return errors.New("min_select cannot be greater than the number of options")
+	}
+
+	seen := make(map[string]bool)
+	for _, option := range req.Options {
+		name := strings.ToLower(option.Name)
+		if seen[name] {
+			return fmt.Errorf("duplicate option: %s", option.Name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// modifierNames lists the modifier names of a line for kitchen tickets, e.g. "Extra Shot, Oat Milk"
+func modifierNames(modifiers []models.TransactionItemModifier) string {
+	names := make([]string, 0, len(modifiers))
+	for _, modifier := range modifiers {
+		names = append(names, modifier.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+func toModifierGroupResponse(group *models.ModifierGroup) dto.ModifierGroupResponse {
+	response := dto.ModifierGroupResponse{
+		ID:        group.ID,
+		Name:      group.Name,
+		MinSelect: group.MinSelect,
+		MaxSelect: group.MaxSelect,
+		Options:   []dto.ModifierOptionResponse{},
+	}
+	for _, option := range group.Options {
+		response.Options = append(response.Options, dto.ModifierOptionResponse{
+			ID:       option.ID,
+			Name:     option.Name,
+			Price:    option.Price,
+			IsActive: option.IsActive,
+		})
+	}
+	for _, product := range group.Products {
+		response.ProductIDs = append(response.ProductIDs, product.ID)
+	}
+	for _, category := range group.Categories {
+		response.CategoryIDs = append(response.CategoryIDs, category.ID)
+	}
+	return response
+}
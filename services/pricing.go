@@ -0,0 +1,159 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/models"
+)
+
+// cartLine is a line being priced during checkout
+type cartLine struct {
+	Product   models.Product
+	Variant   *models.ProductVariant // nil when the product has no variants
+	Modifiers []models.TransactionItemModifier
+	// Ingredients one unit uses, keyed by ingredient ID; with FromRecipe the
+	// line is sold from them instead of from product or variant stock
+	Ingredients map[uint]float64
+	FromRecipe  bool
+	Quantity    int
+	UnitPrice   models.Money
+	Discounts   []models.TransactionDiscount
+}
+
+// Gross is the line amount before discounts
+func (l *cartLine) Gross() models.Money {
+	return l.UnitPrice.Mul(l.Quantity)
+}
+
+// Discount is the sum of all discounts applied to the line
+func (l *cartLine) Discount() models.Money {
+	var total models.Money
+	for _, discount := range l.Discounts {
+		total += discount.Amount
+	}
+	return total
+}
+
+// Net is the line amount after discounts
+func (l *cartLine) Net() models.Money {
+	return l.Gross() - l.Discount()
+}
+
+// manualDiscount turns a cashier-entered percentage and/or amount into a
+// discount on base, capped at base
+func manualDiscount(base models.Money, percent float64, amount models.Money) models.Money {
+	discount := base.MulRate(percent/100) + amount
+	if discount > base {
+		discount = base
+	}
+	return discount
+}
+
+// checkManualDiscount rejects manual discounts that together exceed
+// maxPercent of the gross amount of the cart before promotions
+func checkManualDiscount(manual, gross models.Money, maxPercent float64) error {
+	if manual > 0 && float64(manual)*100 > float64(gross)*maxPercent {
+		return fmt.Errorf("manual discount exceeds the %.0f%% limit for your role", maxPercent)
+	}
+	return nil
+}
+
+// applyDiscounts prices the cart: the best automatic promotion per line, then
+// the cashier's manual line discounts, then the best order promotion, the
+// voucher (when given) and the manual order discount. It returns the subtotal
+// (net of line discounts) and the order-level discounts, which are deducted
+// from it before tax. The manual line and order discounts count together
+// against the role limit.
+func applyDiscounts(lines []*cartLine, promotions []models.Promotion, voucher *models.Voucher, req *dto.CreateTransactionRequest, maxManualPercent float64) (models.Money, []models.TransactionDiscount, error) {
+	applyLinePromotions(lines, promotions)
+
+	var subtotal, gross, manual models.Money
+	for i, line := range lines {
+		gross += line.Gross()
+		itemReq := req.Items[i]
+		if itemReq.DiscountPercent > 0 || itemReq.DiscountAmount > 0 {
+			amount := manualDiscount(line.Net(), itemReq.DiscountPercent, itemReq.DiscountAmount)
+			manual += amount
+			if amount > 0 {
+				line.Discounts = append(line.Discounts, models.TransactionDiscount{
+					Source: "manual",
+					Name:   "Manual discount",
+					Amount: amount,
+				})
+			}
+		}
+		subtotal += line.Net()
+	}
+
+	var orderDiscounts []models.TransactionDiscount
+	remaining := subtotal
+
+	if promotion, amount := bestOrderPromotion(promotions, subtotal); promotion != nil {
+		orderDiscounts = append(orderDiscounts, models.TransactionDiscount{
+			PromotionID: &promotion.ID,
+			Source:      "promotion",
+			Name:        promotion.Name,
+			Amount:      amount,
+		})
+		remaining -= amount
+	}
+
+	if voucher != nil {
+		if !voucher.Stackable && hasPromotionDiscount(lines, orderDiscounts) {
+			return 0, nil, errors.New("voucher cannot be combined with other promotions")
+		}
+
+		amount, err := voucherDiscount(voucher, remaining)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		name := voucher.Name
+		if name == "" {
+			name = voucher.Code
+		}
+		orderDiscounts = append(orderDiscounts, models.TransactionDiscount{
+			VoucherID: &voucher.ID,
+			Source:    "voucher",
+			Name:      name,
+			Amount:    amount,
+		})
+		remaining -= amount
+	}
+
+	if req.DiscountPercent > 0 || req.DiscountAmount > 0 {
+		amount := manualDiscount(remaining, req.DiscountPercent, req.DiscountAmount)
+		manual += amount
+		if amount > 0 {
+			orderDiscounts = append(orderDiscounts, models.TransactionDiscount{
+				Source: "manual",
+				Name:   "Manual discount",
+				Amount: amount,
+			})
+		}
+	}
+
+	if err := checkManualDiscount(manual, gross, maxManualPercent); err != nil {
+		return 0, nil, err
+	}
+
+	return subtotal, orderDiscounts, nil
+}
+
+func hasPromotionDiscount(lines []*cartLine, orderDiscounts []models.TransactionDiscount) bool {
+	for _, line := range lines {
+		for _, discount := range line.Discounts {
+			if discount.Source == "promotion" {
+				return true
+			}
+		}
+	}
+	for _, discount := range orderDiscounts {
+		if discount.Source == "promotion" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/models"
+)
+
+func TestApplyDiscountsManualLimit(t *testing.T) {
+	tests := []struct {
+		name          string
+		items         []dto.TransactionItemRequest
+		orderPercent  float64
+		orderAmount   models.Money
+		maxPercent    float64
+		wantErr       bool
+		wantSubtotal  models.Money
+		wantOrderDisc models.Money
+	}{
+		{
+			name:         "line discount within the limit",
+			items:        []dto.TransactionItemRequest{{Quantity: 2, DiscountPercent: 10}, {Quantity: 1}},
+			maxPercent:   10,
+			wantSubtotal: 28000, // 20,000 - 2,000 + 10,000
+		},
+		{
+			name:          "line and order discounts within the limit together",
+			items:         []dto.TransactionItemRequest{{Quantity: 2, DiscountAmount: 1000}, {Quantity: 1}},
+			orderAmount:   2000,
+			maxPercent:    10,
+			wantSubtotal:  29000,
+			wantOrderDisc: 2000,
+		},
+		{
+			name:         "limit on every line and again on the order",
+			items:        []dto.TransactionItemRequest{{Quantity: 2, DiscountPercent: 10}, {Quantity: 1, DiscountPercent: 10}},
+			orderPercent: 10,
+			maxPercent:   10,
+			wantErr:      true,
+		},
+		{
+			name:        "order discount alone above the limit",
+			items:       []dto.TransactionItemRequest{{Quantity: 3}},
+			orderAmount: 3001,
+			maxPercent:  10,
+			wantErr:     true,
+		},
+		{
+			name:          "admin may discount everything",
+			items:         []dto.TransactionItemRequest{{Quantity: 1, DiscountPercent: 100}, {Quantity: 1}},
+			orderPercent:  100,
+			maxPercent:    100,
+			wantSubtotal:  10000,
+			wantOrderDisc: 10000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var lines []*cartLine
+			for _, item := range tt.items {
+				lines = append(lines, &cartLine{Quantity: item.Quantity, UnitPrice: 10000})
+			}
+			req := &dto.CreateTransactionRequest{Items: tt.items, DiscountPercent: tt.orderPercent, DiscountAmount: tt.orderAmount}
+
+			subtotal, orderDiscounts, err := applyDiscounts(lines, nil, nil, req, tt.maxPercent)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyDiscounts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if subtotal != tt.wantSubtotal {
+				t.Errorf("subtotal = %d, want %d", subtotal, tt.wantSubtotal)
+			}
+			var orderDiscount models.Money
+			for _, discount := range orderDiscounts {
+				orderDiscount += discount.Amount
+			}
+			if orderDiscount != tt.wantOrderDisc {
+				t.Errorf("order discount = %d, want %d", orderDiscount, tt.wantOrderDisc)
+			}
+		})
+	}
+}
+
+func TestApplyDiscountsPromotions(t *testing.T) {
+	id := func(id uint) *uint { return &id }
+
+	tests := []struct {
+		name          string
+		promotions    []models.Promotion
+		items         []dto.TransactionItemRequest
+		wantLineDisc  []models.Money
+		wantSubtotal  models.Money
+		wantOrderDisc models.Money
+	}{
+		{
+			name: "best line promotion wins, line promotions never stack",
+			promotions: []models.Promotion{
+				{ID: 1, Type: "percentage", Scope: "product", ProductID: id(1), DiscountPercent: 10},
+				{ID: 2, Type: "fixed", Scope: "category", CategoryID: id(1), DiscountAmount: 1500},
+			},
+			wantLineDisc: []models.Money{3000, 0},
+			wantSubtotal: 32000,
+		},
+		{
+			name: "line and order promotions stack",
+			promotions: []models.Promotion{
+				{ID: 1, Type: "percentage", Scope: "product", ProductID: id(1), DiscountPercent: 10},
+				{ID: 2, Type: "percentage", Scope: "order", DiscountPercent: 10},
+			},
+			wantLineDisc:  []models.Money{2000, 0},
+			wantSubtotal:  33000,
+			wantOrderDisc: 3300, // 10% of the subtotal after line promotions
+		},
+		{
+			name: "best order promotion wins",
+			promotions: []models.Promotion{
+				{ID: 1, Type: "percentage", Scope: "order", DiscountPercent: 10},
+				{ID: 2, Type: "fixed", Scope: "order", DiscountAmount: 5000},
+			},
+			wantLineDisc:  []models.Money{0, 0},
+			wantSubtotal:  35000,
+			wantOrderDisc: 5000,
+		},
+		{
+			name: "order promotion below its minimum subtotal",
+			promotions: []models.Promotion{
+				{ID: 1, Type: "fixed", Scope: "order", DiscountAmount: 5000, MinSubtotal: 50000},
+			},
+			wantLineDisc: []models.Money{0, 0},
+			wantSubtotal: 35000,
+		},
+		{
+			name: "buy two get one free",
+			promotions: []models.Promotion{
+				{ID: 1, Type: "buy_x_get_y", Scope: "product", ProductID: id(2), BuyQuantity: 2, GetQuantity: 1},
+			},
+			wantLineDisc: []models.Money{0, 5000},
+			wantSubtotal: 30000,
+		},
+		{
+			name: "line promotion capped at the line amount",
+			promotions: []models.Promotion{
+				{ID: 1, Type: "fixed", Scope: "product", ProductID: id(2), DiscountAmount: 15000},
+			},
+			wantLineDisc: []models.Money{0, 15000},
+			wantSubtotal: 20000,
+		},
+		{
+			name: "order promotion capped at the subtotal",
+			promotions: []models.Promotion{
+				{ID: 1, Type: "fixed", Scope: "order", DiscountAmount: 100000},
+			},
+			wantLineDisc:  []models.Money{0, 0},
+			wantSubtotal:  35000,
+			wantOrderDisc: 35000,
+		},
+		{
+			name: "manual line discount applies after the promotion",
+			promotions: []models.Promotion{
+				{ID: 1, Type: "percentage", Scope: "product", ProductID: id(1), DiscountPercent: 10},
+			},
+			items:        []dto.TransactionItemRequest{{Quantity: 2, DiscountPercent: 10}, {Quantity: 3}},
+			wantLineDisc: []models.Money{3800, 0}, // 2,000 promotion + 10% of the remaining 18,000
+			wantSubtotal: 31200,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items := tt.items
+			if items == nil {
+				items = []dto.TransactionItemRequest{{Quantity: 2}, {Quantity: 3}}
+			}
+			lines := []*cartLine{
+				{Product: models.Product{ID: 1, CategoryID: 1}, Quantity: items[0].Quantity, UnitPrice: 10000},
+				{Product: models.Product{ID: 2, CategoryID: 2}, Quantity: items[1].Quantity, UnitPrice: 5000},
+			}
+			req := &dto.CreateTransactionRequest{Items: items}
+
+			subtotal, orderDiscounts, err := applyDiscounts(lines, tt.promotions, nil, req, 100)
+			if err != nil {
+				t.Fatalf("applyDiscounts() error = %v", err)
+			}
+			for i, line := range lines {
+				if line.Discount() != tt.wantLineDisc[i] {
+					t.Errorf("line %d discount = %d, want %d", i, line.Discount(), tt.wantLineDisc[i])
+				}
+			}
+			if subtotal != tt.wantSubtotal {
+				t.Errorf("subtotal = %d, want %d", subtotal, tt.wantSubtotal)
+			}
+			var orderDiscount models.Money
+			for _, discount := range orderDiscounts {
+				orderDiscount += discount.Amount
+			}
+			if orderDiscount != tt.wantOrderDisc {
+				t.Errorf("order discount = %d, want %d", orderDiscount, tt.wantOrderDisc)
+			}
+		})
+	}
+}
@@ -2,21 +2,38 @@ package services
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/syrlramadhan/cashier-app/dto"
 	"github.com/syrlramadhan/cashier-app/models"
 	"github.com/syrlramadhan/cashier-app/repositories"
+	"gorm.io/gorm"
 )
 
 type ProductService struct {
-	productRepo  repositories.ProductRepository
-	categoryRepo repositories.CategoryRepository
+	db             *gorm.DB
+	productRepo    repositories.ProductRepository
+	categoryRepo   repositories.CategoryRepository
+	ingredientRepo repositories.IngredientRepository
+	supplierRepo   repositories.SupplierRepository
+	stockService   *StockService
 }
 
-func NewProductService(productRepo repositories.ProductRepository, categoryRepo repositories.CategoryRepository) *ProductService {
+func NewProductService(
+	db *gorm.DB,
+	productRepo repositories.ProductRepository,
+	categoryRepo repositories.CategoryRepository,
+	ingredientRepo repositories.IngredientRepository,
+	supplierRepo repositories.SupplierRepository,
+	stockService *StockService,
+) *ProductService {
 	return &ProductService{
-		productRepo:  productRepo,
-		categoryRepo: categoryRepo,
+		db:             db,
+		productRepo:    productRepo,
+		categoryRepo:   categoryRepo,
+		ingredientRepo: ingredientRepo,
+		supplierRepo:   supplierRepo,
+		stockService:   stockService,
 	}
 }
 
@@ -42,17 +59,10 @@ func (s *ProductService) GetAllProducts(categoryID *uint, search string) ([]dto.
 			continue
 		}
 
-		response = append(response, dto.ProductResponse{
-			ID:         product.ID,
-			Name:       product.Name,
-			Price:      product.Price,
-			Stock:      product.Stock,
-			CategoryID: product.CategoryID,
-			Image:      product.Image,
-		})
+		response = append(response, toProductResponse(&product))
 	}
 
-	return response, nil
+	return s.withAvailability(response)
 }
 
 func (s *ProductService) GetProductByID(id uint) (*dto.ProductResponse, error) {
@@ -61,16 +71,11 @@ func (s *ProductService) GetProductByID(id uint) (*dto.ProductResponse, error) {
 		return nil, errors.New("product not found")
 	}
 
-	response := &dto.ProductResponse{
-		ID:         product.ID,
-		Name:       product.Name,
-		Price:      product.Price,
-		Stock:      product.Stock,
-		CategoryID: product.CategoryID,
-		Image:      product.Image,
+	response, err := s.withAvailability([]dto.ProductResponse{toProductResponse(product)})
+	if err != nil {
+		return nil, err
 	}
-
-	return response, nil
+	return &response[0], nil
 }
 
 func (s *ProductService) GetProductsByCategory(categoryID uint) ([]dto.ProductResponse, error) {
@@ -86,17 +91,10 @@ func (s *ProductService) GetProductsByCategory(categoryID uint) ([]dto.ProductRe
 
 	var response []dto.ProductResponse
 	for _, product := range products {
-		response = append(response, dto.ProductResponse{
-			ID:         product.ID,
-			Name:       product.Name,
-			Price:      product.Price,
-			Stock:      product.Stock,
-			CategoryID: product.CategoryID,
-			Image:      product.Image,
-		})
+		response = append(response, toProductResponse(&product))
 	}
 
-	return response, nil
+	return s.withAvailability(response)
 }
 
 func (s *ProductService) CreateProduct(req *dto.CreateProductRequest) (*dto.ProductResponse, error) {
@@ -105,29 +103,40 @@ func (s *ProductService) CreateProduct(req *dto.CreateProductRequest) (*dto.Prod
 		return nil, errors.New("category not found")
 	}
 
+	if err := s.checkSupplier(req.SupplierID); err != nil {
+		return nil, err
+	}
+
+	prices, err := productPrices(req.Prices)
+	if err != nil {
+		return nil, err
+	}
+
 	product := &models.Product{
 		Name:       req.Name,
 		Price:      req.Price,
+		Cost:       req.Cost,
 		Stock:      req.Stock,
+		MinStock:   req.MinStock,
+		ReorderQty: req.ReorderQty,
+		SupplierID: req.SupplierID,
 		CategoryID: req.CategoryID,
 		Image:      req.Image,
+		Prices:     prices,
 	}
 
-	err = s.productRepo.Create(product)
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.productRepo.WithTx(tx).Create(product); err != nil {
+			return errors.New("failed to create product")
+		}
+		return s.stockService.open(tx, product.ID, nil, product.Stock, req.UserID)
+	})
 	if err != nil {
-		return nil, errors.New("failed to create product")
-	}
-
-	response := &dto.ProductResponse{
-		ID:         product.ID,
-		Name:       product.Name,
-		Price:      product.Price,
-		Stock:      product.Stock,
-		CategoryID: product.CategoryID,
-		Image:      product.Image,
+		return nil, err
 	}
 
-	return response, nil
+	response := toProductResponse(product)
+	return &response, nil
 }
 
 func (s *ProductService) UpdateProduct(id uint, req *dto.UpdateProductRequest) (*dto.ProductResponse, error) {
@@ -141,48 +150,138 @@ func (s *ProductService) UpdateProduct(id uint, req *dto.UpdateProductRequest) (
 		return nil, errors.New("category not found")
 	}
 
+	if err := s.checkSupplier(req.SupplierID); err != nil {
+		return nil, err
+	}
+
+	prices, err := productPrices(req.Prices)
+	if err != nil {
+		return nil, err
+	}
+
 	product.Name = req.Name
 	product.Price = req.Price
-	product.Stock = req.Stock
+	if req.Cost != nil {
+		product.Cost = *req.Cost
+	}
+	product.MinStock = req.MinStock
+	product.ReorderQty = req.ReorderQty
+	product.SupplierID = req.SupplierID
 	product.CategoryID = req.CategoryID
 	product.Image = req.Image
+	product.Prices = nil
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		productRepo := s.productRepo.WithTx(tx)
+
+		if err := productRepo.Update(product); err != nil {
+			return errors.New("failed to update product")
+		}
 
-	err = s.productRepo.Update(product)
+		if err := productRepo.ReplacePrices(product.ID, prices); err != nil {
+			return errors.New("failed to update product prices")
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, errors.New("failed to update product")
+		return nil, err
 	}
+	product.Prices = prices
 
-	response := &dto.ProductResponse{
-		ID:         product.ID,
-		Name:       product.Name,
-		Price:      product.Price,
-		Stock:      product.Stock,
-		CategoryID: product.CategoryID,
-		Image:      product.Image,
+	response := toProductResponse(product)
+	return &response, nil
+}
+
+func (s *ProductService) DeleteProduct(id uint) error {
+	_, err := s.productRepo.FindByID(id)
+	if err != nil {
+		return errors.New("product not found")
 	}
 
-	return response, nil
+	return s.productRepo.Delete(id)
 }
 
-func (s *ProductService) UpdateStock(id uint, quantity int) error {
-	product, err := s.productRepo.FindByID(id)
+func (s *ProductService) checkSupplier(supplierID *uint) error {
+	if supplierID == nil {
+		return nil
+	}
+	if _, err := s.supplierRepo.FindByID(*supplierID); err != nil {
+		return errors.New("supplier not found")
+	}
+	return nil
+}
+
+// withAvailability fills in how many units of each product made to a recipe
+// the ingredients in stock can make
+func (s *ProductService) withAvailability(products []dto.ProductResponse) ([]dto.ProductResponse, error) {
+	var productIDs []uint
+	for _, product := range products {
+		productIDs = append(productIDs, product.ID)
+	}
+
+	recipes, err := s.ingredientRepo.FindRecipes(productIDs, nil, nil)
 	if err != nil {
-		return errors.New("product not found")
+		return nil, err
 	}
 
-	newStock := product.Stock + quantity
-	if newStock < 0 {
-		return errors.New("insufficient stock")
+	byProduct := make(map[uint][]models.RecipeItem)
+	for _, recipe := range recipes {
+		byProduct[*recipe.ProductID] = append(byProduct[*recipe.ProductID], recipe)
+	}
+	for i := range products {
+		if recipe, ok := byProduct[products[i].ID]; ok {
+			available := availableUnits(recipe)
+			products[i].Available = &available
+		}
 	}
+	return products, nil
+}
+
+// productPrices builds the order type price overrides, one per order type
+func productPrices(requests []dto.ProductPriceRequest) ([]models.ProductPrice, error) {
+	seen := make(map[string]bool)
+	var prices []models.ProductPrice
+	for _, req := range requests {
+		if seen[req.OrderType] {
+			return nil, fmt.Errorf("duplicate price for order type %s", req.OrderType)
+		}
+		seen[req.OrderType] = true
 
-	return s.productRepo.UpdateStock(id, newStock)
+		prices = append(prices, models.ProductPrice{
+			OrderType: req.OrderType,
+			Price:     req.Price,
+		})
+	}
+	return prices, nil
 }
 
-func (s *ProductService) DeleteProduct(id uint) error {
-	_, err := s.productRepo.FindByID(id)
-	if err != nil {
-		return errors.New("product not found")
+func toProductResponse(product *models.Product) dto.ProductResponse {
+	response := dto.ProductResponse{
+		ID:         product.ID,
+		Name:       product.Name,
+		Price:      product.Price,
+		Cost:       product.Cost,
+		Stock:      product.Stock,
+		MinStock:   product.MinStock,
+		ReorderQty: product.ReorderQty,
+		SupplierID: product.SupplierID,
+		CategoryID: product.CategoryID,
+		Image:      product.Image,
 	}
 
-	return s.productRepo.Delete(id)
+	for _, price := range product.Prices {
+		response.Prices = append(response.Prices, dto.ProductPriceResponse{
+			OrderType: price.OrderType,
+			Price:     price.Price,
+		})
+	}
+
+	for _, group := range product.VariantGroups {
+		response.VariantGroups = append(response.VariantGroups, toVariantGroupResponse(&group))
+	}
+	for _, variant := range product.Variants {
+		response.Variants = append(response.Variants, toProductVariantResponse(&variant))
+	}
+
+	return response
 }
@@ -0,0 +1,445 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/models"
+	"github.com/syrlramadhan/cashier-app/repositories"
+	"gorm.io/gorm"
+)
+
+// ProductVariantService manages the variant groups (Size, Temperature, ...)
+// and the variants of a product. A variant picks exactly one option of every
+// group and is named after its options, e.g. "Large, Iced".
+type ProductVariantService struct {
+	db           *gorm.DB
+	productRepo  repositories.ProductRepository
+	variantRepo  repositories.ProductVariantRepository
+	stockService *StockService
+}
+
+func NewProductVariantService(
+	db *gorm.DB,
+	productRepo repositories.ProductRepository,
+	variantRepo repositories.ProductVariantRepository,
+	stockService *StockService,
+) *ProductVariantService {
+	return &ProductVariantService{
+		db:           db,
+		productRepo:  productRepo,
+		variantRepo:  variantRepo,
+		stockService: stockService,
+	}
+}
+
+func (s *ProductVariantService) GetVariants(productID uint) (*dto.ProductVariantsResponse, error) {
+	product, err := s.productRepo.FindByID(productID)
+	if err != nil {
+		return nil, errors.New("product not found")
+	}
+
+	response := &dto.ProductVariantsResponse{
+		ProductID: product.ID,
+		Groups:    []dto.VariantGroupResponse{},
+		Variants:  []dto.ProductVariantResponse{},
+	}
+	for _, group := range product.VariantGroups {
+		response.Groups = append(response.Groups, toVariantGroupResponse(&group))
+	}
+	for _, variant := range product.Variants {
+		response.Variants = append(response.Variants, toProductVariantResponse(&variant))
+	}
+
+	return response, nil
+}
+
+// CreateGroup adds a variant group. Existing variants would be missing an
+// option of the new group, so groups can only be added before variants.
+func (s *ProductVariantService) CreateGroup(productID uint, req *dto.VariantGroupRequest) (*dto.VariantGroupResponse, error) {
+	product, err := s.productRepo.FindByID(productID)
+	if err != nil {
+		return nil, errors.New("product not found")
+	}
+
+	if len(product.Variants) > 0 {
+		return nil, errors.New("delete the variants of the product before adding a variant group")
+	}
+	for _, group := range product.VariantGroups {
+		if strings.EqualFold(group.Name, req.Name) {
+			return nil, errors.New("variant group name already exists")
+		}
+	}
+	if err := validateOptionNames(req.Options); err != nil {
+		return nil, err
+	}
+
+	group := &models.ProductVariantGroup{
+		ProductID: product.ID,
+		Name:      req.Name,
+		Position:  req.Position,
+	}
+	for i, option := range req.Options {
+		if option.ID != nil {
+			return nil, errors.New("new variant groups cannot reference existing options")
+		}
+		group.Options = append(group.Options, models.ProductVariantOption{
+			Name:     option.Name,
+			Position: i,
+		})
+	}
+
+	if err := s.variantRepo.CreateGroup(group); err != nil {
+		return nil, errors.New("failed to create variant group")
+	}
+
+	response := toVariantGroupResponse(group)
+	return &response, nil
+}
+
+// UpdateGroup renames and reorders a group and its options. Options left out
+// of the request are removed unless a variant still uses them. Variant names
+// follow the new option names.
+func (s *ProductVariantService) UpdateGroup(productID, groupID uint, req *dto.VariantGroupRequest) (*dto.VariantGroupResponse, error) {
+	product, err := s.productRepo.FindByID(productID)
+	if err != nil {
+		return nil, errors.New("product not found")
+	}
+
+	group, err := s.variantRepo.FindGroupByID(groupID)
+	if err != nil || group.ProductID != product.ID {
+		return nil, errors.New("variant group not found")
+	}
+
+	for _, other := range product.VariantGroups {
+		if other.ID != group.ID && strings.EqualFold(other.Name, req.Name) {
+			return nil, errors.New("variant group name already exists")
+		}
+	}
+	if err := validateOptionNames(req.Options); err != nil {
+		return nil, err
+	}
+
+	existing := make(map[uint]models.ProductVariantOption)
+	for _, option := range group.Options {
+		existing[option.ID] = option
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		variantRepo := s.variantRepo.WithTx(tx)
+
+		group.Name = req.Name
+		group.Position = req.Position
+		if err := variantRepo.UpdateGroup(group); err != nil {
+			return errors.New("failed to update variant group")
+		}
+
+		var options []models.ProductVariantOption
+		for i, optionReq := range req.Options {
+			if optionReq.ID == nil {
+				option := models.ProductVariantOption{GroupID: group.ID, Name: optionReq.Name, Position: i}
+				if err := variantRepo.CreateOption(&option); err != nil {
+					return errors.New("failed to create variant option")
+				}
+				options = append(options, option)
+				continue
+			}
+
+			option, ok := existing[*optionReq.ID]
+			if !ok {
+				return fmt.Errorf("option %d does not belong to this variant group", *optionReq.ID)
+			}
+			delete(existing, option.ID)
+
+			option.Name = optionReq.Name
+			option.Position = i
+			if err := variantRepo.UpdateOption(&option); err != nil {
+				return errors.New("failed to update variant option")
+			}
+			options = append(options, option)
+		}
+
+		// Whatever is left was removed from the group
+		for _, option := range existing {
+			count, err := variantRepo.CountByOptionIDs([]uint{option.ID})
+			if err != nil {
+				return err
+			}
+			if count > 0 {
+				return fmt.Errorf("option %s is used by %d variant(s)", option.Name, count)
+			}
+			if err := variantRepo.DeleteOption(option.ID); err != nil {
+				return errors.New("failed to delete variant option")
+			}
+		}
+		group.Options = options
+
+		return s.refreshVariantNames(tx, product.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := toVariantGroupResponse(group)
+	return &response, nil
+}
+
+// DeleteGroup removes a group and its options once no variant uses them
+func (s *ProductVariantService) DeleteGroup(productID, groupID uint) error {
+	group, err := s.variantRepo.FindGroupByID(groupID)
+	if err != nil || group.ProductID != productID {
+		return errors.New("variant group not found")
+	}
+
+	var optionIDs []uint
+	for _, option := range group.Options {
+		optionIDs = append(optionIDs, option.ID)
+	}
+	if len(optionIDs) > 0 {
+		count, err := s.variantRepo.CountByOptionIDs(optionIDs)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return errors.New("variant group is used by existing variants")
+		}
+	}
+
+	return s.variantRepo.DeleteGroup(group.ID)
+}
+
+func (s *ProductVariantService) CreateVariant(productID uint, req *dto.CreateProductVariantRequest) (*dto.ProductVariantResponse, error) {
+	product, err := s.productRepo.FindByID(productID)
+	if err != nil {
+		return nil, errors.New("product not found")
+	}
+
+	variant := &models.ProductVariant{ProductID: product.ID, Stock: req.Stock}
+	if err := s.applyVariantRequest(product, variant, &req.ProductVariantRequest); err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.variantRepo.WithTx(tx).Create(variant); err != nil {
+			return errors.New("failed to create variant")
+		}
+		if !variant.TrackStock {
+			return nil
+		}
+		return s.stockService.open(tx, product.ID, &variant.ID, variant.Stock, req.UserID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := toProductVariantResponse(variant)
+	return &response, nil
+}
+
+func (s *ProductVariantService) UpdateVariant(productID, variantID uint, req *dto.ProductVariantRequest) (*dto.ProductVariantResponse, error) {
+	product, err := s.productRepo.FindByID(productID)
+	if err != nil {
+		return nil, errors.New("product not found")
+	}
+
+	variant, err := s.variantRepo.FindByID(variantID)
+	if err != nil || variant.ProductID != product.ID {
+		return nil, errors.New("variant not found")
+	}
+
+	if err := s.applyVariantRequest(product, variant, req); err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the product before the variant, in the same order as checkout
+		if _, err := s.productRepo.WithTx(tx).FindByIDsForUpdate([]uint{product.ID}); err != nil {
+			return errors.New("failed to lock product")
+		}
+		if err := s.variantRepo.WithTx(tx).Update(variant); err != nil {
+			return errors.New("failed to update variant")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := toProductVariantResponse(variant)
+	return &response, nil
+}
+
+// DeleteVariant removes a variant; past sales keep the variant name on their lines
+func (s *ProductVariantService) DeleteVariant(productID, variantID uint) error {
+	variant, err := s.variantRepo.FindByID(variantID)
+	if err != nil || variant.ProductID != productID {
+		return errors.New("variant not found")
+	}
+
+	return s.variantRepo.Delete(variant.ID)
+}
+
+// applyVariantRequest validates req against the variant groups of product and
+// copies it onto variant
+func (s *ProductVariantService) applyVariantRequest(product *models.Product, variant *models.ProductVariant, req *dto.ProductVariantRequest) error {
+	if len(product.VariantGroups) == 0 {
+		return errors.New("product has no variant groups")
+	}
+
+	// Every group must be given exactly one option
+	optionGroups := make(map[uint]*models.ProductVariantGroup)
+	optionsByID := make(map[uint]models.ProductVariantOption)
+	for i := range product.VariantGroups {
+		for _, option := range product.VariantGroups[i].Options {
+			optionGroups[option.ID] = &product.VariantGroups[i]
+			optionsByID[option.ID] = option
+		}
+	}
+
+	chosen := make(map[uint]bool)
+	var options []models.ProductVariantOption
+	for _, optionID := range req.OptionIDs {
+		group, ok := optionGroups[optionID]
+		if !ok {
+			return fmt.Errorf("option %d does not belong to this product", optionID)
+		}
+		if chosen[group.ID] {
+			return fmt.Errorf("choose only one option of %s", group.Name)
+		}
+		chosen[group.ID] = true
+		options = append(options, optionsByID[optionID])
+	}
+	for _, group := range product.VariantGroups {
+		if !chosen[group.ID] {
+			return fmt.Errorf("choose an option of %s", group.Name)
+		}
+	}
+
+	key := optionKey(options)
+	for _, other := range product.Variants {
+		if other.ID != variant.ID && optionKey(other.Options) == key {
+			return errors.New("a variant with these options already exists")
+		}
+	}
+
+	if req.Price == nil && product.Price+req.PriceDelta <= 0 {
+		return errors.New("variant price must be greater than zero")
+	}
+
+	variant.SKU = req.SKU
+	variant.Name = variantName(product.VariantGroups, options)
+	variant.Price = req.Price
+	variant.PriceDelta = req.PriceDelta
+	variant.TrackStock = req.TrackStock
+	variant.IsActive = req.IsActive == nil || *req.IsActive
+	variant.Options = options
+	return nil
+}
+
+// refreshVariantNames renames the variants of a product after its options changed
+func (s *ProductVariantService) refreshVariantNames(tx *gorm.DB, productID uint) error {
+	variantRepo := s.variantRepo.WithTx(tx)
+
+	groups, err := variantRepo.FindGroupsByProductID(productID)
+	if err != nil {
+		return err
+	}
+	variants, err := variantRepo.FindByProductID(productID)
+	if err != nil {
+		return err
+	}
+
+	for i := range variants {
+		name := variantName(groups, variants[i].Options)
+		if name == variants[i].Name {
+			continue
+		}
+		variants[i].Name = name
+		if err := variantRepo.Update(&variants[i]); err != nil {
+			return errors.New("failed to rename variant")
+		}
+	}
+	return nil
+}
+
+// variantName joins the option names in the order of their groups, e.g. "Large, Iced"
+func variantName(groups []models.ProductVariantGroup, options []models.ProductVariantOption) string {
+	position := make(map[uint]int)
+	for i, group := range groups {
+		position[group.ID] = i
+	}
+
+	sorted := append([]models.ProductVariantOption(nil), options...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return position[sorted[i].GroupID] < position[sorted[j].GroupID]
+	})
+
+	names := make([]string, 0, len(sorted))
+	for _, option := range sorted {
+		names = append(names, option.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// optionKey identifies a combination of options regardless of their order
+func optionKey(options []models.ProductVariantOption) string {
+	ids := make([]int, 0, len(options))
+	for _, option := range options {
+		ids = append(ids, int(option.ID))
+	}
+	sort.Ints(ids)
+	return fmt.Sprint(ids)
+}
+
+func validateOptionNames(options []dto.VariantOptionRequest) error {
+	seen := make(map[string]bool)
+	for _, option := range options {
+		name := strings.ToLower(option.Name)
+		if seen[name] {
+			return fmt.Errorf("duplicate option: %s", option.Name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+func toVariantGroupResponse(group *models.ProductVariantGroup) dto.VariantGroupResponse {
+	response := dto.VariantGroupResponse{
+		ID:       group.ID,
+		Name:     group.Name,
+		Position: group.Position,
+		Options:  []dto.VariantOptionResponse{},
+	}
+	for _, option := range group.Options {
+		response.Options = append(response.Options, dto.VariantOptionResponse{
+			ID:   option.ID,
+			Name: option.Name,
+		})
+	}
+	return response
+}
+
+func toProductVariantResponse(variant *models.ProductVariant) dto.ProductVariantResponse {
+	response := dto.ProductVariantResponse{
+		ID:         variant.ID,
+		ProductID:  variant.ProductID,
+		SKU:        variant.SKU,
+		Name:       variant.Name,
+		Price:      variant.Price,
+		PriceDelta: variant.PriceDelta,
+		TrackStock: variant.TrackStock,
+		Stock:      variant.Stock,
+		IsActive:   variant.IsActive,
+		Options:    []dto.VariantOptionResponse{},
+	}
+	for _, option := range variant.Options {
+		response.Options = append(response.Options, dto.VariantOptionResponse{
+			ID:   option.ID,
+			Name: option.Name,
+		})
+	}
+	return response
+}
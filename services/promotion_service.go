@@ -0,0 +1,322 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/models"
+	"github.com/syrlramadhan/cashier-app/repositories"
+)
+
+type PromotionService struct {
+	promotionRepo repositories.PromotionRepository
+	productRepo   repositories.ProductRepository
+	categoryRepo  repositories.CategoryRepository
+}
+
+func NewPromotionService(
+	promotionRepo repositories.PromotionRepository,
+	productRepo repositories.ProductRepository,
+	categoryRepo repositories.CategoryRepository,
+) *PromotionService {
+	return &PromotionService{
+		promotionRepo: promotionRepo,
+		productRepo:   productRepo,
+		categoryRepo:  categoryRepo,
+	}
+}
+
+func (s *PromotionService) GetAllPromotions() ([]dto.PromotionResponse, error) {
+	promotions, err := s.promotionRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var response []dto.PromotionResponse
+	for _, promotion := range promotions {
+		response = append(response, *s.mapPromotionToResponse(&promotion))
+	}
+
+	return response, nil
+}
+
+// GetActivePromotions lists the promotions that apply right now, for the POS screen
+func (s *PromotionService) GetActivePromotions() ([]dto.PromotionResponse, error) {
+	promotions, err := s.activePromotions(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	var response []dto.PromotionResponse
+	for _, promotion := range promotions {
+		response = append(response, *s.mapPromotionToResponse(&promotion))
+	}
+
+	return response, nil
+}
+
+func (s *PromotionService) GetPromotionByID(id uint) (*dto.PromotionResponse, error) {
+	promotion, err := s.promotionRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("promotion not found")
+	}
+
+	return s.mapPromotionToResponse(promotion), nil
+}
+
+func (s *PromotionService) CreatePromotion(req *dto.PromotionRequest) (*dto.PromotionResponse, error) {
+	if err := s.validatePromotion(req); err != nil {
+		return nil, err
+	}
+
+	promotion := &models.Promotion{IsActive: true}
+	applyPromotionRequest(promotion, req)
+
+	err := s.promotionRepo.Create(promotion)
+	if err != nil {
+		return nil, errors.New("failed to create promotion")
+	}
+
+	return s.mapPromotionToResponse(promotion), nil
+}
+
+func (s *PromotionService) UpdatePromotion(id uint, req *dto.PromotionRequest) (*dto.PromotionResponse, error) {
+	promotion, err := s.promotionRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("promotion not found")
+	}
+
+	if err := s.validatePromotion(req); err != nil {
+		return nil, err
+	}
+
+	applyPromotionRequest(promotion, req)
+
+	err = s.promotionRepo.Update(promotion)
+	if err != nil {
+		return nil, errors.New("failed to update promotion")
+	}
+
+	return s.mapPromotionToResponse(promotion), nil
+}
+
+func (s *PromotionService) DeletePromotion(id uint) error {
+	_, err := s.promotionRepo.FindByID(id)
+	if err != nil {
+		return errors.New("promotion not found")
+	}
+
+	return s.promotionRepo.Delete(id)
+}
+
+func (s *PromotionService) validatePromotion(req *dto.PromotionRequest) error {
+	switch req.Type {
+	case "percentage":
+		if req.DiscountPercent <= 0 {
+			return errors.New("discount_percent is required for percentage promotions")
+		}
+	case "fixed":
+		if req.DiscountAmount <= 0 {
+			return errors.New("discount_amount is required for fixed promotions")
+		}
+	case "buy_x_get_y":
+		if req.BuyQuantity < 1 || req.GetQuantity < 1 {
+			return errors.New("buy_quantity and get_quantity are required for buy_x_get_y promotions")
+		}
+		if req.Scope == "order" {
+			return errors.New("buy_x_get_y promotions must target a product or category")
+		}
+	}
+
+	switch req.Scope {
+	case "product":
+		if req.ProductID == nil {
+			return errors.New("product_id is required for product promotions")
+		}
+		if _, err := s.productRepo.FindByID(*req.ProductID); err != nil {
+			return errors.New("product not found")
+		}
+	case "category":
+		if req.CategoryID == nil {
+			return errors.New("category_id is required for category promotions")
+		}
+		if _, err := s.categoryRepo.FindByID(*req.CategoryID); err != nil {
+			return errors.New("category not found")
+		}
+	}
+
+	if req.StartsAt != nil && req.EndsAt != nil && req.EndsAt.Before(*req.StartsAt) {
+		return errors.New("ends_at must be after starts_at")
+	}
+
+	if (req.StartTime == "") != (req.EndTime == "") {
+		return errors.New("start_time and end_time must be set together")
+	}
+
+	return nil
+}
+
+// activePromotions returns the promotions that apply at now, including their happy-hour window
+func (s *PromotionService) activePromotions(now time.Time) ([]models.Promotion, error) {
+	promotions, err := s.promotionRepo.FindActive(now)
+	if err != nil {
+		return nil, errors.New("failed to load promotions")
+	}
+
+	var active []models.Promotion
+	for _, promotion := range promotions {
+		if withinDailyWindow(&promotion, now) {
+			active = append(active, promotion)
+		}
+	}
+	return active, nil
+}
+
+func (s *PromotionService) mapPromotionToResponse(promotion *models.Promotion) *dto.PromotionResponse {
+	return &dto.PromotionResponse{
+		ID:              promotion.ID,
+		Name:            promotion.Name,
+		Type:            promotion.Type,
+		Scope:           promotion.Scope,
+		DiscountPercent: promotion.DiscountPercent,
+		DiscountAmount:  promotion.DiscountAmount,
+		BuyQuantity:     promotion.BuyQuantity,
+		GetQuantity:     promotion.GetQuantity,
+		ProductID:       promotion.ProductID,
+		CategoryID:      promotion.CategoryID,
+		MinSubtotal:     promotion.MinSubtotal,
+		StartsAt:        promotion.StartsAt,
+		EndsAt:          promotion.EndsAt,
+		StartTime:       promotion.StartTime,
+		EndTime:         promotion.EndTime,
+		IsActive:        promotion.IsActive,
+	}
+}
+
+func applyPromotionRequest(promotion *models.Promotion, req *dto.PromotionRequest) {
+	promotion.Name = req.Name
+	promotion.Type = req.Type
+	promotion.Scope = req.Scope
+	promotion.DiscountPercent = req.DiscountPercent
+	promotion.DiscountAmount = req.DiscountAmount
+	promotion.BuyQuantity = req.BuyQuantity
+	promotion.GetQuantity = req.GetQuantity
+	promotion.ProductID = nil
+	promotion.CategoryID = nil
+	if req.Scope == "product" {
+		promotion.ProductID = req.ProductID
+	}
+	if req.Scope == "category" {
+		promotion.CategoryID = req.CategoryID
+	}
+	promotion.MinSubtotal = req.MinSubtotal
+	promotion.StartsAt = req.StartsAt
+	promotion.EndsAt = req.EndsAt
+	promotion.StartTime = req.StartTime
+	promotion.EndTime = req.EndTime
+	if req.IsActive != nil {
+		promotion.IsActive = *req.IsActive
+	}
+}
+
+// withinDailyWindow checks the optional happy-hour window; windows may cross midnight
+func withinDailyWindow(promotion *models.Promotion, now time.Time) bool {
+	if promotion.StartTime == "" || promotion.EndTime == "" {
+		return true
+	}
+
+	current := now.Format("15:04")
+	if promotion.StartTime <= promotion.EndTime {
+		return current >= promotion.StartTime && current < promotion.EndTime
+	}
+	return current >= promotion.StartTime || current < promotion.EndTime
+}
+
+// applyLinePromotions gives each line the single best product or category
+// promotion; line promotions never stack with each other.
+func applyLinePromotions(lines []*cartLine, promotions []models.Promotion) {
+	for _, line := range lines {
+		var best *models.Promotion
+		var bestAmount models.Money
+
+		for i := range promotions {
+			promotion := &promotions[i]
+			if !promotionMatchesLine(promotion, line) {
+				continue
+			}
+			if amount := linePromotionDiscount(promotion, line); amount > bestAmount {
+				best, bestAmount = promotion, amount
+			}
+		}
+
+		if best != nil {
+			line.Discounts = append(line.Discounts, models.TransactionDiscount{
+				PromotionID: &best.ID,
+				Source:      "promotion",
+				Name:        best.Name,
+				Amount:      bestAmount,
+			})
+		}
+	}
+}
+
+func promotionMatchesLine(promotion *models.Promotion, line *cartLine) bool {
+	switch promotion.Scope {
+	case "product":
+		return promotion.ProductID != nil && *promotion.ProductID == line.Product.ID
+	case "category":
+		return promotion.CategoryID != nil && *promotion.CategoryID == line.Product.CategoryID
+	}
+	return false
+}
+
+func linePromotionDiscount(promotion *models.Promotion, line *cartLine) models.Money {
+	gross := line.Gross()
+
+	var amount models.Money
+	switch promotion.Type {
+	case "percentage":
+		amount = gross.MulRate(promotion.DiscountPercent / 100)
+	case "fixed":
+		amount = promotion.DiscountAmount.Mul(line.Quantity)
+	case "buy_x_get_y":
+		free := line.Quantity / (promotion.BuyQuantity + promotion.GetQuantity) * promotion.GetQuantity
+		amount = line.UnitPrice.Mul(free)
+	}
+
+	if amount > gross {
+		amount = gross
+	}
+	return amount
+}
+
+// bestOrderPromotion returns the order promotion giving the biggest discount on subtotal
+func bestOrderPromotion(promotions []models.Promotion, subtotal models.Money) (*models.Promotion, models.Money) {
+	var best *models.Promotion
+	var bestAmount models.Money
+
+	for i := range promotions {
+		promotion := &promotions[i]
+		if promotion.Scope != "order" || subtotal < promotion.MinSubtotal {
+			continue
+		}
+
+		var amount models.Money
+		switch promotion.Type {
+		case "percentage":
+			amount = subtotal.MulRate(promotion.DiscountPercent / 100)
+		case "fixed":
+			amount = promotion.DiscountAmount
+		}
+		if amount > subtotal {
+			amount = subtotal
+		}
+
+		if amount > bestAmount {
+			best, bestAmount = promotion, amount
+		}
+	}
+
+	return best, bestAmount
+}
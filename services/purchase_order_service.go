@@ -0,0 +1,578 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/models"
+	"github.com/syrlramadhan/cashier-app/repositories"
+	"gorm.io/gorm"
+)
+
+// PurchaseOrderService orders stock from suppliers and receives it. Receiving
+// puts the goods in stock through the stock ledger and keeps the average
+// cost of each product up to date.
+type PurchaseOrderService struct {
+	db                *gorm.DB
+	purchaseOrderRepo repositories.PurchaseOrderRepository
+	supplierRepo      repositories.SupplierRepository
+	productRepo       repositories.ProductRepository
+	variantRepo       repositories.ProductVariantRepository
+	ingredientRepo    repositories.IngredientRepository
+	sequenceService   *SequenceService
+	stockService      *StockService
+}
+
+func NewPurchaseOrderService(
+	db *gorm.DB,
+	purchaseOrderRepo repositories.PurchaseOrderRepository,
+	supplierRepo repositories.SupplierRepository,
+	productRepo repositories.ProductRepository,
+	variantRepo repositories.ProductVariantRepository,
+	ingredientRepo repositories.IngredientRepository,
+	sequenceService *SequenceService,
+	stockService *StockService,
+) *PurchaseOrderService {
+	return &PurchaseOrderService{
+		db:                db,
+		purchaseOrderRepo: purchaseOrderRepo,
+		supplierRepo:      supplierRepo,
+		productRepo:       productRepo,
+		variantRepo:       variantRepo,
+		ingredientRepo:    ingredientRepo,
+		sequenceService:   sequenceService,
+		stockService:      stockService,
+	}
+}
+
+func (s *PurchaseOrderService) GetAllPurchaseOrders(status string, supplierID uint) ([]dto.PurchaseOrderResponse, error) {
+	orders, err := s.purchaseOrderRepo.FindAll(status, supplierID)
+	if err != nil {
+		return nil, err
+	}
+
+	var response []dto.PurchaseOrderResponse
+	for _, order := range orders {
+		response = append(response, *toPurchaseOrderResponse(&order))
+	}
+
+	return response, nil
+}
+
+func (s *PurchaseOrderService) GetPurchaseOrderByID(id uint) (*dto.PurchaseOrderResponse, error) {
+	order, err := s.purchaseOrderRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("purchase order not found")
+	}
+
+	return toPurchaseOrderResponse(order), nil
+}
+
+func (s *PurchaseOrderService) CreatePurchaseOrder(req *dto.PurchaseOrderRequest) (*dto.PurchaseOrderResponse, error) {
+	order := &models.PurchaseOrder{
+		Status:    "draft",
+		CreatedBy: req.UserID,
+	}
+	if err := s.applyPurchaseOrderRequest(order, req); err != nil {
+		return nil, err
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		number, err := s.sequenceService.NextCode(tx, "purchase_order", "PO", time.Now())
+		if err != nil {
+			return err
+		}
+		order.PONumber = number
+
+		if err := s.purchaseOrderRepo.WithTx(tx).Create(order); err != nil {
+			return errors.New("failed to create purchase order")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetPurchaseOrderByID(order.ID)
+}
+
+// UpdatePurchaseOrder replaces the supplier and lines of a draft
+func (s *PurchaseOrderService) UpdatePurchaseOrder(id uint, req *dto.PurchaseOrderRequest) (*dto.PurchaseOrderResponse, error) {
+	order, err := s.purchaseOrderRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("purchase order not found")
+	}
+
+	if order.Status != "draft" {
+		return nil, errors.New("only draft purchase orders can be changed")
+	}
+
+	if err := s.applyPurchaseOrderRequest(order, req); err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		purchaseOrderRepo := s.purchaseOrderRepo.WithTx(tx)
+
+		locked, err := purchaseOrderRepo.FindByIDForUpdate(id)
+		if err != nil {
+			return errors.New("purchase order not found")
+		}
+		if locked.Status != "draft" {
+			return errors.New("only draft purchase orders can be changed")
+		}
+
+		if err := purchaseOrderRepo.Update(order); err != nil {
+			return errors.New("failed to update purchase order")
+		}
+		if err := purchaseOrderRepo.ReplaceItems(order.ID, order.Items); err != nil {
+			return errors.New("failed to update purchase order items")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetPurchaseOrderByID(order.ID)
+}
+
+// SendPurchaseOrder marks a draft as sent to the supplier; from then on its
+// lines are fixed and goods can be received
+func (s *PurchaseOrderService) SendPurchaseOrder(id uint) (*dto.PurchaseOrderResponse, error) {
+	return s.transition(id, []string{"draft"}, func(order *models.PurchaseOrder, now time.Time) {
+		order.Status = "sent"
+		order.SentAt = &now
+	})
+}
+
+// ClosePurchaseOrder closes an order once nothing more is expected, e.g.
+// when the supplier cannot deliver the rest of a partial delivery
+func (s *PurchaseOrderService) ClosePurchaseOrder(id uint) (*dto.PurchaseOrderResponse, error) {
+	return s.transition(id, []string{"partially_received", "received"}, func(order *models.PurchaseOrder, now time.Time) {
+		order.Status = "closed"
+		order.ClosedAt = &now
+	})
+}
+
+// CancelPurchaseOrder cancels an order before anything was received
+func (s *PurchaseOrderService) CancelPurchaseOrder(id uint) (*dto.PurchaseOrderResponse, error) {
+	return s.transition(id, []string{"draft", "sent"}, func(order *models.PurchaseOrder, now time.Time) {
+		order.Status = "cancelled"
+		order.ClosedAt = &now
+	})
+}
+
+// ReceivePurchaseOrder books a delivery against a sent purchase order. The
+// received units go into stock with a "receive" movement, on the variant for
+// variant lines, and the product cost becomes the average of the stock on
+// hand and the delivery. Deliveries may be partial, short, or at a different
+// cost, and are kept on the goods receipt; receiving more than is still
+// outstanding is refused. Everything happens in one database transaction.
+func (s *PurchaseOrderService) ReceivePurchaseOrder(id uint, req *dto.ReceivePurchaseOrderRequest) (*dto.PurchaseOrderResponse, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		purchaseOrderRepo := s.purchaseOrderRepo.WithTx(tx)
+		productRepo := s.productRepo.WithTx(tx)
+
+		order, err := purchaseOrderRepo.FindByIDForUpdate(id)
+		if err != nil {
+			return errors.New("purchase order not found")
+		}
+
+		if order.Status != "sent" && order.Status != "partially_received" {
+			return fmt.Errorf("cannot receive goods on a %s purchase order", order.Status)
+		}
+
+		items := make(map[uint]*models.PurchaseOrderItem)
+		for i := range order.Items {
+			items[order.Items[i].ID] = &order.Items[i]
+		}
+
+		seen := make(map[uint]bool)
+		productIDs := make(map[uint]int)
+		variantIDs := make(map[uint]int)
+		var stocked []models.PurchaseOrderItem
+		for _, itemReq := range req.Items {
+			item, ok := items[itemReq.PurchaseOrderItemID]
+			if !ok {
+				return fmt.Errorf("item %d does not belong to this purchase order", itemReq.PurchaseOrderItemID)
+			}
+			if itemReq.Quantity+itemReq.RejectedQuantity == 0 {
+				return fmt.Errorf("nothing received for %s", purchaseItemName(item))
+			}
+			if seen[item.ID] {
+				return fmt.Errorf("%s is listed more than once", purchaseItemName(item))
+			}
+			seen[item.ID] = true
+
+			if outstanding := item.Quantity - item.ReceivedQuantity; itemReq.Quantity > outstanding {
+				return fmt.Errorf("cannot receive %d of %s, only %d outstanding", itemReq.Quantity, purchaseItemName(item), outstanding)
+			}
+			if itemReq.Quantity == 0 {
+				continue
+			}
+			stocked = append(stocked, *item)
+			productIDs[item.ProductID] += itemReq.Quantity
+			if item.VariantID != nil {
+				variantIDs[*item.VariantID] += itemReq.Quantity
+			}
+		}
+
+		if err := s.refuseRecipes(tx, stocked); err != nil {
+			return err
+		}
+
+		// Products before variants, as in checkout
+		products := make(map[uint]models.Product)
+		locked, err := productRepo.FindByIDsForUpdate(sortedProductIDs(productIDs))
+		if err != nil {
+			return errors.New("failed to lock products")
+		}
+		for _, product := range locked {
+			products[product.ID] = product
+		}
+
+		variants := make(map[uint]models.ProductVariant)
+		if len(variantIDs) > 0 {
+			locked, err := s.variantRepo.WithTx(tx).FindByIDsForUpdate(sortedProductIDs(variantIDs))
+			if err != nil {
+				return errors.New("failed to lock variants")
+			}
+			for _, variant := range locked {
+				variants[variant.ID] = variant
+			}
+		}
+
+		receipt := &models.GoodsReceipt{
+			PurchaseOrderID: order.ID,
+			ReceivedBy:      req.UserID,
+			Notes:           req.Notes,
+		}
+		var movements []models.StockMovement
+		for _, itemReq := range req.Items {
+			item := items[itemReq.PurchaseOrderItemID]
+
+			unitCost := item.UnitCost
+			if itemReq.UnitCost != nil {
+				unitCost = *itemReq.UnitCost
+			}
+
+			item.ReceivedQuantity += itemReq.Quantity
+			item.RejectedQuantity += itemReq.RejectedQuantity
+			order.ReceivedTotal += unitCost.Mul(itemReq.Quantity)
+			receipt.Items = append(receipt.Items, models.GoodsReceiptItem{
+				PurchaseOrderItemID: item.ID,
+				ProductID:           item.ProductID,
+				VariantID:           item.VariantID,
+				Quantity:            itemReq.Quantity,
+				RejectedQuantity:    itemReq.RejectedQuantity,
+				UnitCost:            unitCost,
+				Note:                itemReq.Note,
+			})
+
+			if itemReq.Quantity == 0 {
+				continue
+			}
+
+			product, ok := products[item.ProductID]
+			if !ok {
+				return fmt.Errorf("product not found: %s", item.ProductName)
+			}
+			movement := models.StockMovement{
+				ProductID:   product.ID,
+				Quantity:    itemReq.Quantity,
+				Balance:     product.Stock + itemReq.Quantity,
+				Reason:      "receive",
+				ReferenceID: &order.ID,
+				UserID:      &req.UserID,
+				Note:        order.PONumber,
+			}
+
+			// A variant delivery is averaged with the stock of that variant
+			onHand := product.Stock
+			if item.VariantID != nil {
+				variant, ok := variants[*item.VariantID]
+				if !ok || variant.ProductID != product.ID {
+					return fmt.Errorf("variant not found: %s", purchaseItemName(item))
+				}
+				if !variant.TrackStock {
+					return fmt.Errorf("%s does not keep its own stock", purchaseItemName(item))
+				}
+				onHand = variant.Stock
+				movement.VariantID = &variant.ID
+				movement.Balance = variant.Stock + itemReq.Quantity
+			}
+
+			product.Cost = averageCost(onHand, product.Cost, itemReq.Quantity, unitCost)
+			products[product.ID] = product
+			if err := productRepo.UpdateCost(product.ID, product.Cost); err != nil {
+				return errors.New("failed to update product cost")
+			}
+			movements = append(movements, movement)
+		}
+
+		if err := purchaseOrderRepo.CreateReceipt(receipt); err != nil {
+			return errors.New("failed to create goods receipt")
+		}
+
+		complete := true
+		for i := range order.Items {
+			if order.Items[i].ReceivedQuantity < order.Items[i].Quantity {
+				complete = false
+			}
+			if err := purchaseOrderRepo.UpdateItem(&order.Items[i]); err != nil {
+				return errors.New("failed to update purchase order item")
+			}
+		}
+
+		order.Status = "partially_received"
+		if complete {
+			order.Status = "received"
+		}
+		if err := purchaseOrderRepo.Update(order); err != nil {
+			return errors.New("failed to update purchase order")
+		}
+
+		return s.stockService.record(tx, movements)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetPurchaseOrderByID(id)
+}
+
+// refuseRecipes refuses lines for products, or variants, made to a recipe.
+// Selling them takes their ingredients instead, so stock received for them
+// would never go out again. tx may be nil outside a transaction.
+func (s *PurchaseOrderService) refuseRecipes(tx *gorm.DB, items []models.PurchaseOrderItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	ingredientRepo := s.ingredientRepo
+	if tx != nil {
+		ingredientRepo = ingredientRepo.WithTx(tx)
+	}
+
+	var productIDs, variantIDs []uint
+	for _, item := range items {
+		productIDs = append(productIDs, item.ProductID)
+		if item.VariantID != nil {
+			variantIDs = append(variantIDs, *item.VariantID)
+		}
+	}
+	recipes, err := ingredientRepo.FindRecipes(productIDs, variantIDs, nil)
+	if err != nil {
+		return errors.New("failed to load recipes")
+	}
+
+	madeProducts := make(map[uint]bool)
+	madeVariants := make(map[uint]bool)
+	for _, recipe := range recipes {
+		if recipe.ProductID != nil {
+			madeProducts[*recipe.ProductID] = true
+		}
+		if recipe.VariantID != nil {
+			madeVariants[*recipe.VariantID] = true
+		}
+	}
+
+	for i := range items {
+		item := &items[i]
+		if madeProducts[item.ProductID] || (item.VariantID != nil && madeVariants[*item.VariantID]) {
+			return fmt.Errorf("%s is made to a recipe; order its ingredients instead", purchaseItemName(item))
+		}
+	}
+	return nil
+}
+
+// transition moves a purchase order from one of the allowed statuses
+func (s *PurchaseOrderService) transition(id uint, from []string, apply func(order *models.PurchaseOrder, now time.Time)) (*dto.PurchaseOrderResponse, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		purchaseOrderRepo := s.purchaseOrderRepo.WithTx(tx)
+
+		order, err := purchaseOrderRepo.FindByIDForUpdate(id)
+		if err != nil {
+			return errors.New("purchase order not found")
+		}
+
+		allowed := false
+		for _, status := range from {
+			if order.Status == status {
+				allowed = true
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("purchase order is %s", order.Status)
+		}
+
+		apply(order, time.Now())
+		if err := purchaseOrderRepo.Update(order); err != nil {
+			return errors.New("failed to update purchase order")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetPurchaseOrderByID(id)
+}
+
+// applyPurchaseOrderRequest validates req and copies it onto order
+func (s *PurchaseOrderService) applyPurchaseOrderRequest(order *models.PurchaseOrder, req *dto.PurchaseOrderRequest) error {
+	supplier, err := s.supplierRepo.FindByID(req.SupplierID)
+	if err != nil {
+		return errors.New("supplier not found")
+	}
+	if !supplier.IsActive {
+		return fmt.Errorf("supplier %s is not active", supplier.Name)
+	}
+
+	seen := make(map[stockKey]bool)
+	var items []models.PurchaseOrderItem
+	var total models.Money
+	for _, itemReq := range req.Items {
+		key := stockKeyOf(itemReq.ProductID, itemReq.VariantID)
+		if seen[key] {
+			return fmt.Errorf("%s is listed more than once", key)
+		}
+		seen[key] = true
+
+		product, err := s.productRepo.FindByID(itemReq.ProductID)
+		if err != nil {
+			return fmt.Errorf("product not found: %d", itemReq.ProductID)
+		}
+
+		unitCost := itemReq.UnitCost
+		if unitCost == 0 {
+			unitCost = product.Cost
+		}
+
+		item := models.PurchaseOrderItem{
+			ProductID:   product.ID,
+			ProductName: product.Name,
+			Quantity:    itemReq.Quantity,
+			UnitCost:    unitCost,
+		}
+
+		// A variant without its own stock sells out of the product, so the
+		// product is ordered instead
+		if itemReq.VariantID != nil {
+			variant, err := s.variantRepo.FindByID(*itemReq.VariantID)
+			if err != nil || variant.ProductID != product.ID {
+				return fmt.Errorf("variant not found: %d", *itemReq.VariantID)
+			}
+			if !variant.TrackStock {
+				return fmt.Errorf("%s (%s) does not keep its own stock; order %s instead", product.Name, variant.Name, product.Name)
+			}
+			item.VariantID = &variant.ID
+			item.VariantName = variant.Name
+		}
+
+		items = append(items, item)
+		total += unitCost.Mul(itemReq.Quantity)
+	}
+
+	if err := s.refuseRecipes(nil, items); err != nil {
+		return err
+	}
+
+	order.SupplierID = supplier.ID
+	order.Supplier = *supplier
+	order.ExpectedAt = req.ExpectedAt
+	order.Notes = req.Notes
+	order.Total = total
+	order.Items = items
+	return nil
+}
+
+// purchaseItemName is the product name of a line, with its variant
+func purchaseItemName(item *models.PurchaseOrderItem) string {
+	if item.VariantID != nil {
+		return item.ProductName + " (" + item.VariantName + ")"
+	}
+	return item.ProductName
+}
+
+// averageCost is the cost of one unit after receiving quantity units at
+// unitCost on top of stock units at cost. Negative stock counts as none.
+func averageCost(stock int, cost models.Money, quantity int, unitCost models.Money) models.Money {
+	if stock < 0 {
+		stock = 0
+	}
+	if stock+quantity <= 0 {
+		return cost
+	}
+	value := cost.Mul(stock) + unitCost.Mul(quantity)
+	return value.MulDiv(1, int64(stock+quantity))
+}
+
+func toPurchaseOrderResponse(order *models.PurchaseOrder) *dto.PurchaseOrderResponse {
+	response := &dto.PurchaseOrderResponse{
+		ID:            order.ID,
+		PONumber:      order.PONumber,
+		SupplierID:    order.SupplierID,
+		SupplierName:  order.Supplier.Name,
+		Status:        order.Status,
+		ExpectedAt:    order.ExpectedAt,
+		Notes:         order.Notes,
+		Total:         order.Total,
+		ReceivedTotal: order.ReceivedTotal,
+		CreatedBy:     order.Creator.Name,
+		SentAt:        order.SentAt,
+		ClosedAt:      order.ClosedAt,
+		Items:         []dto.PurchaseOrderItemResponse{},
+		CreatedAt:     order.CreatedAt,
+	}
+
+	orderedCost := make(map[uint]models.Money)
+	productNames := make(map[uint]string)
+	for _, item := range order.Items {
+		orderedCost[item.ID] = item.UnitCost
+		productNames[item.ID] = item.ProductName
+		response.Items = append(response.Items, dto.PurchaseOrderItemResponse{
+			ID:               item.ID,
+			ProductID:        item.ProductID,
+			ProductName:      item.ProductName,
+			VariantID:        item.VariantID,
+			VariantName:      item.VariantName,
+			Quantity:         item.Quantity,
+			ReceivedQuantity: item.ReceivedQuantity,
+			RejectedQuantity: item.RejectedQuantity,
+			Outstanding:      item.Quantity - item.ReceivedQuantity,
+			UnitCost:         item.UnitCost,
+			Subtotal:         item.UnitCost.Mul(item.Quantity),
+		})
+	}
+
+	for _, receipt := range order.Receipts {
+		receiptResponse := dto.GoodsReceiptResponse{
+			ID:         receipt.ID,
+			ReceivedBy: receipt.Receiver.Name,
+			Notes:      receipt.Notes,
+			Items:      []dto.GoodsReceiptItemResponse{},
+			CreatedAt:  receipt.CreatedAt,
+		}
+		for _, item := range receipt.Items {
+			receiptResponse.Total += item.UnitCost.Mul(item.Quantity)
+			receiptResponse.Items = append(receiptResponse.Items, dto.GoodsReceiptItemResponse{
+				PurchaseOrderItemID: item.PurchaseOrderItemID,
+				ProductID:           item.ProductID,
+				ProductName:         productNames[item.PurchaseOrderItemID],
+				VariantID:           item.VariantID,
+				Quantity:            item.Quantity,
+				RejectedQuantity:    item.RejectedQuantity,
+				UnitCost:            item.UnitCost,
+				CostVariance:        item.UnitCost - orderedCost[item.PurchaseOrderItemID],
+				Note:                item.Note,
+			})
+		}
+		response.Receipts = append(response.Receipts, receiptResponse)
+	}
+
+	return response
+}
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/models"
+)
+
+// newTestPurchaseOrders sets up a sent purchase order for 10 bags of coffee
+// and 4 medium T-shirts, whose variant keeps its own stock, and a latte made
+// to a recipe
+func newTestPurchaseOrders(t *testing.T, status string) (*fakeDB, *PurchaseOrderService) {
+	db := newFakeDB()
+	latte, medium := uint(3), uint(10)
+	db.products[1] = models.Product{ID: 1, Name: "Kopi Bubuk", Stock: 6, Cost: 50000}
+	db.products[2] = models.Product{ID: 2, Name: "T-Shirt", Stock: 5, Cost: 40000}
+	db.products[3] = models.Product{ID: 3, Name: "Latte"}
+	db.variants[10] = models.ProductVariant{ID: 10, ProductID: 2, Name: "M", TrackStock: true, Stock: 2}
+	db.variants[11] = models.ProductVariant{ID: 11, ProductID: 2, Name: "L"}
+	db.recipes = []models.RecipeItem{{ID: 1, ProductID: &latte, IngredientID: 1, Quantity: 18}}
+	db.orders[1] = models.PurchaseOrder{
+		ID:       1,
+		PONumber: "PO-20240115-0001",
+		Status:   status,
+		Items: []models.PurchaseOrderItem{
+			{ID: 1, PurchaseOrderID: 1, ProductID: 1, ProductName: "Kopi Bubuk", Quantity: 10, UnitCost: 60000},
+			{ID: 2, PurchaseOrderID: 1, ProductID: 2, ProductName: "T-Shirt", VariantID: &medium, VariantName: "M", Quantity: 4, UnitCost: 46000},
+			{ID: 3, PurchaseOrderID: 1, ProductID: 3, ProductName: "Latte", Quantity: 5, UnitCost: 20000},
+		},
+	}
+	db.nextID = 100
+
+	productRepo := &fakeProductRepository{db: db}
+	return db, NewPurchaseOrderService(
+		db.open(t),
+		&fakePurchaseOrderRepository{db: db},
+		&fakeSupplierRepository{suppliers: []models.Supplier{{ID: 1, Name: "Toko Kopi", IsActive: true}}},
+		productRepo,
+		&fakeProductVariantRepository{db: db},
+		&fakeIngredientRepository{db: db},
+		newTestSequenceService(db, nil),
+		newTestStockService(db),
+	)
+}
+
+func TestReceivePurchaseOrder(t *testing.T) {
+	db, service := newTestPurchaseOrders(t, "sent")
+	newCost := models.Money(54000)
+
+	// First delivery: 4 of the 10 bags plus 1 damaged one, and all the shirts
+	order, err := service.ReceivePurchaseOrder(1, &dto.ReceivePurchaseOrderRequest{
+		Items: []dto.ReceiveItemRequest{
+			{PurchaseOrderItemID: 1, Quantity: 4, RejectedQuantity: 1},
+			{PurchaseOrderItemID: 2, Quantity: 4},
+		},
+		UserID: 1,
+	})
+	if err != nil {
+		t.Fatalf("ReceivePurchaseOrder() error = %v", err)
+	}
+
+	if order.Status != "partially_received" {
+		t.Errorf("status = %s, want partially_received", order.Status)
+	}
+	if stock := db.products[1].Stock; stock != 10 {
+		t.Errorf("coffee stock = %d, want 10 without the rejected bag", stock)
+	}
+	// (6 x 50000 + 4 x 60000) / 10
+	if cost := db.products[1].Cost; cost != 54000 {
+		t.Errorf("coffee cost = %d, want 54000", cost)
+	}
+	if stock := db.variants[10].Stock; stock != 6 {
+		t.Errorf("shirt M stock = %d, want 6", stock)
+	}
+	if stock := db.products[2].Stock; stock != 5 {
+		t.Errorf("shirt product stock = %d, want 5", stock)
+	}
+	// Averaged with the stock of the variant: (2 x 40000 + 4 x 46000) / 6
+	if cost := db.products[2].Cost; cost != 44000 {
+		t.Errorf("shirt cost = %d, want 44000", cost)
+	}
+	if order.Items[0].RejectedQuantity != 1 || order.Items[0].Outstanding != 6 {
+		t.Errorf("coffee line = %+v, want 1 rejected and 6 outstanding", order.Items[0])
+	}
+
+	// Second delivery: the rest of the coffee, invoiced at a different cost
+	order, err = service.ReceivePurchaseOrder(1, &dto.ReceivePurchaseOrderRequest{
+		Items:  []dto.ReceiveItemRequest{{PurchaseOrderItemID: 1, Quantity: 6, UnitCost: &newCost}},
+		UserID: 1,
+	})
+	if err != nil {
+		t.Fatalf("ReceivePurchaseOrder() error = %v", err)
+	}
+
+	if order.Status != "partially_received" {
+		t.Errorf("status = %s, want partially_received while the latte line is open", order.Status)
+	}
+	if stock := db.products[1].Stock; stock != 16 {
+		t.Errorf("coffee stock = %d, want 16", stock)
+	}
+	if cost := db.products[1].Cost; cost != 54000 {
+		t.Errorf("coffee cost = %d, want 54000", cost)
+	}
+	if len(order.Receipts) != 2 || order.Receipts[1].Items[0].CostVariance != -6000 {
+		t.Errorf("receipts = %+v, want a second receipt with a cost variance of -6000", order.Receipts)
+	}
+	// 4 x 60000 + 4 x 46000 + 6 x 54000
+	if order.ReceivedTotal != 748000 {
+		t.Errorf("received total = %d, want 748000", order.ReceivedTotal)
+	}
+
+	want := []struct {
+		productID uint
+		variant   bool
+		quantity  int
+		balance   int
+	}{
+		{productID: 1, quantity: 4, balance: 10},
+		{productID: 2, variant: true, quantity: 4, balance: 6},
+		{productID: 1, quantity: 6, balance: 16},
+	}
+	if len(db.movements) != len(want) {
+		t.Fatalf("%d stock movements, want %d", len(db.movements), len(want))
+	}
+	for i, movement := range db.movements {
+		if movement.ProductID != want[i].productID || (movement.VariantID != nil) != want[i].variant ||
+			movement.Quantity != want[i].quantity || movement.Balance != want[i].balance || movement.Reason != "receive" {
+			t.Errorf("movement %d = %+v, want %+v", i, movement, want[i])
+		}
+	}
+}
+
+func TestReceivePurchaseOrderCompletes(t *testing.T) {
+	db, service := newTestPurchaseOrders(t, "sent")
+	order := db.orders[1]
+	order.Items = order.Items[:2]
+	db.orders[1] = order
+
+	received, err := service.ReceivePurchaseOrder(1, &dto.ReceivePurchaseOrderRequest{
+		Items: []dto.ReceiveItemRequest{
+			{PurchaseOrderItemID: 1, Quantity: 10},
+			{PurchaseOrderItemID: 2, Quantity: 4},
+		},
+		UserID: 1,
+	})
+	if err != nil {
+		t.Fatalf("ReceivePurchaseOrder() error = %v", err)
+	}
+	if received.Status != "received" {
+		t.Errorf("status = %s, want received", received.Status)
+	}
+}
+
+func TestReceivePurchaseOrderRefused(t *testing.T) {
+	tests := []struct {
+		name   string
+		status string
+		items  []dto.ReceiveItemRequest
+	}{
+		{name: "draft", status: "draft", items: []dto.ReceiveItemRequest{{PurchaseOrderItemID: 1, Quantity: 1}}},
+		{name: "cancelled", status: "cancelled", items: []dto.ReceiveItemRequest{{PurchaseOrderItemID: 1, Quantity: 1}}},
+		{name: "more than ordered", status: "sent", items: []dto.ReceiveItemRequest{{PurchaseOrderItemID: 1, Quantity: 11}}},
+		{name: "more than outstanding", status: "partially_received", items: []dto.ReceiveItemRequest{
+			{PurchaseOrderItemID: 2, Quantity: 4},
+			{PurchaseOrderItemID: 1, Quantity: 10},
+		}},
+		{name: "product made to a recipe", status: "sent", items: []dto.ReceiveItemRequest{
+			{PurchaseOrderItemID: 1, Quantity: 1},
+			{PurchaseOrderItemID: 3, Quantity: 5},
+		}},
+		{name: "line of another order", status: "sent", items: []dto.ReceiveItemRequest{{PurchaseOrderItemID: 9, Quantity: 1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, service := newTestPurchaseOrders(t, tt.status)
+			if tt.status == "partially_received" {
+				order := db.orders[1]
+				order.Items[0].ReceivedQuantity = 1
+				db.orders[1] = order
+			}
+
+			_, err := service.ReceivePurchaseOrder(1, &dto.ReceivePurchaseOrderRequest{Items: tt.items, UserID: 1})
+			if err == nil {
+				t.Fatal("ReceivePurchaseOrder() succeeded, want an error")
+			}
+
+			if stock := db.products[1].Stock; stock != 6 {
+				t.Errorf("coffee stock = %d, want 6", stock)
+			}
+			if stock := db.variants[10].Stock; stock != 2 {
+				t.Errorf("shirt M stock = %d, want 2", stock)
+			}
+			if status := db.orders[1].Status; status != tt.status {
+				t.Errorf("status = %s, want %s", status, tt.status)
+			}
+			if len(db.movements) != 0 {
+				t.Errorf("%d stock movements, want none", len(db.movements))
+			}
+		})
+	}
+}
+
+func TestCreatePurchaseOrderRefusesLines(t *testing.T) {
+	variant := func(id uint) *uint { return &id }
+
+	tests := []struct {
+		name string
+		item dto.PurchaseOrderItemRequest
+	}{
+		{name: "product made to a recipe", item: dto.PurchaseOrderItemRequest{ProductID: 3, Quantity: 5}},
+		{name: "variant without its own stock", item: dto.PurchaseOrderItemRequest{ProductID: 2, VariantID: variant(11), Quantity: 5}},
+		{name: "variant of another product", item: dto.PurchaseOrderItemRequest{ProductID: 1, VariantID: variant(10), Quantity: 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, service := newTestPurchaseOrders(t, "sent")
+
+			_, err := service.CreatePurchaseOrder(&dto.PurchaseOrderRequest{
+				SupplierID: 1,
+				Items:      []dto.PurchaseOrderItemRequest{tt.item},
+				UserID:     1,
+			})
+			if err == nil {
+				t.Error("CreatePurchaseOrder() succeeded, want an error")
+			}
+		})
+	}
+}
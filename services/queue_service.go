@@ -0,0 +1,108 @@
+package services
+
+import (
+	"time"
+
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/models"
+	"github.com/syrlramadhan/cashier-app/repositories"
+	"gorm.io/gorm"
+)
+
+// queueTopic is the event hub topic the queue board displays subscribe to
+const queueTopic = "queue"
+
+// QueueService runs the customer-facing queue board. Counter orders get a
+// daily queue number at checkout and move from preparing to ready to
+// collected as the kitchen bumps their tickets.
+type QueueService struct {
+	transactionRepo repositories.TransactionRepository
+	ticketRepo      repositories.KitchenTicketRepository
+	eventHub        *EventHub
+}
+
+func NewQueueService(
+	transactionRepo repositories.TransactionRepository,
+	ticketRepo repositories.KitchenTicketRepository,
+	eventHub *EventHub,
+) *QueueService {
+	return &QueueService{
+		transactionRepo: transactionRepo,
+		ticketRepo:      ticketRepo,
+		eventHub:        eventHub,
+	}
+}
+
+// GetBoard lists today's queue numbers that are preparing or ready
+func (s *QueueService) GetBoard() (*dto.QueueBoardResponse, error) {
+	now := time.Now()
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	transactions, err := s.transactionRepo.FindQueueBoard(startOfDay)
+	if err != nil {
+		return nil, err
+	}
+
+	board := &dto.QueueBoardResponse{Preparing: []int{}, Ready: []int{}}
+	for _, transaction := range transactions {
+		if transaction.FulfillmentStatus == "ready" {
+			board.Ready = append(board.Ready, transaction.QueueNumber)
+		} else {
+			board.Preparing = append(board.Preparing, transaction.QueueNumber)
+		}
+	}
+
+	return board, nil
+}
+
+// Subscribe returns the board updates for a queue board stream
+func (s *QueueService) Subscribe() (<-chan Event, func()) {
+	return s.eventHub.Subscribe(queueTopic)
+}
+
+// refreshFulfillment derives the fulfillment status of an order from its
+// kitchen tickets inside tx: preparing while any ticket is open, ready once
+// every ticket is ready and collected once every ticket is served.
+func (s *QueueService) refreshFulfillment(tx *gorm.DB, transactionID uint) error {
+	tickets, err := s.ticketRepo.WithTx(tx).FindByTransactionID(transactionID)
+	if err != nil {
+		return err
+	}
+
+	status := fulfillmentStatus(tickets)
+	if status == "" {
+		return nil
+	}
+	return s.transactionRepo.WithTx(tx).UpdateFulfillmentStatus(transactionID, status)
+}
+
+// publishBoard pushes the current board to the queue displays
+func (s *QueueService) publishBoard() {
+	board, err := s.GetBoard()
+	if err != nil {
+		return
+	}
+
+	s.eventHub.Publish(Event{
+		Topic: queueTopic,
+		Type:  "board",
+		Data:  board,
+	})
+}
+
+func fulfillmentStatus(tickets []models.KitchenTicket) string {
+	status := ""
+	for _, ticket := range tickets {
+		switch ticket.Status {
+		case "new", "in_progress":
+			return "preparing"
+		case "ready":
+			status = "ready"
+		case "served":
+			if status == "" {
+				status = "collected"
+			}
+		}
+	}
+	return status
+}
@@ -0,0 +1,256 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/models"
+	"github.com/syrlramadhan/cashier-app/repositories"
+	"gorm.io/gorm"
+)
+
+type RefundService struct {
+	db                  *gorm.DB
+	refundRepo          repositories.RefundRepository
+	transactionRepo     repositories.TransactionRepository
+	transactionItemRepo repositories.TransactionItemRepository
+	sequenceService     *SequenceService
+	stockService        *StockService
+}
+
+func NewRefundService(
+	db *gorm.DB,
+	refundRepo repositories.RefundRepository,
+	transactionRepo repositories.TransactionRepository,
+	transactionItemRepo repositories.TransactionItemRepository,
+	sequenceService *SequenceService,
+	stockService *StockService,
+) *RefundService {
+	return &RefundService{
+		db:                  db,
+		refundRepo:          refundRepo,
+		transactionRepo:     transactionRepo,
+		transactionItemRepo: transactionItemRepo,
+		sequenceService:     sequenceService,
+		stockService:        stockService,
+	}
+}
+
+func (s *RefundService) GetRefundsByTransaction(transactionID uint) ([]dto.RefundResponse, error) {
+	_, err := s.transactionRepo.FindByID(transactionID)
+	if err != nil {
+		return nil, errors.New("transaction not found")
+	}
+
+	refunds, err := s.refundRepo.FindByTransactionID(transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var response []dto.RefundResponse
+	for _, refund := range refunds {
+		response = append(response, *s.mapRefundToResponse(&refund))
+	}
+
+	return response, nil
+}
+
+// CreateRefund returns the selected items of a completed transaction. Each
+// line is refunded at its prorated share of the transaction total, and the
+// transaction row is locked so concurrent refunds cannot exceed what was sold.
+// A split order is refunded as a whole order once all its bills are paid,
+// against what its bills collected.
+func (s *RefundService) CreateRefund(transactionID uint, req *dto.CreateRefundRequest) (*dto.RefundResponse, error) {
+	var refundID uint
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		transactionRepo := s.transactionRepo.WithTx(tx)
+		transactionItemRepo := s.transactionItemRepo.WithTx(tx)
+		refundRepo := s.refundRepo.WithTx(tx)
+
+		transaction, err := transactionRepo.FindByIDForUpdate(transactionID)
+		if err != nil {
+			return errors.New("transaction not found")
+		}
+
+		if transaction.ParentID != nil {
+			return errors.New("split bills cannot be refunded; refund their split order instead")
+		}
+
+		var bills []models.Transaction
+		if transaction.Status == "split" {
+			bills, err = transactionRepo.FindChildrenForUpdate(transaction.ID)
+			if err != nil {
+				return errors.New("failed to load split bills")
+			}
+		}
+		base, err := refundBase(transaction, bills)
+		if err != nil {
+			return err
+		}
+
+		items := make(map[uint]*models.TransactionItem)
+		for i := range transaction.Items {
+			items[transaction.Items[i].ID] = &transaction.Items[i]
+		}
+
+		refund := &models.Refund{
+			TransactionID: transaction.ID,
+			ApprovedBy:    req.ApprovedBy,
+			Reason:        req.Reason,
+			RefundMethod:  req.RefundMethod,
+			Restock:       req.Restock,
+		}
+		restocked, err := prorateRefund(refund, items, base, transaction.RefundedTotal, req.Items)
+		if err != nil {
+			return err
+		}
+
+		refund.RefundCode, err = s.sequenceService.NextCode(tx, "refund", "RFD", time.Now())
+		if err != nil {
+			return err
+		}
+
+		if err := refundRepo.Create(refund); err != nil {
+			return errors.New("failed to create refund")
+		}
+
+		for _, item := range items {
+			if err := transactionItemRepo.Update(item); err != nil {
+				return errors.New("failed to update transaction item")
+			}
+		}
+
+		transaction.RefundedTotal += refund.Total
+		transaction.Items = nil
+		if err := transactionRepo.Update(transaction); err != nil {
+			return errors.New("failed to update transaction")
+		}
+
+		if req.Restock {
+			origin := models.StockMovement{Reason: "refund", ReferenceID: &refund.ID, UserID: &req.ApprovedBy}
+			if err := s.stockService.restock(tx, restocked, origin); err != nil {
+				return err
+			}
+		}
+
+		refundID = refund.ID
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	refund, err := s.refundRepo.FindByID(refundID)
+	if err != nil {
+		return nil, errors.New("refund not found")
+	}
+
+	return s.mapRefundToResponse(refund), nil
+}
+
+// refundBase is the money a refund is prorated against: the total of a
+// completed transaction, or what the bills of a split order collected. A
+// split order can only be refunded once every bill is paid.
+func refundBase(transaction *models.Transaction, bills []models.Transaction) (models.Money, error) {
+	switch transaction.Status {
+	case "completed":
+		return transaction.Total, nil
+	case "split":
+		var collected models.Money
+		for _, bill := range bills {
+			if bill.Status != "completed" {
+				return 0, errors.New("every split bill must be paid before the order can be refunded")
+			}
+			collected += bill.Total
+		}
+		return collected, nil
+	}
+	return 0, errors.New("only completed transactions can be refunded")
+}
+
+// prorateRefund adds the requested lines to refund, each at its share of base
+// so tax and rounding are refunded too, and returns the lines to restock
+// per product. Once everything is returned the refund takes exactly what is
+// left of base, so rounding never over- or under-refunds.
+func prorateRefund(refund *models.Refund, items map[uint]*models.TransactionItem, base, refunded models.Money, requests []dto.RefundItemRequest) ([]restockLine, error) {
+	var itemsSubtotal models.Money
+	for _, item := range items {
+		itemsSubtotal += item.Subtotal
+	}
+
+	var restock []restockLine
+	for _, itemReq := range requests {
+		item, ok := items[itemReq.TransactionItemID]
+		if !ok {
+			return nil, fmt.Errorf("item %d does not belong to this transaction", itemReq.TransactionItemID)
+		}
+
+		if item.RefundedQty+itemReq.Quantity > item.Quantity {
+			return nil, fmt.Errorf("cannot refund more than %d of %s", item.Quantity-item.RefundedQty, item.ProductName)
+		}
+
+		lineSubtotal := item.Subtotal.MulDiv(int64(itemReq.Quantity), int64(item.Quantity))
+		var amount models.Money
+		if itemsSubtotal > 0 {
+			amount = base.MulDiv(int64(lineSubtotal), int64(itemsSubtotal))
+		}
+
+		item.RefundedQty += itemReq.Quantity
+		refund.Total += amount
+		refund.Items = append(refund.Items, models.RefundItem{
+			TransactionItemID: item.ID,
+			ProductID:         item.ProductID,
+			ProductName:       item.ProductName,
+			Quantity:          itemReq.Quantity,
+			Amount:            amount,
+		})
+		restock = append(restock, restockLine{Item: *item, Quantity: itemReq.Quantity})
+	}
+
+	fullyRefunded := true
+	for _, item := range items {
+		if item.RefundedQty < item.Quantity {
+			fullyRefunded = false
+		}
+	}
+	remaining := base - refunded
+	if len(refund.Items) > 0 && (fullyRefunded || refund.Total > remaining) {
+		refund.Items[len(refund.Items)-1].Amount += remaining - refund.Total
+		refund.Total = remaining
+	}
+	return restock, nil
+}
+
+func (s *RefundService) mapRefundToResponse(refund *models.Refund) *dto.RefundResponse {
+	var itemResponses []dto.RefundItemResponse
+	for _, item := range refund.Items {
+		itemResponses = append(itemResponses, dto.RefundItemResponse{
+			ID:                item.ID,
+			TransactionItemID: item.TransactionItemID,
+			ProductID:         item.ProductID,
+			ProductName:       item.ProductName,
+			Quantity:          item.Quantity,
+			Amount:            item.Amount,
+		})
+	}
+
+	approvedBy := ""
+	if refund.Approver.ID > 0 {
+		approvedBy = refund.Approver.Name
+	}
+
+	return &dto.RefundResponse{
+		ID:            refund.ID,
+		RefundCode:    refund.RefundCode,
+		TransactionID: refund.TransactionID,
+		ApprovedBy:    approvedBy,
+		Reason:        refund.Reason,
+		RefundMethod:  refund.RefundMethod,
+		Restock:       refund.Restock,
+		Total:         refund.Total,
+		Items:         itemResponses,
+		CreatedAt:     refund.CreatedAt,
+	}
+}
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/models"
+)
+
+func TestProrateRefund(t *testing.T) {
+	// An order of 100,000 with a 10,000 discount and 11% tax came to 99,900
+	base := models.Money(99900)
+
+	tests := []struct {
+		name        string
+		refundedQty map[uint]int
+		refunded    models.Money
+		requests    []dto.RefundItemRequest
+		want        models.Money
+		wantAmounts []models.Money
+		wantErr     bool
+	}{
+		{
+			name:        "part of a line carries its share of discount and tax",
+			requests:    []dto.RefundItemRequest{{TransactionItemID: 1, Quantity: 1}},
+			want:        26973,
+			wantAmounts: []models.Money{26973},
+		},
+		{
+			name: "several lines",
+			requests: []dto.RefundItemRequest{
+				{TransactionItemID: 1, Quantity: 1},
+				{TransactionItemID: 2, Quantity: 2},
+			},
+			want:        38961,
+			wantAmounts: []models.Money{26973, 11988},
+		},
+		{
+			name:        "a third of a line rounds to the rupiah",
+			requests:    []dto.RefundItemRequest{{TransactionItemID: 3, Quantity: 1}},
+			want:        7326, // 99,900 * 7,333 / 100,000
+			wantAmounts: []models.Money{7326},
+		},
+		{
+			name: "full refund returns the total",
+			requests: []dto.RefundItemRequest{
+				{TransactionItemID: 1, Quantity: 2},
+				{TransactionItemID: 2, Quantity: 4},
+				{TransactionItemID: 3, Quantity: 3},
+			},
+			want:        99900,
+			wantAmounts: []models.Money{53946, 23976, 21978},
+		},
+		{
+			name:        "last refund takes the remainder",
+			refundedQty: map[uint]int{1: 2, 2: 4, 3: 2},
+			refunded:    92563, // earlier refunds, rounded differently
+			requests:    []dto.RefundItemRequest{{TransactionItemID: 3, Quantity: 1}},
+			want:        7337,
+			wantAmounts: []models.Money{7337},
+		},
+		{
+			name:     "more than was bought",
+			requests: []dto.RefundItemRequest{{TransactionItemID: 1, Quantity: 3}},
+			wantErr:  true,
+		},
+		{
+			name:        "already refunded",
+			refundedQty: map[uint]int{2: 3},
+			requests:    []dto.RefundItemRequest{{TransactionItemID: 2, Quantity: 2}},
+			wantErr:     true,
+		},
+		{
+			name:     "item of another transaction",
+			requests: []dto.RefundItemRequest{{TransactionItemID: 9, Quantity: 1}},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items := map[uint]*models.TransactionItem{
+				1: {ID: 1, ProductName: "Nasi Goreng", Quantity: 2, Subtotal: 54000},
+				2: {ID: 2, ProductName: "Es Teh", Quantity: 4, Subtotal: 24000},
+				3: {ID: 3, ProductName: "Kerupuk", Quantity: 3, Subtotal: 22000},
+			}
+			for id, qty := range tt.refundedQty {
+				items[id].RefundedQty = qty
+			}
+
+			refund := &models.Refund{}
+			restocked, err := prorateRefund(refund, items, base, tt.refunded, tt.requests)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("prorateRefund() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if refund.Total != tt.want {
+				t.Errorf("refund total = %d, want %d", refund.Total, tt.want)
+			}
+			var sum models.Money
+			for i, item := range refund.Items {
+				sum += item.Amount
+				if i < len(tt.wantAmounts) && item.Amount != tt.wantAmounts[i] {
+					t.Errorf("line %d amount = %d, want %d", i, item.Amount, tt.wantAmounts[i])
+				}
+			}
+			if sum != refund.Total {
+				t.Errorf("line amounts sum to %d, want %d", sum, refund.Total)
+			}
+			var wantRestocked, gotRestocked int
+			for _, request := range tt.requests {
+				wantRestocked += request.Quantity
+			}
+			for _, line := range restocked {
+				gotRestocked += line.Quantity
+			}
+			if gotRestocked != wantRestocked {
+				t.Errorf("restocked %d units, want %d", gotRestocked, wantRestocked)
+			}
+		})
+	}
+}
+
+func TestRefundBase(t *testing.T) {
+	tests := []struct {
+		name        string
+		transaction models.Transaction
+		bills       []models.Transaction
+		want        models.Money
+		wantErr     bool
+	}{
+		{
+			name:        "completed transaction uses its total",
+			transaction: models.Transaction{Status: "completed", Total: 55500},
+			want:        55500,
+		},
+		{
+			name:        "split order uses what its bills collected",
+			transaction: models.Transaction{Status: "split", Total: 100050},
+			bills: []models.Transaction{
+				{Status: "completed", Total: 50000},
+				{Status: "completed", Total: 50100}, // cash rounding on the last bill
+			},
+			want: 100100,
+		},
+		{
+			name:        "split order with an unpaid bill",
+			transaction: models.Transaction{Status: "split", Total: 100000},
+			bills: []models.Transaction{
+				{Status: "completed", Total: 50000},
+				{Status: "unpaid", Total: 50000},
+			},
+			wantErr: true,
+		},
+		{
+			name:        "cancelled transaction",
+			transaction: models.Transaction{Status: "cancelled", Total: 10000},
+			wantErr:     true,
+		},
+		{
+			name:        "open tab",
+			transaction: models.Transaction{Status: "open", Total: 10000},
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := refundBase(&tt.transaction, tt.bills)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("refundBase() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("refundBase() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProrateRefundSplitOrder(t *testing.T) {
+	// A split order of two lines whose bills collected 100,100 including cash rounding
+	items := map[uint]*models.TransactionItem{
+		1: {ID: 1, ProductName: "Nasi Goreng", Quantity: 2, Subtotal: 60000},
+		2: {ID: 2, ProductName: "Es Teh", Quantity: 4, Subtotal: 30000},
+	}
+	base := models.Money(100100)
+
+	refund := &models.Refund{}
+	_, err := prorateRefund(refund, items, base, 0, []dto.RefundItemRequest{{TransactionItemID: 1, Quantity: 1}})
+	if err != nil {
+		t.Fatalf("prorateRefund() error = %v", err)
+	}
+	if refund.Total != 33367 { // 100,100 * 30,000 / 90,000
+		t.Errorf("first refund = %d, want 33367", refund.Total)
+	}
+
+	// Refunding the rest returns exactly what the bills collected
+	rest := &models.Refund{}
+	_, err = prorateRefund(rest, items, base, refund.Total, []dto.RefundItemRequest{
+		{TransactionItemID: 1, Quantity: 1},
+		{TransactionItemID: 2, Quantity: 4},
+	})
+	if err != nil {
+		t.Fatalf("prorateRefund() error = %v", err)
+	}
+	if refund.Total+rest.Total != base {
+		t.Errorf("refunds total %d, want %d", refund.Total+rest.Total, base)
+	}
+}
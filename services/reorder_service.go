@@ -0,0 +1,244 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/models"
+	"github.com/syrlramadhan/cashier-app/repositories"
+)
+
+// ReorderService suggests what to order from the recent sales of each
+// product. A product should hold enough stock for its supplier's lead time
+// plus the cover days, on top of its minimum stock; the suggestion is what is
+// missing after the stock on hand and what is already on order.
+type ReorderService struct {
+	productRepo          repositories.ProductRepository
+	supplierRepo         repositories.SupplierRepository
+	transactionItemRepo  repositories.TransactionItemRepository
+	purchaseOrderRepo    repositories.PurchaseOrderRepository
+	settingService       *SettingService
+	stockAlertService    *StockAlertService
+	purchaseOrderService *PurchaseOrderService
+}
+
+func NewReorderService(
+	productRepo repositories.ProductRepository,
+	supplierRepo repositories.SupplierRepository,
+	transactionItemRepo repositories.TransactionItemRepository,
+	purchaseOrderRepo repositories.PurchaseOrderRepository,
+	settingService *SettingService,
+	stockAlertService *StockAlertService,
+	purchaseOrderService *PurchaseOrderService,
+) *ReorderService {
+	return &ReorderService{
+		productRepo:          productRepo,
+		supplierRepo:         supplierRepo,
+		transactionItemRepo:  transactionItemRepo,
+		purchaseOrderRepo:    purchaseOrderRepo,
+		settingService:       settingService,
+		stockAlertService:    stockAlertService,
+		purchaseOrderService: purchaseOrderService,
+	}
+}
+
+// GetSuggestions reports the sales velocity, days of cover and suggested
+// order quantity of the products that keep their own stock, those running
+// out first at the top
+func (s *ReorderService) GetSuggestions(filter *dto.ReorderFilter) (*dto.ReorderReportResponse, error) {
+	report, err := s.suggest(filter.Days, filter.CoverDays, func(product *models.Product) bool {
+		return filter.SupplierID == 0 || (product.SupplierID != nil && *product.SupplierID == filter.SupplierID)
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if !filter.All {
+		items := []dto.ReorderSuggestionResponse{}
+		for _, item := range report.Items {
+			if item.SuggestedQuantity > 0 {
+				items = append(items, item)
+			}
+		}
+		report.Items = items
+	}
+	return report, nil
+}
+
+// CreatePurchaseOrder turns the suggestions for a supplier into a draft
+// purchase order, expected after the supplier's lead time. Listed products
+// are ordered from the supplier even when they usually come from another.
+func (s *ReorderService) CreatePurchaseOrder(req *dto.ReorderPurchaseOrderRequest) (*dto.PurchaseOrderResponse, error) {
+	supplier, err := s.supplierRepo.FindByID(req.SupplierID)
+	if err != nil {
+		return nil, errors.New("supplier not found")
+	}
+
+	listed := make(map[uint]bool)
+	for _, productID := range req.ProductIDs {
+		listed[productID] = true
+	}
+
+	report, err := s.suggest(req.Days, req.CoverDays, func(product *models.Product) bool {
+		if len(listed) > 0 {
+			return listed[product.ID]
+		}
+		return product.SupplierID != nil && *product.SupplierID == supplier.ID
+	}, supplier)
+	if err != nil {
+		return nil, err
+	}
+
+	orderReq := &dto.PurchaseOrderRequest{
+		SupplierID: supplier.ID,
+		Notes:      req.Notes,
+		UserID:     req.UserID,
+	}
+	if orderReq.Notes == "" {
+		orderReq.Notes = "Reorder suggestion"
+	}
+	expectedAt := time.Now().AddDate(0, 0, supplier.LeadTimeDays)
+	orderReq.ExpectedAt = &expectedAt
+
+	for _, item := range report.Items {
+		if item.SuggestedQuantity <= 0 {
+			continue
+		}
+		orderReq.Items = append(orderReq.Items, dto.PurchaseOrderItemRequest{
+			ProductID: item.ProductID,
+			Quantity:  item.SuggestedQuantity,
+		})
+	}
+	if len(orderReq.Items) == 0 {
+		return nil, errors.New("nothing needs to be reordered from this supplier")
+	}
+
+	return s.purchaseOrderService.CreatePurchaseOrder(orderReq)
+}
+
+// suggest computes the suggestions for the products accepted by include.
+// When supplier is given its lead time is used for every product, otherwise
+// each product's own supplier.
+func (s *ReorderService) suggest(days, coverDays int, include func(product *models.Product) bool, supplier *models.Supplier) (*dto.ReorderReportResponse, error) {
+	if days <= 0 {
+		days = s.settingService.GetInt("reorder_window_days", 30)
+	}
+	if coverDays <= 0 {
+		coverDays = s.settingService.GetInt("reorder_cover_days", 7)
+	}
+	if days <= 0 {
+		return nil, errors.New("invalid reorder_window_days setting")
+	}
+
+	endDate := time.Now()
+	startDate := endDate.AddDate(0, 0, -days)
+
+	products, err := s.productRepo.FindStocked()
+	if err != nil {
+		return nil, err
+	}
+	suppliers, err := s.supplierRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	sold, err := s.transactionItemRepo.GetSoldQuantities(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	onOrder, err := s.purchaseOrderRepo.GetOnOrderQuantities()
+	if err != nil {
+		return nil, err
+	}
+
+	suppliersByID := make(map[uint]models.Supplier)
+	for _, other := range suppliers {
+		suppliersByID[other.ID] = other
+	}
+	soldByProduct := quantitiesByProduct(sold)
+	onOrderByProduct := quantitiesByProduct(onOrder)
+	defaultMin := s.stockAlertService.lowStockThreshold()
+
+	report := &dto.ReorderReportResponse{
+		StartDate: startDate,
+		EndDate:   endDate,
+		Days:      days,
+		CoverDays: coverDays,
+		Items:     []dto.ReorderSuggestionResponse{},
+	}
+	for i := range products {
+		product := &products[i]
+		if !include(product) {
+			continue
+		}
+
+		item := dto.ReorderSuggestionResponse{
+			ProductID:   product.ID,
+			ProductName: product.Name,
+			SupplierID:  product.SupplierID,
+			Stock:       product.Stock,
+			MinStock:    product.MinStockOr(defaultMin),
+			OnOrder:     onOrderByProduct[product.ID],
+			Sold:        soldByProduct[product.ID],
+			UnitCost:    product.Cost,
+		}
+
+		orderFrom, known := models.Supplier{}, false
+		if supplier != nil {
+			orderFrom, known = *supplier, true
+		} else if product.SupplierID != nil {
+			orderFrom, known = suppliersByID[*product.SupplierID]
+		}
+		if known {
+			item.SupplierID = &orderFrom.ID
+			item.SupplierName = orderFrom.Name
+			item.LeadTimeDays = orderFrom.LeadTimeDays
+		}
+
+		averageDaily := float64(item.Sold) / float64(days)
+		item.AverageDaily = math.Round(averageDaily*100) / 100
+		if averageDaily > 0 {
+			cover := math.Round(float64(item.Stock)/averageDaily*10) / 10
+			item.DaysOfCover = &cover
+		}
+
+		needed := int(math.Ceil(averageDaily*float64(item.LeadTimeDays+coverDays))) + item.MinStock
+		item.SuggestedQuantity = roundUpTo(needed-item.Stock-item.OnOrder, product.ReorderQty)
+		item.EstimatedCost = item.UnitCost.Mul(item.SuggestedQuantity)
+		report.EstimatedCost += item.EstimatedCost
+
+		report.Items = append(report.Items, item)
+	}
+
+	// Running out first: fewest days of cover, products without sales last
+	sort.SliceStable(report.Items, func(i, j int) bool {
+		a, b := report.Items[i].DaysOfCover, report.Items[j].DaysOfCover
+		if a == nil || b == nil {
+			return a != nil
+		}
+		return *a < *b
+	})
+	return report, nil
+}
+
+// roundUpTo rounds a positive quantity up to a multiple of pack; anything
+// not positive is nothing to order
+func roundUpTo(quantity, pack int) int {
+	if quantity <= 0 {
+		return 0
+	}
+	if pack <= 0 {
+		return quantity
+	}
+	return (quantity + pack - 1) / pack * pack
+}
+
+func quantitiesByProduct(rows []dto.ProductQuantityData) map[uint]int {
+	quantities := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		quantities[row.ProductID] = row.TotalQuantity
+	}
+	return quantities
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/models"
+)
+
+func TestRoundUpTo(t *testing.T) {
+	tests := []struct {
+		name     string
+		quantity int
+		pack     int
+		want     int
+	}{
+		{name: "rounds up to the next pack", quantity: 13, pack: 12, want: 24},
+		{name: "exact packs stay", quantity: 24, pack: 12, want: 24},
+		{name: "less than one pack orders a pack", quantity: 1, pack: 12, want: 12},
+		{name: "no pack size orders what is needed", quantity: 7, pack: 0, want: 7},
+		{name: "nothing needed", quantity: 0, pack: 12, want: 0},
+		{name: "more than enough stock", quantity: -5, pack: 12, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := roundUpTo(tt.quantity, tt.pack); got != tt.want {
+				t.Errorf("roundUpTo(%d, %d) = %d, want %d", tt.quantity, tt.pack, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReorderSuggest(t *testing.T) {
+	supplierID := func(id uint) *uint { return &id }
+	minStock := func(n int) *int { return &n }
+	settingService := newTestSettingService(map[string]string{"low_stock_threshold": "5"})
+
+	db := newFakeDB()
+	// 2 a day over a 3 day lead time and 7 days of cover, plus the default minimum of 5
+	db.products[1] = models.Product{ID: 1, Name: "Kopi Susu", Stock: 10, ReorderQty: 12, Cost: 1000, SupplierID: supplierID(1)}
+	// Own minimum of 2, with 3 already on order
+	db.products[2] = models.Product{ID: 2, Name: "Roti Bakar", Stock: 4, MinStock: minStock(2)}
+	// Plenty of stock and no sales
+	db.products[3] = models.Product{ID: 3, Name: "Gula Aren", Stock: 50, SupplierID: supplierID(1)}
+	// 1.5 a day rounds the need up to whole units
+	db.products[4] = models.Product{ID: 4, Name: "Susu UHT", Stock: 1, MinStock: minStock(0), SupplierID: supplierID(2)}
+
+	service := NewReorderService(
+		&fakeProductRepository{db: db},
+		&fakeSupplierRepository{suppliers: []models.Supplier{
+			{ID: 1, Name: "Toko Kopi", LeadTimeDays: 3},
+			{ID: 2, Name: "Grosir Susu"},
+		}},
+		&fakeTransactionItemRepository{sold: []dto.ProductQuantityData{
+			{ProductID: 1, TotalQuantity: 60},
+			{ProductID: 2, TotalQuantity: 30},
+			{ProductID: 4, TotalQuantity: 45},
+		}},
+		&fakePurchaseOrderRepository{onOrder: []dto.ProductQuantityData{
+			{ProductID: 2, TotalQuantity: 3},
+		}},
+		settingService,
+		&StockAlertService{settingService: settingService},
+		nil,
+	)
+
+	all := func(product *models.Product) bool { return true }
+	tests := []struct {
+		name          string
+		include       func(product *models.Product) bool
+		supplier      *models.Supplier
+		wantIDs       []uint // running out first
+		wantQuantity  []int
+		wantLeadTimes []int
+		wantCost      models.Money
+	}{
+		{
+			name:          "each product's own supplier",
+			include:       all,
+			wantIDs:       []uint{4, 2, 1, 3},
+			wantQuantity:  []int{10, 2, 24, 0},
+			wantLeadTimes: []int{0, 0, 3, 3},
+			wantCost:      24000,
+		},
+		{
+			name:          "ordering from one supplier uses its lead time",
+			include:       all,
+			supplier:      &models.Supplier{ID: 2, Name: "Grosir Susu"},
+			wantIDs:       []uint{4, 2, 1, 3},
+			wantQuantity:  []int{10, 2, 12, 0},
+			wantLeadTimes: []int{0, 0, 0, 0},
+			wantCost:      12000,
+		},
+		{
+			name: "only the included products",
+			include: func(product *models.Product) bool {
+				return product.SupplierID != nil && *product.SupplierID == 1
+			},
+			wantIDs:       []uint{1, 3},
+			wantQuantity:  []int{24, 0},
+			wantLeadTimes: []int{3, 3},
+			wantCost:      24000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := service.suggest(30, 7, tt.include, tt.supplier)
+			if err != nil {
+				t.Fatalf("suggest() error = %v", err)
+			}
+
+			var ids []uint
+			var quantities, leadTimes []int
+			for _, item := range report.Items {
+				ids = append(ids, item.ProductID)
+				quantities = append(quantities, item.SuggestedQuantity)
+				leadTimes = append(leadTimes, item.LeadTimeDays)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("products = %v, want %v", ids, tt.wantIDs)
+			}
+			if !reflect.DeepEqual(quantities, tt.wantQuantity) {
+				t.Errorf("suggested quantities = %v, want %v", quantities, tt.wantQuantity)
+			}
+			if !reflect.DeepEqual(leadTimes, tt.wantLeadTimes) {
+				t.Errorf("lead times = %v, want %v", leadTimes, tt.wantLeadTimes)
+			}
+			if report.EstimatedCost != tt.wantCost {
+				t.Errorf("estimated cost = %d, want %d", report.EstimatedCost, tt.wantCost)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/models"
 	"github.com/syrlramadhan/cashier-app/repositories"
 )
 
@@ -12,6 +13,8 @@ type ReportService struct {
 	transactionItemRepo repositories.TransactionItemRepository
 	productRepo         repositories.ProductRepository
 	categoryRepo        repositories.CategoryRepository
+	wasteRepo           repositories.WasteRepository
+	stockAlertService   *StockAlertService
 }
 
 func NewReportService(
@@ -19,12 +22,16 @@ func NewReportService(
 	transactionItemRepo repositories.TransactionItemRepository,
 	productRepo repositories.ProductRepository,
 	categoryRepo repositories.CategoryRepository,
+	wasteRepo repositories.WasteRepository,
+	stockAlertService *StockAlertService,
 ) *ReportService {
 	return &ReportService{
 		transactionRepo:     transactionRepo,
 		transactionItemRepo: transactionItemRepo,
 		productRepo:         productRepo,
 		categoryRepo:        categoryRepo,
+		wasteRepo:           wasteRepo,
+		stockAlertService:   stockAlertService,
 	}
 }
 
@@ -34,7 +41,7 @@ func (s *ReportService) GetDashboard() (*dto.DashboardResponse, error) {
 	endOfDay := time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 59, 999999999, now.Location())
 
 	// Get today's revenue
-	todayRevenue, _ := s.transactionRepo.GetTotalRevenue(startOfDay, endOfDay)
+	todayRevenue, _ := s.transactionRepo.GetTotalRevenue(startOfDay, endOfDay, "")
 
 	// Get today's transactions count
 	transactions, _ := s.transactionRepo.FindByDateRange(startOfDay, endOfDay)
@@ -44,19 +51,19 @@ func (s *ReportService) GetDashboard() (*dto.DashboardResponse, error) {
 	products, _ := s.productRepo.FindAll()
 	totalProducts := len(products)
 
-	// Get low stock count (stock < 10)
-	lowStockCount := 0
-	for _, p := range products {
-		if p.Stock < 10 {
-			lowStockCount++
-		}
-	}
+	// Get low stock count (below each product's minimum stock)
+	lowStock, _ := s.stockAlertService.GetLowStockProducts()
+	lowStockCount := len(lowStock)
+
+	// Get today's waste at cost
+	todayWaste, _ := s.wasteRepo.GetTotalCost(startOfDay, endOfDay)
 
 	return &dto.DashboardResponse{
 		TodayRevenue:      todayRevenue,
 		TodayTransactions: todayTransactions,
 		TotalProducts:     totalProducts,
 		LowStockCount:     lowStockCount,
+		TodayWaste:        todayWaste,
 	}, nil
 }
 
@@ -69,7 +76,7 @@ func (s *ReportService) GetDailyRevenue(days int) ([]dto.DailyRevenueResponse, e
 		startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
 		endOfDay := time.Date(date.Year(), date.Month(), date.Day(), 23, 59, 59, 999999999, date.Location())
 
-		revenue, _ := s.transactionRepo.GetTotalRevenue(startOfDay, endOfDay)
+		revenue, _ := s.transactionRepo.GetTotalRevenue(startOfDay, endOfDay, "")
 		transactions, _ := s.transactionRepo.FindByDateRange(startOfDay, endOfDay)
 
 		result = append(result, dto.DailyRevenueResponse{
@@ -86,23 +93,28 @@ func (s *ReportService) GetPaymentDistribution() ([]dto.PaymentDistributionRespo
 	paymentMethods := []string{"cash", "card", "qris"}
 	var result []dto.PaymentDistributionResponse
 
-	totalCount := 0
-	counts := make(map[string]int)
+	stats, err := s.transactionRepo.GetPaymentMethodStats()
+	if err != nil {
+		return nil, err
+	}
 
-	for _, method := range paymentMethods {
-		count, _ := s.transactionRepo.CountByPaymentMethod(method)
-		counts[method] = int(count)
-		totalCount += int(count)
+	totalCount := 0
+	byMethod := make(map[string]dto.PaymentMethodStatData)
+	for _, stat := range stats {
+		byMethod[stat.Method] = stat
+		totalCount += stat.Count
 	}
 
 	for _, method := range paymentMethods {
+		stat := byMethod[method]
 		percentage := 0.0
 		if totalCount > 0 {
-			percentage = float64(counts[method]) / float64(totalCount) * 100
+			percentage = float64(stat.Count) / float64(totalCount) * 100
 		}
 		result = append(result, dto.PaymentDistributionResponse{
 			PaymentMethod: method,
-			Count:         counts[method],
+			Count:         stat.Count,
+			TotalAmount:   stat.TotalAmount,
 			Percentage:    percentage,
 		})
 	}
@@ -129,48 +141,150 @@ func (s *ReportService) GetTopProducts(limit int) ([]dto.TopProductResponse, err
 	return result, nil
 }
 
-func (s *ReportService) GetRevenueByDateRange(startDate, endDate time.Time) (float64, error) {
-	return s.transactionRepo.GetTotalRevenue(startDate, endDate)
+// GetTopVariants ranks the variants sold, e.g. how many Large, Iced Cappucinos
+func (s *ReportService) GetTopVariants(limit int) ([]dto.TopVariantResponse, error) {
+	topVariants, err := s.transactionItemRepo.GetTopVariants(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []dto.TopVariantResponse
+	for _, tv := range topVariants {
+		result = append(result, dto.TopVariantResponse{
+			ProductID:    tv.ProductID,
+			ProductName:  tv.ProductName,
+			VariantName:  tv.VariantName,
+			TotalSold:    tv.TotalQuantity,
+			TotalRevenue: tv.TotalRevenue,
+		})
+	}
+
+	return result, nil
+}
+
+// GetTopModifiers ranks the modifiers chosen, e.g. how many extra shots were sold
+func (s *ReportService) GetTopModifiers(limit int) ([]dto.TopModifierResponse, error) {
+	topModifiers, err := s.transactionItemRepo.GetTopModifiers(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []dto.TopModifierResponse
+	for _, tm := range topModifiers {
+		result = append(result, dto.TopModifierResponse{
+			ModifierOptionID: tm.ModifierOptionID,
+			GroupName:        tm.GroupName,
+			Name:             tm.Name,
+			TotalSold:        tm.TotalQuantity,
+			TotalRevenue:     tm.TotalRevenue,
+		})
+	}
+
+	return result, nil
 }
 
-func (s *ReportService) ExportTransactions(startDate, endDate time.Time) ([]dto.TransactionResponse, error) {
-	transactions, err := s.transactionRepo.FindByDateRange(startDate, endDate)
+// GetRevenueByDateRange is the net revenue of a date range; orderType limits it to one order type
+func (s *ReportService) GetRevenueByDateRange(startDate, endDate time.Time, orderType string) (models.Money, error) {
+	return s.transactionRepo.GetTotalRevenue(startDate, endDate, orderType)
+}
+
+// GetTaxSummary groups completed sales by the tax rate and mode that applied at sale time
+func (s *ReportService) GetTaxSummary(startDate, endDate time.Time, orderType string) ([]dto.TaxSummaryResponse, error) {
+	summaries, err := s.transactionRepo.GetTaxSummary(startDate, endDate, orderType)
 	if err != nil {
 		return nil, err
 	}
 
-	var result []dto.TransactionResponse
-	for _, t := range transactions {
-		var items []dto.TransactionItemResponse
-		for _, item := range t.Items {
-			items = append(items, dto.TransactionItemResponse{
-				ID:          item.ID,
-				ProductID:   item.ProductID,
-				ProductName: item.ProductName,
-				Price:       item.Price,
-				Quantity:    item.Quantity,
-				Subtotal:    item.Subtotal,
-			})
-		}
+	var result []dto.TaxSummaryResponse
+	for _, summary := range summaries {
+		result = append(result, dto.TaxSummaryResponse{
+			TaxRate:          summary.TaxRate,
+			TaxInclusive:     summary.TaxInclusive,
+			TransactionCount: summary.TransactionCount,
+			Subtotal:         summary.Subtotal,
+			Tax:              summary.Tax,
+			Total:            summary.Total,
+		})
+	}
+
+	return result, nil
+}
+
+// GetOrderTypeSummary breaks completed sales down by dine-in, takeaway and delivery
+func (s *ReportService) GetOrderTypeSummary(startDate, endDate time.Time) ([]dto.OrderTypeSummaryResponse, error) {
+	orderTypes := []string{"dine_in", "takeaway", "delivery"}
+
+	summaries, err := s.transactionRepo.GetOrderTypeSummary(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
 
-		cashierName := ""
-		if t.User.ID > 0 {
-			cashierName = t.User.Name
+	var totalRevenue models.Money
+	byOrderType := make(map[string]dto.OrderTypeSummaryData)
+	for _, summary := range summaries {
+		byOrderType[summary.OrderType] = summary
+		totalRevenue += summary.Total - summary.RefundedTotal
+	}
+
+	var result []dto.OrderTypeSummaryResponse
+	for _, orderType := range orderTypes {
+		summary := byOrderType[orderType]
+		netRevenue := summary.Total - summary.RefundedTotal
+		percentage := 0.0
+		if totalRevenue > 0 {
+			percentage = float64(netRevenue) / float64(totalRevenue) * 100
 		}
+		result = append(result, dto.OrderTypeSummaryResponse{
+			OrderType:        orderType,
+			TransactionCount: summary.TransactionCount,
+			Subtotal:         summary.Subtotal,
+			Discount:         summary.Discount,
+			ServiceCharge:    summary.ServiceCharge,
+			Tax:              summary.Tax,
+			Total:            summary.Total,
+			RefundedTotal:    summary.RefundedTotal,
+			NetRevenue:       netRevenue,
+			Percentage:       percentage,
+		})
+	}
+
+	return result, nil
+}
 
-		result = append(result, dto.TransactionResponse{
-			ID:              t.ID,
-			TransactionCode: t.TransactionCode,
-			CashierName:     cashierName,
-			Subtotal:        t.Subtotal,
-			Tax:             t.Tax,
-			Total:           t.Total,
-			PaymentMethod:   t.PaymentMethod,
-			Status:          t.Status,
-			Items:           items,
-			CreatedAt:       t.CreatedAt,
+// GetDiscountSummary reports the promotion and manual discounts given on completed sales
+func (s *ReportService) GetDiscountSummary(startDate, endDate time.Time) ([]dto.DiscountSummaryResponse, error) {
+	summaries, err := s.transactionRepo.GetDiscountSummary(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []dto.DiscountSummaryResponse
+	for _, summary := range summaries {
+		result = append(result, dto.DiscountSummaryResponse{
+			Source:           summary.Source,
+			PromotionID:      summary.PromotionID,
+			Name:             summary.Name,
+			TransactionCount: summary.TransactionCount,
+			TotalAmount:      summary.TotalAmount,
 		})
 	}
 
 	return result, nil
 }
+
+func (s *ReportService) ExportTransactions(startDate, endDate time.Time, orderType string) ([]dto.TransactionResponse, error) {
+	transactions, err := s.transactionRepo.FindByDateRange(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []dto.TransactionResponse
+	for _, t := range transactions {
+		if orderType != "" && t.OrderType != orderType {
+			continue
+		}
+		result = append(result, toTransactionResponse(&t))
+	}
+
+	return result, nil
+}
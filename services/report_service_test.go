@@ -0,0 +1,70 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/models"
+)
+
+func TestGetOrderTypeSummary(t *testing.T) {
+	tests := []struct {
+		name           string
+		summaries      []dto.OrderTypeSummaryData
+		wantNetRevenue [3]models.Money // dine_in, takeaway, delivery
+		wantPercentage [3]float64
+	}{
+		{
+			name: "refunds taken off their order type",
+			summaries: []dto.OrderTypeSummaryData{
+				{OrderType: "delivery", TransactionCount: 2, Total: 40000, RefundedTotal: 20000},
+				{OrderType: "dine_in", TransactionCount: 3, Total: 60000, RefundedTotal: 10000},
+				{OrderType: "takeaway", TransactionCount: 1, Total: 30000},
+			},
+			wantNetRevenue: [3]models.Money{50000, 30000, 20000},
+			wantPercentage: [3]float64{50, 30, 20},
+		},
+		{
+			// A refund issued in the range for a sale made before it
+			name: "refund without sales in the range",
+			summaries: []dto.OrderTypeSummaryData{
+				{OrderType: "dine_in", TransactionCount: 2, Total: 50000},
+				{OrderType: "takeaway", RefundedTotal: 10000},
+			},
+			wantNetRevenue: [3]models.Money{50000, -10000, 0},
+			wantPercentage: [3]float64{125, -25, 0},
+		},
+		{
+			name:           "no sales",
+			wantNetRevenue: [3]models.Money{0, 0, 0},
+			wantPercentage: [3]float64{0, 0, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewReportService(&fakeReportRepository{orderTypes: tt.summaries}, nil, nil, nil, nil, nil)
+
+			got, err := service.GetOrderTypeSummary(time.Now().AddDate(0, 0, -1), time.Now())
+			if err != nil {
+				t.Fatalf("GetOrderTypeSummary() error = %v", err)
+			}
+
+			if len(got) != 3 {
+				t.Fatalf("GetOrderTypeSummary() = %+v, want a row per order type", got)
+			}
+			for i, orderType := range []string{"dine_in", "takeaway", "delivery"} {
+				if got[i].OrderType != orderType {
+					t.Errorf("row %d order type = %q, want %q", i, got[i].OrderType, orderType)
+				}
+				if got[i].NetRevenue != tt.wantNetRevenue[i] {
+					t.Errorf("%s net revenue = %d, want %d", orderType, got[i].NetRevenue, tt.wantNetRevenue[i])
+				}
+				if got[i].Percentage != tt.wantPercentage[i] {
+					t.Errorf("%s percentage = %v, want %v", orderType, got[i].Percentage, tt.wantPercentage[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/syrlramadhan/cashier-app/repositories"
+	"gorm.io/gorm"
+)
+
+// SequenceService hands out gapless daily numbers and formats them into
+// document codes using the "<name>_code_*" settings.
+type SequenceService struct {
+	sequenceRepo   repositories.SequenceRepository
+	settingService *SettingService
+}
+
+func NewSequenceService(sequenceRepo repositories.SequenceRepository, settingService *SettingService) *SequenceService {
+	return &SequenceService{
+		sequenceRepo:   sequenceRepo,
+		settingService: settingService,
+	}
+}
+
+// NextNumber reserves the next number of today's sequence within tx.
+func (s *SequenceService) NextNumber(tx *gorm.DB, name string, now time.Time) (int, error) {
+	outletCode := s.settingService.GetValue("outlet_code", "01")
+	number, err := s.sequenceRepo.WithTx(tx).Next(name, outletCode, now.Format("2006-01-02"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate %s number", name)
+	}
+	return number, nil
+}
+
+// NextCode reserves the next number of today's sequence within tx and formats
+// it, e.g. "transaction" with the default settings gives TRX-20240115-0001.
+// Supported placeholders are {prefix}, {outlet}, {date} and {seq}.
+func (s *SequenceService) NextCode(tx *gorm.DB, name, defaultPrefix string, now time.Time) (string, error) {
+	number, err := s.NextNumber(tx, name, now)
+	if err != nil {
+		return "", err
+	}
+
+	format := s.settingService.GetValue(name+"_code_format", "{prefix}-{date}-{seq}")
+	prefix := s.settingService.GetValue(name+"_code_prefix", defaultPrefix)
+	digits := s.settingService.GetInt(name+"_code_digits", 4)
+	if digits < 1 || digits > 10 {
+		return "", errors.New("invalid " + name + "_code_digits setting")
+	}
+
+	replacer := strings.NewReplacer(
+		"{prefix}", prefix,
+		"{outlet}", s.settingService.GetValue("outlet_code", "01"),
+		"{date}", now.Format("20060102"),
+		"{seq}", fmt.Sprintf("%0*d", digits, number),
+	)
+	return replacer.Replace(format), nil
+}
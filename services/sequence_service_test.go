@@ -0,0 +1,145 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestSequenceServiceNextCode(t *testing.T) {
+	day := time.Date(2024, 1, 15, 9, 30, 0, 0, time.Local)
+	nextDay := day.AddDate(0, 0, 1)
+
+	type call struct {
+		outlet string // outlet_code setting; the default outlet when empty
+		name   string
+		prefix string
+		now    time.Time
+		want   string
+	}
+
+	tests := []struct {
+		name     string
+		settings map[string]string
+		calls    []call
+	}{
+		{
+			name: "numbers run on within a day",
+			calls: []call{
+				{name: "transaction", prefix: "TRX", now: day, want: "TRX-20240115-0001"},
+				{name: "transaction", prefix: "TRX", now: day.Add(8 * time.Hour), want: "TRX-20240115-0002"},
+			},
+		},
+		{
+			name: "restarts every day",
+			calls: []call{
+				{name: "transaction", prefix: "TRX", now: day, want: "TRX-20240115-0001"},
+				{name: "transaction", prefix: "TRX", now: day, want: "TRX-20240115-0002"},
+				{name: "transaction", prefix: "TRX", now: nextDay, want: "TRX-20240116-0001"},
+				{name: "transaction", prefix: "TRX", now: nextDay, want: "TRX-20240116-0002"},
+			},
+		},
+		{
+			name: "each sequence name counts on its own",
+			calls: []call{
+				{name: "transaction", prefix: "TRX", now: day, want: "TRX-20240115-0001"},
+				{name: "refund", prefix: "RF", now: day, want: "RF-20240115-0001"},
+				{name: "transaction", prefix: "TRX", now: day, want: "TRX-20240115-0002"},
+				{name: "refund", prefix: "RF", now: day, want: "RF-20240115-0002"},
+			},
+		},
+		{
+			name:     "each outlet counts on its own",
+			settings: map[string]string{"transaction_code_format": "{prefix}-{outlet}-{date}-{seq}"},
+			calls: []call{
+				{outlet: "01", name: "transaction", prefix: "TRX", now: day, want: "TRX-01-20240115-0001"},
+				{outlet: "02", name: "transaction", prefix: "TRX", now: day, want: "TRX-02-20240115-0001"},
+				{outlet: "01", name: "transaction", prefix: "TRX", now: day, want: "TRX-01-20240115-0002"},
+			},
+		},
+		{
+			name:     "prefix and digits settings",
+			settings: map[string]string{"transaction_code_prefix": "INV", "transaction_code_digits": "6"},
+			calls: []call{
+				{name: "transaction", prefix: "TRX", now: day, want: "INV-20240115-000001"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newFakeDB()
+			gormDB := db.open(t)
+
+			for i, c := range tt.calls {
+				settings := map[string]string{}
+				for key, value := range tt.settings {
+					settings[key] = value
+				}
+				if c.outlet != "" {
+					settings["outlet_code"] = c.outlet
+				}
+				service := newTestSequenceService(db, settings)
+
+				var code string
+				err := gormDB.Transaction(func(tx *gorm.DB) error {
+					var err error
+					code, err = service.NextCode(tx, c.name, c.prefix, c.now)
+					return err
+				})
+				if err != nil {
+					t.Fatalf("call %d: NextCode() error = %v", i, err)
+				}
+				if code != c.want {
+					t.Errorf("call %d: NextCode() = %q, want %q", i, code, c.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSequenceServiceNextCodeIsGapless(t *testing.T) {
+	db := newFakeDB()
+	gormDB := db.open(t)
+	service := newTestSequenceService(db, nil)
+	day := time.Date(2024, 1, 15, 9, 30, 0, 0, time.Local)
+
+	// A checkout that fails after taking its number gives the number back
+	failed := errors.New("checkout failed")
+	err := gormDB.Transaction(func(tx *gorm.DB) error {
+		if _, err := service.NextCode(tx, "transaction", "TRX", day); err != nil {
+			return err
+		}
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("Transaction() error = %v, want %v", err, failed)
+	}
+
+	var code string
+	err = gormDB.Transaction(func(tx *gorm.DB) error {
+		code, err = service.NextCode(tx, "transaction", "TRX", day)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("NextCode() error = %v", err)
+	}
+	if code != "TRX-20240115-0001" {
+		t.Errorf("NextCode() = %q, want TRX-20240115-0001", code)
+	}
+}
+
+func TestSequenceServiceNextCodeRejectsInvalidDigits(t *testing.T) {
+	db := newFakeDB()
+	service := newTestSequenceService(db, map[string]string{"transaction_code_digits": "11"})
+
+	err := db.open(t).Transaction(func(tx *gorm.DB) error {
+		_, err := service.NextCode(tx, "transaction", "TRX", time.Now())
+		return err
+	})
+	if err == nil {
+		t.Error("NextCode() succeeded, want an error")
+	}
+}
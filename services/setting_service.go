@@ -2,6 +2,7 @@ package services
 
 import (
 	"errors"
+	"strconv"
 
 	"github.com/syrlramadhan/cashier-app/dto"
 	"github.com/syrlramadhan/cashier-app/models"
@@ -47,6 +48,61 @@ func (s *SettingService) GetSettingByKey(key string) (*dto.SettingResponse, erro
 	return response, nil
 }
 
+// GetValue returns the value of a setting, or fallback when it is missing or empty
+func (s *SettingService) GetValue(key, fallback string) string {
+	setting, err := s.settingRepo.FindByKey(key)
+	if err != nil || setting.Value == "" {
+		return fallback
+	}
+	return setting.Value
+}
+
+// GetInt returns the integer value of a setting, or fallback when it is missing or invalid
+func (s *SettingService) GetInt(key string, fallback int) int {
+	value, err := strconv.Atoi(s.GetValue(key, ""))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// GetFloat returns the numeric value of a setting, or fallback when it is missing or invalid
+func (s *SettingService) GetFloat(key string, fallback float64) float64 {
+	value, err := strconv.ParseFloat(s.GetValue(key, ""), 64)
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// GetBool returns the boolean value of a setting, or fallback when it is missing or invalid
+func (s *SettingService) GetBool(key string, fallback bool) bool {
+	value, err := strconv.ParseBool(s.GetValue(key, ""))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// GetTaxConfig returns the configured tax rate as a fraction (11 -> 0.11) and
+// whether product prices already include the tax
+func (s *SettingService) GetTaxConfig() (float64, bool, error) {
+	ratePercent := s.GetFloat("tax_rate", 11)
+	if ratePercent < 0 || ratePercent > 100 {
+		return 0, false, errors.New("invalid tax_rate setting")
+	}
+	return ratePercent / 100, s.GetBool("tax_inclusive", false), nil
+}
+
+// GetServiceChargeRate returns the dine-in service charge as a fraction (5 -> 0.05)
+func (s *SettingService) GetServiceChargeRate() (float64, error) {
+	ratePercent := s.GetFloat("service_charge_rate", 0)
+	if ratePercent < 0 || ratePercent > 100 {
+		return 0, errors.New("invalid service_charge_rate setting")
+	}
+	return ratePercent / 100, nil
+}
+
 func (s *SettingService) UpdateSetting(req *dto.UpdateSettingRequest) (*dto.SettingResponse, error) {
 	_, err := s.settingRepo.FindByKey(req.Key)
 	if err != nil {
@@ -0,0 +1,364 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/models"
+	"github.com/syrlramadhan/cashier-app/repositories"
+	"gorm.io/gorm"
+)
+
+// SplitBillService splits an open tab into bills that are paid separately.
+// The tab is priced and its stock taken when it is split; it becomes a "split"
+// order that links its "unpaid" bills. Each bill gets its own receipt number
+// when it is paid, and reports count the bills' money and the order's lines.
+type SplitBillService struct {
+	db                 *gorm.DB
+	tableRepo          repositories.TableRepository
+	transactionRepo    repositories.TransactionRepository
+	sequenceService    *SequenceService
+	transactionService *TransactionService
+	stockService       *StockService
+}
+
+func NewSplitBillService(
+	db *gorm.DB,
+	tableRepo repositories.TableRepository,
+	transactionRepo repositories.TransactionRepository,
+	sequenceService *SequenceService,
+	transactionService *TransactionService,
+	stockService *StockService,
+) *SplitBillService {
+	return &SplitBillService{
+		db:                 db,
+		tableRepo:          tableRepo,
+		transactionRepo:    transactionRepo,
+		sequenceService:    sequenceService,
+		transactionService: transactionService,
+		stockService:       stockService,
+	}
+}
+
+func (s *SplitBillService) GetSplitBill(id uint) (*dto.TransactionResponse, error) {
+	bill, err := s.transactionRepo.FindByIDWithDetails(id)
+	if err != nil || bill.ParentID == nil {
+		return nil, errors.New("split bill not found")
+	}
+
+	response := toTransactionResponse(bill)
+	return &response, nil
+}
+
+// SplitTab splits an open tab into bills by items or into equal shares
+func (s *SplitBillService) SplitTab(id uint, req *dto.SplitOrderRequest) (*dto.TransactionResponse, error) {
+	if req.Mode == "equal" && req.Shares < 2 {
+		return nil, errors.New("shares must be at least 2")
+	}
+	if req.Mode == "items" && len(req.Bills) < 2 {
+		return nil, errors.New("at least 2 bills are required")
+	}
+
+	var order *models.Transaction
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		tab, err := s.transactionRepo.WithTx(tx).FindByIDForUpdate(id)
+		if err != nil || tab.Status != "open" {
+			return errors.New("tab not found")
+		}
+		if len(tab.Items) == 0 {
+			return errors.New("tab has no items")
+		}
+
+		if req.Mode == "items" {
+			if err := validateSplitItems(tab.Items, req.Bills); err != nil {
+				return err
+			}
+		}
+
+		// Price the whole order and take its stock; the bills only share the amount due
+		finalize := &dto.FinalizeOrderRequest{
+			UserID:          req.UserID,
+			Role:            req.Role,
+			DiscountPercent: req.DiscountPercent,
+			DiscountAmount:  req.DiscountAmount,
+			VoucherCode:     req.VoucherCode,
+			CustomerRef:     req.CustomerRef,
+		}
+		order, err = s.transactionService.checkout(tx, checkoutRequest(tab.Items, finalize), tab, "split")
+		if err != nil {
+			return err
+		}
+
+		var bills []models.Transaction
+		if req.Mode == "equal" {
+			bills = splitEqually(order, req.Shares)
+		} else {
+			bills = splitByItems(order, req.Bills)
+		}
+
+		transactionRepo := s.transactionRepo.WithTx(tx)
+		for i := range bills {
+			bills[i].TransactionCode = fmt.Sprintf("%s-%d", order.TransactionCode, i+1)
+			bills[i].ParentID = &order.ID
+			bills[i].UserID = req.UserID
+			bills[i].TableID = order.TableID
+			bills[i].OrderType = order.OrderType
+			bills[i].ServiceChargeRate = order.ServiceChargeRate
+			bills[i].TaxRate = order.TaxRate
+			bills[i].TaxInclusive = order.TaxInclusive
+			bills[i].Status = "unpaid"
+			if err := transactionRepo.Create(&bills[i]); err != nil {
+				return errors.New("failed to create split bills")
+			}
+		}
+		order.Children = bills
+
+		if order.TableID != nil {
+			return s.tableRepo.WithTx(tx).UpdateStatus(*order.TableID, "bill_requested")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.stockService.publishAlerts()
+
+	response := toTransactionResponse(order)
+	return &response, nil
+}
+
+// PaySplitBill settles one bill with its own tenders and receipt number. The
+// table is freed once every bill of the order is paid.
+func (s *SplitBillService) PaySplitBill(id, userID uint, req *dto.PaySplitBillRequest) (*dto.TransactionResponse, error) {
+	existing, err := s.transactionRepo.FindByID(id)
+	if err != nil || existing.ParentID == nil {
+		return nil, errors.New("split bill not found")
+	}
+
+	var bill *models.Transaction
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		transactionRepo := s.transactionRepo.WithTx(tx)
+
+		// Lock the order before the bill so the last payment frees the table exactly once
+		order, err := transactionRepo.FindByIDForUpdate(*existing.ParentID)
+		if err != nil {
+			return errors.New("split order not found")
+		}
+
+		bill, err = transactionRepo.FindByIDForUpdate(id)
+		if err != nil {
+			return errors.New("split bill not found")
+		}
+		if bill.Status != "unpaid" {
+			return errors.New("split bill is already paid")
+		}
+
+		settlement, err := s.transactionService.settlePayments(bill.Total, &dto.CreateTransactionRequest{
+			PaymentMethod:  req.PaymentMethod,
+			Payments:       req.Payments,
+			AmountTendered: req.AmountTendered,
+		})
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		code, err := s.sequenceService.NextCode(tx, "transaction", "TRX", now)
+		if err != nil {
+			return err
+		}
+
+		bill.TransactionCode = code
+		bill.UserID = userID
+		bill.Rounding = settlement.Rounding
+		bill.Total += settlement.Rounding
+		bill.AmountTendered = settlement.AmountTendered
+		bill.ChangeDue = settlement.ChangeDue
+		bill.PaymentMethod = settlement.Method
+		bill.Payments = settlement.Payments
+		bill.Status = "completed"
+		bill.CreatedAt = now
+		if err := transactionRepo.Update(bill); err != nil {
+			return errors.New("failed to pay split bill")
+		}
+
+		// Reports count the order rather than its bills, so carry the cash
+		// rounding over to keep its total equal to what the bills collected
+		if settlement.Rounding != 0 {
+			order.Rounding += settlement.Rounding
+			order.Total += settlement.Rounding
+			order.Items = nil
+			if err := transactionRepo.Update(order); err != nil {
+				return errors.New("failed to update split order")
+			}
+		}
+
+		unpaid, err := transactionRepo.CountUnpaidChildren(order.ID)
+		if err != nil {
+			return err
+		}
+		if unpaid == 0 && order.TableID != nil {
+			return s.tableRepo.WithTx(tx).UpdateStatus(*order.TableID, "free")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := toTransactionResponse(bill)
+	return &response, nil
+}
+
+// validateSplitItems checks that every line of the tab is assigned in full
+func validateSplitItems(items []models.TransactionItem, bills []dto.SplitBillLinesRequest) error {
+	assigned := make(map[uint]int)
+	for _, bill := range bills {
+		for _, line := range bill.Items {
+			assigned[line.TransactionItemID] += line.Quantity
+		}
+	}
+
+	ordered := make(map[uint]int, len(items))
+	for _, item := range items {
+		ordered[item.ID] = item.Quantity
+		if assigned[item.ID] != item.Quantity {
+			return fmt.Errorf("item %d has %d ordered but %d assigned", item.ID, item.Quantity, assigned[item.ID])
+		}
+	}
+
+	for itemID := range assigned {
+		if _, ok := ordered[itemID]; !ok {
+			return fmt.Errorf("item %d does not belong to this tab", itemID)
+		}
+	}
+
+	return nil
+}
+
+// splitByItems builds one bill per assignment. Partial quantities take their
+// share of the line amount; the last share of a line takes what is left so
+// the bills always add up to the order.
+func splitByItems(order *models.Transaction, billRequests []dto.SplitBillLinesRequest) []models.Transaction {
+	items := make(map[uint]models.TransactionItem, len(order.Items))
+	remainingQty := make(map[uint]int, len(order.Items))
+	remainingNet := make(map[uint]models.Money, len(order.Items))
+	remainingDiscount := make(map[uint]models.Money, len(order.Items))
+	for _, item := range order.Items {
+		items[item.ID] = item
+		remainingQty[item.ID] = item.Quantity
+		remainingNet[item.ID] = item.Subtotal
+		remainingDiscount[item.ID] = item.Discount
+	}
+
+	bills := make([]models.Transaction, len(billRequests))
+	weights := make([]int64, len(billRequests))
+	for i, billRequest := range billRequests {
+		for _, line := range billRequest.Items {
+			item := items[line.TransactionItemID]
+
+			net, discount := remainingNet[item.ID], remainingDiscount[item.ID]
+			remainingQty[item.ID] -= line.Quantity
+			if remainingQty[item.ID] > 0 {
+				net = item.Subtotal.MulDiv(int64(line.Quantity), int64(item.Quantity))
+				discount = item.Discount.MulDiv(int64(line.Quantity), int64(item.Quantity))
+			}
+			remainingNet[item.ID] -= net
+			remainingDiscount[item.ID] -= discount
+
+			bills[i].Items = append(bills[i].Items, models.TransactionItem{
+				ProductID:   item.ProductID,
+				ProductName: item.ProductName,
+				VariantID:   item.VariantID,
+				VariantName: item.VariantName,
+				Modifiers:   copyModifiers(item.Modifiers),
+				Price:       item.Price,
+				Quantity:    line.Quantity,
+				Round:       item.Round,
+				Discount:    discount,
+				Subtotal:    net,
+			})
+			bills[i].Subtotal += net
+		}
+		weights[i] = int64(bills[i].Subtotal)
+	}
+
+	shareOrderAmounts(order, bills, weights)
+	return bills
+}
+
+// copyModifiers copies the modifiers of a line for a bill line
+func copyModifiers(modifiers []models.TransactionItemModifier) []models.TransactionItemModifier {
+	var copies []models.TransactionItemModifier
+	for _, modifier := range modifiers {
+		modifier.ID = 0
+		modifier.TransactionItemID = 0
+		copies = append(copies, modifier)
+	}
+	return copies
+}
+
+// splitEqually divides the amount due into shares equal bills without lines
+func splitEqually(order *models.Transaction, shares int) []models.Transaction {
+	bills := make([]models.Transaction, shares)
+	weights := make([]int64, shares)
+	for i := range weights {
+		weights[i] = 1
+	}
+
+	subtotals := allocate(order.Subtotal, weights)
+	for i := range bills {
+		bills[i].Subtotal = subtotals[i]
+	}
+
+	shareOrderAmounts(order, bills, weights)
+	return bills
+}
+
+// shareOrderAmounts gives each bill its share of the order discount, service
+// charge and tax and sets its total
+func shareOrderAmounts(order *models.Transaction, bills []models.Transaction, weights []int64) {
+	discounts := allocate(order.Discount, weights)
+	serviceCharges := allocate(order.ServiceCharge, weights)
+	taxes := allocate(order.Tax, weights)
+
+	for i := range bills {
+		bills[i].Discount = discounts[i]
+		bills[i].ServiceCharge = serviceCharges[i]
+		bills[i].Tax = taxes[i]
+		bills[i].Total = bills[i].Subtotal - bills[i].Discount + bills[i].ServiceCharge
+		if !order.TaxInclusive {
+			bills[i].Total += bills[i].Tax
+		}
+	}
+}
+
+// allocate splits amount in proportion to weights; the last part takes the
+// rounding difference so the parts always add up to amount.
+func allocate(amount models.Money, weights []int64) []models.Money {
+	var total int64
+	for _, weight := range weights {
+		total += weight
+	}
+	if total == 0 {
+		weights = make([]int64, len(weights))
+		for i := range weights {
+			weights[i] = 1
+		}
+		total = int64(len(weights))
+	}
+
+	parts := make([]models.Money, len(weights))
+	remaining := amount
+	for i, weight := range weights {
+		if i == len(weights)-1 {
+			parts[i] = remaining
+			break
+		}
+		parts[i] = amount.MulDiv(weight, total)
+		remaining -= parts[i]
+	}
+	return parts
+}
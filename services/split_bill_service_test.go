@@ -0,0 +1,110 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/models"
+)
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  models.Money
+		weights []int64
+		want    []models.Money
+	}{
+		{
+			name:    "equal split puts the rounding on the last bill",
+			amount:  100000,
+			weights: []int64{1, 1, 1},
+			want:    []models.Money{33333, 33333, 33334},
+		},
+		{
+			name:    "proportional split rounds half away from zero",
+			amount:  100,
+			weights: []int64{2, 1},
+			want:    []models.Money{67, 33},
+		},
+		{
+			name:    "proportional to line subtotals",
+			amount:  99999,
+			weights: []int64{30000, 30000, 40000},
+			want:    []models.Money{30000, 30000, 39999},
+		},
+		{
+			name:    "all zero weights split equally",
+			amount:  10001,
+			weights: []int64{0, 0},
+			want:    []models.Money{5001, 5000},
+		},
+		{
+			name:    "zero weight takes nothing",
+			amount:  5000,
+			weights: []int64{0, 3},
+			want:    []models.Money{0, 5000},
+		},
+		{
+			name:    "single bill takes everything",
+			amount:  12345,
+			weights: []int64{7},
+			want:    []models.Money{12345},
+		},
+		{
+			name:    "zero amount",
+			amount:  0,
+			weights: []int64{1, 2},
+			want:    []models.Money{0, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := allocate(tt.amount, tt.weights)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("allocate(%d, %v) = %v, want %v", tt.amount, tt.weights, got, tt.want)
+			}
+
+			var sum models.Money
+			for _, part := range got {
+				sum += part
+			}
+			if sum != tt.amount {
+				t.Errorf("parts sum to %d, want %d", sum, tt.amount)
+			}
+		})
+	}
+}
+
+func TestPaySplitBillCarriesRoundingToOrder(t *testing.T) {
+	db := newFakeDB()
+	orderID := uint(1)
+	db.transactions[1] = models.Transaction{ID: 1, Status: "split", Total: 100000}
+	db.transactions[2] = models.Transaction{ID: 2, ParentID: &orderID, Status: "unpaid", Total: 33333}
+	db.transactions[3] = models.Transaction{ID: 3, ParentID: &orderID, Status: "unpaid", Total: 33333}
+	db.transactions[4] = models.Transaction{ID: 4, ParentID: &orderID, Status: "unpaid", Total: 33334}
+	db.nextID = 4
+
+	settingService := newTestSettingService(map[string]string{"cash_rounding": "500"})
+	transactionService := &TransactionService{settingService: settingService}
+	service := NewSplitBillService(db.open(t), nil, &fakeTransactionRepository{db: db},
+		NewSequenceService(&fakeSequenceRepository{db: db}, settingService), transactionService, nil)
+
+	var collected models.Money
+	for _, id := range []uint{2, 3, 4} {
+		bill, err := service.PaySplitBill(id, 1, &dto.PaySplitBillRequest{PaymentMethod: "cash"})
+		if err != nil {
+			t.Fatalf("PaySplitBill(%d) error = %v", id, err)
+		}
+		collected += bill.Total
+	}
+
+	order := db.transactions[1]
+	if order.Total != collected {
+		t.Errorf("order total = %d (rounding %d), want what the bills collected, %d", order.Total, order.Rounding, collected)
+	}
+	if order.Rounding != 500 { // +167, +167, +166
+		t.Errorf("order rounding = %d, want 500", order.Rounding)
+	}
+}
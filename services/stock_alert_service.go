@@ -0,0 +1,196 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/models"
+	"github.com/syrlramadhan/cashier-app/repositories"
+	"gorm.io/gorm"
+)
+
+const stockTopic = "stock"
+
+// StockAlertService watches the stock of products against their minimum. An
+// alert is raised only when a movement takes a product from at or above its
+// minimum to below it, so a product that stays low is not reported again
+// until it has been restocked.
+type StockAlertService struct {
+	alertRepo      repositories.StockAlertRepository
+	productRepo    repositories.ProductRepository
+	settingService *SettingService
+	eventHub       *EventHub
+}
+
+func NewStockAlertService(
+	alertRepo repositories.StockAlertRepository,
+	productRepo repositories.ProductRepository,
+	settingService *SettingService,
+	eventHub *EventHub,
+) *StockAlertService {
+	return &StockAlertService{
+		alertRepo:      alertRepo,
+		productRepo:    productRepo,
+		settingService: settingService,
+		eventHub:       eventHub,
+	}
+}
+
+// GetLowStockProducts lists the products below their minimum, lowest stock first
+func (s *StockAlertService) GetLowStockProducts() ([]dto.LowStockProductResponse, error) {
+	defaultMin := s.lowStockThreshold()
+	products, err := s.productRepo.FindLowStock(defaultMin)
+	if err != nil {
+		return nil, err
+	}
+
+	response := []dto.LowStockProductResponse{}
+	for _, product := range products {
+		response = append(response, dto.LowStockProductResponse{
+			ProductID:    product.ID,
+			ProductName:  product.Name,
+			CategoryName: product.Category.Name,
+			Stock:        product.Stock,
+			MinStock:     product.MinStockOr(defaultMin),
+			ReorderQty:   product.ReorderQty,
+		})
+	}
+	return response, nil
+}
+
+func (s *StockAlertService) GetAlerts(status string) ([]dto.StockAlertResponse, error) {
+	alerts, err := s.alertRepo.FindAll(status)
+	if err != nil {
+		return nil, err
+	}
+
+	response := []dto.StockAlertResponse{}
+	for _, alert := range alerts {
+		response = append(response, toStockAlertResponse(&alert))
+	}
+	return response, nil
+}
+
+func (s *StockAlertService) AcknowledgeAlert(id, userID uint) (*dto.StockAlertResponse, error) {
+	if _, err := s.alertRepo.FindByID(id); err != nil {
+		return nil, errors.New("stock alert not found")
+	}
+
+	acknowledged, err := s.alertRepo.Acknowledge(id, userID, time.Now())
+	if err != nil {
+		return nil, errors.New("failed to acknowledge stock alert")
+	}
+	if !acknowledged {
+		return nil, errors.New("stock alert is already acknowledged")
+	}
+
+	alert, err := s.alertRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("stock alert not found")
+	}
+	response := toStockAlertResponse(alert)
+	return &response, nil
+}
+
+// Subscribe returns the stock alert events for a stream
+func (s *StockAlertService) Subscribe() (<-chan Event, func()) {
+	return s.eventHub.Subscribe(stockTopic)
+}
+
+// check raises an alert for every product movement in movements that takes
+// the product below its minimum. It runs inside tx, after the new balances
+// were written, so the alerts commit or roll back with the movements.
+func (s *StockAlertService) check(tx *gorm.DB, movements []models.StockMovement) error {
+	taken := make(map[uint]models.StockMovement)
+	quantities := make(map[uint]int)
+	for _, movement := range movements {
+		if movement.VariantID != nil || movement.Quantity >= 0 {
+			continue
+		}
+		taken[movement.ProductID] = movement
+		quantities[movement.ProductID] = movement.Quantity
+	}
+	if len(taken) == 0 {
+		return nil
+	}
+
+	// The caller already holds the locks, so this only reads the minimums
+	products, err := s.productRepo.WithTx(tx).FindByIDsForUpdate(sortedProductIDs(quantities))
+	if err != nil {
+		return errors.New("failed to load products")
+	}
+
+	defaultMin := s.lowStockThreshold()
+	var alerts []models.StockAlert
+	for _, product := range products {
+		movement := taken[product.ID]
+		minStock := product.MinStockOr(defaultMin)
+		before := movement.Balance - movement.Quantity
+		if before < minStock || movement.Balance >= minStock {
+			continue
+		}
+		alerts = append(alerts, models.StockAlert{
+			ProductID:   product.ID,
+			ProductName: product.Name,
+			Stock:       movement.Balance,
+			MinStock:    minStock,
+			ReorderQty:  product.ReorderQty,
+			Reason:      movement.Reason,
+			ReferenceID: movement.ReferenceID,
+		})
+	}
+
+	if err := s.alertRepo.WithTx(tx).Create(alerts); err != nil {
+		return errors.New("failed to record stock alerts")
+	}
+	return nil
+}
+
+// publish announces the alerts not announced yet. It is called once the
+// changes that raised them have committed; each alert is claimed before it
+// is announced, so it goes out once even when two requests publish at the
+// same time. Alerts left over by a failed publish go out with the next one.
+func (s *StockAlertService) publish() {
+	alerts, err := s.alertRepo.FindUnnotified()
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	for i := range alerts {
+		claimed, err := s.alertRepo.MarkNotified(alerts[i].ID, now)
+		if err != nil || !claimed {
+			continue
+		}
+		s.eventHub.Publish(Event{
+			Topic: stockTopic,
+			Type:  "stock.low",
+			Data:  toStockAlertResponse(&alerts[i]),
+		})
+	}
+}
+
+// lowStockThreshold is the minimum stock of products that do not set their own
+func (s *StockAlertService) lowStockThreshold() int {
+	return s.settingService.GetInt("low_stock_threshold", 10)
+}
+
+func toStockAlertResponse(alert *models.StockAlert) dto.StockAlertResponse {
+	response := dto.StockAlertResponse{
+		ID:             alert.ID,
+		ProductID:      alert.ProductID,
+		ProductName:    alert.ProductName,
+		Stock:          alert.Stock,
+		MinStock:       alert.MinStock,
+		ReorderQty:     alert.ReorderQty,
+		Reason:         alert.Reason,
+		ReferenceID:    alert.ReferenceID,
+		AcknowledgedAt: alert.AcknowledgedAt,
+		CreatedAt:      alert.CreatedAt,
+	}
+	if alert.Acknowledger != nil {
+		response.AcknowledgedBy = alert.Acknowledger.Name
+	}
+	return response
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/models"
+)
+
+func TestStockAlertCheck(t *testing.T) {
+	five := 5
+
+	tests := []struct {
+		name       string
+		minStock   *int
+		moves      []int // Stock adjustments in order, starting from 12
+		wantAlerts []int // Stock left by each movement that raised an alert
+		wantMin    int
+	}{
+		{
+			name:       "falls below the low_stock_threshold",
+			moves:      []int{-3},
+			wantAlerts: []int{9},
+			wantMin:    10,
+		},
+		{
+			name:    "down to the minimum",
+			moves:   []int{-2},
+			wantMin: 10,
+		},
+		{
+			name:       "second sale while already low",
+			moves:      []int{-3, -2},
+			wantAlerts: []int{9},
+			wantMin:    10,
+		},
+		{
+			name:       "restocked but still low",
+			moves:      []int{-4, 1, -1},
+			wantAlerts: []int{8},
+			wantMin:    10,
+		},
+		{
+			name:       "re-arms after restocking to the minimum",
+			moves:      []int{-3, 1, -2},
+			wantAlerts: []int{9, 8},
+			wantMin:    10,
+		},
+		{
+			name:       "re-arms after restocking above the minimum",
+			moves:      []int{-3, 10, -10},
+			wantAlerts: []int{9, 9},
+			wantMin:    10,
+		},
+		{
+			name:       "own min_stock overrides the threshold",
+			minStock:   &five,
+			moves:      []int{-3, -5},
+			wantAlerts: []int{4},
+			wantMin:    5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newFakeDB()
+			db.products[1] = models.Product{ID: 1, Name: "Croissant", Stock: 12, MinStock: tt.minStock, ReorderQty: 24}
+			service := newTestStockService(db)
+			service.db = db.open(t)
+
+			for _, quantity := range tt.moves {
+				if err := service.AdjustStock(1, &dto.AdjustStockRequest{Quantity: quantity, UserID: 1}); err != nil {
+					t.Fatalf("AdjustStock(%d) error = %v", quantity, err)
+				}
+			}
+
+			var alerts []models.StockAlert
+			for _, alert := range db.alerts {
+				alerts = append(alerts, alert)
+			}
+			sort.Slice(alerts, func(i, j int) bool { return alerts[i].ID < alerts[j].ID })
+
+			if len(alerts) != len(tt.wantAlerts) {
+				t.Fatalf("alerts = %+v, want %d", alerts, len(tt.wantAlerts))
+			}
+			for i, alert := range alerts {
+				if alert.Stock != tt.wantAlerts[i] || alert.MinStock != tt.wantMin || alert.ReorderQty != 24 || alert.Reason != "adjustment" {
+					t.Errorf("alert %d = %+v, want stock %d below %d", i, alert, tt.wantAlerts[i], tt.wantMin)
+				}
+				if alert.NotifiedAt == nil {
+					t.Errorf("alert %d was not published", i)
+				}
+			}
+		})
+	}
+}
+
+func TestStockAlertCheckSkipsVariants(t *testing.T) {
+	db := newFakeDB()
+	db.products[1] = models.Product{ID: 1, Name: "Croissant", Stock: 12}
+	db.variants[1] = models.ProductVariant{ID: 1, ProductID: 1, Name: "Almond", Stock: 12, TrackStock: true}
+	service := newTestStockService(db)
+	service.db = db.open(t)
+
+	variantID := uint(1)
+	err := service.AdjustStock(1, &dto.AdjustStockRequest{Quantity: -10, VariantID: &variantID, UserID: 1})
+	if err != nil {
+		t.Fatalf("AdjustStock() error = %v", err)
+	}
+
+	// The minimum is kept per product, so variant stock raises no alert
+	if len(db.alerts) != 0 {
+		t.Errorf("alerts = %+v, want none", db.alerts)
+	}
+}
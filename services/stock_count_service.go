@@ -0,0 +1,544 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/models"
+	"github.com/syrlramadhan/cashier-app/repositories"
+	"gorm.io/gorm"
+)
+
+// StockCountService runs physical stock counts (stock opname). A count freezes
+// the expected stock of its products and stocked variants when it starts,
+// collects the counted quantities from any number of users and, when posted,
+// books the variance of every counted line through the stock ledger.
+type StockCountService struct {
+	db              *gorm.DB
+	stockCountRepo  repositories.StockCountRepository
+	productRepo     repositories.ProductRepository
+	variantRepo     repositories.ProductVariantRepository
+	ingredientRepo  repositories.IngredientRepository
+	categoryRepo    repositories.CategoryRepository
+	sequenceService *SequenceService
+	stockService    *StockService
+}
+
+func NewStockCountService(
+	db *gorm.DB,
+	stockCountRepo repositories.StockCountRepository,
+	productRepo repositories.ProductRepository,
+	variantRepo repositories.ProductVariantRepository,
+	ingredientRepo repositories.IngredientRepository,
+	categoryRepo repositories.CategoryRepository,
+	sequenceService *SequenceService,
+	stockService *StockService,
+) *StockCountService {
+	return &StockCountService{
+		db:              db,
+		stockCountRepo:  stockCountRepo,
+		productRepo:     productRepo,
+		variantRepo:     variantRepo,
+		ingredientRepo:  ingredientRepo,
+		categoryRepo:    categoryRepo,
+		sequenceService: sequenceService,
+		stockService:    stockService,
+	}
+}
+
+func (s *StockCountService) GetAllStockCounts(status string) ([]dto.StockCountResponse, error) {
+	counts, err := s.stockCountRepo.FindAll(status)
+	if err != nil {
+		return nil, err
+	}
+
+	// The list only shows how far each count got, not its lines
+	response := []dto.StockCountResponse{}
+	for _, count := range counts {
+		summary := toStockCountResponse(&count)
+		summary.Items = nil
+		response = append(response, *summary)
+	}
+	return response, nil
+}
+
+func (s *StockCountService) GetStockCountByID(id uint) (*dto.StockCountResponse, error) {
+	count, err := s.stockCountRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("stock count not found")
+	}
+
+	return toStockCountResponse(count), nil
+}
+
+// StartStockCount opens a count of all products, or of one category, and
+// freezes their current stock and cost. Variants that keep their own stock
+// are counted on lines of their own. Only one count can be open at a time
+// so that no product is counted twice.
+func (s *StockCountService) StartStockCount(req *dto.StartStockCountRequest) (*dto.StockCountResponse, error) {
+	var products []models.Product
+	var err error
+	if req.CategoryID != nil {
+		if _, err := s.categoryRepo.FindByID(*req.CategoryID); err != nil {
+			return nil, errors.New("category not found")
+		}
+		products, err = s.productRepo.FindByCategoryID(*req.CategoryID)
+	} else {
+		products, err = s.productRepo.FindAll()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(products) == 0 {
+		return nil, errors.New("no products to count")
+	}
+
+	count := &models.StockCount{
+		Status:     "counting",
+		CategoryID: req.CategoryID,
+		Notes:      req.Notes,
+		StartedBy:  req.UserID,
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		stockCountRepo := s.stockCountRepo.WithTx(tx)
+
+		open, err := stockCountRepo.CountByStatus("counting")
+		if err != nil {
+			return err
+		}
+		if open > 0 {
+			return errors.New("another stock count is still open")
+		}
+
+		// Lock the products so the frozen quantities are a consistent snapshot
+		ids := make(map[uint]int, len(products))
+		for _, product := range products {
+			ids[product.ID] = 0
+		}
+		locked, err := s.productRepo.WithTx(tx).FindByIDsForUpdate(sortedProductIDs(ids))
+		if err != nil {
+			return errors.New("failed to lock products")
+		}
+		count.Items, err = s.countItems(tx, locked)
+		if err != nil {
+			return err
+		}
+		if len(count.Items) == 0 {
+			return errors.New("no products to count")
+		}
+
+		number, err := s.sequenceService.NextCode(tx, "stock_count", "SC", time.Now())
+		if err != nil {
+			return err
+		}
+		count.CountNumber = number
+
+		if err := stockCountRepo.Create(count); err != nil {
+			return errors.New("failed to create stock count")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetStockCountByID(count.ID)
+}
+
+// countItems lists the lines of a count over the locked products: one per
+// product and one per variant that keeps its own stock, with the variants
+// locked after their products as in checkout. Products and variants made to
+// a recipe are left out, since their stock is in the ingredients.
+func (s *StockCountService) countItems(tx *gorm.DB, products []models.Product) ([]models.StockCountItem, error) {
+	variantRepo := s.variantRepo.WithTx(tx)
+
+	productIDs := make([]uint, 0, len(products))
+	for _, product := range products {
+		productIDs = append(productIDs, product.ID)
+	}
+	tracked, err := variantRepo.FindTrackedByProductIDs(productIDs)
+	if err != nil {
+		return nil, errors.New("failed to load variants")
+	}
+
+	var variants []models.ProductVariant
+	var variantIDs []uint
+	if len(tracked) > 0 {
+		ids := make(map[uint]int, len(tracked))
+		for _, variant := range tracked {
+			ids[variant.ID] = 0
+		}
+		variants, err = variantRepo.FindByIDsForUpdate(sortedProductIDs(ids))
+		if err != nil {
+			return nil, errors.New("failed to lock variants")
+		}
+		for _, variant := range variants {
+			variantIDs = append(variantIDs, variant.ID)
+		}
+	}
+
+	recipes, err := s.ingredientRepo.WithTx(tx).FindRecipes(productIDs, variantIDs, nil)
+	if err != nil {
+		return nil, errors.New("failed to load recipes")
+	}
+	productRecipes := make(map[uint]bool)
+	variantRecipes := make(map[uint]bool)
+	for _, recipe := range recipes {
+		if recipe.ProductID != nil {
+			productRecipes[*recipe.ProductID] = true
+		}
+		if recipe.VariantID != nil {
+			variantRecipes[*recipe.VariantID] = true
+		}
+	}
+
+	byProduct := make(map[uint][]models.ProductVariant)
+	for _, variant := range variants {
+		if variant.TrackStock && !variantRecipes[variant.ID] {
+			byProduct[variant.ProductID] = append(byProduct[variant.ProductID], variant)
+		}
+	}
+
+	var items []models.StockCountItem
+	for _, product := range products {
+		if productRecipes[product.ID] {
+			continue
+		}
+		items = append(items, models.StockCountItem{
+			ProductID:   product.ID,
+			ProductName: product.Name,
+			Expected:    product.Stock,
+			UnitCost:    product.Cost,
+		})
+		for _, variant := range byProduct[product.ID] {
+			variantID := variant.ID
+			items = append(items, models.StockCountItem{
+				ProductID:   product.ID,
+				ProductName: product.Name,
+				VariantID:   &variantID,
+				VariantName: variant.Name,
+				Expected:    variant.Stock,
+				UnitCost:    product.Cost,
+			})
+		}
+	}
+	return items, nil
+}
+
+// RecordCounts enters counted quantities on an open count. Each entry
+// replaces what was counted for the product or variant before, or adds to
+// it when req.Add is set, and remembers who counted it.
+func (s *StockCountService) RecordCounts(id uint, req *dto.RecordStockCountRequest) (*dto.StockCountResponse, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		stockCountRepo := s.stockCountRepo.WithTx(tx)
+
+		count, err := stockCountRepo.FindByIDForUpdate(id)
+		if err != nil {
+			return errors.New("stock count not found")
+		}
+		if count.Status != "counting" {
+			return fmt.Errorf("stock count is %s", count.Status)
+		}
+
+		items := make(map[stockKey]*models.StockCountItem)
+		for i := range count.Items {
+			items[stockKeyOf(count.Items[i].ProductID, count.Items[i].VariantID)] = &count.Items[i]
+		}
+
+		now := time.Now()
+		seen := make(map[stockKey]bool)
+		var changed []models.StockCountItem
+		for _, entry := range req.Items {
+			key := stockKeyOf(entry.ProductID, entry.VariantID)
+			if seen[key] {
+				return fmt.Errorf("%s is listed more than once", key)
+			}
+			seen[key] = true
+
+			item, ok := items[key]
+			if !ok {
+				return fmt.Errorf("%s is not part of this stock count", key)
+			}
+
+			counted := entry.Quantity
+			if req.Add && item.Counted != nil {
+				counted += *item.Counted
+			}
+			item.Counted = &counted
+			item.CountedBy = &req.UserID
+			item.CountedAt = &now
+			if entry.Note != "" {
+				item.Note = entry.Note
+			}
+			changed = append(changed, *item)
+		}
+
+		if err := stockCountRepo.UpdateItems(changed); err != nil {
+			return errors.New("failed to save counts")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetStockCountByID(id)
+}
+
+// PostStockCount closes an open count and books the variance of every
+// counted product and variant as a "count" stock movement. The variance is
+// applied to today's stock rather than replacing it, so sales made while
+// counting are kept. Lines that were not counted are left alone.
+func (s *StockCountService) PostStockCount(id, userID uint) (*dto.StockCountResponse, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		stockCountRepo := s.stockCountRepo.WithTx(tx)
+
+		count, err := stockCountRepo.FindByIDForUpdate(id)
+		if err != nil {
+			return errors.New("stock count not found")
+		}
+		if count.Status != "counting" {
+			return fmt.Errorf("stock count is %s", count.Status)
+		}
+
+		variances := make(map[stockKey]int)
+		productIDs := make(map[uint]int)
+		variantIDs := make(map[uint]int)
+		counted := 0
+		for _, item := range count.Items {
+			if item.Counted == nil {
+				continue
+			}
+			counted++
+			if *item.Counted != item.Expected {
+				variances[stockKeyOf(item.ProductID, item.VariantID)] = *item.Counted - item.Expected
+				productIDs[item.ProductID] = 0
+				if item.VariantID != nil {
+					variantIDs[*item.VariantID] = 0
+				}
+			}
+		}
+		if counted == 0 {
+			return errors.New("nothing has been counted yet")
+		}
+
+		// Lock the products before their variants, as in checkout
+		products, err := s.productRepo.WithTx(tx).FindByIDsForUpdate(sortedProductIDs(productIDs))
+		if err != nil {
+			return errors.New("failed to lock products")
+		}
+		stocks := make(map[stockKey]int)
+		for _, product := range products {
+			stocks[stockKey{productID: product.ID}] = product.Stock
+		}
+		if len(variantIDs) > 0 {
+			variants, err := s.variantRepo.WithTx(tx).FindByIDsForUpdate(sortedProductIDs(variantIDs))
+			if err != nil {
+				return errors.New("failed to lock variants")
+			}
+			for _, variant := range variants {
+				if variant.TrackStock {
+					stocks[stockKey{productID: variant.ProductID, variantID: variant.ID}] = variant.Stock
+				}
+			}
+		}
+
+		// Products and variants deleted since the count started, and variants
+		// that no longer keep their own stock, are skipped
+		var movements []models.StockMovement
+		adjusted := make(map[stockKey]int)
+		for _, item := range count.Items {
+			key := stockKeyOf(item.ProductID, item.VariantID)
+			variance, ok := variances[key]
+			if !ok {
+				continue
+			}
+			stock, ok := stocks[key]
+			if !ok {
+				continue
+			}
+
+			movement := models.StockMovement{
+				ProductID:   item.ProductID,
+				VariantID:   item.VariantID,
+				Quantity:    variance,
+				Balance:     stock + variance,
+				Reason:      "count",
+				ReferenceID: &count.ID,
+				UserID:      &userID,
+				Note:        count.CountNumber,
+			}
+			if movement.Balance < 0 {
+				name := item.ProductName
+				if item.VariantName != "" {
+					name += " (" + item.VariantName + ")"
+				}
+				return fmt.Errorf("stock of %s would go below zero", name)
+			}
+			movements = append(movements, movement)
+			adjusted[key] = variance
+		}
+		if err := s.stockService.record(tx, movements); err != nil {
+			return err
+		}
+
+		var changed []models.StockCountItem
+		for _, item := range count.Items {
+			if adjustment := adjusted[stockKeyOf(item.ProductID, item.VariantID)]; adjustment != 0 {
+				item.Adjustment = adjustment
+				changed = append(changed, item)
+			}
+		}
+		if err := stockCountRepo.UpdateItems(changed); err != nil {
+			return errors.New("failed to update stock count items")
+		}
+
+		now := time.Now()
+		count.Status = "posted"
+		count.PostedBy = &userID
+		count.PostedAt = &now
+		if err := stockCountRepo.Update(count); err != nil {
+			return errors.New("failed to update stock count")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.stockService.publishAlerts()
+
+	return s.GetStockCountByID(id)
+}
+
+// CancelStockCount drops an open count without touching stock
+func (s *StockCountService) CancelStockCount(id uint) (*dto.StockCountResponse, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		stockCountRepo := s.stockCountRepo.WithTx(tx)
+
+		count, err := stockCountRepo.FindByIDForUpdate(id)
+		if err != nil {
+			return errors.New("stock count not found")
+		}
+		if count.Status != "counting" {
+			return fmt.Errorf("stock count is %s", count.Status)
+		}
+
+		count.Status = "cancelled"
+		if err := stockCountRepo.Update(count); err != nil {
+			return errors.New("failed to update stock count")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetStockCountByID(id)
+}
+
+// GetVarianceReport lists the counted products whose count differs from the
+// expected quantity, largest loss first, with their value at the cost frozen
+// when the count started
+func (s *StockCountService) GetVarianceReport(id uint) (*dto.StockCountVarianceResponse, error) {
+	count, err := s.stockCountRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("stock count not found")
+	}
+
+	summary := toStockCountResponse(count)
+	report := &dto.StockCountVarianceResponse{
+		ID:           count.ID,
+		CountNumber:  count.CountNumber,
+		Status:       count.Status,
+		TotalItems:   summary.TotalItems,
+		CountedItems: summary.CountedItems,
+		Items:        []dto.StockCountItemResponse{},
+		CreatedAt:    count.CreatedAt,
+		PostedAt:     count.PostedAt,
+	}
+	for _, item := range summary.Items {
+		if item.Variance == 0 {
+			continue
+		}
+		if item.VarianceValue < 0 {
+			report.ShortageValue -= item.VarianceValue
+		} else {
+			report.SurplusValue += item.VarianceValue
+		}
+		report.Items = append(report.Items, item)
+	}
+	report.NetValue = report.SurplusValue - report.ShortageValue
+
+	sort.SliceStable(report.Items, func(i, j int) bool {
+		return report.Items[i].VarianceValue < report.Items[j].VarianceValue
+	})
+	return report, nil
+}
+
+func toStockCountResponse(count *models.StockCount) *dto.StockCountResponse {
+	response := &dto.StockCountResponse{
+		ID:          count.ID,
+		CountNumber: count.CountNumber,
+		Status:      count.Status,
+		CategoryID:  count.CategoryID,
+		Notes:       count.Notes,
+		StartedBy:   count.Starter.Name,
+		PostedAt:    count.PostedAt,
+		TotalItems:  len(count.Items),
+		CreatedAt:   count.CreatedAt,
+	}
+	if count.Poster != nil {
+		response.PostedBy = count.Poster.Name
+	}
+
+	for _, item := range count.Items {
+		itemResponse := dto.StockCountItemResponse{
+			ProductID:   item.ProductID,
+			ProductName: item.ProductName,
+			VariantID:   item.VariantID,
+			VariantName: item.VariantName,
+			Expected:    item.Expected,
+			Counted:     item.Counted,
+			UnitCost:    item.UnitCost,
+			Adjustment:  item.Adjustment,
+			CountedAt:   item.CountedAt,
+			Note:        item.Note,
+		}
+		if item.Counted != nil {
+			response.CountedItems++
+			itemResponse.Variance = *item.Counted - item.Expected
+			itemResponse.VarianceValue = item.UnitCost.Mul(itemResponse.Variance)
+		}
+		if item.Counter != nil {
+			itemResponse.CountedBy = item.Counter.Name
+		}
+		response.Items = append(response.Items, itemResponse)
+	}
+	return response
+}
+
+// stockKey identifies a line of a stock count: a product, or one of its
+// variants that keeps its own stock
+type stockKey struct {
+	productID uint
+	variantID uint // 0 for the product itself
+}
+
+func stockKeyOf(productID uint, variantID *uint) stockKey {
+	key := stockKey{productID: productID}
+	if variantID != nil {
+		key.variantID = *variantID
+	}
+	return key
+}
+
+func (k stockKey) String() string {
+	if k.variantID != 0 {
+		return fmt.Sprintf("variant %d of product %d", k.variantID, k.productID)
+	}
+	return fmt.Sprintf("product %d", k.productID)
+}
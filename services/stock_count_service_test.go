@@ -0,0 +1,119 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/models"
+)
+
+func TestStockCountVariantsAndRecipes(t *testing.T) {
+	db := newFakeDB()
+	latte, variantRecipe := uint(1), uint(13)
+	db.products[1] = models.Product{ID: 1, Name: "Latte", Stock: 0}
+	db.products[2] = models.Product{ID: 2, Name: "T-Shirt", Stock: 5, Cost: 40000}
+	db.variants[10] = models.ProductVariant{ID: 10, ProductID: 2, Name: "M", TrackStock: true, Stock: 3}
+	db.variants[11] = models.ProductVariant{ID: 11, ProductID: 2, Name: "L", TrackStock: true, Stock: 4}
+	db.variants[12] = models.ProductVariant{ID: 12, ProductID: 2, Name: "XL"}
+	db.variants[13] = models.ProductVariant{ID: 13, ProductID: 2, Name: "Printed", TrackStock: true, Stock: 9}
+	db.recipes = []models.RecipeItem{
+		{ID: 1, ProductID: &latte, IngredientID: 1, Quantity: 18},
+		{ID: 2, VariantID: &variantRecipe, IngredientID: 2, Quantity: 1},
+	}
+	db.nextID = 100
+
+	productRepo := &fakeProductRepository{db: db}
+	variantRepo := &fakeProductVariantRepository{db: db}
+	ingredientRepo := &fakeIngredientRepository{db: db}
+	service := NewStockCountService(
+		db.open(t),
+		&fakeStockCountRepository{db: db},
+		productRepo,
+		variantRepo,
+		ingredientRepo,
+		nil,
+		newTestSequenceService(db, nil),
+		newTestStockService(db),
+	)
+
+	count, err := service.StartStockCount(&dto.StartStockCountRequest{UserID: 1})
+	if err != nil {
+		t.Fatalf("StartStockCount() error = %v", err)
+	}
+
+	type line struct {
+		productID uint
+		variantID uint
+		expected  int
+	}
+	var got []line
+	for _, item := range count.Items {
+		l := line{productID: item.ProductID, expected: item.Expected}
+		if item.VariantID != nil {
+			l.variantID = *item.VariantID
+		}
+		got = append(got, l)
+	}
+	want := []line{{productID: 2, expected: 5}, {productID: 2, variantID: 10, expected: 3}, {productID: 2, variantID: 11, expected: 4}}
+	if len(got) != len(want) {
+		t.Fatalf("count lines = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	t.Run("rejects lines that are not counted", func(t *testing.T) {
+		entries := []dto.StockCountEntryRequest{
+			{ProductID: 1, Quantity: 0},
+			{ProductID: 2, VariantID: &variantRecipe, Quantity: 9},
+		}
+		for _, entry := range entries {
+			_, err := service.RecordCounts(count.ID, &dto.RecordStockCountRequest{Items: []dto.StockCountEntryRequest{entry}, UserID: 1})
+			if err == nil {
+				t.Errorf("RecordCounts(%+v) succeeded, want an error", entry)
+			}
+		}
+	})
+
+	t.Run("posts the variance of each variant", func(t *testing.T) {
+		medium, large := uint(10), uint(11)
+		_, err := service.RecordCounts(count.ID, &dto.RecordStockCountRequest{
+			Items: []dto.StockCountEntryRequest{
+				{ProductID: 2, Quantity: 5},
+				{ProductID: 2, VariantID: &medium, Quantity: 2},
+				{ProductID: 2, VariantID: &large, Quantity: 6},
+			},
+			UserID: 1,
+		})
+		if err != nil {
+			t.Fatalf("RecordCounts() error = %v", err)
+		}
+
+		if _, err := service.PostStockCount(count.ID, 1); err != nil {
+			t.Fatalf("PostStockCount() error = %v", err)
+		}
+
+		if stock := db.products[2].Stock; stock != 5 {
+			t.Errorf("product stock = %d, want 5", stock)
+		}
+		if stock := db.variants[10].Stock; stock != 2 {
+			t.Errorf("variant M stock = %d, want 2", stock)
+		}
+		if stock := db.variants[11].Stock; stock != 6 {
+			t.Errorf("variant L stock = %d, want 6", stock)
+		}
+		if len(db.movements) != 2 {
+			t.Fatalf("%d stock movements, want 2", len(db.movements))
+		}
+		for _, movement := range db.movements {
+			if movement.VariantID == nil || movement.Reason != "count" {
+				t.Errorf("movement = %+v, want a count movement of a variant", movement)
+			}
+		}
+		if status := db.stockCounts[count.ID].Status; status != "posted" {
+			t.Errorf("status = %q, want posted", status)
+		}
+	})
+}
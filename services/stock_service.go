@@ -0,0 +1,318 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/models"
+	"github.com/syrlramadhan/cashier-app/repositories"
+	"gorm.io/gorm"
+)
+
+// StockService keeps the stock ledger. Every change to the stock of a product
+// or variant is written as a stock movement in the same database transaction,
+// so the stock on hand can always be explained movement by movement.
+type StockService struct {
+	db             *gorm.DB
+	productRepo    repositories.ProductRepository
+	variantRepo    repositories.ProductVariantRepository
+	ingredientRepo repositories.IngredientRepository
+	movementRepo   repositories.StockMovementRepository
+	alertService   *StockAlertService
+}
+
+func NewStockService(
+	db *gorm.DB,
+	productRepo repositories.ProductRepository,
+	variantRepo repositories.ProductVariantRepository,
+	ingredientRepo repositories.IngredientRepository,
+	movementRepo repositories.StockMovementRepository,
+	alertService *StockAlertService,
+) *StockService {
+	return &StockService{
+		db:             db,
+		productRepo:    productRepo,
+		variantRepo:    variantRepo,
+		ingredientRepo: ingredientRepo,
+		movementRepo:   movementRepo,
+		alertService:   alertService,
+	}
+}
+
+// GetMovements lists the latest stock movements of a product, newest first
+func (s *StockService) GetMovements(productID uint, variantID *uint, reason string, limit int) ([]dto.StockMovementResponse, error) {
+	if _, err := s.productRepo.FindByID(productID); err != nil {
+		return nil, errors.New("product not found")
+	}
+
+	movements, err := s.movementRepo.FindByProductID(productID, variantID, reason, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	response := []dto.StockMovementResponse{}
+	for _, movement := range movements {
+		response = append(response, toStockMovementResponse(&movement))
+	}
+	return response, nil
+}
+
+// AdjustStock adds to (or, with a negative quantity, takes from) the stock of
+// a product or one of its variants by hand. With req.Stock the stock is set
+// to that count instead, and the difference is recorded.
+func (s *StockService) AdjustStock(productID uint, req *dto.AdjustStockRequest) error {
+	if req.Stock != nil && req.Quantity != 0 {
+		return errors.New("choose either a quantity or a stock")
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		quantity := req.Quantity
+		if req.Stock != nil {
+			current, err := s.lockStock(tx, productID, req.VariantID)
+			if err != nil {
+				return err
+			}
+			quantity = *req.Stock - current
+			if quantity == 0 {
+				return nil
+			}
+		}
+		return s.move(tx, models.StockMovement{
+			ProductID: productID,
+			VariantID: req.VariantID,
+			Quantity:  quantity,
+			Reason:    "adjustment",
+			UserID:    &req.UserID,
+			Note:      req.Note,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	s.publishAlerts()
+	return nil
+}
+
+// open records the stock a new product or variant starts with
+func (s *StockService) open(tx *gorm.DB, productID uint, variantID *uint, stock int, userID uint) error {
+	if stock == 0 {
+		return nil
+	}
+	return s.record(tx, []models.StockMovement{{
+		ProductID: productID,
+		VariantID: variantID,
+		Quantity:  stock,
+		Balance:   stock,
+		Reason:    "opening",
+		UserID:    &userID,
+	}})
+}
+
+// move locks the product (or variant) of movement and moves its stock by
+// movement.Quantity, refusing to take more than is in stock
+func (s *StockService) move(tx *gorm.DB, movement models.StockMovement) error {
+	current, err := s.lockStock(tx, movement.ProductID, movement.VariantID)
+	if err != nil {
+		return err
+	}
+
+	movement.Balance = current + movement.Quantity
+	if movement.Balance < 0 {
+		return errors.New("insufficient stock")
+	}
+	return s.record(tx, []models.StockMovement{movement})
+}
+
+// lockStock locks a product, or one of its variants that keeps its own
+// stock, and returns the stock on hand
+func (s *StockService) lockStock(tx *gorm.DB, productID uint, variantID *uint) (int, error) {
+	products, err := s.productRepo.WithTx(tx).FindByIDsForUpdate([]uint{productID})
+	if err != nil {
+		return 0, errors.New("failed to lock product")
+	}
+	if len(products) == 0 {
+		return 0, errors.New("product not found")
+	}
+	if variantID == nil {
+		return products[0].Stock, nil
+	}
+
+	variants, err := s.variantRepo.WithTx(tx).FindByIDsForUpdate([]uint{*variantID})
+	if err != nil {
+		return 0, errors.New("failed to lock variant")
+	}
+	if len(variants) == 0 || variants[0].ProductID != productID {
+		return 0, errors.New("variant not found")
+	}
+	if !variants[0].TrackStock {
+		return 0, errors.New("variant does not keep its own stock")
+	}
+	return variants[0].Stock, nil
+}
+
+// record writes the balance each movement leaves on its product or variant
+// and appends the movements to the ledger. The rows must already be locked.
+// Products taken below their minimum get a stock alert, which the caller
+// announces with publishAlerts once tx has committed.
+func (s *StockService) record(tx *gorm.DB, movements []models.StockMovement) error {
+	if len(movements) == 0 {
+		return nil
+	}
+
+	productRepo := s.productRepo.WithTx(tx)
+	variantRepo := s.variantRepo.WithTx(tx)
+	for _, movement := range movements {
+		if movement.VariantID != nil {
+			if err := variantRepo.UpdateStock(*movement.VariantID, movement.Balance); err != nil {
+				return errors.New("failed to update variant stock")
+			}
+			continue
+		}
+		if err := productRepo.UpdateStock(movement.ProductID, movement.Balance); err != nil {
+			return errors.New("failed to update product stock")
+		}
+	}
+
+	if err := s.movementRepo.WithTx(tx).Create(movements); err != nil {
+		return errors.New("failed to record stock movements")
+	}
+	return s.alertService.check(tx, movements)
+}
+
+// publishAlerts announces the stock alerts raised by committed changes
+func (s *StockService) publishAlerts() {
+	s.alertService.publish()
+}
+
+// restockLine is a quantity of a sold line going back on the shelf
+type restockLine struct {
+	Item     models.TransactionItem
+	Quantity int
+}
+
+// restock puts the lines back in stock. Lines made to a recipe return the
+// ingredients they used, in proportion to the quantity returned. Other lines
+// go back on the variant when it keeps its own stock, otherwise on the
+// product, and modifier ingredients are returned too. Products, variants and
+// ingredients deleted since the sale are skipped. Rows are locked in the
+// same order as in checkout. The movements written copy the reason,
+// reference and user of origin.
+func (s *StockService) restock(tx *gorm.DB, lines []restockLine, origin models.StockMovement) error {
+	productRepo := s.productRepo.WithTx(tx)
+	variantRepo := s.variantRepo.WithTx(tx)
+	ingredientRepo := s.ingredientRepo.WithTx(tx)
+
+	productIDs := make(map[uint]int)
+	variantIDs := make(map[uint]int)
+	var itemIDs []uint
+	for _, line := range lines {
+		itemIDs = append(itemIDs, line.Item.ID)
+		if line.Item.FromRecipe {
+			continue
+		}
+		productIDs[line.Item.ProductID] += line.Quantity
+		if line.Item.VariantID != nil {
+			variantIDs[*line.Item.VariantID] += line.Quantity
+		}
+	}
+
+	products, err := productRepo.FindByIDsForUpdate(sortedProductIDs(productIDs))
+	if err != nil {
+		return errors.New("failed to lock products")
+	}
+
+	variants := make(map[uint]models.ProductVariant)
+	if len(variantIDs) > 0 {
+		locked, err := variantRepo.FindByIDsForUpdate(sortedProductIDs(variantIDs))
+		if err != nil {
+			return errors.New("failed to lock variants")
+		}
+		for _, variant := range locked {
+			variants[variant.ID] = variant
+		}
+	}
+
+	productQuantities := make(map[uint]int)
+	variantQuantities := make(map[uint]int)
+	for _, line := range lines {
+		if line.Item.FromRecipe {
+			continue
+		}
+		if line.Item.VariantID != nil {
+			if variant, ok := variants[*line.Item.VariantID]; ok && variant.TrackStock {
+				variantQuantities[variant.ID] += line.Quantity
+				continue
+			}
+		}
+		productQuantities[line.Item.ProductID] += line.Quantity
+	}
+
+	// Ingredients are returned as recorded at the sale, not by today's recipes
+	usages, err := ingredientRepo.FindUsageByItemIDs(itemIDs)
+	if err != nil {
+		return errors.New("failed to load ingredient usage")
+	}
+	returned := make(map[uint]float64)
+	for _, usage := range usages {
+		for _, line := range lines {
+			if line.Item.ID == usage.TransactionItemID {
+				returned[usage.IngredientID] += usage.Quantity * float64(line.Quantity) / float64(line.Item.Quantity)
+			}
+		}
+	}
+
+	var movements []models.StockMovement
+	for _, product := range products {
+		if productQuantities[product.ID] == 0 {
+			continue
+		}
+		movement := origin
+		movement.ProductID = product.ID
+		movement.Quantity = productQuantities[product.ID]
+		movement.Balance = product.Stock + movement.Quantity
+		movements = append(movements, movement)
+	}
+	for _, variantID := range sortedProductIDs(variantQuantities) {
+		id := variantID
+		movement := origin
+		movement.ProductID = variants[variantID].ProductID
+		movement.VariantID = &id
+		movement.Quantity = variantQuantities[variantID]
+		movement.Balance = variants[variantID].Stock + movement.Quantity
+		movements = append(movements, movement)
+	}
+	if err := s.record(tx, movements); err != nil {
+		return err
+	}
+
+	if len(returned) > 0 {
+		ingredients, err := ingredientRepo.FindByIDsForUpdate(sortedIngredientIDs(returned))
+		if err != nil {
+			return errors.New("failed to lock ingredients")
+		}
+		for _, ingredient := range ingredients {
+			if err := ingredientRepo.UpdateStock(ingredient.ID, roundQuantity(ingredient.Stock+returned[ingredient.ID])); err != nil {
+				return errors.New("failed to restore ingredient stock")
+			}
+		}
+	}
+	return nil
+}
+
+func toStockMovementResponse(movement *models.StockMovement) dto.StockMovementResponse {
+	response := dto.StockMovementResponse{
+		ID:          movement.ID,
+		ProductID:   movement.ProductID,
+		VariantID:   movement.VariantID,
+		Quantity:    movement.Quantity,
+		Balance:     movement.Balance,
+		Reason:      movement.Reason,
+		ReferenceID: movement.ReferenceID,
+		Note:        movement.Note,
+		CreatedAt:   movement.CreatedAt,
+	}
+	if movement.User != nil {
+		response.UserName = movement.User.Name
+	}
+	return response
+}
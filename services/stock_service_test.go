@@ -0,0 +1,226 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/models"
+	"gorm.io/gorm"
+)
+
+func TestAdjustStock(t *testing.T) {
+	id := func(id uint) *uint { return &id }
+	count := func(stock int) *int { return &stock }
+
+	tests := []struct {
+		name         string
+		req          dto.AdjustStockRequest
+		wantErr      bool
+		wantProduct  int
+		wantVariant  int
+		wantMovement *models.StockMovement // Nil when nothing should be recorded
+	}{
+		{
+			name:         "add",
+			req:          dto.AdjustStockRequest{Quantity: 5},
+			wantProduct:  15,
+			wantVariant:  4,
+			wantMovement: &models.StockMovement{ProductID: 1, Quantity: 5, Balance: 15},
+		},
+		{
+			name:         "take out",
+			req:          dto.AdjustStockRequest{Quantity: -10},
+			wantProduct:  0,
+			wantVariant:  4,
+			wantMovement: &models.StockMovement{ProductID: 1, Quantity: -10, Balance: 0},
+		},
+		{
+			name:        "take out more than in stock",
+			req:         dto.AdjustStockRequest{Quantity: -11},
+			wantErr:     true,
+			wantProduct: 10,
+			wantVariant: 4,
+		},
+		{
+			name:         "set the stock",
+			req:          dto.AdjustStockRequest{Stock: count(3)},
+			wantProduct:  3,
+			wantVariant:  4,
+			wantMovement: &models.StockMovement{ProductID: 1, Quantity: -7, Balance: 3},
+		},
+		{
+			name:        "set the stock it already has",
+			req:         dto.AdjustStockRequest{Stock: count(10)},
+			wantProduct: 10,
+			wantVariant: 4,
+		},
+		{
+			name:        "both a quantity and a stock",
+			req:         dto.AdjustStockRequest{Quantity: 2, Stock: count(3)},
+			wantErr:     true,
+			wantProduct: 10,
+			wantVariant: 4,
+		},
+		{
+			name:         "variant",
+			req:          dto.AdjustStockRequest{Quantity: -4, VariantID: id(1)},
+			wantProduct:  10,
+			wantVariant:  0,
+			wantMovement: &models.StockMovement{ProductID: 1, VariantID: id(1), Quantity: -4, Balance: 0},
+		},
+		{
+			name:        "variant below zero",
+			req:         dto.AdjustStockRequest{Quantity: -5, VariantID: id(1)},
+			wantErr:     true,
+			wantProduct: 10,
+			wantVariant: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newFakeDB()
+			db.products[1] = models.Product{ID: 1, Name: "Croissant", Stock: 10}
+			db.variants[1] = models.ProductVariant{ID: 1, ProductID: 1, Name: "Almond", Stock: 4, TrackStock: true}
+			service := newTestStockService(db)
+			service.db = db.open(t)
+			tt.req.UserID = 7
+
+			err := service.AdjustStock(1, &tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AdjustStock() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if stock := db.products[1].Stock; stock != tt.wantProduct {
+				t.Errorf("product stock = %d, want %d", stock, tt.wantProduct)
+			}
+			if stock := db.variants[1].Stock; stock != tt.wantVariant {
+				t.Errorf("variant stock = %d, want %d", stock, tt.wantVariant)
+			}
+			if tt.wantMovement == nil {
+				if len(db.movements) != 0 {
+					t.Errorf("movements = %+v, want none", db.movements)
+				}
+				return
+			}
+			if len(db.movements) != 1 {
+				t.Fatalf("movements = %+v, want one", db.movements)
+			}
+			got, want := db.movements[0], tt.wantMovement
+			if got.ProductID != want.ProductID || !sameID(got.VariantID, want.VariantID) || got.Quantity != want.Quantity || got.Balance != want.Balance {
+				t.Errorf("movement = %+v, want %+v", got, *want)
+			}
+			if got.Reason != "adjustment" || got.UserID == nil || *got.UserID != 7 {
+				t.Errorf("movement = %+v, want an adjustment by user 7", got)
+			}
+		})
+	}
+}
+
+func TestStockServiceOpen(t *testing.T) {
+	db := newFakeDB()
+	db.products[1] = models.Product{ID: 1, Name: "Croissant", Stock: 12}
+	db.products[2] = models.Product{ID: 2, Name: "Bagel"}
+	db.variants[1] = models.ProductVariant{ID: 1, ProductID: 1, Name: "Almond", Stock: 5, TrackStock: true}
+	service := newTestStockService(db)
+
+	variantID := uint(1)
+	err := db.open(t).Transaction(func(tx *gorm.DB) error {
+		if err := service.open(tx, 1, nil, 12, 7); err != nil {
+			return err
+		}
+		if err := service.open(tx, 1, &variantID, 5, 7); err != nil {
+			return err
+		}
+		return service.open(tx, 2, nil, 0, 7)
+	})
+	if err != nil {
+		t.Fatalf("open() error = %v", err)
+	}
+
+	if len(db.movements) != 2 {
+		t.Fatalf("movements = %+v, want one per product or variant with stock", db.movements)
+	}
+	want := []models.StockMovement{
+		{ProductID: 1, Quantity: 12, Balance: 12},
+		{ProductID: 1, VariantID: &variantID, Quantity: 5, Balance: 5},
+	}
+	for i, got := range db.movements {
+		if got.ProductID != want[i].ProductID || !sameID(got.VariantID, want[i].VariantID) || got.Quantity != want[i].Quantity || got.Balance != want[i].Balance {
+			t.Errorf("movement %d = %+v, want %+v", i, got, want[i])
+		}
+		if got.Reason != "opening" || got.UserID == nil || *got.UserID != 7 {
+			t.Errorf("movement %d = %+v, want an opening by user 7", i, got)
+		}
+	}
+	if db.products[1].Stock != 12 || db.variants[1].Stock != 5 {
+		t.Errorf("stock = %d and %d, want the opening balances 12 and 5", db.products[1].Stock, db.variants[1].Stock)
+	}
+}
+
+func TestStockServiceRestock(t *testing.T) {
+	latte := uint(3)
+	almond := uint(1)
+	large := uint(2)
+	db := newFakeDB()
+	db.products[1] = models.Product{ID: 1, Name: "Croissant", Stock: 10}
+	db.products[2] = models.Product{ID: 2, Name: "Water", Stock: 20}
+	db.products[3] = models.Product{ID: 3, Name: "Latte"}
+	db.variants[1] = models.ProductVariant{ID: 1, ProductID: 1, Name: "Almond", Stock: 4, TrackStock: true}
+	db.variants[2] = models.ProductVariant{ID: 2, ProductID: 2, Name: "Large"}
+	db.ingredients[1] = models.Ingredient{ID: 1, Name: "Milk", Unit: "ml", Stock: 1000}
+	db.usages = []models.TransactionItemIngredient{{TransactionItemID: 13, IngredientID: 1, Quantity: 450}}
+	service := newTestStockService(db)
+
+	lines := []restockLine{
+		{Item: models.TransactionItem{ID: 11, ProductID: 1, VariantID: &almond, Quantity: 3}, Quantity: 2},
+		{Item: models.TransactionItem{ID: 12, ProductID: 2, VariantID: &large, Quantity: 5}, Quantity: 5},
+		{Item: models.TransactionItem{ID: 13, ProductID: latte, Quantity: 3, FromRecipe: true}, Quantity: 1},
+	}
+	refundID := uint(9)
+	origin := models.StockMovement{Reason: "refund", ReferenceID: &refundID}
+	err := db.open(t).Transaction(func(tx *gorm.DB) error {
+		return service.restock(tx, lines, origin)
+	})
+	if err != nil {
+		t.Fatalf("restock() error = %v", err)
+	}
+
+	// The variant keeps its own stock, the untracked variant goes back on
+	// its product, and the recipe line returns a third of its milk
+	want := []models.StockMovement{
+		{ProductID: 2, Quantity: 5, Balance: 25},
+		{ProductID: 1, VariantID: &almond, Quantity: 2, Balance: 6},
+	}
+	if len(db.movements) != len(want) {
+		t.Fatalf("movements = %+v, want %+v", db.movements, want)
+	}
+	for i, got := range db.movements {
+		if got.ProductID != want[i].ProductID || !sameID(got.VariantID, want[i].VariantID) || got.Quantity != want[i].Quantity || got.Balance != want[i].Balance {
+			t.Errorf("movement %d = %+v, want %+v", i, got, want[i])
+		}
+		if got.Reason != "refund" || got.ReferenceID == nil || *got.ReferenceID != refundID {
+			t.Errorf("movement %d = %+v, want a refund movement of refund %d", i, got, refundID)
+		}
+	}
+	if stock := db.products[1].Stock; stock != 10 {
+		t.Errorf("product 1 stock = %d, want 10", stock)
+	}
+	if stock := db.variants[1].Stock; stock != 6 {
+		t.Errorf("variant stock = %d, want 6", stock)
+	}
+	if stock := db.products[2].Stock; stock != 25 {
+		t.Errorf("product 2 stock = %d, want 25", stock)
+	}
+	if stock := db.ingredients[1].Stock; stock != 1150 {
+		t.Errorf("ingredient stock = %v, want 1150", stock)
+	}
+}
+
+// sameID reports whether two optional IDs are the same
+func sameID(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/models"
+	"github.com/syrlramadhan/cashier-app/repositories"
+)
+
+type SupplierService struct {
+	supplierRepo      repositories.SupplierRepository
+	purchaseOrderRepo repositories.PurchaseOrderRepository
+}
+
+func NewSupplierService(supplierRepo repositories.SupplierRepository, purchaseOrderRepo repositories.PurchaseOrderRepository) *SupplierService {
+	return &SupplierService{
+		supplierRepo:      supplierRepo,
+		purchaseOrderRepo: purchaseOrderRepo,
+	}
+}
+
+func (s *SupplierService) GetAllSuppliers() ([]dto.SupplierResponse, error) {
+	suppliers, err := s.supplierRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var response []dto.SupplierResponse
+	for _, supplier := range suppliers {
+		response = append(response, toSupplierResponse(&supplier))
+	}
+
+	return response, nil
+}
+
+func (s *SupplierService) GetSupplierByID(id uint) (*dto.SupplierResponse, error) {
+	supplier, err := s.supplierRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("supplier not found")
+	}
+
+	response := toSupplierResponse(supplier)
+	return &response, nil
+}
+
+func (s *SupplierService) CreateSupplier(req *dto.SupplierRequest) (*dto.SupplierResponse, error) {
+	supplier := &models.Supplier{}
+	applySupplierRequest(supplier, req)
+
+	if err := s.supplierRepo.Create(supplier); err != nil {
+		return nil, errors.New("failed to create supplier")
+	}
+
+	response := toSupplierResponse(supplier)
+	return &response, nil
+}
+
+func (s *SupplierService) UpdateSupplier(id uint, req *dto.SupplierRequest) (*dto.SupplierResponse, error) {
+	supplier, err := s.supplierRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("supplier not found")
+	}
+
+	applySupplierRequest(supplier, req)
+
+	if err := s.supplierRepo.Update(supplier); err != nil {
+		return nil, errors.New("failed to update supplier")
+	}
+
+	response := toSupplierResponse(supplier)
+	return &response, nil
+}
+
+// DeleteSupplier removes a supplier that has no open purchase orders; past
+// purchase orders keep pointing at it
+func (s *SupplierService) DeleteSupplier(id uint) error {
+	if _, err := s.supplierRepo.FindByID(id); err != nil {
+		return errors.New("supplier not found")
+	}
+
+	count, err := s.purchaseOrderRepo.CountOpenBySupplierID(id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("supplier has %d open purchase order(s)", count)
+	}
+
+	return s.supplierRepo.Delete(id)
+}
+
+func applySupplierRequest(supplier *models.Supplier, req *dto.SupplierRequest) {
+	supplier.Name = req.Name
+	supplier.ContactName = req.ContactName
+	supplier.Phone = req.Phone
+	supplier.Email = req.Email
+	supplier.Address = req.Address
+	supplier.LeadTimeDays = req.LeadTimeDays
+	supplier.IsActive = req.IsActive == nil || *req.IsActive
+}
+
+func toSupplierResponse(supplier *models.Supplier) dto.SupplierResponse {
+	return dto.SupplierResponse{
+		ID:           supplier.ID,
+		Name:         supplier.Name,
+		ContactName:  supplier.ContactName,
+		Phone:        supplier.Phone,
+		Email:        supplier.Email,
+		Address:      supplier.Address,
+		LeadTimeDays: supplier.LeadTimeDays,
+		IsActive:     supplier.IsActive,
+	}
+}
@@ -0,0 +1,410 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/models"
+	"github.com/syrlramadhan/cashier-app/repositories"
+	"gorm.io/gorm"
+)
+
+// TabService runs dine-in tabs: an order with status "open" tied to a table
+// that collects rounds of items until it is paid. Stock is taken when the tab
+// is closed.
+type TabService struct {
+	db                  *gorm.DB
+	tableRepo           repositories.TableRepository
+	transactionRepo     repositories.TransactionRepository
+	transactionItemRepo repositories.TransactionItemRepository
+	sequenceService     *SequenceService
+	transactionService  *TransactionService
+	kitchenService      *KitchenService
+	stockService        *StockService
+}
+
+func NewTabService(
+	db *gorm.DB,
+	tableRepo repositories.TableRepository,
+	transactionRepo repositories.TransactionRepository,
+	transactionItemRepo repositories.TransactionItemRepository,
+	sequenceService *SequenceService,
+	transactionService *TransactionService,
+	kitchenService *KitchenService,
+	stockService *StockService,
+) *TabService {
+	return &TabService{
+		db:                  db,
+		tableRepo:           tableRepo,
+		transactionRepo:     transactionRepo,
+		transactionItemRepo: transactionItemRepo,
+		sequenceService:     sequenceService,
+		transactionService:  transactionService,
+		kitchenService:      kitchenService,
+		stockService:        stockService,
+	}
+}
+
+func (s *TabService) GetOpenTabs() ([]dto.TransactionResponse, error) {
+	tabs, err := s.transactionRepo.FindOpenTabs()
+	if err != nil {
+		return nil, err
+	}
+
+	var response []dto.TransactionResponse
+	for _, tab := range tabs {
+		response = append(response, toTransactionResponse(&tab))
+	}
+
+	return response, nil
+}
+
+func (s *TabService) GetTab(id uint) (*dto.TransactionResponse, error) {
+	tab, err := s.transactionRepo.FindByIDWithDetails(id)
+	if err != nil || tab.Status != "open" {
+		return nil, errors.New("tab not found")
+	}
+
+	response := toTransactionResponse(tab)
+	return &response, nil
+}
+
+// OpenTab starts a tab on a free table
+func (s *TabService) OpenTab(tableID uint, req *dto.OpenTabRequest) (*dto.TransactionResponse, error) {
+	var tab *models.Transaction
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		tableRepo := s.tableRepo.WithTx(tx)
+
+		tables, err := tableRepo.FindByIDsForUpdate([]uint{tableID})
+		if err != nil || len(tables) == 0 {
+			return errors.New("table not found")
+		}
+		if tables[0].Status != "free" {
+			return errors.New("table already has an open tab")
+		}
+
+		code, err := s.sequenceService.NextCode(tx, "tab", "TAB", time.Now())
+		if err != nil {
+			return err
+		}
+
+		tab = &models.Transaction{
+			TransactionCode: code,
+			UserID:          req.UserID,
+			TableID:         &tables[0].ID,
+			OrderType:       "dine_in",
+			Status:          "open",
+			Note:            req.Note,
+		}
+		if err := s.transactionRepo.WithTx(tx).Create(tab); err != nil {
+			return errors.New("failed to open tab")
+		}
+
+		return tableRepo.UpdateStatus(tables[0].ID, "occupied")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := toTransactionResponse(tab)
+	return &response, nil
+}
+
+// AddRound adds a round of items to the tab
+func (s *TabService) AddRound(id uint, req *dto.AddRoundRequest) (*dto.TransactionResponse, error) {
+	var tab *models.Transaction
+	var tickets []models.KitchenTicket
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		tab, err = s.lockTab(tx, id)
+		if err != nil {
+			return err
+		}
+
+		items, err := s.transactionService.orderItems(req.Items, tab.OrderType)
+		if err != nil {
+			return err
+		}
+
+		round := lastRound(tab.Items) + 1
+		for i := range items {
+			items[i].TransactionID = tab.ID
+			items[i].Round = round
+		}
+		if err := s.transactionItemRepo.WithTx(tx).CreateBatch(items); err != nil {
+			return errors.New("failed to add round")
+		}
+
+		// Every round goes to the kitchen as soon as it is ordered
+		tickets, err = s.kitchenService.createTickets(tx, tab, items)
+		if err != nil {
+			return err
+		}
+
+		return s.refreshTab(tx, tab)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.kitchenService.publish("ticket.created", tickets)
+
+	response := toTransactionResponse(tab)
+	return &response, nil
+}
+
+// MoveTab moves the tab to another free table
+func (s *TabService) MoveTab(id uint, req *dto.MoveTabRequest) (*dto.TransactionResponse, error) {
+	var tab *models.Transaction
+	var tickets []models.KitchenTicket
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		tableRepo := s.tableRepo.WithTx(tx)
+
+		var err error
+		tab, err = s.lockTab(tx, id)
+		if err != nil {
+			return err
+		}
+		if tab.TableID == nil {
+			return errors.New("tab has no table")
+		}
+		if *tab.TableID == req.TableID {
+			return errors.New("tab is already on this table")
+		}
+
+		tables, err := tableRepo.FindByIDsForUpdate(sortedIDs(*tab.TableID, req.TableID))
+		if err != nil {
+			return errors.New("failed to lock tables")
+		}
+
+		var from, to *models.Table
+		for i := range tables {
+			switch tables[i].ID {
+			case *tab.TableID:
+				from = &tables[i]
+			case req.TableID:
+				to = &tables[i]
+			}
+		}
+		if to == nil {
+			return errors.New("table not found")
+		}
+		if to.Status != "free" {
+			return errors.New("table already has an open tab")
+		}
+
+		status := "occupied"
+		if from != nil {
+			status = from.Status
+			if err := tableRepo.UpdateStatus(from.ID, "free"); err != nil {
+				return err
+			}
+		}
+		if err := tableRepo.UpdateStatus(to.ID, status); err != nil {
+			return err
+		}
+
+		tab.TableID = &to.ID
+		if err := s.transactionRepo.WithTx(tx).Update(tab); err != nil {
+			return err
+		}
+
+		tickets, err = s.kitchenService.moveTickets(tx, tab.ID, tab)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.kitchenService.publish("ticket.updated", tickets)
+
+	response := toTransactionResponse(tab)
+	return &response, nil
+}
+
+// MergeTab moves every round of the source tab into this tab and frees the
+// source table; the source tab is removed.
+func (s *TabService) MergeTab(id uint, req *dto.MergeTabRequest) (*dto.TransactionResponse, error) {
+	if id == req.SourceTabID {
+		return nil, errors.New("cannot merge a tab into itself")
+	}
+
+	var tab *models.Transaction
+	var tickets []models.KitchenTicket
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Lock both tabs in ID order
+		var source *models.Transaction
+		for _, tabID := range sortedIDs(id, req.SourceTabID) {
+			locked, err := s.lockTab(tx, tabID)
+			if err != nil {
+				return err
+			}
+			if tabID == id {
+				tab = locked
+			} else {
+				source = locked
+			}
+		}
+
+		err := s.transactionItemRepo.WithTx(tx).MoveToTransaction(source.ID, tab.ID, lastRound(tab.Items))
+		if err != nil {
+			return errors.New("failed to merge tabs")
+		}
+
+		tickets, err = s.kitchenService.moveTickets(tx, source.ID, tab)
+		if err != nil {
+			return err
+		}
+
+		if err := s.transactionRepo.WithTx(tx).Delete(source.ID); err != nil {
+			return errors.New("failed to merge tabs")
+		}
+		if source.TableID != nil {
+			if err := s.tableRepo.WithTx(tx).UpdateStatus(*source.TableID, "free"); err != nil {
+				return err
+			}
+		}
+
+		return s.refreshTab(tx, tab)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.kitchenService.publish("ticket.updated", tickets)
+
+	response := toTransactionResponse(tab)
+	return &response, nil
+}
+
+// RequestBill flags the table so the floor view shows the bill was asked for
+func (s *TabService) RequestBill(id uint) (*dto.TransactionResponse, error) {
+	var tab *models.Transaction
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		tab, err = s.lockTab(tx, id)
+		if err != nil {
+			return err
+		}
+		if len(tab.Items) == 0 {
+			return errors.New("tab has no items")
+		}
+		if tab.TableID == nil {
+			return nil
+		}
+		return s.tableRepo.WithTx(tx).UpdateStatus(*tab.TableID, "bill_requested")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := toTransactionResponse(tab)
+	return &response, nil
+}
+
+// CloseTab pays the tab, turning it into a completed transaction, and frees the table
+func (s *TabService) CloseTab(id uint, req *dto.FinalizeOrderRequest) (*dto.TransactionResponse, error) {
+	var transaction *models.Transaction
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		tab, err := s.lockTab(tx, id)
+		if err != nil {
+			return err
+		}
+		if len(tab.Items) == 0 {
+			return errors.New("tab has no items")
+		}
+
+		transaction, err = s.transactionService.checkout(tx, checkoutRequest(tab.Items, req), tab, "completed")
+		if err != nil {
+			return err
+		}
+
+		if tab.TableID != nil {
+			return s.tableRepo.WithTx(tx).UpdateStatus(*tab.TableID, "free")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.stockService.publishAlerts()
+
+	response := toTransactionResponse(transaction)
+	return &response, nil
+}
+
+// VoidTab discards a tab opened by mistake and frees its table
+func (s *TabService) VoidTab(id uint) error {
+	var tickets []models.KitchenTicket
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		tab, err := s.lockTab(tx, id)
+		if err != nil {
+			return err
+		}
+
+		tickets, err = s.kitchenService.cancelTickets(tx, tab.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := s.transactionRepo.WithTx(tx).Delete(tab.ID); err != nil {
+			return errors.New("failed to void tab")
+		}
+		if tab.TableID != nil {
+			return s.tableRepo.WithTx(tx).UpdateStatus(*tab.TableID, "free")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.kitchenService.publish("ticket.cancelled", tickets)
+	return nil
+}
+
+func (s *TabService) lockTab(tx *gorm.DB, id uint) (*models.Transaction, error) {
+	tab, err := s.transactionRepo.WithTx(tx).FindByIDForUpdate(id)
+	if err != nil || tab.Status != "open" {
+		return nil, errors.New("tab not found")
+	}
+	return tab, nil
+}
+
+// refreshTab reloads the lines of the tab and updates its estimated totals
+func (s *TabService) refreshTab(tx *gorm.DB, tab *models.Transaction) error {
+	items, err := s.transactionItemRepo.WithTx(tx).FindByTransactionID(tab.ID)
+	if err != nil {
+		return errors.New("failed to load tab items")
+	}
+
+	tab.Items = items
+	s.transactionService.estimateTotals(tab)
+
+	// Only the header changes here; the lines are already stored
+	tab.Items = nil
+	err = s.transactionRepo.WithTx(tx).Update(tab)
+	tab.Items = items
+	if err != nil {
+		return errors.New("failed to update tab")
+	}
+
+	if tab.TableID != nil {
+		return s.tableRepo.WithTx(tx).UpdateStatus(*tab.TableID, "occupied")
+	}
+	return nil
+}
+
+func lastRound(items []models.TransactionItem) int {
+	round := 0
+	for _, item := range items {
+		if item.Round > round {
+			round = item.Round
+		}
+	}
+	return round
+}
+
+func sortedIDs(ids ...uint) []uint {
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
@@ -0,0 +1,232 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/models"
+)
+
+// newTestTabs returns a tab service over a fake database with three free
+// tables and coffee (20000) and tea (15000), ten of each in stock
+func newTestTabs(t *testing.T) (*fakeDB, *TabService) {
+	db := newFakeDB()
+	for id := uint(1); id <= 3; id++ {
+		db.tables[id] = models.Table{ID: id, Status: "free"}
+	}
+	db.products[1] = models.Product{ID: 1, Name: "Coffee", Price: 20000, Stock: 10}
+	db.products[2] = models.Product{ID: 2, Name: "Tea", Price: 15000, Stock: 10}
+	db.nextID = 10
+
+	itemRepo := &fakeTransactionItemRepository{db: db}
+	transactionService := newTestTransactionService(t, db)
+	transactionService.transactionItemRepo = itemRepo
+	ticketRepo := &fakeKitchenTicketRepository{db: db}
+	eventHub := NewEventHub()
+	return db, NewTabService(
+		db.open(t),
+		&fakeTableRepository{db: db},
+		&fakeTransactionRepository{db: db},
+		itemRepo,
+		NewSequenceService(&fakeSequenceRepository{db: db}, newTestSettingService(nil)),
+		transactionService,
+		NewKitchenService(nil, ticketRepo, NewQueueService(&fakeTransactionRepository{db: db}, ticketRepo, eventHub), eventHub),
+		newTestStockService(db),
+	)
+}
+
+// openTestTab opens a tab on table with a round for each of rounds
+func openTestTab(t *testing.T, service *TabService, table uint, rounds ...[]dto.OrderItemRequest) uint {
+	t.Helper()
+	tab, err := service.OpenTab(table, &dto.OpenTabRequest{UserID: 1})
+	if err != nil {
+		t.Fatalf("OpenTab() error = %v", err)
+	}
+	for _, items := range rounds {
+		if _, err := service.AddRound(tab.ID, &dto.AddRoundRequest{Items: items}); err != nil {
+			t.Fatalf("AddRound() error = %v", err)
+		}
+	}
+	return tab.ID
+}
+
+func TestMoveTab(t *testing.T) {
+	tests := []struct {
+		name       string
+		to         uint
+		wantErr    bool
+		wantTable  uint
+		wantStatus map[uint]string
+	}{
+		{
+			name:       "free table",
+			to:         3,
+			wantTable:  3,
+			wantStatus: map[uint]string{1: "free", 2: "occupied", 3: "occupied"},
+		},
+		{
+			name:       "table with an open tab",
+			to:         2,
+			wantErr:    true,
+			wantTable:  1,
+			wantStatus: map[uint]string{1: "occupied", 2: "occupied", 3: "free"},
+		},
+		{
+			name:       "same table",
+			to:         1,
+			wantErr:    true,
+			wantTable:  1,
+			wantStatus: map[uint]string{1: "occupied", 2: "occupied", 3: "free"},
+		},
+		{
+			name:       "unknown table",
+			to:         9,
+			wantErr:    true,
+			wantTable:  1,
+			wantStatus: map[uint]string{1: "occupied", 2: "occupied", 3: "free"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, service := newTestTabs(t)
+			tab := openTestTab(t, service, 1, []dto.OrderItemRequest{{ProductID: 1, Quantity: 1}})
+			openTestTab(t, service, 2)
+
+			_, err := service.MoveTab(tab, &dto.MoveTabRequest{TableID: tt.to})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MoveTab() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if table := db.transactions[tab].TableID; table == nil || *table != tt.wantTable {
+				t.Errorf("tab table = %v, want %d", table, tt.wantTable)
+			}
+			for id, want := range tt.wantStatus {
+				if status := db.tables[id].Status; status != want {
+					t.Errorf("table %d status = %q, want %q", id, status, want)
+				}
+			}
+			for _, ticket := range db.tickets {
+				if ticket.TableID == nil || *ticket.TableID != tt.wantTable {
+					t.Errorf("ticket table = %v, want %d", ticket.TableID, tt.wantTable)
+				}
+			}
+		})
+	}
+}
+
+func TestMergeTab(t *testing.T) {
+	db, service := newTestTabs(t)
+	tab := openTestTab(t, service, 1, []dto.OrderItemRequest{{ProductID: 1, Quantity: 2}})
+	source := openTestTab(t, service, 2,
+		[]dto.OrderItemRequest{{ProductID: 2, Quantity: 1}},
+		[]dto.OrderItemRequest{{ProductID: 1, Quantity: 1}},
+	)
+
+	merged, err := service.MergeTab(tab, &dto.MergeTabRequest{SourceTabID: source})
+	if err != nil {
+		t.Fatalf("MergeTab() error = %v", err)
+	}
+
+	// 2 coffee + 1 tea + 1 coffee, with the default 11% tax on top
+	if merged.Subtotal != 75000 || merged.Tax != 8250 || merged.Total != 83250 {
+		t.Errorf("subtotal, tax, total = %d, %d, %d, want 75000, 8250, 83250", merged.Subtotal, merged.Tax, merged.Total)
+	}
+	if stored := db.transactions[tab]; stored.Subtotal != 75000 || stored.Total != 83250 {
+		t.Errorf("stored subtotal, total = %d, %d, want 75000, 83250", stored.Subtotal, stored.Total)
+	}
+
+	// The rounds of the source follow the rounds already on the tab
+	wantRounds := []int{1, 2, 3}
+	items := db.itemsOf(nil, tab)
+	if len(items) != len(wantRounds) {
+		t.Fatalf("%d lines on the tab, want %d", len(items), len(wantRounds))
+	}
+	for i, item := range items {
+		if item.Round != wantRounds[i] {
+			t.Errorf("line %d round = %d, want %d", i, item.Round, wantRounds[i])
+		}
+	}
+
+	if _, ok := db.transactions[source]; ok {
+		t.Error("source tab kept, want it removed")
+	}
+	if status := db.tables[2].Status; status != "free" {
+		t.Errorf("source table status = %q, want free", status)
+	}
+	if status := db.tables[1].Status; status != "occupied" {
+		t.Errorf("tab table status = %q, want occupied", status)
+	}
+	for _, ticket := range db.tickets {
+		if ticket.TransactionID != tab {
+			t.Errorf("ticket %d on order %d, want %d", ticket.ID, ticket.TransactionID, tab)
+		}
+	}
+
+	if _, err := service.MergeTab(tab, &dto.MergeTabRequest{SourceTabID: tab}); err == nil {
+		t.Error("MergeTab() into itself succeeded, want an error")
+	}
+}
+
+func TestCloseTab(t *testing.T) {
+	db, service := newTestTabs(t)
+	tab := openTestTab(t, service, 1,
+		[]dto.OrderItemRequest{{ProductID: 1, Quantity: 2}},
+		[]dto.OrderItemRequest{{ProductID: 2, Quantity: 3}},
+	)
+
+	// Stock is only taken when the tab is paid
+	if db.products[1].Stock != 10 || db.products[2].Stock != 10 {
+		t.Fatalf("stock = %d, %d before closing, want 10, 10", db.products[1].Stock, db.products[2].Stock)
+	}
+
+	transaction, err := service.CloseTab(tab, &dto.FinalizeOrderRequest{UserID: 1, PaymentMethod: "card"})
+	if err != nil {
+		t.Fatalf("CloseTab() error = %v", err)
+	}
+
+	if transaction.Status != "completed" || transaction.Total != 94350 {
+		t.Errorf("status, total = %q, %d, want completed, 94350", transaction.Status, transaction.Total)
+	}
+	if db.products[1].Stock != 8 || db.products[2].Stock != 7 {
+		t.Errorf("stock = %d, %d, want 8, 7", db.products[1].Stock, db.products[2].Stock)
+	}
+	if status := db.tables[1].Status; status != "free" {
+		t.Errorf("table status = %q, want free", status)
+	}
+	if _, err := service.CloseTab(tab, &dto.FinalizeOrderRequest{UserID: 1, PaymentMethod: "card"}); err == nil {
+		t.Error("CloseTab() twice succeeded, want an error")
+	}
+}
+
+func TestVoidTab(t *testing.T) {
+	db, service := newTestTabs(t)
+	tab := openTestTab(t, service, 1, []dto.OrderItemRequest{{ProductID: 1, Quantity: 2}, {ProductID: 2, Quantity: 1}})
+
+	if err := service.VoidTab(tab); err != nil {
+		t.Fatalf("VoidTab() error = %v", err)
+	}
+
+	// An open tab has taken no stock yet, so the stock is whole again and
+	// the ledger has nothing to undo
+	if db.products[1].Stock != 10 || db.products[2].Stock != 10 {
+		t.Errorf("stock = %d, %d, want 10, 10", db.products[1].Stock, db.products[2].Stock)
+	}
+	if len(db.movements) != 0 {
+		t.Errorf("movements = %+v, want none", db.movements)
+	}
+	if _, ok := db.transactions[tab]; ok {
+		t.Error("tab kept, want it removed")
+	}
+	if status := db.tables[1].Status; status != "free" {
+		t.Errorf("table status = %q, want free", status)
+	}
+	for _, ticket := range db.tickets {
+		if ticket.Status != "cancelled" {
+			t.Errorf("ticket %d status = %q, want cancelled", ticket.ID, ticket.Status)
+		}
+	}
+	if err := service.VoidTab(tab); err == nil {
+		t.Error("VoidTab() twice succeeded, want an error")
+	}
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/models"
+	"github.com/syrlramadhan/cashier-app/repositories"
+)
+
+type TableService struct {
+	tableRepo       repositories.TableRepository
+	transactionRepo repositories.TransactionRepository
+}
+
+func NewTableService(tableRepo repositories.TableRepository, transactionRepo repositories.TransactionRepository) *TableService {
+	return &TableService{
+		tableRepo:       tableRepo,
+		transactionRepo: transactionRepo,
+	}
+}
+
+// GetAllTables returns the floor view: every table with its status and open tab
+func (s *TableService) GetAllTables() ([]dto.TableResponse, error) {
+	tables, err := s.tableRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+
+	tabs, err := s.transactionRepo.FindOpenTabs()
+	if err != nil {
+		return nil, err
+	}
+
+	tabsByTable := make(map[uint]*models.Transaction)
+	for i := range tabs {
+		if tabs[i].TableID != nil {
+			tabsByTable[*tabs[i].TableID] = &tabs[i]
+		}
+	}
+
+	var response []dto.TableResponse
+	for _, table := range tables {
+		response = append(response, *s.mapTableToResponse(&table, tabsByTable[table.ID]))
+	}
+
+	return response, nil
+}
+
+func (s *TableService) GetTableByID(id uint) (*dto.TableResponse, error) {
+	table, err := s.tableRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("table not found")
+	}
+
+	tab, _ := s.transactionRepo.FindOpenTabByTable(table.ID)
+	if tab != nil {
+		tab, _ = s.transactionRepo.FindByIDWithDetails(tab.ID)
+	}
+
+	return s.mapTableToResponse(table, tab), nil
+}
+
+func (s *TableService) CreateTable(req *dto.CreateTableRequest) (*dto.TableResponse, error) {
+	if _, err := s.tableRepo.FindByName(req.Name); err == nil {
+		return nil, errors.New("table name already exists")
+	}
+
+	table := &models.Table{
+		Name:     req.Name,
+		Area:     req.Area,
+		Capacity: req.Capacity,
+		Status:   "free",
+	}
+
+	err := s.tableRepo.Create(table)
+	if err != nil {
+		return nil, errors.New("failed to create table")
+	}
+
+	return s.mapTableToResponse(table, nil), nil
+}
+
+func (s *TableService) UpdateTable(id uint, req *dto.UpdateTableRequest) (*dto.TableResponse, error) {
+	table, err := s.tableRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("table not found")
+	}
+
+	if req.Name != "" && req.Name != table.Name {
+		if _, err := s.tableRepo.FindByName(req.Name); err == nil {
+			return nil, errors.New("table name already exists")
+		}
+		table.Name = req.Name
+	}
+	if req.Area != "" {
+		table.Area = req.Area
+	}
+	if req.Capacity != nil {
+		table.Capacity = *req.Capacity
+	}
+
+	err = s.tableRepo.Update(table)
+	if err != nil {
+		return nil, errors.New("failed to update table")
+	}
+
+	return s.mapTableToResponse(table, nil), nil
+}
+
+func (s *TableService) DeleteTable(id uint) error {
+	table, err := s.tableRepo.FindByID(id)
+	if err != nil {
+		return errors.New("table not found")
+	}
+
+	if table.Status != "free" {
+		return errors.New("table has an open tab")
+	}
+
+	return s.tableRepo.Delete(id)
+}
+
+func (s *TableService) mapTableToResponse(table *models.Table, tab *models.Transaction) *dto.TableResponse {
+	response := &dto.TableResponse{
+		ID:       table.ID,
+		Name:     table.Name,
+		Area:     table.Area,
+		Capacity: table.Capacity,
+		Status:   table.Status,
+	}
+
+	if tab != nil {
+		itemCount := 0
+		for _, item := range tab.Items {
+			itemCount += item.Quantity
+		}
+		response.OpenTab = &dto.TableTabSummary{
+			ID:              tab.ID,
+			TransactionCode: tab.TransactionCode,
+			ItemCount:       itemCount,
+			Total:           tab.Total,
+			OpenedAt:        tab.CreatedAt,
+		}
+	}
+
+	return response
+}
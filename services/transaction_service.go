@@ -3,32 +3,67 @@ package services
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/syrlramadhan/cashier-app/dto"
 	"github.com/syrlramadhan/cashier-app/models"
 	"github.com/syrlramadhan/cashier-app/repositories"
+	"gorm.io/gorm"
 )
 
 type TransactionService struct {
+	db                  *gorm.DB
 	transactionRepo     repositories.TransactionRepository
 	transactionItemRepo repositories.TransactionItemRepository
 	productRepo         repositories.ProductRepository
+	variantRepo         repositories.ProductVariantRepository
+	tableRepo           repositories.TableRepository
+	sequenceService     *SequenceService
+	settingService      *SettingService
+	promotionService    *PromotionService
+	voucherService      *VoucherService
+	modifierService     *ModifierService
+	ingredientService   *IngredientService
+	stockService        *StockService
+	kitchenService      *KitchenService
 }
 
 func NewTransactionService(
+	db *gorm.DB,
 	transactionRepo repositories.TransactionRepository,
 	transactionItemRepo repositories.TransactionItemRepository,
 	productRepo repositories.ProductRepository,
+	variantRepo repositories.ProductVariantRepository,
+	tableRepo repositories.TableRepository,
+	sequenceService *SequenceService,
+	settingService *SettingService,
+	promotionService *PromotionService,
+	voucherService *VoucherService,
+	modifierService *ModifierService,
+	ingredientService *IngredientService,
+	stockService *StockService,
+	kitchenService *KitchenService,
 ) *TransactionService {
 	return &TransactionService{
+		db:                  db,
 		transactionRepo:     transactionRepo,
 		transactionItemRepo: transactionItemRepo,
 		productRepo:         productRepo,
+		variantRepo:         variantRepo,
+		tableRepo:           tableRepo,
+		sequenceService:     sequenceService,
+		settingService:      settingService,
+		promotionService:    promotionService,
+		voucherService:      voucherService,
+		modifierService:     modifierService,
+		ingredientService:   ingredientService,
+		stockService:        stockService,
+		kitchenService:      kitchenService,
 	}
 }
 
-func (s *TransactionService) GetAllTransactions(startDate, endDate *time.Time, paymentMethod string) ([]dto.TransactionResponse, error) {
+func (s *TransactionService) GetAllTransactions(startDate, endDate *time.Time, paymentMethod, orderType string) ([]dto.TransactionResponse, error) {
 	transactions, err := s.transactionRepo.FindAll()
 	if err != nil {
 		return nil, err
@@ -43,8 +78,11 @@ func (s *TransactionService) GetAllTransactions(startDate, endDate *time.Time, p
 		if endDate != nil && transaction.CreatedAt.After(*endDate) {
 			continue
 		}
-		// Apply payment method filter
-		if paymentMethod != "" && transaction.PaymentMethod != paymentMethod {
+		// Apply payment method filter (matches any tender of a split payment)
+		if paymentMethod != "" && !paidWith(&transaction, paymentMethod) {
+			continue
+		}
+		if orderType != "" && transaction.OrderType != orderType {
 			continue
 		}
 
@@ -91,119 +129,801 @@ func (s *TransactionService) CreateTransaction(req *dto.CreateTransactionRequest
 		return nil, errors.New("transaction must have at least one item")
 	}
 
-	taxRate := 0.11 // 11% tax rate
+	var transaction *models.Transaction
+	var tickets []models.KitchenTicket
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		transaction, err = s.checkout(tx, req, nil, "completed")
+		if err != nil {
+			return err
+		}
 
-	// Validate products and calculate totals
-	var subtotal float64
-	var items []models.TransactionItem
+		tickets, err = s.kitchenService.createTickets(tx, transaction, transaction.Items)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.kitchenService.publish("ticket.created", tickets)
+	s.stockService.publishAlerts()
+
+	return s.mapTransactionToResponse(transaction), nil
+}
+
+// checkout prices the cart, settles the payments and takes the stock inside
+// tx. When order is given (a held order or an open tab) the sale takes over
+// its row and lines, otherwise a new transaction is created. With status
+// "split" the order is priced and its stock taken, but it keeps its code and
+// is paid through its split bills instead.
+func (s *TransactionService) checkout(tx *gorm.DB, req *dto.CreateTransactionRequest, order *models.Transaction, status string) (*models.Transaction, error) {
+	taxRate, taxInclusive, err := s.settingService.GetTaxConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	// A held order or tab keeps the order type it was taken with
+	orderType := req.OrderType
+	if order != nil {
+		orderType = order.OrderType
+	}
+	orderType = orderTypeOrDefault(orderType)
+
+	serviceChargeRate, err := s.serviceChargeRate(orderType)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	promotions, err := s.promotionService.activePromotions(now)
+	if err != nil {
+		return nil, err
+	}
+	maxManualDiscount := s.manualDiscountLimit(req.Role)
+
+	productRepo := s.productRepo.WithTx(tx)
+	variantRepo := s.variantRepo.WithTx(tx)
+	transactionRepo := s.transactionRepo.WithTx(tx)
+
+	// Lock every product in the cart so stock cannot change until commit
+	products, err := lockProducts(productRepo, req.Items)
+	if err != nil {
+		return nil, err
+	}
+	variants, err := lockVariants(variantRepo, req.Items)
+	if err != nil {
+		return nil, err
+	}
+	recipes, err := s.ingredientService.recipeBook(tx, req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate products and build the cart
+	var lines []*cartLine
+	requested := make(map[uint]int)
+	requestedVariants := make(map[uint]int)
+	requestedIngredients := make(map[uint]float64)
 
 	for _, itemReq := range req.Items {
-		product, err := s.productRepo.FindByID(itemReq.ProductID)
-		if err != nil {
+		product, ok := products[itemReq.ProductID]
+		if !ok {
 			return nil, fmt.Errorf("product not found: %d", itemReq.ProductID)
 		}
 
-		if product.Stock < itemReq.Quantity {
-			return nil, fmt.Errorf("insufficient stock for product: %s", product.Name)
+		variant, err := chooseVariant(&product, itemReq.VariantID)
+		if err != nil {
+			return nil, err
 		}
 
-		itemSubtotal := product.Price * float64(itemReq.Quantity)
-		subtotal += itemSubtotal
+		unitPrice := product.PriceFor(orderType)
+		if variant != nil {
+			locked, ok := variants[variant.ID]
+			if !ok {
+				return nil, fmt.Errorf("variant not found: %d", variant.ID)
+			}
+			variant = &locked
+			unitPrice = variant.PriceFrom(unitPrice)
+		}
 
-		items = append(items, models.TransactionItem{
-			ProductID:   product.ID,
-			ProductName: product.Name,
-			Price:       product.Price,
+		modifiers, err := s.modifierService.resolveModifiers(&product, itemReq.ModifierIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, modifier := range modifiers {
+			unitPrice += modifier.Price
+		}
+
+		// Lines made to a recipe are sold from their ingredients, otherwise a
+		// variant with its own stock is sold from it, otherwise from the product
+		ingredients, fromRecipe := recipes.forLine(product.ID, variant, modifiers)
+		for ingredientID, quantity := range ingredients {
+			requestedIngredients[ingredientID] += quantity * float64(itemReq.Quantity)
+		}
+
+		if fromRecipe {
+			// Checked once the ingredients of the whole cart are locked
+		} else if variant != nil && variant.TrackStock {
+			requestedVariants[variant.ID] += itemReq.Quantity
+			if variant.Stock < requestedVariants[variant.ID] {
+				return nil, fmt.Errorf("insufficient stock for product: %s (%s)", product.Name, variant.Name)
+			}
+		} else {
+			requested[product.ID] += itemReq.Quantity
+			if product.Stock < requested[product.ID] {
+				return nil, fmt.Errorf("insufficient stock for product: %s", product.Name)
+			}
+		}
+
+		lines = append(lines, &cartLine{
+			Product:     product,
+			Variant:     variant,
+			Modifiers:   modifiers,
+			Ingredients: ingredients,
+			FromRecipe:  fromRecipe,
 			Quantity:    itemReq.Quantity,
-			Subtotal:    itemSubtotal,
+			UnitPrice:   unitPrice,
 		})
 	}
 
-	tax := subtotal * taxRate
-	total := subtotal + tax
+	lockedIngredients, err := s.ingredientService.lockIngredients(tx, requestedIngredients)
+	if err != nil {
+		return nil, err
+	}
 
-	// Generate transaction code
-	transactionCode := fmt.Sprintf("TRX%s%04d", time.Now().Format("20060102"), time.Now().UnixNano()%10000)
+	// Lock the voucher so a single-use code is only redeemed once
+	var voucher *models.Voucher
+	if req.VoucherCode != "" {
+		voucher, err = s.voucherService.lockVoucher(tx, req.VoucherCode, req.CustomerRef, now)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	subtotal, orderDiscounts, err := applyDiscounts(lines, promotions, voucher, req, maxManualDiscount)
+	if err != nil {
+		return nil, err
+	}
+
+	var discount models.Money
+	for _, orderDiscount := range orderDiscounts {
+		discount += orderDiscount.Amount
+	}
+
+	var items []models.TransactionItem
+	for i, line := range lines {
+		var item models.TransactionItem
+		if order != nil {
+			// Keep the order's line (ID, round, time ordered) and reprice it
+			item = order.Items[i]
+		}
+		item.ProductID = line.Product.ID
+		item.ProductName = line.Product.Name
+		item.VariantID = nil
+		item.VariantName = ""
+		if line.Variant != nil {
+			item.VariantID = &line.Variant.ID
+			item.VariantName = line.Variant.Name
+		}
+		item.Modifiers = line.Modifiers
+		item.FromRecipe = line.FromRecipe
+		item.Price = line.UnitPrice
+		item.Quantity = line.Quantity
+		item.Discount = line.Discount()
+		item.Subtotal = line.Net()
+		items = append(items, item)
+	}
+
+	serviceCharge := (subtotal - discount).MulRate(serviceChargeRate)
+	tax, total := calculateTax(subtotal-discount+serviceCharge, taxRate, taxInclusive)
+
+	settlement := &paymentSettlement{}
+	var transactionCode, fulfillmentStatus string
+	var queueNumber int
+	if status == "split" {
+		transactionCode = order.TransactionCode
+	} else {
+		settlement, err = s.settlePayments(total, req)
+		if err != nil {
+			return nil, err
+		}
+
+		// Reserve the next consecutive receipt number; it is released on rollback
+		transactionCode, err = s.sequenceService.NextCode(tx, "transaction", "TRX", now)
+		if err != nil {
+			return nil, err
+		}
+
+		// Counter orders are called by a daily queue number; tabs are served at the table
+		if order == nil || order.TableID == nil {
+			queueNumber, err = s.sequenceService.NextNumber(tx, "queue", now)
+			if err != nil {
+				return nil, err
+			}
+			fulfillmentStatus = "preparing"
+		}
+	}
 
 	// Create transaction
 	transaction := &models.Transaction{
-		TransactionCode: transactionCode,
-		UserID:          req.UserID,
-		Subtotal:        subtotal,
-		Tax:             tax,
-		Total:           total,
-		PaymentMethod:   req.PaymentMethod,
-		Status:          "completed",
-		Items:           items,
+		TransactionCode:   transactionCode,
+		UserID:            req.UserID,
+		OrderType:         orderType,
+		Subtotal:          subtotal,
+		Discount:          discount,
+		ServiceChargeRate: serviceChargeRate,
+		ServiceCharge:     serviceCharge,
+		TaxRate:           taxRate,
+		TaxInclusive:      taxInclusive,
+		Tax:               tax,
+		Rounding:          settlement.Rounding,
+		Total:             total + settlement.Rounding,
+		AmountTendered:    settlement.AmountTendered,
+		ChangeDue:         settlement.ChangeDue,
+		PaymentMethod:     settlement.Method,
+		Status:            status,
+		QueueNumber:       queueNumber,
+		FulfillmentStatus: fulfillmentStatus,
+		Items:             items,
+		Payments:          settlement.Payments,
 	}
 
-	err := s.transactionRepo.Create(transaction)
-	if err != nil {
-		return nil, errors.New("failed to create transaction")
+	if order == nil {
+		if err := transactionRepo.Create(transaction); err != nil {
+			return nil, errors.New("failed to create transaction")
+		}
+	} else {
+		// The sale takes over the order row and its lines
+		transaction.ID = order.ID
+		transaction.TableID = order.TableID
+		transaction.Note = order.Note
+		transaction.CreatedAt = now
+		transaction.Items = nil
+		if err := transactionRepo.Update(transaction); err != nil {
+			return nil, errors.New("failed to finalize order")
+		}
+
+		transactionItemRepo := s.transactionItemRepo.WithTx(tx)
+		for i := range items {
+			if err := transactionItemRepo.Update(&items[i]); err != nil {
+				return nil, errors.New("failed to finalize order")
+			}
+			if err := transactionItemRepo.ReplaceModifiers(items[i].ID, items[i].Modifiers); err != nil {
+				return nil, errors.New("failed to finalize order")
+			}
+		}
+		transaction.Items = items
+	}
+
+	// Record the applied discounts now that the item IDs are known
+	var discounts []models.TransactionDiscount
+	for i, line := range lines {
+		for _, lineDiscount := range line.Discounts {
+			lineDiscount.TransactionItemID = &transaction.Items[i].ID
+			discounts = append(discounts, lineDiscount)
+		}
+	}
+	discounts = append(discounts, orderDiscounts...)
+	for i := range discounts {
+		discounts[i].TransactionID = transaction.ID
+	}
+	if len(discounts) > 0 {
+		if err := transactionRepo.CreateDiscounts(discounts); err != nil {
+			return nil, errors.New("failed to record discounts")
+		}
+	}
+	transaction.Discounts = discounts
+
+	for _, discount := range discounts {
+		if discount.VoucherID != nil {
+			if err := s.voucherService.redeem(tx, voucher, transaction.ID, req.CustomerRef, discount.Amount); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	// Update product stock
-	for _, itemReq := range req.Items {
-		product, _ := s.productRepo.FindByID(itemReq.ProductID)
-		newStock := product.Stock - itemReq.Quantity
-		s.productRepo.UpdateStock(itemReq.ProductID, newStock)
+	var movements []models.StockMovement
+	for _, productID := range sortedProductIDs(requested) {
+		movements = append(movements, models.StockMovement{
+			ProductID:   productID,
+			Quantity:    -requested[productID],
+			Balance:     products[productID].Stock - requested[productID],
+			Reason:      "sale",
+			ReferenceID: &transaction.ID,
+			UserID:      &transaction.UserID,
+		})
+	}
+	for _, variantID := range sortedProductIDs(requestedVariants) {
+		id := variantID
+		movements = append(movements, models.StockMovement{
+			ProductID:   variants[variantID].ProductID,
+			VariantID:   &id,
+			Quantity:    -requestedVariants[variantID],
+			Balance:     variants[variantID].Stock - requestedVariants[variantID],
+			Reason:      "sale",
+			ReferenceID: &transaction.ID,
+			UserID:      &transaction.UserID,
+		})
+	}
+	if err := s.stockService.record(tx, movements); err != nil {
+		return nil, err
 	}
 
-	return s.mapTransactionToResponse(transaction), nil
+	var usages []models.TransactionItemIngredient
+	for i, line := range lines {
+		for _, ingredientID := range sortedIngredientIDs(line.Ingredients) {
+			usages = append(usages, models.TransactionItemIngredient{
+				TransactionItemID: transaction.Items[i].ID,
+				IngredientID:      ingredientID,
+				Quantity:          roundQuantity(line.Ingredients[ingredientID] * float64(line.Quantity)),
+			})
+		}
+	}
+	if err := s.ingredientService.consumeIngredients(tx, lockedIngredients, usages); err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
 }
 
-func (s *TransactionService) CancelTransaction(id uint) error {
-	transaction, err := s.transactionRepo.FindByID(id)
+func (s *TransactionService) CancelTransaction(id, userID uint) error {
+	var tickets []models.KitchenTicket
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		transactionRepo := s.transactionRepo.WithTx(tx)
+
+		transaction, err := transactionRepo.FindByIDForUpdate(id)
+		if err != nil {
+			return errors.New("transaction not found")
+		}
+
+		if transaction.Status == "cancelled" {
+			return errors.New("transaction is already cancelled")
+		}
+
+		if transaction.ParentID != nil {
+			return errors.New("split bills cannot be cancelled; cancel their split order instead")
+		}
+
+		if transaction.Status != "completed" && transaction.Status != "split" {
+			return errors.New("only completed transactions can be cancelled")
+		}
+
+		if transaction.RefundedTotal > 0 {
+			return errors.New("transaction has refunds; refund the remaining items instead")
+		}
+
+		var lines []restockLine
+		for _, item := range transaction.Items {
+			lines = append(lines, restockLine{Item: item, Quantity: item.Quantity})
+		}
+		if err := s.stockService.restock(tx, lines, models.StockMovement{Reason: "cancel", ReferenceID: &transaction.ID, UserID: &userID}); err != nil {
+			return err
+		}
+
+		if err := s.voucherService.release(tx, transaction.ID); err != nil {
+			return err
+		}
+
+		tickets, err = s.kitchenService.cancelTickets(tx, transaction.ID)
+		if err != nil {
+			return err
+		}
+
+		// A split order is voided with all its bills, paid or not
+		if transaction.Status == "split" {
+			bills, err := transactionRepo.FindChildrenForUpdate(transaction.ID)
+			if err != nil {
+				return errors.New("failed to load split bills")
+			}
+			unpaid := false
+			for i := range bills {
+				unpaid = unpaid || bills[i].Status == "unpaid"
+				bills[i].Status = "cancelled"
+				if err := transactionRepo.Update(&bills[i]); err != nil {
+					return errors.New("failed to cancel split bills")
+				}
+			}
+			// The table waited for the unpaid bills; it is freed by the last payment otherwise
+			if unpaid && transaction.TableID != nil {
+				if err := s.tableRepo.WithTx(tx).UpdateStatus(*transaction.TableID, "free"); err != nil {
+					return err
+				}
+			}
+		}
+
+		// Update transaction status
+		transaction.Status = "cancelled"
+		return transactionRepo.Update(transaction)
+	})
 	if err != nil {
-		return errors.New("transaction not found")
+		return err
+	}
+
+	s.kitchenService.publish("ticket.cancelled", tickets)
+	return nil
+}
+
+// orderItems prices the lines of an unpaid order at the current product prices for its order type
+func (s *TransactionService) orderItems(lines []dto.OrderItemRequest, orderType string) ([]models.TransactionItem, error) {
+	var items []models.TransactionItem
+	for _, line := range lines {
+		product, err := s.productRepo.FindByID(line.ProductID)
+		if err != nil {
+			return nil, fmt.Errorf("product not found: %d", line.ProductID)
+		}
+
+		variant, err := chooseVariant(product, line.VariantID)
+		if err != nil {
+			return nil, err
+		}
+
+		modifiers, err := s.modifierService.resolveModifiers(product, line.ModifierIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		item := models.TransactionItem{
+			ProductID:   product.ID,
+			ProductName: product.Name,
+			Price:       product.PriceFor(orderType),
+			Quantity:    line.Quantity,
+		}
+		if variant != nil {
+			item.VariantID = &variant.ID
+			item.VariantName = variant.Name
+			item.Price = variant.PriceFrom(item.Price)
+		}
+		for _, modifier := range modifiers {
+			item.Price += modifier.Price
+		}
+		item.Modifiers = modifiers
+		item.Subtotal = item.Price.Mul(line.Quantity)
+		items = append(items, item)
 	}
+	return items, nil
+}
 
-	if transaction.Status == "cancelled" {
-		return errors.New("transaction is already cancelled")
+// estimateTotals shows the amount due of an unpaid order before discounts;
+// the final figures are calculated at checkout.
+func (s *TransactionService) estimateTotals(order *models.Transaction) {
+	var subtotal models.Money
+	for _, item := range order.Items {
+		subtotal += item.Subtotal
 	}
 
-	// Restore stock
-	for _, item := range transaction.Items {
-		product, err := s.productRepo.FindByID(item.ProductID)
-		if err == nil {
-			newStock := product.Stock + item.Quantity
-			s.productRepo.UpdateStock(item.ProductID, newStock)
+	taxRate, taxInclusive, err := s.settingService.GetTaxConfig()
+	if err != nil {
+		taxRate, taxInclusive = 0, false
+	}
+
+	serviceChargeRate, err := s.serviceChargeRate(order.OrderType)
+	if err != nil {
+		serviceChargeRate = 0
+	}
+
+	order.Subtotal = subtotal
+	order.ServiceChargeRate = serviceChargeRate
+	order.ServiceCharge = subtotal.MulRate(serviceChargeRate)
+	order.TaxRate = taxRate
+	order.TaxInclusive = taxInclusive
+	order.Tax, order.Total = calculateTax(subtotal+order.ServiceCharge, taxRate, taxInclusive)
+}
+
+// manualDiscountLimit is the largest manual discount, in percent, a role may give
+func (s *TransactionService) manualDiscountLimit(role string) float64 {
+	switch role {
+	case "admin":
+		return 100
+	case "manager":
+		return s.settingService.GetFloat("max_manual_discount_manager", 50)
+	default:
+		return s.settingService.GetFloat("max_manual_discount_cashier", 10)
+	}
+}
+
+// serviceChargeRate is the service charge, as a fraction, of an order type;
+// only dine-in orders are charged
+func (s *TransactionService) serviceChargeRate(orderType string) (float64, error) {
+	if orderType != "dine_in" {
+		return 0, nil
+	}
+	return s.settingService.GetServiceChargeRate()
+}
+
+// orderTypeOrDefault treats orders without an order type as takeaway
+func orderTypeOrDefault(orderType string) string {
+	if orderType == "" {
+		return "takeaway"
+	}
+	return orderType
+}
+
+// calculateTax returns the tax (rounded half-up to whole rupiah) and the
+// amount payable for subtotal. With tax-inclusive pricing the subtotal already
+// contains the tax, so the tax portion is back-calculated and the total equals
+// the subtotal.
+func calculateTax(subtotal models.Money, taxRate float64, taxInclusive bool) (models.Money, models.Money) {
+	if taxInclusive {
+		return subtotal.InclusiveTax(taxRate), subtotal
+	}
+	tax := subtotal.MulRate(taxRate)
+	return tax, subtotal + tax
+}
+
+// paymentSettlement is the outcome of matching the tenders against the total
+type paymentSettlement struct {
+	Method         string // single tender method, or "split"
+	Rounding       models.Money
+	AmountTendered models.Money
+	ChangeDue      models.Money
+	Payments       []models.TransactionPayment
+}
+
+// settlePayments validates the tenders of req against total. Without a
+// payments list the whole total is paid with req.PaymentMethod. Cash rounding
+// (cash_rounding setting) only applies when every tender is cash, and the
+// amount tendered is matched against the cash portion to compute the change.
+func (s *TransactionService) settlePayments(total models.Money, req *dto.CreateTransactionRequest) (*paymentSettlement, error) {
+	tenders := req.Payments
+	if len(tenders) == 0 {
+		if req.PaymentMethod == "" {
+			return nil, errors.New("payment method is required")
+		}
+		tenders = []dto.PaymentRequest{{Method: req.PaymentMethod}}
+	}
+
+	allCash := true
+	for _, tender := range tenders {
+		if !s.settingService.GetBool("payment_"+tender.Method+"_enabled", true) {
+			return nil, fmt.Errorf("payment method %s is disabled", tender.Method)
+		}
+		if tender.Method != "cash" {
+			allCash = false
+		}
+	}
+
+	settlement := &paymentSettlement{Method: tenders[0].Method}
+	if len(tenders) > 1 {
+		settlement.Method = "split"
+	}
+	if req.PaymentMethod != "" && req.PaymentMethod != settlement.Method {
+		return nil, fmt.Errorf("payment method %s does not match the payments", req.PaymentMethod)
+	}
+
+	if allCash {
+		roundingUnit := models.Money(s.settingService.GetInt("cash_rounding", 0))
+		settlement.Rounding = total.RoundTo(roundingUnit) - total
+	}
+	payable := total + settlement.Rounding
+
+	// A single tender without an amount pays the whole total
+	if len(req.Payments) == 0 {
+		tenders[0].Amount = payable
+	}
+
+	var paid, cashAmount models.Money
+	for _, tender := range tenders {
+		paid += tender.Amount
+		if tender.Method == "cash" {
+			cashAmount += tender.Amount
+		}
+		settlement.Payments = append(settlement.Payments, models.TransactionPayment{
+			Method:    tender.Method,
+			Amount:    tender.Amount,
+			Reference: tender.Reference,
+		})
+	}
+
+	if paid != payable {
+		return nil, fmt.Errorf("payments total %d does not match the amount due of %d", paid, payable)
+	}
+
+	if req.AmountTendered != nil {
+		if cashAmount == 0 {
+			return nil, errors.New("amount tendered requires a cash payment")
+		}
+		if *req.AmountTendered < cashAmount {
+			return nil, fmt.Errorf("amount tendered is less than the cash amount of %d", cashAmount)
+		}
+		settlement.AmountTendered = *req.AmountTendered
+		settlement.ChangeDue = *req.AmountTendered - cashAmount
+	} else {
+		settlement.AmountTendered = cashAmount
+	}
+
+	return settlement, nil
+}
+
+// paidWith reports whether any tender of the transaction used method
+func paidWith(transaction *models.Transaction, method string) bool {
+	if transaction.PaymentMethod == method {
+		return true
+	}
+	for _, payment := range transaction.Payments {
+		if payment.Method == method {
+			return true
 		}
 	}
+	return false
+}
+
+// lockProducts loads and locks every product referenced by the cart, keyed by ID.
+func lockProducts(productRepo repositories.ProductRepository, items []dto.TransactionItemRequest) (map[uint]models.Product, error) {
+	quantities := make(map[uint]int)
+	for _, item := range items {
+		quantities[item.ProductID] += item.Quantity
+	}
 
-	// Update transaction status
-	transaction.Status = "cancelled"
-	return s.transactionRepo.Update(transaction)
+	products, err := productRepo.FindByIDsForUpdate(sortedProductIDs(quantities))
+	if err != nil {
+		return nil, errors.New("failed to lock products")
+	}
+
+	result := make(map[uint]models.Product, len(products))
+	for _, product := range products {
+		result[product.ID] = product
+	}
+	return result, nil
+}
+
+// lockVariants loads and locks every variant chosen in the cart, keyed by ID.
+// It is called after lockProducts so rows are always locked products first.
+func lockVariants(variantRepo repositories.ProductVariantRepository, items []dto.TransactionItemRequest) (map[uint]models.ProductVariant, error) {
+	quantities := make(map[uint]int)
+	for _, item := range items {
+		if item.VariantID != nil {
+			quantities[*item.VariantID] += item.Quantity
+		}
+	}
+
+	result := make(map[uint]models.ProductVariant, len(quantities))
+	if len(quantities) == 0 {
+		return result, nil
+	}
+
+	variants, err := variantRepo.FindByIDsForUpdate(sortedProductIDs(quantities))
+	if err != nil {
+		return nil, errors.New("failed to lock variants")
+	}
+	for _, variant := range variants {
+		result[variant.ID] = variant
+	}
+	return result, nil
+}
+
+// chooseVariant returns the variant picked for a line of product. A product
+// with active variants is only sold as one of them.
+func chooseVariant(product *models.Product, variantID *uint) (*models.ProductVariant, error) {
+	if variantID == nil {
+		for _, variant := range product.Variants {
+			if variant.IsActive {
+				return nil, fmt.Errorf("choose a variant for product: %s", product.Name)
+			}
+		}
+		return nil, nil
+	}
+
+	for i := range product.Variants {
+		variant := &product.Variants[i]
+		if variant.ID != *variantID {
+			continue
+		}
+		if !variant.IsActive {
+			return nil, fmt.Errorf("variant %s of %s is not available", variant.Name, product.Name)
+		}
+		return variant, nil
+	}
+	return nil, fmt.Errorf("variant %d does not belong to product: %s", *variantID, product.Name)
+}
+
+// sortedProductIDs returns the keys in ascending order so rows are always
+// locked and updated in the same sequence.
+func sortedProductIDs(quantities map[uint]int) []uint {
+	ids := make([]uint, 0, len(quantities))
+	for id := range quantities {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
 }
 
 func (s *TransactionService) mapTransactionToResponse(transaction *models.Transaction) *dto.TransactionResponse {
+	response := toTransactionResponse(transaction)
+	return &response
+}
+
+// toTransactionResponse is shared with ReportService so exports show the same
+// figures (including the tax rate applied at sale time) as receipts.
+func toTransactionResponse(transaction *models.Transaction) dto.TransactionResponse {
 	var itemResponses []dto.TransactionItemResponse
 	for _, item := range transaction.Items {
+		var modifierResponses []dto.TransactionItemModifierResponse
+		for _, modifier := range item.Modifiers {
+			modifierResponses = append(modifierResponses, dto.TransactionItemModifierResponse{
+				ModifierOptionID: modifier.ModifierOptionID,
+				GroupName:        modifier.GroupName,
+				Name:             modifier.Name,
+				Price:            modifier.Price,
+			})
+		}
+
 		itemResponses = append(itemResponses, dto.TransactionItemResponse{
 			ID:          item.ID,
 			ProductID:   item.ProductID,
 			ProductName: item.ProductName,
+			VariantID:   item.VariantID,
+			VariantName: item.VariantName,
+			Modifiers:   modifierResponses,
 			Price:       item.Price,
 			Quantity:    item.Quantity,
+			RefundedQty: item.RefundedQty,
+			Round:       item.Round,
+			Discount:    item.Discount,
 			Subtotal:    item.Subtotal,
 		})
 	}
 
+	var discountResponses []dto.TransactionDiscountResponse
+	for _, discount := range transaction.Discounts {
+		discountResponses = append(discountResponses, dto.TransactionDiscountResponse{
+			ID:                discount.ID,
+			TransactionItemID: discount.TransactionItemID,
+			PromotionID:       discount.PromotionID,
+			VoucherID:         discount.VoucherID,
+			Source:            discount.Source,
+			Name:              discount.Name,
+			Amount:            discount.Amount,
+		})
+	}
+
+	var paymentResponses []dto.TransactionPaymentResponse
+	for _, payment := range transaction.Payments {
+		paymentResponses = append(paymentResponses, dto.TransactionPaymentResponse{
+			ID:        payment.ID,
+			Method:    payment.Method,
+			Amount:    payment.Amount,
+			Reference: payment.Reference,
+		})
+	}
+
+	var childResponses []dto.TransactionResponse
+	for _, child := range transaction.Children {
+		childResponses = append(childResponses, toTransactionResponse(&child))
+	}
+
 	cashierName := ""
 	if transaction.User.ID > 0 {
 		cashierName = transaction.User.Name
 	}
 
-	return &dto.TransactionResponse{
-		ID:              transaction.ID,
-		TransactionCode: transaction.TransactionCode,
-		CashierName:     cashierName,
-		Subtotal:        transaction.Subtotal,
-		Tax:             transaction.Tax,
-		Total:           transaction.Total,
-		PaymentMethod:   transaction.PaymentMethod,
-		Status:          transaction.Status,
-		Items:           itemResponses,
-		CreatedAt:       transaction.CreatedAt,
+	return dto.TransactionResponse{
+		ID:                transaction.ID,
+		TransactionCode:   transaction.TransactionCode,
+		CashierName:       cashierName,
+		TableID:           transaction.TableID,
+		ParentID:          transaction.ParentID,
+		OrderType:         transaction.OrderType,
+		Subtotal:          transaction.Subtotal,
+		Discount:          transaction.Discount,
+		ServiceChargeRate: transaction.ServiceChargeRate,
+		ServiceCharge:     transaction.ServiceCharge,
+		TaxRate:           transaction.TaxRate,
+		TaxInclusive:      transaction.TaxInclusive,
+		Tax:               transaction.Tax,
+		Rounding:          transaction.Rounding,
+		Total:             transaction.Total,
+		AmountTendered:    transaction.AmountTendered,
+		ChangeDue:         transaction.ChangeDue,
+		RefundedTotal:     transaction.RefundedTotal,
+		PaymentMethod:     transaction.PaymentMethod,
+		Status:            transaction.Status,
+		QueueNumber:       transaction.QueueNumber,
+		FulfillmentStatus: transaction.FulfillmentStatus,
+		Note:              transaction.Note,
+		Items:             itemResponses,
+		Payments:          paymentResponses,
+		Discounts:         discountResponses,
+		Children:          childResponses,
+		CreatedAt:         transaction.CreatedAt,
 	}
 }
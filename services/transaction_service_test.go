@@ -0,0 +1,410 @@
+package services
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/models"
+)
+
+func newTestTransactionService(t *testing.T, db *fakeDB) *TransactionService {
+	settingService := newTestSettingService(nil)
+	productRepo := &fakeProductRepository{db: db}
+	variantRepo := &fakeProductVariantRepository{db: db}
+	ingredientRepo := &fakeIngredientRepository{db: db}
+	ticketRepo := &fakeKitchenTicketRepository{db: db}
+	eventHub := NewEventHub()
+	queueService := NewQueueService(&fakeTransactionRepository{db: db}, ticketRepo, eventHub)
+	return NewTransactionService(
+		db.open(t),
+		&fakeTransactionRepository{db: db},
+		nil,
+		productRepo,
+		variantRepo,
+		nil,
+		NewSequenceService(&fakeSequenceRepository{db: db}, settingService),
+		settingService,
+		NewPromotionService(&fakePromotionRepository{}, nil, nil),
+		NewVoucherService(nil, &fakeVoucherRepository{db: db}),
+		NewModifierService(nil, &fakeModifierRepository{}, nil, nil),
+		NewIngredientService(nil, ingredientRepo, nil, nil, nil),
+		newTestStockService(db),
+		NewKitchenService(nil, ticketRepo, queueService, eventHub),
+	)
+}
+
+func TestCreateTransactionRollsBack(t *testing.T) {
+	tests := []struct {
+		name string
+		fail string
+	}{
+		{name: "transaction insert fails", fail: "transactions.Create"},
+		{name: "stock update fails", fail: "products.UpdateStock"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newFakeDB()
+			db.products[1] = models.Product{ID: 1, Name: "Coffee", Price: 20000, Stock: 10}
+			db.products[2] = models.Product{ID: 2, Name: "Tea", Price: 15000, Stock: 10}
+			db.nextID = 2
+			db.fail[tt.fail] = true
+			service := newTestTransactionService(t, db)
+
+			_, err := service.CreateTransaction(&dto.CreateTransactionRequest{
+				UserID:        1,
+				PaymentMethod: "cash",
+				Items:         []dto.TransactionItemRequest{{ProductID: 1, Quantity: 2}, {ProductID: 2, Quantity: 1}},
+			})
+			if err == nil {
+				t.Fatal("CreateTransaction() succeeded, want an error")
+			}
+
+			if db.rollbacks != 1 || db.commits != 0 {
+				t.Errorf("commits, rollbacks = %d, %d, want 0, 1", db.commits, db.rollbacks)
+			}
+			if len(db.transactions) != 0 {
+				t.Errorf("%d transactions stored, want none", len(db.transactions))
+			}
+			for id, product := range db.products {
+				if product.Stock != 10 {
+					t.Errorf("product %d stock = %d, want 10", id, product.Stock)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateTransactionDoesNotOversell(t *testing.T) {
+	db := newFakeDB()
+	db.products[1] = models.Product{ID: 1, Name: "Coffee", Price: 20000, Stock: 5}
+	db.nextID = 1
+	service := newTestTransactionService(t, db)
+
+	const buyers = 20
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	sold := 0
+	for i := 0; i < buyers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := service.CreateTransaction(&dto.CreateTransactionRequest{
+				UserID:        1,
+				PaymentMethod: "cash",
+				Items:         []dto.TransactionItemRequest{{ProductID: 1, Quantity: 1}},
+			})
+			if err == nil {
+				mu.Lock()
+				sold++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if sold != 5 {
+		t.Errorf("%d checkouts succeeded, want 5", sold)
+	}
+	if stock := db.products[1].Stock; stock != 0 {
+		t.Errorf("stock = %d, want 0", stock)
+	}
+	if len(db.transactions) != 5 {
+		t.Errorf("%d transactions stored, want 5", len(db.transactions))
+	}
+}
+
+func TestCreateTransactionPricesByOrderType(t *testing.T) {
+	tests := []struct {
+		name              string
+		orderType         string
+		taxInclusive      string
+		wantSubtotal      models.Money
+		wantServiceCharge models.Money
+		wantTax           models.Money
+		wantTotal         models.Money
+	}{
+		// 2 x 22000 dine-in price, 5% service charge, 10% tax on top of both
+		{"dine-in tax exclusive", "dine_in", "false", 44000, 2200, 4620, 50820},
+		// The tax is part of the 46200 charged
+		{"dine-in tax inclusive", "dine_in", "true", 44000, 2200, 4200, 46200},
+		{"takeaway tax exclusive", "takeaway", "false", 40000, 0, 4000, 44000},
+		{"delivery tax inclusive", "delivery", "true", 50000, 0, 4545, 50000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newFakeDB()
+			db.products[1] = models.Product{
+				ID:    1,
+				Name:  "Coffee",
+				Price: 20000,
+				Stock: 10,
+				Prices: []models.ProductPrice{
+					{OrderType: "dine_in", Price: 22000},
+					{OrderType: "delivery", Price: 25000},
+				},
+			}
+			db.nextID = 1
+			service := newTestTransactionService(t, db)
+			service.settingService = newTestSettingService(map[string]string{
+				"service_charge_rate": "5",
+				"tax_rate":            "10",
+				"tax_inclusive":       tt.taxInclusive,
+			})
+
+			transaction, err := service.CreateTransaction(&dto.CreateTransactionRequest{
+				UserID:        1,
+				OrderType:     tt.orderType,
+				PaymentMethod: "card",
+				Items:         []dto.TransactionItemRequest{{ProductID: 1, Quantity: 2}},
+			})
+			if err != nil {
+				t.Fatalf("CreateTransaction() error = %v", err)
+			}
+
+			if transaction.Subtotal != tt.wantSubtotal {
+				t.Errorf("subtotal = %d, want %d", transaction.Subtotal, tt.wantSubtotal)
+			}
+			if transaction.ServiceCharge != tt.wantServiceCharge {
+				t.Errorf("service charge = %d, want %d", transaction.ServiceCharge, tt.wantServiceCharge)
+			}
+			if transaction.Tax != tt.wantTax {
+				t.Errorf("tax = %d, want %d", transaction.Tax, tt.wantTax)
+			}
+			if transaction.Total != tt.wantTotal {
+				t.Errorf("total = %d, want %d", transaction.Total, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestCancelTransaction(t *testing.T) {
+	newSale := func(t *testing.T) (*fakeDB, *TransactionService, uint) {
+		db := newFakeDB()
+		db.products[1] = models.Product{ID: 1, Name: "Coffee", Price: 20000, Stock: 10}
+		db.nextID = 1
+		service := newTestTransactionService(t, db)
+
+		sale, err := service.CreateTransaction(&dto.CreateTransactionRequest{
+			UserID:        1,
+			PaymentMethod: "cash",
+			Items:         []dto.TransactionItemRequest{{ProductID: 1, Quantity: 3}},
+		})
+		if err != nil {
+			t.Fatalf("CreateTransaction() error = %v", err)
+		}
+		return db, service, sale.ID
+	}
+
+	t.Run("restocks once under concurrent cancels", func(t *testing.T) {
+		db, service, id := newSale(t)
+
+		var wg sync.WaitGroup
+		errs := make([]error, 2)
+		for i := range errs {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				errs[i] = service.CancelTransaction(id, 1)
+			}(i)
+		}
+		wg.Wait()
+
+		if (errs[0] == nil) == (errs[1] == nil) {
+			t.Errorf("CancelTransaction() errors = %v, %v, want exactly one to succeed", errs[0], errs[1])
+		}
+		if stock := db.products[1].Stock; stock != 10 {
+			t.Errorf("stock = %d, want 10", stock)
+		}
+		if status := db.transactions[id].Status; status != "cancelled" {
+			t.Errorf("status = %q, want cancelled", status)
+		}
+	})
+
+	t.Run("rolls back when the status update fails", func(t *testing.T) {
+		db, service, id := newSale(t)
+		db.fail["transactions.Update"] = true
+
+		if err := service.CancelTransaction(id, 1); err == nil {
+			t.Fatal("CancelTransaction() succeeded, want an error")
+		}
+		if stock := db.products[1].Stock; stock != 7 {
+			t.Errorf("stock = %d, want 7", stock)
+		}
+		if status := db.transactions[id].Status; status != "completed" {
+			t.Errorf("status = %q, want completed", status)
+		}
+	})
+}
+
+func TestSettlePayments(t *testing.T) {
+	tendered := func(amount models.Money) *models.Money { return &amount }
+
+	tests := []struct {
+		name         string
+		settings     map[string]string
+		total        models.Money
+		req          dto.CreateTransactionRequest
+		wantErr      bool
+		wantMethod   string
+		wantRounding models.Money
+		wantTendered models.Money
+		wantChange   models.Money
+		wantPayments []models.Money
+	}{
+		{
+			name:         "single tender pays the total",
+			total:        55500,
+			req:          dto.CreateTransactionRequest{PaymentMethod: "card"},
+			wantMethod:   "card",
+			wantPayments: []models.Money{55500},
+		},
+		{
+			name:         "exact cash defaults the amount tendered",
+			total:        55500,
+			req:          dto.CreateTransactionRequest{PaymentMethod: "cash"},
+			wantMethod:   "cash",
+			wantTendered: 55500,
+			wantPayments: []models.Money{55500},
+		},
+		{
+			name:         "cash with change",
+			total:        55500,
+			req:          dto.CreateTransactionRequest{PaymentMethod: "cash", AmountTendered: tendered(100000)},
+			wantMethod:   "cash",
+			wantTendered: 100000,
+			wantChange:   44500,
+			wantPayments: []models.Money{55500},
+		},
+		{
+			name:    "cash tendered below the total",
+			total:   55500,
+			req:     dto.CreateTransactionRequest{PaymentMethod: "cash", AmountTendered: tendered(50000)},
+			wantErr: true,
+		},
+		{
+			name:         "cash rounding",
+			settings:     map[string]string{"cash_rounding": "500"},
+			total:        55300,
+			req:          dto.CreateTransactionRequest{PaymentMethod: "cash", AmountTendered: tendered(60000)},
+			wantMethod:   "cash",
+			wantRounding: 200,
+			wantTendered: 60000,
+			wantChange:   4500,
+			wantPayments: []models.Money{55500},
+		},
+		{
+			name:         "no cash rounding on card",
+			settings:     map[string]string{"cash_rounding": "500"},
+			total:        55300,
+			req:          dto.CreateTransactionRequest{PaymentMethod: "card"},
+			wantMethod:   "card",
+			wantPayments: []models.Money{55300},
+		},
+		{
+			name:  "mixed tenders with change on the cash part",
+			total: 55500,
+			req: dto.CreateTransactionRequest{
+				Payments:       []dto.PaymentRequest{{Method: "card", Amount: 30000}, {Method: "cash", Amount: 25500}},
+				AmountTendered: tendered(50000),
+			},
+			wantMethod:   "split",
+			wantTendered: 50000,
+			wantChange:   24500,
+			wantPayments: []models.Money{30000, 25500},
+		},
+		{
+			name:     "mixed tenders are not cash rounded",
+			settings: map[string]string{"cash_rounding": "500"},
+			total:    55300,
+			req: dto.CreateTransactionRequest{
+				Payments: []dto.PaymentRequest{{Method: "qris", Amount: 30000}, {Method: "cash", Amount: 25300}},
+			},
+			wantMethod:   "split",
+			wantTendered: 25300,
+			wantPayments: []models.Money{30000, 25300},
+		},
+		{
+			name:  "tenders that do not add up to the total",
+			total: 55500,
+			req: dto.CreateTransactionRequest{
+				Payments: []dto.PaymentRequest{{Method: "card", Amount: 30000}, {Method: "cash", Amount: 20000}},
+			},
+			wantErr: true,
+		},
+		{
+			name:  "payment method that agrees with the payments",
+			total: 55500,
+			req: dto.CreateTransactionRequest{
+				PaymentMethod: "qris",
+				Payments:      []dto.PaymentRequest{{Method: "qris", Amount: 55500}},
+			},
+			wantMethod:   "qris",
+			wantPayments: []models.Money{55500},
+		},
+		{
+			name:  "payment method that disagrees with the payments",
+			total: 55500,
+			req: dto.CreateTransactionRequest{
+				PaymentMethod: "cash",
+				Payments:      []dto.PaymentRequest{{Method: "card", Amount: 30000}, {Method: "cash", Amount: 25500}},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "amount tendered without cash",
+			total:   55500,
+			req:     dto.CreateTransactionRequest{PaymentMethod: "card", AmountTendered: tendered(60000)},
+			wantErr: true,
+		},
+		{
+			name:     "disabled payment method",
+			settings: map[string]string{"payment_qris_enabled": "false"},
+			total:    55500,
+			req:      dto.CreateTransactionRequest{PaymentMethod: "qris"},
+			wantErr:  true,
+		},
+		{
+			name:    "missing payment method",
+			total:   55500,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &TransactionService{settingService: newTestSettingService(tt.settings)}
+
+			settlement, err := service.settlePayments(tt.total, &tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("settlePayments() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if settlement.Method != tt.wantMethod {
+				t.Errorf("method = %s, want %s", settlement.Method, tt.wantMethod)
+			}
+			if settlement.Rounding != tt.wantRounding {
+				t.Errorf("rounding = %d, want %d", settlement.Rounding, tt.wantRounding)
+			}
+			if settlement.AmountTendered != tt.wantTendered {
+				t.Errorf("amount tendered = %d, want %d", settlement.AmountTendered, tt.wantTendered)
+			}
+			if settlement.ChangeDue != tt.wantChange {
+				t.Errorf("change due = %d, want %d", settlement.ChangeDue, tt.wantChange)
+			}
+			if len(settlement.Payments) != len(tt.wantPayments) {
+				t.Fatalf("payments = %+v, want amounts %v", settlement.Payments, tt.wantPayments)
+			}
+			for i, payment := range settlement.Payments {
+				if payment.Amount != tt.wantPayments[i] {
+					t.Errorf("payment %d = %d, want %d", i, payment.Amount, tt.wantPayments[i])
+				}
+			}
+		})
+	}
+}
@@ -12,6 +12,14 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// StreamTokenScope is the scope claim of stream tokens; only
+// middleware.StreamAuthMiddleware accepts them
+const StreamTokenScope = "stream"
+
+// streamTokenTTL only bounds opening the stream; an open stream is not cut
+// off when its token expires
+const streamTokenTTL = time.Minute
+
 type UserService struct {
 	userRepo repositories.UserRepository
 }
@@ -134,6 +142,36 @@ func (s *UserService) GetUserByID(id uint) (*dto.UserResponse, error) {
 	return response, nil
 }
 
+// GenerateStreamToken issues a short-lived token for Server-Sent Events
+// routes. EventSource cannot send an Authorization header, so the token
+// travels in the query string; its scope keeps it off every other route.
+func (s *UserService) GenerateStreamToken(userID uint) (*dto.StreamTokenResponse, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	if !user.IsActive {
+		return nil, errors.New("user account is inactive")
+	}
+
+	token, err := signToken(jwt.MapClaims{
+		"user_id": user.ID,
+		"email":   user.Email,
+		"role":    user.Role,
+		"scope":   StreamTokenScope,
+		"exp":     time.Now().Add(streamTokenTTL).Unix(),
+	})
+	if err != nil {
+		return nil, errors.New("failed to generate token")
+	}
+
+	return &dto.StreamTokenResponse{
+		Token:     token,
+		ExpiresIn: int(streamTokenTTL.Seconds()),
+	}, nil
+}
+
 func (s *UserService) UpdateUser(id uint, req *dto.UpdateUserRequest) (*dto.UserResponse, error) {
 	user, err := s.userRepo.FindByID(id)
 	if err != nil {
@@ -208,16 +246,18 @@ func (s *UserService) DeleteUser(id uint) error {
 }
 
 func generateToken(user *models.User) (string, error) {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		secret = "default-secret-key"
-	}
-
-	claims := jwt.MapClaims{
+	return signToken(jwt.MapClaims{
 		"user_id": user.ID,
 		"email":   user.Email,
 		"role":    user.Role,
 		"exp":     time.Now().Add(time.Hour * 24).Unix(),
+	})
+}
+
+func signToken(claims jwt.MapClaims) (string, error) {
+	secret := os.Getenv("JWT_SECRET")
+	if secret == "" {
+		secret = "default-secret-key"
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
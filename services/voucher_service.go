@@ -0,0 +1,380 @@
+package services
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/models"
+	"github.com/syrlramadhan/cashier-app/repositories"
+	"gorm.io/gorm"
+)
+
+// voucherCodeAlphabet leaves out characters that are easy to misread (0/O, 1/I)
+const voucherCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+type VoucherService struct {
+	db          *gorm.DB
+	voucherRepo repositories.VoucherRepository
+}
+
+func NewVoucherService(db *gorm.DB, voucherRepo repositories.VoucherRepository) *VoucherService {
+	return &VoucherService{
+		db:          db,
+		voucherRepo: voucherRepo,
+	}
+}
+
+func (s *VoucherService) GetAllVouchers(batchCode string) ([]dto.VoucherResponse, error) {
+	vouchers, err := s.voucherRepo.FindAll(batchCode)
+	if err != nil {
+		return nil, err
+	}
+
+	var response []dto.VoucherResponse
+	for _, voucher := range vouchers {
+		response = append(response, *s.mapVoucherToResponse(&voucher))
+	}
+
+	return response, nil
+}
+
+func (s *VoucherService) GetVoucherByID(id uint) (*dto.VoucherResponse, error) {
+	voucher, err := s.voucherRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("voucher not found")
+	}
+
+	return s.mapVoucherToResponse(voucher), nil
+}
+
+func (s *VoucherService) CreateVoucher(req *dto.VoucherRequest) (*dto.VoucherResponse, error) {
+	if err := validateVoucherDiscount(req.Type, req.DiscountPercent, req.DiscountAmount, req.StartsAt, req.EndsAt); err != nil {
+		return nil, err
+	}
+
+	code := normalizeVoucherCode(req.Code)
+	if _, err := s.voucherRepo.FindByCode(code); err == nil {
+		return nil, errors.New("voucher code already exists")
+	}
+
+	voucher := &models.Voucher{Code: code, IsActive: true}
+	applyVoucherRequest(voucher, req)
+
+	err := s.voucherRepo.Create(voucher)
+	if err != nil {
+		return nil, errors.New("failed to create voucher")
+	}
+
+	return s.mapVoucherToResponse(voucher), nil
+}
+
+func (s *VoucherService) UpdateVoucher(id uint, req *dto.VoucherRequest) (*dto.VoucherResponse, error) {
+	voucher, err := s.voucherRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("voucher not found")
+	}
+
+	if err := validateVoucherDiscount(req.Type, req.DiscountPercent, req.DiscountAmount, req.StartsAt, req.EndsAt); err != nil {
+		return nil, err
+	}
+
+	code := normalizeVoucherCode(req.Code)
+	if code != voucher.Code {
+		if _, err := s.voucherRepo.FindByCode(code); err == nil {
+			return nil, errors.New("voucher code already exists")
+		}
+		voucher.Code = code
+	}
+
+	applyVoucherRequest(voucher, req)
+
+	err = s.voucherRepo.Update(voucher)
+	if err != nil {
+		return nil, errors.New("failed to update voucher")
+	}
+
+	return s.mapVoucherToResponse(voucher), nil
+}
+
+func (s *VoucherService) DeleteVoucher(id uint) error {
+	_, err := s.voucherRepo.FindByID(id)
+	if err != nil {
+		return errors.New("voucher not found")
+	}
+
+	return s.voucherRepo.Delete(id)
+}
+
+// GenerateVouchers creates a batch of random single-use codes sharing one
+// batch code (PREFIX-YYYYMMDDHHMMSS) so they can be listed and reported together.
+func (s *VoucherService) GenerateVouchers(req *dto.GenerateVouchersRequest) ([]dto.VoucherResponse, error) {
+	if err := validateVoucherDiscount(req.Type, req.DiscountPercent, req.DiscountAmount, req.StartsAt, req.EndsAt); err != nil {
+		return nil, err
+	}
+
+	prefix := normalizeVoucherCode(req.Prefix)
+	batchCode := fmt.Sprintf("%s-%s", prefix, time.Now().Format("20060102150405"))
+
+	vouchers := make([]models.Voucher, 0, req.Quantity)
+	seen := make(map[string]bool, req.Quantity)
+	for len(vouchers) < req.Quantity {
+		suffix, err := randomVoucherSuffix(8)
+		if err != nil {
+			return nil, errors.New("failed to generate voucher codes")
+		}
+		code := prefix + suffix
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+
+		vouchers = append(vouchers, models.Voucher{
+			Code:            code,
+			Name:            req.Name,
+			BatchCode:       batchCode,
+			Type:            req.Type,
+			DiscountPercent: req.DiscountPercent,
+			DiscountAmount:  req.DiscountAmount,
+			MaxDiscount:     req.MaxDiscount,
+			MinSubtotal:     req.MinSubtotal,
+			StartsAt:        req.StartsAt,
+			EndsAt:          req.EndsAt,
+			UsageLimit:      1,
+			Stackable:       req.Stackable,
+			IsActive:        true,
+		})
+	}
+
+	err := s.voucherRepo.CreateBatch(vouchers)
+	if err != nil {
+		return nil, errors.New("failed to create vouchers")
+	}
+
+	vouchers, err = s.voucherRepo.FindAll(batchCode)
+	if err != nil {
+		return nil, err
+	}
+
+	var response []dto.VoucherResponse
+	for _, voucher := range vouchers {
+		response = append(response, *s.mapVoucherToResponse(&voucher))
+	}
+
+	return response, nil
+}
+
+// ValidateVoucher lets the POS check a code and preview its discount before
+// payment. The voucher is only reserved when the transaction is created.
+func (s *VoucherService) ValidateVoucher(req *dto.ValidateVoucherRequest) (*dto.VoucherValidationResponse, error) {
+	voucher, err := s.voucherRepo.FindByCode(normalizeVoucherCode(req.Code))
+	if err != nil {
+		return nil, errors.New("voucher not found")
+	}
+
+	if err := s.checkVoucher(s.voucherRepo, voucher, req.CustomerRef, time.Now()); err != nil {
+		return nil, err
+	}
+
+	discount, err := voucherDiscount(voucher, req.Subtotal)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.VoucherValidationResponse{
+		Code:      voucher.Code,
+		Name:      voucher.Name,
+		Discount:  discount,
+		Stackable: voucher.Stackable,
+	}, nil
+}
+
+// lockVoucher loads and locks the voucher for redemption inside tx, so a
+// single-use code cannot be redeemed by two checkouts at the same time.
+func (s *VoucherService) lockVoucher(tx *gorm.DB, code, customerRef string, now time.Time) (*models.Voucher, error) {
+	voucherRepo := s.voucherRepo.WithTx(tx)
+
+	voucher, err := voucherRepo.FindByCodeForUpdate(normalizeVoucherCode(code))
+	if err != nil {
+		return nil, errors.New("voucher not found")
+	}
+
+	if err := s.checkVoucher(voucherRepo, voucher, customerRef, now); err != nil {
+		return nil, err
+	}
+
+	return voucher, nil
+}
+
+// redeem records the voucher on the transaction and counts the usage
+func (s *VoucherService) redeem(tx *gorm.DB, voucher *models.Voucher, transactionID uint, customerRef string, amount models.Money) error {
+	voucherRepo := s.voucherRepo.WithTx(tx)
+
+	err := voucherRepo.CreateRedemption(&models.VoucherRedemption{
+		VoucherID:     voucher.ID,
+		TransactionID: transactionID,
+		CustomerRef:   customerRef,
+		Amount:        amount,
+	})
+	if err != nil {
+		return errors.New("failed to redeem voucher")
+	}
+
+	if err := voucherRepo.AddUsage(voucher.ID, 1); err != nil {
+		return errors.New("failed to redeem voucher")
+	}
+
+	return nil
+}
+
+// release gives back the vouchers redeemed on a cancelled transaction
+func (s *VoucherService) release(tx *gorm.DB, transactionID uint) error {
+	voucherRepo := s.voucherRepo.WithTx(tx)
+
+	redemptions, err := voucherRepo.FindRedemptionsByTransactionID(transactionID)
+	if err != nil {
+		return errors.New("failed to release vouchers")
+	}
+
+	for _, redemption := range redemptions {
+		if err := voucherRepo.AddUsage(redemption.VoucherID, -1); err != nil {
+			return errors.New("failed to release vouchers")
+		}
+	}
+
+	if len(redemptions) > 0 {
+		if err := voucherRepo.DeleteRedemptionsByTransactionID(transactionID); err != nil {
+			return errors.New("failed to release vouchers")
+		}
+	}
+
+	return nil
+}
+
+// checkVoucher validates the validity period and the usage caps
+func (s *VoucherService) checkVoucher(voucherRepo repositories.VoucherRepository, voucher *models.Voucher, customerRef string, now time.Time) error {
+	if !voucher.IsActive {
+		return errors.New("voucher is not active")
+	}
+	if voucher.StartsAt != nil && now.Before(*voucher.StartsAt) {
+		return errors.New("voucher is not valid yet")
+	}
+	if voucher.EndsAt != nil && now.After(*voucher.EndsAt) {
+		return errors.New("voucher has expired")
+	}
+	if voucher.UsageLimit > 0 && voucher.UsedCount >= voucher.UsageLimit {
+		return errors.New("voucher has been fully redeemed")
+	}
+
+	if voucher.PerCustomerLimit > 0 {
+		if customerRef == "" {
+			return errors.New("customer_ref is required for this voucher")
+		}
+		used, err := voucherRepo.CountRedemptionsByCustomer(voucher.ID, customerRef)
+		if err != nil {
+			return errors.New("failed to check voucher usage")
+		}
+		if used >= int64(voucher.PerCustomerLimit) {
+			return errors.New("customer has already used this voucher")
+		}
+	}
+
+	return nil
+}
+
+func (s *VoucherService) mapVoucherToResponse(voucher *models.Voucher) *dto.VoucherResponse {
+	return &dto.VoucherResponse{
+		ID:               voucher.ID,
+		Code:             voucher.Code,
+		Name:             voucher.Name,
+		BatchCode:        voucher.BatchCode,
+		Type:             voucher.Type,
+		DiscountPercent:  voucher.DiscountPercent,
+		DiscountAmount:   voucher.DiscountAmount,
+		MaxDiscount:      voucher.MaxDiscount,
+		MinSubtotal:      voucher.MinSubtotal,
+		StartsAt:         voucher.StartsAt,
+		EndsAt:           voucher.EndsAt,
+		UsageLimit:       voucher.UsageLimit,
+		PerCustomerLimit: voucher.PerCustomerLimit,
+		UsedCount:        voucher.UsedCount,
+		Stackable:        voucher.Stackable,
+		IsActive:         voucher.IsActive,
+		CreatedAt:        voucher.CreatedAt,
+	}
+}
+
+func applyVoucherRequest(voucher *models.Voucher, req *dto.VoucherRequest) {
+	voucher.Name = req.Name
+	voucher.Type = req.Type
+	voucher.DiscountPercent = req.DiscountPercent
+	voucher.DiscountAmount = req.DiscountAmount
+	voucher.MaxDiscount = req.MaxDiscount
+	voucher.MinSubtotal = req.MinSubtotal
+	voucher.StartsAt = req.StartsAt
+	voucher.EndsAt = req.EndsAt
+	voucher.UsageLimit = req.UsageLimit
+	voucher.PerCustomerLimit = req.PerCustomerLimit
+	voucher.Stackable = req.Stackable
+	if req.IsActive != nil {
+		voucher.IsActive = *req.IsActive
+	}
+}
+
+func validateVoucherDiscount(voucherType string, percent float64, amount models.Money, startsAt, endsAt *time.Time) error {
+	if voucherType == "percentage" && percent <= 0 {
+		return errors.New("discount_percent is required for percentage vouchers")
+	}
+	if voucherType == "fixed" && amount <= 0 {
+		return errors.New("discount_amount is required for fixed vouchers")
+	}
+	if startsAt != nil && endsAt != nil && endsAt.Before(*startsAt) {
+		return errors.New("ends_at must be after starts_at")
+	}
+	return nil
+}
+
+// voucherDiscount returns the discount the voucher gives on subtotal
+func voucherDiscount(voucher *models.Voucher, subtotal models.Money) (models.Money, error) {
+	if subtotal < voucher.MinSubtotal {
+		return 0, fmt.Errorf("voucher requires a minimum spend of %d", voucher.MinSubtotal)
+	}
+
+	var amount models.Money
+	switch voucher.Type {
+	case "percentage":
+		amount = subtotal.MulRate(voucher.DiscountPercent / 100)
+		if voucher.MaxDiscount > 0 && amount > voucher.MaxDiscount {
+			amount = voucher.MaxDiscount
+		}
+	case "fixed":
+		amount = voucher.DiscountAmount
+	}
+
+	if amount > subtotal {
+		amount = subtotal
+	}
+	return amount, nil
+}
+
+// normalizeVoucherCode makes codes case-insensitive
+func normalizeVoucherCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func randomVoucherSuffix(length int) (string, error) {
+	max := big.NewInt(int64(len(voucherCodeAlphabet)))
+	suffix := make([]byte, length)
+	for i := range suffix {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		suffix[i] = voucherCodeAlphabet[n.Int64()]
+	}
+	return string(suffix), nil
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/models"
+)
+
+// newVoucherSale sets up a product and voucher and returns a checkout that
+// redeems the voucher for customer
+func newVoucherSale(t *testing.T, voucher models.Voucher) (*fakeDB, *TransactionService, func(customer string) (*dto.TransactionResponse, error)) {
+	db := newFakeDB()
+	db.products[1] = models.Product{ID: 1, Name: "Coffee", Price: 20000, Stock: 100}
+	voucher.ID = 2
+	voucher.Code = "HEMAT"
+	voucher.Type = "fixed"
+	voucher.DiscountAmount = 5000
+	voucher.IsActive = true
+	db.vouchers[voucher.ID] = voucher
+	db.nextID = 2
+	service := newTestTransactionService(t, db)
+
+	checkout := func(customer string) (*dto.TransactionResponse, error) {
+		return service.CreateTransaction(&dto.CreateTransactionRequest{
+			UserID:        1,
+			PaymentMethod: "cash",
+			Items:         []dto.TransactionItemRequest{{ProductID: 1, Quantity: 1}},
+			VoucherCode:   "hemat",
+			CustomerRef:   customer,
+		})
+	}
+	return db, service, checkout
+}
+
+func TestVoucherRedemption(t *testing.T) {
+	t.Run("stops at the usage limit", func(t *testing.T) {
+		db, _, checkout := newVoucherSale(t, models.Voucher{UsageLimit: 2})
+
+		for i := 0; i < 2; i++ {
+			sale, err := checkout("")
+			if err != nil {
+				t.Fatalf("checkout %d: CreateTransaction() error = %v", i, err)
+			}
+			if sale.Discount != 5000 {
+				t.Errorf("checkout %d: discount = %d, want 5000", i, sale.Discount)
+			}
+		}
+		if _, err := checkout(""); err == nil {
+			t.Error("third checkout succeeded, want the voucher to be used up")
+		}
+		if used := db.vouchers[2].UsedCount; used != 2 {
+			t.Errorf("used count = %d, want 2", used)
+		}
+	})
+
+	t.Run("redeems a single-use code once under concurrent checkouts", func(t *testing.T) {
+		db, _, checkout := newVoucherSale(t, models.Voucher{UsageLimit: 1})
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		redeemed := 0
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := checkout(""); err == nil {
+					mu.Lock()
+					redeemed++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		if redeemed != 1 {
+			t.Errorf("%d checkouts redeemed the voucher, want 1", redeemed)
+		}
+		if used := db.vouchers[2].UsedCount; used != 1 {
+			t.Errorf("used count = %d, want 1", used)
+		}
+		if len(db.redemptions) != 1 {
+			t.Errorf("%d redemptions stored, want 1", len(db.redemptions))
+		}
+	})
+
+	t.Run("limits every customer on their own", func(t *testing.T) {
+		_, _, checkout := newVoucherSale(t, models.Voucher{PerCustomerLimit: 1})
+
+		if _, err := checkout("0811"); err != nil {
+			t.Fatalf("first checkout of customer 0811: CreateTransaction() error = %v", err)
+		}
+		if _, err := checkout("0811"); err == nil {
+			t.Error("second checkout of customer 0811 succeeded, want the per-customer limit to apply")
+		}
+		if _, err := checkout("0822"); err != nil {
+			t.Errorf("checkout of customer 0822: CreateTransaction() error = %v", err)
+		}
+		if _, err := checkout(""); err == nil {
+			t.Error("checkout without customer_ref succeeded, want an error")
+		}
+	})
+
+	t.Run("cancelling gives the use back", func(t *testing.T) {
+		db, service, checkout := newVoucherSale(t, models.Voucher{UsageLimit: 1, PerCustomerLimit: 1})
+
+		sale, err := checkout("0811")
+		if err != nil {
+			t.Fatalf("CreateTransaction() error = %v", err)
+		}
+		if err := service.CancelTransaction(sale.ID, 1); err != nil {
+			t.Fatalf("CancelTransaction() error = %v", err)
+		}
+		if used := db.vouchers[2].UsedCount; used != 0 {
+			t.Errorf("used count after cancel = %d, want 0", used)
+		}
+		if len(db.redemptions) != 0 {
+			t.Errorf("%d redemptions left after cancel, want none", len(db.redemptions))
+		}
+
+		if _, err := checkout("0811"); err != nil {
+			t.Errorf("checkout after cancel: CreateTransaction() error = %v", err)
+		}
+	})
+}
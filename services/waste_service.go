@@ -0,0 +1,361 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/models"
+	"github.com/syrlramadhan/cashier-app/repositories"
+	"gorm.io/gorm"
+)
+
+// WasteService records stock that is thrown away instead of sold and values
+// it at cost, so shrinkage can be reported next to revenue.
+type WasteService struct {
+	db                *gorm.DB
+	wasteRepo         repositories.WasteRepository
+	productRepo       repositories.ProductRepository
+	variantRepo       repositories.ProductVariantRepository
+	ingredientRepo    repositories.IngredientRepository
+	transactionRepo   repositories.TransactionRepository
+	ingredientService *IngredientService
+	stockService      *StockService
+}
+
+func NewWasteService(
+	db *gorm.DB,
+	wasteRepo repositories.WasteRepository,
+	productRepo repositories.ProductRepository,
+	variantRepo repositories.ProductVariantRepository,
+	ingredientRepo repositories.IngredientRepository,
+	transactionRepo repositories.TransactionRepository,
+	ingredientService *IngredientService,
+	stockService *StockService,
+) *WasteService {
+	return &WasteService{
+		db:                db,
+		wasteRepo:         wasteRepo,
+		productRepo:       productRepo,
+		variantRepo:       variantRepo,
+		ingredientRepo:    ingredientRepo,
+		transactionRepo:   transactionRepo,
+		ingredientService: ingredientService,
+		stockService:      stockService,
+	}
+}
+
+func (s *WasteService) GetWasteEntries(startDate, endDate *time.Time, reason string) ([]dto.WasteEntryResponse, error) {
+	entries, err := s.wasteRepo.FindAll(startDate, endDate, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	response := []dto.WasteEntryResponse{}
+	for _, entry := range entries {
+		response = append(response, toWasteEntryResponse(&entry))
+	}
+	return response, nil
+}
+
+// RecordWaste takes the wasted quantity out of stock and records what it
+// cost. A product made to a recipe uses up its ingredients; any other
+// product goes out of product (or variant) stock through the ledger.
+func (s *WasteService) RecordWaste(req *dto.WasteRequest) (*dto.WasteEntryResponse, error) {
+	if req.ProductID != nil && req.IngredientID != nil {
+		return nil, errors.New("choose either a product or an ingredient")
+	}
+	if req.VariantID != nil && req.ProductID == nil {
+		return nil, errors.New("variant_id needs a product_id")
+	}
+
+	entry := &models.WasteEntry{
+		ProductID:    req.ProductID,
+		VariantID:    req.VariantID,
+		IngredientID: req.IngredientID,
+		Reason:       req.Reason,
+		Note:         req.Note,
+		UserID:       req.UserID,
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if req.IngredientID != nil {
+			return s.wasteIngredient(tx, entry, req.Quantity)
+		}
+		return s.wasteProduct(tx, entry, req.Quantity)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if entry.ProductID != nil && !entry.FromRecipe {
+		s.stockService.publishAlerts()
+	}
+
+	entry, err = s.wasteRepo.FindByID(entry.ID)
+	if err != nil {
+		return nil, errors.New("waste entry not found")
+	}
+
+	response := toWasteEntryResponse(entry)
+	return &response, nil
+}
+
+func (s *WasteService) wasteProduct(tx *gorm.DB, entry *models.WasteEntry, quantity float64) error {
+	if quantity != math.Trunc(quantity) {
+		return errors.New("products are wasted in whole units")
+	}
+	units := int(quantity)
+
+	products, err := s.productRepo.WithTx(tx).FindByIDsForUpdate([]uint{*entry.ProductID})
+	if err != nil {
+		return errors.New("failed to lock product")
+	}
+	if len(products) == 0 {
+		return errors.New("product not found")
+	}
+	product := products[0]
+
+	var variant *models.ProductVariant
+	entry.ItemName = product.Name
+	if entry.VariantID != nil {
+		variants, err := s.variantRepo.WithTx(tx).FindByIDsForUpdate([]uint{*entry.VariantID})
+		if err != nil {
+			return errors.New("failed to lock variant")
+		}
+		if len(variants) == 0 || variants[0].ProductID != product.ID {
+			return errors.New("variant not found")
+		}
+		variant = &variants[0]
+		entry.ItemName = product.Name + " (" + variant.Name + ")"
+	}
+
+	book, err := s.ingredientService.recipeBook(tx, []dto.TransactionItemRequest{{ProductID: product.ID, VariantID: entry.VariantID}})
+	if err != nil {
+		return err
+	}
+	perUnit, fromRecipe := book.forLine(product.ID, variant, nil)
+
+	entry.Quantity = float64(units)
+	entry.Unit = "pcs"
+	entry.FromRecipe = fromRecipe
+
+	if fromRecipe {
+		needed := make(map[uint]float64, len(perUnit))
+		for id, qty := range perUnit {
+			needed[id] = qty * float64(units)
+		}
+		ingredients, err := s.ingredientService.lockIngredients(tx, needed)
+		if err != nil {
+			return err
+		}
+
+		for _, id := range sortedIngredientIDs(needed) {
+			if err := s.useIngredient(tx, entry, ingredients[id], needed[id]); err != nil {
+				return err
+			}
+		}
+		return s.createEntry(tx, entry)
+	}
+
+	entry.Cost = product.Cost.Mul(units)
+	if err := s.createEntry(tx, entry); err != nil {
+		return err
+	}
+
+	// A variant without its own stock sells out of the product
+	var variantID *uint
+	if variant != nil && variant.TrackStock {
+		variantID = entry.VariantID
+	}
+	return s.stockService.move(tx, models.StockMovement{
+		ProductID:   product.ID,
+		VariantID:   variantID,
+		Quantity:    -units,
+		Reason:      "waste",
+		ReferenceID: &entry.ID,
+		UserID:      &entry.UserID,
+		Note:        entry.Reason,
+	})
+}
+
+func (s *WasteService) wasteIngredient(tx *gorm.DB, entry *models.WasteEntry, quantity float64) error {
+	quantity = roundQuantity(quantity)
+	if quantity <= 0 {
+		return errors.New("quantity must be greater than zero")
+	}
+
+	ingredients, err := s.ingredientService.lockIngredients(tx, map[uint]float64{*entry.IngredientID: quantity})
+	if err != nil {
+		return err
+	}
+	ingredient := ingredients[*entry.IngredientID]
+
+	entry.ItemName = ingredient.Name
+	entry.Quantity = quantity
+	entry.Unit = ingredient.Unit
+
+	if err := s.useIngredient(tx, entry, ingredient, quantity); err != nil {
+		return err
+	}
+	return s.createEntry(tx, entry)
+}
+
+// useIngredient takes quantity of a locked ingredient out of stock, adds its
+// cost to the entry and keeps the quantity with the entry
+func (s *WasteService) useIngredient(tx *gorm.DB, entry *models.WasteEntry, ingredient models.Ingredient, quantity float64) error {
+	if err := s.ingredientRepo.WithTx(tx).UpdateStock(ingredient.ID, roundQuantity(ingredient.Stock-quantity)); err != nil {
+		return errors.New("failed to update ingredient stock")
+	}
+
+	cost := models.Money(math.Round(float64(ingredient.Cost) * quantity))
+	entry.Cost += cost
+	entry.Ingredients = append(entry.Ingredients, models.WasteEntryIngredient{
+		IngredientID:   ingredient.ID,
+		IngredientName: ingredient.Name,
+		Quantity:       quantity,
+		Unit:           ingredient.Unit,
+		Cost:           cost,
+	})
+	return nil
+}
+
+func (s *WasteService) createEntry(tx *gorm.DB, entry *models.WasteEntry) error {
+	if err := s.wasteRepo.WithTx(tx).Create(entry); err != nil {
+		return errors.New("failed to record waste")
+	}
+	return nil
+}
+
+// GetWasteReport totals the waste of a period by reason, by item and by day,
+// next to the net revenue of the same period
+func (s *WasteService) GetWasteReport(startDate, endDate time.Time) (*dto.WasteReportResponse, error) {
+	entries, err := s.wasteRepo.FindAll(&startDate, &endDate, "")
+	if err != nil {
+		return nil, err
+	}
+
+	revenue, err := s.transactionRepo.GetTotalRevenue(startDate, endDate, "")
+	if err != nil {
+		return nil, err
+	}
+
+	report := &dto.WasteReportResponse{
+		StartDate: startDate,
+		EndDate:   endDate,
+		Revenue:   revenue,
+		ByReason:  []dto.WasteReasonSummary{},
+		ByItem:    []dto.WasteItemSummary{},
+		ByDay:     []dto.WasteDailySummary{},
+	}
+
+	type itemKey struct {
+		productID, variantID, ingredientID uint
+	}
+	reasons := make(map[string]*dto.WasteReasonSummary)
+	items := make(map[itemKey]*dto.WasteItemSummary)
+	days := make(map[string]models.Money)
+	var reasonOrder []string
+	var itemOrder []itemKey
+	for _, entry := range entries {
+		report.TotalCost += entry.Cost
+
+		reason, ok := reasons[entry.Reason]
+		if !ok {
+			reason = &dto.WasteReasonSummary{Reason: entry.Reason}
+			reasons[entry.Reason] = reason
+			reasonOrder = append(reasonOrder, entry.Reason)
+		}
+		reason.EntryCount++
+		reason.Cost += entry.Cost
+
+		var key itemKey
+		if entry.ProductID != nil {
+			key.productID = *entry.ProductID
+		}
+		if entry.VariantID != nil {
+			key.variantID = *entry.VariantID
+		}
+		if entry.IngredientID != nil {
+			key.ingredientID = *entry.IngredientID
+		}
+		item, ok := items[key]
+		if !ok {
+			// Entries come newest first, so the item keeps its latest name
+			item = &dto.WasteItemSummary{
+				ProductID:    entry.ProductID,
+				VariantID:    entry.VariantID,
+				IngredientID: entry.IngredientID,
+				ItemName:     entry.ItemName,
+				Unit:         entry.Unit,
+			}
+			items[key] = item
+			itemOrder = append(itemOrder, key)
+		}
+		item.Quantity = roundQuantity(item.Quantity + entry.Quantity)
+		item.EntryCount++
+		item.Cost += entry.Cost
+
+		days[entry.CreatedAt.Format("2006-01-02")] += entry.Cost
+	}
+
+	for _, key := range reasonOrder {
+		report.ByReason = append(report.ByReason, *reasons[key])
+	}
+	sort.SliceStable(report.ByReason, func(i, j int) bool { return report.ByReason[i].Cost > report.ByReason[j].Cost })
+
+	for _, key := range itemOrder {
+		report.ByItem = append(report.ByItem, *items[key])
+	}
+	sort.SliceStable(report.ByItem, func(i, j int) bool { return report.ByItem[i].Cost > report.ByItem[j].Cost })
+
+	dailyRevenue, err := s.transactionRepo.GetDailyNetRevenue(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	revenues := make(map[string]models.Money)
+	for _, day := range dailyRevenue {
+		revenues[day.Date.Format("2006-01-02")] += day.Amount
+	}
+
+	for day := time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, startDate.Location()); !day.After(endDate); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		report.ByDay = append(report.ByDay, dto.WasteDailySummary{Date: date, Cost: days[date], Revenue: revenues[date]})
+	}
+
+	if revenue > 0 {
+		report.WastePercent = math.Round(float64(report.TotalCost)/float64(revenue)*10000) / 100
+	}
+	return report, nil
+}
+
+func toWasteEntryResponse(entry *models.WasteEntry) dto.WasteEntryResponse {
+	var ingredients []dto.WasteEntryIngredientResponse
+	for _, ingredient := range entry.Ingredients {
+		ingredients = append(ingredients, dto.WasteEntryIngredientResponse{
+			IngredientID:   ingredient.IngredientID,
+			IngredientName: ingredient.IngredientName,
+			Quantity:       ingredient.Quantity,
+			Unit:           ingredient.Unit,
+			Cost:           ingredient.Cost,
+		})
+	}
+
+	return dto.WasteEntryResponse{
+		ID:           entry.ID,
+		ProductID:    entry.ProductID,
+		VariantID:    entry.VariantID,
+		IngredientID: entry.IngredientID,
+		ItemName:     entry.ItemName,
+		Quantity:     entry.Quantity,
+		Unit:         entry.Unit,
+		Reason:       entry.Reason,
+		Cost:         entry.Cost,
+		FromRecipe:   entry.FromRecipe,
+		Note:         entry.Note,
+		UserName:     entry.User.Name,
+		CreatedAt:    entry.CreatedAt,
+		Ingredients:  ingredients,
+	}
+}
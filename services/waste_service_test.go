@@ -0,0 +1,117 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/syrlramadhan/cashier-app/dto"
+	"github.com/syrlramadhan/cashier-app/models"
+)
+
+func TestRecordWaste(t *testing.T) {
+	latte := uint(1)
+	id := func(id uint) *uint { return &id }
+	newService := func(t *testing.T) (*fakeDB, *WasteService) {
+		db := newFakeDB()
+		db.products[1] = models.Product{ID: 1, Name: "Latte"}
+		db.products[2] = models.Product{ID: 2, Name: "Croissant", Stock: 10, Cost: 8000}
+		db.ingredients[1] = models.Ingredient{ID: 1, Name: "Espresso Beans", Unit: "g", Stock: 1000, Cost: 200}
+		db.ingredients[2] = models.Ingredient{ID: 2, Name: "Milk", Unit: "ml", Stock: 2000, Cost: 20}
+		db.recipes = []models.RecipeItem{
+			{ID: 1, ProductID: &latte, IngredientID: 1, Quantity: 18},
+			{ID: 2, ProductID: &latte, IngredientID: 2, Quantity: 150},
+		}
+		db.nextID = 100
+
+		productRepo := &fakeProductRepository{db: db}
+		variantRepo := &fakeProductVariantRepository{db: db}
+		ingredientRepo := &fakeIngredientRepository{db: db}
+		return db, NewWasteService(
+			db.open(t),
+			&fakeWasteRepository{db: db},
+			productRepo,
+			variantRepo,
+			ingredientRepo,
+			nil,
+			NewIngredientService(nil, ingredientRepo, nil, nil, nil),
+			newTestStockService(db),
+		)
+	}
+
+	type usage struct {
+		ingredientID uint
+		quantity     float64
+		cost         models.Money
+	}
+
+	tests := []struct {
+		name          string
+		req           dto.WasteRequest
+		wantCost      models.Money
+		wantUsage     []usage
+		wantStock     map[uint]float64 // Ingredient stock after the waste
+		wantMovements int
+	}{
+		{
+			name:      "ingredient",
+			req:       dto.WasteRequest{IngredientID: id(2), Quantity: 250, Reason: "spoiled"},
+			wantCost:  5000,
+			wantUsage: []usage{{ingredientID: 2, quantity: 250, cost: 5000}},
+			wantStock: map[uint]float64{1: 1000, 2: 1750},
+		},
+		{
+			name:     "product made to a recipe",
+			req:      dto.WasteRequest{ProductID: id(1), Quantity: 2, Reason: "spilled"},
+			wantCost: 13200,
+			wantUsage: []usage{
+				{ingredientID: 1, quantity: 36, cost: 7200},
+				{ingredientID: 2, quantity: 300, cost: 6000},
+			},
+			wantStock: map[uint]float64{1: 964, 2: 1700},
+		},
+		{
+			name:          "stocked product",
+			req:           dto.WasteRequest{ProductID: id(2), Quantity: 3, Reason: "expired"},
+			wantCost:      24000,
+			wantStock:     map[uint]float64{1: 1000, 2: 2000},
+			wantMovements: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, service := newService(t)
+			tt.req.UserID = 1
+
+			entry, err := service.RecordWaste(&tt.req)
+			if err != nil {
+				t.Fatalf("RecordWaste() error = %v", err)
+			}
+
+			if entry.Cost != tt.wantCost {
+				t.Errorf("cost = %d, want %d", entry.Cost, tt.wantCost)
+			}
+			if len(entry.Ingredients) != len(tt.wantUsage) {
+				t.Fatalf("ingredients = %+v, want %+v", entry.Ingredients, tt.wantUsage)
+			}
+			for i, want := range tt.wantUsage {
+				got := entry.Ingredients[i]
+				if got.IngredientID != want.ingredientID || got.Quantity != want.quantity || got.Cost != want.cost {
+					t.Errorf("ingredient %d = %+v, want %+v", i, got, want)
+				}
+			}
+			for ingredientID, want := range tt.wantStock {
+				if stock := db.ingredients[ingredientID].Stock; stock != want {
+					t.Errorf("ingredient %d stock = %v, want %v", ingredientID, stock, want)
+				}
+			}
+			if len(db.movements) != tt.wantMovements {
+				t.Fatalf("%d stock movements, want %d", len(db.movements), tt.wantMovements)
+			}
+			for _, movement := range db.movements {
+				if movement.Reason != "waste" || movement.ReferenceID == nil || *movement.ReferenceID != entry.ID {
+					t.Errorf("movement = %+v, want a waste movement of entry %d", movement, entry.ID)
+				}
+			}
+		})
+	}
+}